@@ -0,0 +1,79 @@
+package alerting
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+)
+
+// apiURL returns the URL of the API path on the Grafana server at base. The path must be escaped.
+func apiURL(base, path string, query url.Values) (string, error) {
+	if base == "" {
+		base = "http://localhost:3000"
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("%v: %w", "invalid Grafana URL", err)
+	}
+	u.RawPath = strings.TrimSuffix(u.EscapedPath(), "/") + path
+	if u.Path, err = url.PathUnescape(u.RawPath); err != nil {
+		return "", err
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// doRequest sends a request to the Grafana API with the token, organization and TLS options given by the flags of
+// the command. It returns the body of the response, or an error if the status of the response is not expected.
+func doRequest(c utils.CommandLine, method, u, contentType string, body io.Reader, expected ...int) ([]byte, error) {
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token := c.String("token"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if orgID := c.Int("org-id"); orgID > 0 {
+		req.Header.Set("X-Grafana-Org-Id", strconv.Itoa(orgID))
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			// nolint:gosec
+			// The insecure flag is set explicitly by the user to skip TLS verification.
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: c.Bool("insecure")},
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Warnf("Failed to close response body: %s\n", err)
+		}
+	}()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "failed to read the response", err)
+	}
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+}
@@ -0,0 +1,51 @@
+package alerting
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+)
+
+const exportPath = "/api/v1/provisioning/export"
+
+// ExportCommand downloads the alert rules, contact points, notification policies, mute timings and templates
+// of an organization from a running Grafana server in the format of alerting file provisioning. The export
+// is written to the file given by the output flag, or to stdout if there is none.
+func ExportCommand(c utils.CommandLine) error {
+	format := c.String("format")
+	if format == "" {
+		format = "yaml"
+	}
+	if format != "yaml" && format != "json" {
+		return fmt.Errorf("unsupported export format '%s', expected yaml or json", format)
+	}
+
+	q := url.Values{}
+	q.Set("format", format)
+	if c.Bool("decrypt") {
+		q.Set("decrypt", "true")
+	}
+	u, err := apiURL(c.String("url"), exportPath, q)
+	if err != nil {
+		return err
+	}
+	body, err := doRequest(c, http.MethodGet, u, "", nil, http.StatusOK)
+	if err != nil {
+		return fmt.Errorf("%v: %w", "export failed", err)
+	}
+
+	output := c.String("output")
+	if output == "" || output == "-" {
+		_, err = os.Stdout.Write(body)
+		return err
+	}
+	if err := os.WriteFile(output, body, 0640); err != nil {
+		return fmt.Errorf("%v: %w", "failed to write the export", err)
+	}
+	logger.Infof("Alerting resources exported to %s\n", output)
+	return nil
+}
@@ -0,0 +1,76 @@
+package alerting
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/commandstest"
+)
+
+func TestExportCommand(t *testing.T) {
+	export := "apiVersion: 1\n"
+
+	t.Run("it writes the export to the output file", func(t *testing.T) {
+		var req *http.Request
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req = r
+			_, _ = w.Write([]byte(export))
+		}))
+		t.Cleanup(server.Close)
+		output := filepath.Join(t.TempDir(), "alerting.yaml")
+		c, err := commandstest.NewCliContext(map[string]string{
+			"url":     server.URL + "/grafana/",
+			"token":   "secret",
+			"org-id":  "2",
+			"decrypt": "true",
+			"output":  output,
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, ExportCommand(c))
+
+		require.Equal(t, "/grafana/api/v1/provisioning/export", req.URL.Path)
+		require.Equal(t, "yaml", req.URL.Query().Get("format"))
+		require.Equal(t, "true", req.URL.Query().Get("decrypt"))
+		require.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+		require.Equal(t, "2", req.Header.Get("X-Grafana-Org-Id"))
+		b, err := os.ReadFile(output)
+		require.NoError(t, err)
+		require.Equal(t, export, string(b))
+	})
+
+	t.Run("it returns the error of the server", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"permission denied"}`))
+		}))
+		t.Cleanup(server.Close)
+		output := filepath.Join(t.TempDir(), "alerting.json")
+		c, err := commandstest.NewCliContext(map[string]string{
+			"url":    server.URL,
+			"format": "json",
+			"output": output,
+		})
+		require.NoError(t, err)
+
+		err = ExportCommand(c)
+
+		require.ErrorContains(t, err, "status 403")
+		require.ErrorContains(t, err, "permission denied")
+		require.NoFileExists(t, output)
+	})
+
+	t.Run("it rejects unknown formats", func(t *testing.T) {
+		c, err := commandstest.NewCliContext(map[string]string{
+			"format": "xml",
+		})
+		require.NoError(t, err)
+
+		require.ErrorContains(t, ExportCommand(c), "unsupported export format")
+	})
+}
@@ -0,0 +1,106 @@
+package alerting
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+)
+
+const importPrometheusPath = "/api/ruler/grafana/api/v1/import/prometheus/"
+
+// promRuleFile is a Prometheus rule file. The rule files of mimirtool and cortextool also have a namespace.
+type promRuleFile struct {
+	Namespace string          `yaml:"namespace" json:"-"`
+	Groups    []promRuleGroup `yaml:"groups" json:"groups"`
+}
+
+type promRuleGroup struct {
+	Name     string     `yaml:"name" json:"name"`
+	Interval string     `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Rules    []promRule `yaml:"rules" json:"rules"`
+}
+
+type promRule struct {
+	Alert       string            `yaml:"alert,omitempty" json:"alert,omitempty"`
+	Record      string            `yaml:"record,omitempty" json:"record,omitempty"`
+	Expr        string            `yaml:"expr" json:"expr"`
+	For         string            `yaml:"for,omitempty" json:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+}
+
+// ImportPrometheusCommand converts the rule groups of Prometheus rule files to Grafana-managed rule groups that
+// query a Prometheus data source, and creates or updates them in a folder of a running Grafana server. The folder
+// is given by the folder flag or, for the rule files of mimirtool and cortextool, by the namespace of the file.
+// With the dry-run flag, the converted rule groups are written to stdout instead of being saved.
+func ImportPrometheusCommand(c utils.CommandLine) error {
+	datasourceUID := c.String("datasource-uid")
+	if datasourceUID == "" {
+		return errors.New("the UID of the Prometheus data source is required")
+	}
+	files := c.Args().Slice()
+	if len(files) == 0 {
+		return errors.New("no rule files to import")
+	}
+	for _, file := range files {
+		if err := importRuleFile(c, file, datasourceUID); err != nil {
+			return fmt.Errorf("failed to import %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+func importRuleFile(c utils.CommandLine, file, datasourceUID string) error {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the path is given explicitly by the user.
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var rules promRuleFile
+	if err := yaml.Unmarshal(b, &rules); err != nil {
+		return fmt.Errorf("%v: %w", "invalid rule file", err)
+	}
+	folder := c.String("folder")
+	if folder == "" {
+		folder = rules.Namespace
+	}
+	if folder == "" {
+		return errors.New("the folder is required for rule files without a namespace")
+	}
+
+	q := url.Values{}
+	q.Set("datasourceUID", datasourceUID)
+	dryRun := c.Bool("dry-run")
+	if dryRun {
+		q.Set("dryRun", "true")
+	}
+	u, err := apiURL(c.String("url"), importPrometheusPath+url.PathEscape(folder), q)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	resp, err := doRequest(c, http.MethodPost, u, "application/json", bytes.NewReader(body), http.StatusOK, http.StatusAccepted)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		_, err = os.Stdout.Write(resp)
+		return err
+	}
+	logger.Infof("Imported %d rule groups from %s to folder %s\n", len(rules.Groups), file, folder)
+	return nil
+}
@@ -0,0 +1,151 @@
+package alerting
+
+import (
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+)
+
+const ruleFile = `namespace: infra
+groups:
+  - name: node
+    interval: 1m
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+        for: 5m
+        labels:
+          severity: critical
+        annotations:
+          summary: "{{ $labels.instance }} is down"
+      - record: job:up:sum
+        expr: sum by (job) (up)
+`
+
+func TestImportPrometheusCommand(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(ruleFile), 0600))
+
+	type request struct {
+		path  string
+		query map[string][]string
+		body  string
+	}
+	newServer := func(t *testing.T, status int, response string) (string, *[]request) {
+		var requests []request
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			requests = append(requests, request{path: r.URL.EscapedPath(), query: r.URL.Query(), body: string(b)})
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(response))
+		}))
+		t.Cleanup(server.Close)
+		return server.URL, &requests
+	}
+
+	t.Run("it imports the rule groups to the folder of the namespace", func(t *testing.T) {
+		u, requests := newServer(t, http.StatusAccepted, `{"groups":[]}`)
+		c := newCliContextWithArgs(t, map[string]string{
+			"url":            u,
+			"datasource-uid": "prometheus-uid",
+		}, file)
+
+		require.NoError(t, ImportPrometheusCommand(c))
+
+		require.Len(t, *requests, 1)
+		req := (*requests)[0]
+		require.Equal(t, "/api/ruler/grafana/api/v1/import/prometheus/infra", req.path)
+		require.Equal(t, []string{"prometheus-uid"}, req.query["datasourceUID"])
+		require.NotContains(t, req.query, "dryRun")
+		require.JSONEq(t, `{
+			"groups": [{
+				"name": "node",
+				"interval": "1m",
+				"rules": [{
+					"alert": "InstanceDown",
+					"expr": "up == 0",
+					"for": "5m",
+					"labels": {"severity": "critical"},
+					"annotations": {"summary": "{{ $labels.instance }} is down"}
+				}, {
+					"record": "job:up:sum",
+					"expr": "sum by (job) (up)"
+				}]
+			}]
+		}`, req.body)
+	})
+
+	t.Run("the folder flag takes precedence over the namespace", func(t *testing.T) {
+		u, requests := newServer(t, http.StatusOK, `{"groups":[],"dryRun":true}`)
+		c := newCliContextWithArgs(t, map[string]string{
+			"url":            u,
+			"datasource-uid": "prometheus-uid",
+			"folder":         "Node/alerts",
+			"dry-run":        "true",
+		}, file)
+
+		require.NoError(t, ImportPrometheusCommand(c))
+
+		require.Len(t, *requests, 1)
+		require.Equal(t, "/api/ruler/grafana/api/v1/import/prometheus/Node%2Falerts", (*requests)[0].path)
+		require.Equal(t, []string{"true"}, (*requests)[0].query["dryRun"])
+	})
+
+	t.Run("it returns the error of the server", func(t *testing.T) {
+		u, _ := newServer(t, http.StatusBadRequest, `{"message":"invalid rule group 'node'"}`)
+		c := newCliContextWithArgs(t, map[string]string{
+			"url":            u,
+			"datasource-uid": "prometheus-uid",
+		}, file)
+
+		err := ImportPrometheusCommand(c)
+
+		require.ErrorContains(t, err, "status 400")
+		require.ErrorContains(t, err, "invalid rule group 'node'")
+	})
+
+	t.Run("it requires a folder for rule files without a namespace", func(t *testing.T) {
+		u, requests := newServer(t, http.StatusAccepted, `{"groups":[]}`)
+		plain := filepath.Join(t.TempDir(), "plain.yaml")
+		require.NoError(t, os.WriteFile(plain, []byte("groups: []\n"), 0600))
+		c := newCliContextWithArgs(t, map[string]string{
+			"url":            u,
+			"datasource-uid": "prometheus-uid",
+		}, plain)
+
+		require.ErrorContains(t, ImportPrometheusCommand(c), "the folder is required")
+		require.Empty(t, *requests)
+	})
+
+	t.Run("it requires rule files", func(t *testing.T) {
+		c := newCliContextWithArgs(t, map[string]string{
+			"datasource-uid": "prometheus-uid",
+		})
+
+		require.ErrorContains(t, ImportPrometheusCommand(c), "no rule files to import")
+	})
+}
+
+func newCliContextWithArgs(t *testing.T, flags map[string]string, args ...string) utils.CommandLine {
+	t.Helper()
+	flagSet := flag.NewFlagSet("Test", 0)
+	for name, value := range flags {
+		flagSet.String(name, "", "")
+		require.NoError(t, flagSet.Set(name, value))
+	}
+	require.NoError(t, flagSet.Parse(args))
+	return &utils.ContextCommandLine{
+		Context: cli.NewContext(&cli.App{Name: "Test"}, flagSet, nil),
+	}
+}
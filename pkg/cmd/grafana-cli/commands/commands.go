@@ -8,6 +8,7 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/alerting"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/datamigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/secretsmigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
@@ -105,6 +106,13 @@ func runPluginCommand(command func(commandLine utils.CommandLine) error) func(co
 	}
 }
 
+func runAlertingCommand(command func(commandLine utils.CommandLine) error) func(context *cli.Context) error {
+	return func(context *cli.Context) error {
+		cmd := &utils.ContextCommandLine{Context: context}
+		return command(cmd)
+	}
+}
+
 // Command contains command state.
 type Command struct {
 	Client utils.ApiClient
@@ -258,6 +266,78 @@ grafana-cli user-manager conflicts ingest-file <filepath>
 	},
 }
 
+var alertingCommands = []*cli.Command{
+	{
+		Name:   "export",
+		Usage:  "export alert rules, contact points, notification policies, mute timings and templates as a provisioning file",
+		Action: runAlertingCommand(alerting.ExportCommand),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "url",
+				Usage: "URL of the Grafana server",
+				Value: "http://localhost:3000",
+			},
+			&cli.StringFlag{
+				Name:    "token",
+				Usage:   "Service account or API key token used to authenticate to Grafana",
+				EnvVars: []string{"GF_API_TOKEN"},
+			},
+			&cli.IntFlag{
+				Name:  "org-id",
+				Usage: "ID of the organization to export, the organization of the token if not set",
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "Format of the provisioning file, yaml or json",
+				Value: "yaml",
+			},
+			&cli.BoolFlag{
+				Name:  "decrypt",
+				Usage: "Include the values of secure settings of contact points instead of redacting them",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "Path of the file to write the export to, stdout if not set",
+			},
+		},
+	},
+	{
+		Name:      "import-prometheus",
+		Usage:     "convert the rule groups of Prometheus rule files to Grafana-managed alert rules and recording rules",
+		ArgsUsage: "<rule file>...",
+		Action:    runAlertingCommand(alerting.ImportPrometheusCommand),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "url",
+				Usage: "URL of the Grafana server",
+				Value: "http://localhost:3000",
+			},
+			&cli.StringFlag{
+				Name:    "token",
+				Usage:   "Service account or API key token used to authenticate to Grafana",
+				EnvVars: []string{"GF_API_TOKEN"},
+			},
+			&cli.IntFlag{
+				Name:  "org-id",
+				Usage: "ID of the organization to import the rules to, the organization of the token if not set",
+			},
+			&cli.StringFlag{
+				Name:     "datasource-uid",
+				Usage:    "UID of the Prometheus data source the rules query",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "folder",
+				Usage: "Title of the folder to import the rules to, the namespace of the rule file if not set",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Write the converted rule groups to stdout instead of saving them",
+			},
+		},
+	},
+}
+
 var Commands = []*cli.Command{
 	{
 		Name:        "plugins",
@@ -269,4 +349,9 @@ var Commands = []*cli.Command{
 		Usage:       "Grafana admin commands",
 		Subcommands: adminCommands,
 	},
+	{
+		Name:        "alerting",
+		Usage:       "Grafana Alerting commands",
+		Subcommands: alertingCommands,
+	},
 }
@@ -0,0 +1,176 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+const (
+	// AnomalyOutputScore outputs the anomaly score, the distance from the baseline in standard deviations.
+	AnomalyOutputScore = "score"
+	// AnomalyOutputBoolean outputs 1 if the absolute anomaly score is greater than the threshold, and 0 otherwise.
+	AnomalyOutputBoolean = "boolean"
+
+	// AnomalyModePoint outputs a series with a value for each point of the input series.
+	AnomalyModePoint = "point"
+	// AnomalyModeSeries outputs a number for each input series with the value for its last point.
+	AnomalyModeSeries = "series"
+
+	defaultAnomalyThreshold = 3
+)
+
+var (
+	supportedAnomalyMethods = []string{mathexp.BaselineStdDev, mathexp.BaselineMAD}
+	supportedAnomalyOutputs = []string{AnomalyOutputScore, AnomalyOutputBoolean}
+	supportedAnomalyModes   = []string{AnomalyModeSeries, AnomalyModePoint}
+)
+
+// AnomalyCommand is an expression command that detects points of a series that deviate from
+// a rolling baseline computed over the window before each point.
+type AnomalyCommand struct {
+	VarToDetect string
+	Window      time.Duration
+	Method      string
+	Output      string
+	Threshold   float64
+	Mode        string
+	refID       string
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand. Empty method, output and mode default to stddev, score and series.
+func NewAnomalyCommand(refID, varToDetect string, window time.Duration, method, output string, threshold float64, mode string) (*AnomalyCommand, error) {
+	if method == "" {
+		method = mathexp.BaselineStdDev
+	}
+	if output == "" {
+		output = AnomalyOutputScore
+	}
+	if mode == "" {
+		mode = AnomalyModeSeries
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("anomaly window must be positive, got %s", window)
+	}
+	if !mathexp.IsSupportedBaseline(method) {
+		return nil, fmt.Errorf("expected anomaly method to be one of %s, got %s", strings.Join(supportedAnomalyMethods, ", "), method)
+	}
+	if !isOneOf(output, supportedAnomalyOutputs) {
+		return nil, fmt.Errorf("expected anomaly output to be one of %s, got %s", strings.Join(supportedAnomalyOutputs, ", "), output)
+	}
+	if !isOneOf(mode, supportedAnomalyModes) {
+		return nil, fmt.Errorf("expected anomaly mode to be one of %s, got %s", strings.Join(supportedAnomalyModes, ", "), mode)
+	}
+	if threshold < 0 || math.IsNaN(threshold) {
+		return nil, fmt.Errorf("anomaly threshold must not be negative")
+	}
+	return &AnomalyCommand{
+		VarToDetect: varToDetect,
+		Window:      window,
+		Method:      method,
+		Output:      output,
+		Threshold:   threshold,
+		Mode:        mode,
+		refID:       refID,
+	}, nil
+}
+
+type anomalyCommandJSON struct {
+	Expression string   `json:"expression"`
+	Window     string   `json:"window"`
+	Method     string   `json:"method"`
+	Output     string   `json:"output"`
+	Threshold  *float64 `json:"threshold"`
+	Mode       string   `json:"mode"`
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	jsonFromM, err := json.Marshal(rn.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remarshal anomaly expression body: %w", err)
+	}
+	var cmd anomalyCommandJSON
+	if err = json.Unmarshal(jsonFromM, &cmd); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal remarshaled anomaly expression body: %w", err)
+	}
+	if cmd.Expression == "" {
+		return nil, fmt.Errorf("no variable specified to reference for refId %v", rn.RefID)
+	}
+	if cmd.Window == "" {
+		return nil, fmt.Errorf("no time duration specified for the window in anomaly command")
+	}
+	window, err := gtime.ParseDuration(cmd.Window)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse anomaly window: %w", err)
+	}
+	threshold := float64(defaultAnomalyThreshold)
+	if cmd.Threshold != nil {
+		threshold = *cmd.Threshold
+	}
+	return NewAnomalyCommand(rn.RefID, strings.TrimPrefix(cmd.Expression, "$"), window, cmd.Method, cmd.Output, threshold, cmd.Mode)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.VarToDetect}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AnomalyCommand) Execute(_ context.Context, _ time.Time, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[ac.VarToDetect].Values {
+		var series mathexp.Series
+		switch v := val.(type) {
+		case mathexp.Series:
+			series = v
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v)
+			continue
+		default:
+			return newRes, fmt.Errorf("can only detect anomalies in type series, got type %v", val.Type())
+		}
+
+		scores, err := series.AnomalyScores(ac.refID, ac.Window, ac.Method)
+		if err != nil {
+			return newRes, err
+		}
+		if ac.Output == AnomalyOutputBoolean {
+			for i := 0; i < scores.Len(); i++ {
+				t, f := scores.GetPoint(i)
+				scores.SetPoint(i, t, ac.isAnomaly(f))
+			}
+		}
+
+		if ac.Mode == AnomalyModePoint {
+			newRes.Values = append(newRes.Values, scores)
+			continue
+		}
+		n := mathexp.NewNumber(ac.refID, scores.GetLabels())
+		if scores.Len() > 0 {
+			n.SetValue(scores.GetValue(scores.Len() - 1))
+		}
+		newRes.Values = append(newRes.Values, n)
+	}
+	return newRes, nil
+}
+
+func (ac *AnomalyCommand) isAnomaly(score *float64) *float64 {
+	if score == nil {
+		return nil
+	}
+	var v float64
+	if math.Abs(*score) > ac.Threshold {
+		v = 1
+	}
+	return &v
+}
@@ -0,0 +1,130 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	cases := []struct {
+		description   string
+		query         string
+		expected      *AnomalyCommand
+		expectedError string
+	}{
+		{
+			description: "unmarshal with defaults",
+			query:       `{"expression": "$A", "type": "anomaly", "window": "1h"}`,
+			expected: &AnomalyCommand{
+				VarToDetect: "A",
+				Window:      time.Hour,
+				Method:      "stddev",
+				Output:      AnomalyOutputScore,
+				Threshold:   3,
+				Mode:        AnomalyModeSeries,
+				refID:       "B",
+			},
+		},
+		{
+			description: "unmarshal proper object",
+			query:       `{"expression": "A", "type": "anomaly", "window": "30m", "method": "mad", "output": "boolean", "threshold": 0, "mode": "point"}`,
+			expected: &AnomalyCommand{
+				VarToDetect: "A",
+				Window:      30 * time.Minute,
+				Method:      "mad",
+				Output:      AnomalyOutputBoolean,
+				Threshold:   0,
+				Mode:        AnomalyModePoint,
+				refID:       "B",
+			},
+		},
+		{
+			description:   "unmarshal without window should error",
+			query:         `{"expression": "A", "type": "anomaly"}`,
+			expectedError: "no time duration specified",
+		},
+		{
+			description:   "unmarshal with unsupported method should error",
+			query:         `{"expression": "A", "type": "anomaly", "window": "1h", "method": "foo"}`,
+			expectedError: "expected anomaly method to be one of",
+		},
+		{
+			description:   "unmarshal with unsupported output should error",
+			query:         `{"expression": "A", "type": "anomaly", "window": "1h", "output": "foo"}`,
+			expectedError: "expected anomaly output to be one of",
+		},
+		{
+			description:   "unmarshal with negative threshold should error",
+			query:         `{"expression": "A", "type": "anomaly", "window": "1h", "threshold": -1}`,
+			expectedError: "must not be negative",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			q := make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(tc.query), &q))
+
+			cmd, err := UnmarshalAnomalyCommand(&rawNode{RefID: "B", Query: q})
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cmd)
+		})
+	}
+}
+
+func TestAnomalyExecute(t *testing.T) {
+	series := mathexp.NewSeries("A", data.Labels{"host": "a"}, 4)
+	for i, v := range []float64{1, 3, 2, 8} {
+		series.SetPoint(i, time.Unix(int64(i*60), 0), ptr.Float64(v))
+	}
+	vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{series, mathexp.NoData{}.New()}}}
+
+	t.Run("series mode outputs the score of the last point", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", time.Hour, "", AnomalyOutputScore, 3, AnomalyModeSeries)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+
+		n, ok := res.Values[0].(mathexp.Number)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"host": "a"}, n.GetLabels())
+		require.InDelta(t, 7.34846922835, *n.GetFloat64Value(), 1e-9)
+		require.Equal(t, mathexp.NoData{}.New(), res.Values[1])
+	})
+
+	t.Run("point mode with boolean output", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", time.Hour, "", AnomalyOutputBoolean, 3, AnomalyModePoint)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+
+		s, ok := res.Values[0].(mathexp.Series)
+		require.True(t, ok)
+		require.Equal(t, 4, s.Len())
+		for i, expected := range []*float64{nil, nil, ptr.Float64(0), ptr.Float64(1)} {
+			require.Equal(t, expected, s.GetValue(i))
+		}
+	})
+
+	t.Run("numbers should error", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", time.Hour, "", "", 3, "")
+		require.NoError(t, err)
+		_, err = cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{makeJoinNumber("A", nil, 1)}},
+		})
+		require.ErrorContains(t, err, "can only detect anomalies in type series")
+	})
+}
@@ -187,22 +187,37 @@ type ResampleCommand struct {
 	VarToResample string
 	Downsampler   string
 	Upsampler     string
-	TimeRange     TimeRange
-	refID         string
+	// Alignment is either mathexp.ResampleAlignStart or mathexp.ResampleAlignEpoch.
+	Alignment string
+	TimeRange TimeRange
+	refID     string
 }
 
-// NewResampleCommand creates a new ResampleCMD.
+// NewResampleCommand creates a new ResampleCMD that aligns the points to the start of the time range.
 func NewResampleCommand(refID, rawWindow, varToResample string, downsampler string, upsampler string, tr TimeRange) (*ResampleCommand, error) {
+	return NewResampleCommandWithAlignment(refID, rawWindow, varToResample, downsampler, upsampler, mathexp.ResampleAlignStart, tr)
+}
+
+// NewResampleCommandWithAlignment creates a new ResampleCMD with the given alignment of the points. An empty
+// alignment aligns the points to the start of the time range.
+func NewResampleCommandWithAlignment(refID, rawWindow, varToResample string, downsampler string, upsampler string, alignment string, tr TimeRange) (*ResampleCommand, error) {
 	// TODO: validate reducer here, before execution
 	window, err := gtime.ParseDuration(rawWindow)
 	if err != nil {
 		return nil, fmt.Errorf(`failed to parse resample "window" duration field %q: %w`, window, err)
 	}
+	if alignment == "" {
+		alignment = mathexp.ResampleAlignStart
+	}
+	if alignment != mathexp.ResampleAlignStart && alignment != mathexp.ResampleAlignEpoch {
+		return nil, fmt.Errorf("expected resample alignment to be one of %s, %s, got %s", mathexp.ResampleAlignStart, mathexp.ResampleAlignEpoch, alignment)
+	}
 	return &ResampleCommand{
 		Window:        window,
 		VarToResample: varToResample,
 		Downsampler:   downsampler,
 		Upsampler:     upsampler,
+		Alignment:     alignment,
 		TimeRange:     tr,
 		refID:         refID,
 	}, nil
@@ -251,7 +266,15 @@ func UnmarshalResampleCommand(rn *rawNode) (*ResampleCommand, error) {
 		return nil, fmt.Errorf("expected resample downsampler to be a string, got type %T", upsampler)
 	}
 
-	return NewResampleCommand(rn.RefID, window, varToResample, downsampler, upsampler, rn.TimeRange)
+	var alignment string
+	if rawAlignment, ok := rn.Query["alignment"]; ok {
+		alignment, ok = rawAlignment.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected resample alignment to be a string, got type %T", rawAlignment)
+		}
+	}
+
+	return NewResampleCommandWithAlignment(rn.RefID, window, varToResample, downsampler, upsampler, alignment, rn.TimeRange)
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -265,12 +288,16 @@ func (gr *ResampleCommand) NeedsVars() []string {
 func (gr *ResampleCommand) Execute(_ context.Context, now time.Time, vars mathexp.Vars) (mathexp.Results, error) {
 	newRes := mathexp.Results{}
 	timeRange := gr.TimeRange.AbsoluteTime(now)
+	from, err := mathexp.AlignResampleStart(timeRange.From, gr.Window, gr.Alignment)
+	if err != nil {
+		return newRes, err
+	}
 	for _, val := range vars[gr.VarToResample].Values {
 		series, ok := val.(mathexp.Series)
 		if !ok {
 			return newRes, fmt.Errorf("can only resample type series, got type %v", val.Type())
 		}
-		num, err := series.Resample(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, timeRange.From, timeRange.To)
+		num, err := series.Resample(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, from, timeRange.To)
 		if err != nil {
 			return newRes, err
 		}
@@ -295,6 +322,12 @@ const (
 	TypeClassicConditions
 	// TypeThreshold is the CMDType for checking if a threshold has been crossed
 	TypeThreshold
+	// TypeJoin is the CMDType for relabeling and joining series or numbers by labels.
+	TypeJoin
+	// TypeAnomaly is the CMDType for detecting deviations of series from a rolling baseline.
+	TypeAnomaly
+	// TypeForecast is the CMDType for predicting future values of series.
+	TypeForecast
 )
 
 func (gt CommandType) String() string {
@@ -307,6 +340,12 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeJoin:
+		return "join"
+	case TypeAnomaly:
+		return "anomaly"
+	case TypeForecast:
+		return "forecast"
 	default:
 		return "unknown"
 	}
@@ -325,7 +364,23 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeClassicConditions, nil
 	case "threshold":
 		return TypeThreshold, nil
+	case "join":
+		return TypeJoin, nil
+	case "anomaly":
+		return TypeAnomaly, nil
+	case "forecast":
+		return TypeForecast, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
 }
+
+// isOneOf returns true if s is one of the values, such as the supported modes of a command.
+func isOneOf(s string, values []string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -170,3 +170,37 @@ func randomReduceFunc() string {
 	res := mathexp.GetSupportedReduceFuncs()
 	return res[rand.Intn(len(res)-1)]
 }
+
+func TestResampleCommandAlignment(t *testing.T) {
+	series := mathexp.NewSeries("A", nil, 2)
+	series.SetPoint(0, time.Unix(50, 0), ptr.Float64(1))
+	series.SetPoint(1, time.Unix(110, 0), ptr.Float64(2))
+	vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{series}}}
+	tr := AbsoluteTimeRange{From: time.Unix(45, 0), To: time.Unix(180, 0)}
+
+	t.Run("start alignment", func(t *testing.T) {
+		cmd, err := NewResampleCommand("B", "1m", "A", "last", "pad", tr)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		s := res.Values[0].(mathexp.Series)
+		require.Equal(t, time.Unix(45, 0), s.GetTime(0))
+	})
+
+	t.Run("epoch alignment", func(t *testing.T) {
+		cmd, err := NewResampleCommandWithAlignment("B", "1m", "A", "last", "pad", mathexp.ResampleAlignEpoch, tr)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		s := res.Values[0].(mathexp.Series)
+		require.Equal(t, 3, s.Len())
+		for i, expected := range []time.Time{time.Unix(60, 0), time.Unix(120, 0), time.Unix(180, 0)} {
+			require.Equal(t, expected, s.GetTime(i))
+		}
+	})
+
+	t.Run("unsupported alignment should error", func(t *testing.T) {
+		_, err := NewResampleCommandWithAlignment("B", "1m", "A", "last", "pad", "foo", tr)
+		require.ErrorContains(t, err, "expected resample alignment to be one of")
+	})
+}
@@ -0,0 +1,139 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+const (
+	// ForecastMethodLinear predicts values with a least squares linear regression, like predict_linear in Prometheus.
+	ForecastMethodLinear = "linear"
+	// ForecastMethodHoltWinters predicts values with double exponential smoothing, like holt_winters in Prometheus.
+	ForecastMethodHoltWinters = "holt_winters"
+
+	defaultSmoothingFactor = 0.5
+	defaultTrendFactor     = 0.5
+)
+
+var supportedForecastMethods = []string{ForecastMethodLinear, ForecastMethodHoltWinters}
+
+// ForecastCommand is an expression command that predicts the value of each series at a point in the future.
+// The result is a number for each series, so it can be used in Threshold and Math expressions.
+type ForecastCommand struct {
+	VarToPredict    string
+	Method          string
+	Horizon         time.Duration
+	SmoothingFactor float64
+	TrendFactor     float64
+	refID           string
+}
+
+// NewForecastCommand creates a new ForecastCommand. An empty method defaults to linear.
+// The smoothing and trend factors are only used by the holt_winters method.
+func NewForecastCommand(refID, varToPredict, method string, horizon time.Duration, smoothingFactor, trendFactor float64) (*ForecastCommand, error) {
+	if method == "" {
+		method = ForecastMethodLinear
+	}
+	if !isOneOf(method, supportedForecastMethods) {
+		return nil, fmt.Errorf("expected forecast method to be one of %s, got %s", strings.Join(supportedForecastMethods, ", "), method)
+	}
+	if horizon < 0 {
+		return nil, fmt.Errorf("forecast horizon must not be negative, got %s", horizon)
+	}
+	if method == ForecastMethodHoltWinters {
+		if smoothingFactor <= 0 || smoothingFactor >= 1 {
+			return nil, fmt.Errorf("smoothing factor must be between 0 and 1, got %v", smoothingFactor)
+		}
+		if trendFactor <= 0 || trendFactor >= 1 {
+			return nil, fmt.Errorf("trend factor must be between 0 and 1, got %v", trendFactor)
+		}
+	}
+	return &ForecastCommand{
+		VarToPredict:    varToPredict,
+		Method:          method,
+		Horizon:         horizon,
+		SmoothingFactor: smoothingFactor,
+		TrendFactor:     trendFactor,
+		refID:           refID,
+	}, nil
+}
+
+type forecastCommandJSON struct {
+	Expression      string   `json:"expression"`
+	Method          string   `json:"method"`
+	Horizon         string   `json:"horizon"`
+	SmoothingFactor *float64 `json:"smoothingFactor"`
+	TrendFactor     *float64 `json:"trendFactor"`
+}
+
+// UnmarshalForecastCommand creates a ForecastCommand from Grafana's frontend query.
+func UnmarshalForecastCommand(rn *rawNode) (*ForecastCommand, error) {
+	jsonFromM, err := json.Marshal(rn.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remarshal forecast expression body: %w", err)
+	}
+	var cmd forecastCommandJSON
+	if err = json.Unmarshal(jsonFromM, &cmd); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal remarshaled forecast expression body: %w", err)
+	}
+	if cmd.Expression == "" {
+		return nil, fmt.Errorf("no variable specified to reference for refId %v", rn.RefID)
+	}
+	if cmd.Horizon == "" {
+		return nil, fmt.Errorf("no time duration specified for the horizon in forecast command")
+	}
+	horizon, err := gtime.ParseDuration(cmd.Horizon)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse forecast horizon: %w", err)
+	}
+	smoothingFactor, trendFactor := defaultSmoothingFactor, defaultTrendFactor
+	if cmd.SmoothingFactor != nil {
+		smoothingFactor = *cmd.SmoothingFactor
+	}
+	if cmd.TrendFactor != nil {
+		trendFactor = *cmd.TrendFactor
+	}
+	return NewForecastCommand(rn.RefID, strings.TrimPrefix(cmd.Expression, "$"), cmd.Method, horizon, smoothingFactor, trendFactor)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (fc *ForecastCommand) NeedsVars() []string {
+	return []string{fc.VarToPredict}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute. Values are predicted at now plus the horizon.
+func (fc *ForecastCommand) Execute(_ context.Context, now time.Time, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	at := now.Add(fc.Horizon)
+	for _, val := range vars[fc.VarToPredict].Values {
+		var series mathexp.Series
+		switch v := val.(type) {
+		case mathexp.Series:
+			series = v
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v)
+			continue
+		default:
+			return newRes, fmt.Errorf("can only forecast type series, got type %v", val.Type())
+		}
+
+		n := mathexp.NewNumber(fc.refID, series.GetLabels())
+		switch fc.Method {
+		case ForecastMethodHoltWinters:
+			n.SetValue(series.PredictHoltWinters(at, fc.SmoothingFactor, fc.TrendFactor))
+		default:
+			n.SetValue(series.PredictLinear(at))
+		}
+		newRes.Values = append(newRes.Values, n)
+	}
+	return newRes, nil
+}
@@ -0,0 +1,107 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestUnmarshalForecastCommand(t *testing.T) {
+	cases := []struct {
+		description   string
+		query         string
+		expected      *ForecastCommand
+		expectedError string
+	}{
+		{
+			description: "unmarshal with defaults",
+			query:       `{"expression": "$A", "type": "forecast", "horizon": "4h"}`,
+			expected: &ForecastCommand{
+				VarToPredict:    "A",
+				Method:          ForecastMethodLinear,
+				Horizon:         4 * time.Hour,
+				SmoothingFactor: 0.5,
+				TrendFactor:     0.5,
+				refID:           "B",
+			},
+		},
+		{
+			description: "unmarshal holt winters",
+			query:       `{"expression": "A", "type": "forecast", "horizon": "30m", "method": "holt_winters", "smoothingFactor": 0.3, "trendFactor": 0.1}`,
+			expected: &ForecastCommand{
+				VarToPredict:    "A",
+				Method:          ForecastMethodHoltWinters,
+				Horizon:         30 * time.Minute,
+				SmoothingFactor: 0.3,
+				TrendFactor:     0.1,
+				refID:           "B",
+			},
+		},
+		{
+			description:   "unmarshal without horizon should error",
+			query:         `{"expression": "A", "type": "forecast"}`,
+			expectedError: "no time duration specified",
+		},
+		{
+			description:   "unmarshal with unsupported method should error",
+			query:         `{"expression": "A", "type": "forecast", "horizon": "1h", "method": "arima"}`,
+			expectedError: "expected forecast method to be one of",
+		},
+		{
+			description:   "unmarshal with invalid smoothing factor should error",
+			query:         `{"expression": "A", "type": "forecast", "horizon": "1h", "method": "holt_winters", "smoothingFactor": 1}`,
+			expectedError: "smoothing factor must be between 0 and 1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			q := make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(tc.query), &q))
+
+			cmd, err := UnmarshalForecastCommand(&rawNode{RefID: "B", Query: q})
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cmd)
+		})
+	}
+}
+
+func TestForecastExecute(t *testing.T) {
+	now := time.Unix(3600, 0)
+	series := mathexp.NewSeries("A", data.Labels{"mount": "/"}, 3)
+	for i, v := range []float64{50, 60, 70} {
+		series.SetPoint(i, now.Add(time.Duration(i-2)*time.Hour), ptr.Float64(v))
+	}
+	vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{series, mathexp.NoData{}.New()}}}
+
+	cmd, err := NewForecastCommand("B", "A", ForecastMethodLinear, 3*time.Hour, 0, 0)
+	require.NoError(t, err)
+	res, err := cmd.Execute(context.Background(), now, vars)
+	require.NoError(t, err)
+	require.Len(t, res.Values, 2)
+
+	n, ok := res.Values[0].(mathexp.Number)
+	require.True(t, ok)
+	require.Equal(t, data.Labels{"mount": "/"}, n.GetLabels())
+	require.InDelta(t, 100, *n.GetFloat64Value(), 1e-9)
+	require.Equal(t, mathexp.NoData{}.New(), res.Values[1])
+
+	t.Run("result can be used in a threshold expression", func(t *testing.T) {
+		threshold, err := NewThresholdCommand("C", "B", ThresholdIsAbove, []float64{90})
+		require.NoError(t, err)
+		thresholdRes, err := threshold.Execute(context.Background(), now, mathexp.Vars{"B": mathexp.Results{Values: res.Values[:1]}})
+		require.NoError(t, err)
+		require.Equal(t, 1.0, *thresholdRes.Values[0].(mathexp.Number).GetFloat64Value())
+	})
+}
@@ -0,0 +1,314 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+const (
+	JoinModeInner = "inner"
+	JoinModeLeft  = "left"
+	JoinModeOuter = "outer"
+)
+
+const (
+	RelabelActionRename  = "rename"
+	RelabelActionDrop    = "drop"
+	RelabelActionKeep    = "keep"
+	RelabelActionReplace = "replace"
+)
+
+var (
+	supportedJoinModes      = []string{JoinModeInner, JoinModeLeft, JoinModeOuter}
+	supportedRelabelActions = []string{RelabelActionRename, RelabelActionDrop, RelabelActionKeep, RelabelActionReplace}
+)
+
+// RelabelRule changes the labels of a series or number.
+type RelabelRule struct {
+	// Action is one of rename, drop, keep or replace.
+	Action string `json:"action"`
+	// Source is the label that is renamed, or whose value is matched by replace.
+	Source string `json:"source,omitempty"`
+	// Target is the label that is created by rename and replace.
+	Target string `json:"target,omitempty"`
+	// Labels are the labels that are removed by drop, or the only labels kept by keep.
+	Labels []string `json:"labels,omitempty"`
+	// Regex must fully match the value of the source label for replace to set the target label.
+	// It defaults to "(.*)".
+	Regex string `json:"regex,omitempty"`
+	// Replacement is the value of the target label set by replace. Capture groups of Regex can be referenced with $1, $2, etc.
+	// It defaults to "$1".
+	Replacement *string `json:"replacement,omitempty"`
+
+	regex *regexp.Regexp
+}
+
+// Apply returns a copy of the labels changed by the rule.
+func (r RelabelRule) Apply(labels data.Labels) data.Labels {
+	result := labels.Copy()
+	if result == nil {
+		result = data.Labels{}
+	}
+	switch r.Action {
+	case RelabelActionRename:
+		if v, ok := result[r.Source]; ok {
+			delete(result, r.Source)
+			result[r.Target] = v
+		}
+	case RelabelActionDrop:
+		for _, l := range r.Labels {
+			delete(result, l)
+		}
+	case RelabelActionKeep:
+		keep := make(map[string]struct{}, len(r.Labels))
+		for _, l := range r.Labels {
+			keep[l] = struct{}{}
+		}
+		for k := range result {
+			if _, ok := keep[k]; !ok {
+				delete(result, k)
+			}
+		}
+	case RelabelActionReplace:
+		v, ok := result[r.Source]
+		if !ok {
+			break
+		}
+		match := r.regex.FindStringSubmatchIndex(v)
+		if match == nil {
+			break
+		}
+		replacement := "$1"
+		if r.Replacement != nil {
+			replacement = *r.Replacement
+		}
+		result[r.Target] = string(r.regex.ExpandString(nil, replacement, v, match))
+	}
+	return result
+}
+
+func (r *RelabelRule) validate() error {
+	switch r.Action {
+	case RelabelActionRename:
+		if r.Source == "" || r.Target == "" {
+			return fmt.Errorf("relabel action %s requires a source and a target label", r.Action)
+		}
+	case RelabelActionDrop, RelabelActionKeep:
+		if len(r.Labels) == 0 {
+			return fmt.Errorf("relabel action %s requires at least one label", r.Action)
+		}
+	case RelabelActionReplace:
+		if r.Source == "" || r.Target == "" {
+			return fmt.Errorf("relabel action %s requires a source and a target label", r.Action)
+		}
+		regex := r.Regex
+		if regex == "" {
+			regex = "(.*)"
+		}
+		re, err := regexp.Compile("^(?:" + regex + ")$")
+		if err != nil {
+			return fmt.Errorf("failed to parse regex of relabel action %s: %w", r.Action, err)
+		}
+		r.regex = re
+	default:
+		return fmt.Errorf("expected relabel action to be one of %s, got %s", strings.Join(supportedRelabelActions, ", "), r.Action)
+	}
+	return nil
+}
+
+// JoinCommand is an expression command that relabels the series or numbers of an expression and joins them
+// with the series or numbers of another expression on a subset of their labels.
+// The result contains the values of the left expression. Joined values also get the labels of
+// the matching right value, so they can be combined with the right expression in a Math expression.
+type JoinCommand struct {
+	LeftVar      string
+	RightVar     string
+	On           []string
+	Mode         string
+	LeftRelabel  []RelabelRule
+	RightRelabel []RelabelRule
+	refID        string
+}
+
+// NewJoinCommand creates a new JoinCommand. If rightVar is empty, the command only relabels the left expression.
+func NewJoinCommand(refID, leftVar, rightVar string, on []string, mode string, leftRelabel, rightRelabel []RelabelRule) (*JoinCommand, error) {
+	if mode == "" {
+		mode = JoinModeInner
+	}
+	if !isOneOf(mode, supportedJoinModes) {
+		return nil, fmt.Errorf("expected join mode to be one of %s, got %s", strings.Join(supportedJoinModes, ", "), mode)
+	}
+	if rightVar != "" && len(on) == 0 {
+		return nil, fmt.Errorf("at least one label to join on must be specified")
+	}
+	for _, rules := range [][]RelabelRule{leftRelabel, rightRelabel} {
+		for i := range rules {
+			if err := rules[i].validate(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &JoinCommand{
+		LeftVar:      leftVar,
+		RightVar:     rightVar,
+		On:           on,
+		Mode:         mode,
+		LeftRelabel:  leftRelabel,
+		RightRelabel: rightRelabel,
+		refID:        refID,
+	}, nil
+}
+
+type joinCommandJSON struct {
+	Expression      string        `json:"expression"`
+	RightExpression string        `json:"rightExpression"`
+	On              []string      `json:"on"`
+	Mode            string        `json:"mode"`
+	Relabel         []RelabelRule `json:"relabel"`
+	RightRelabel    []RelabelRule `json:"rightRelabel"`
+}
+
+// UnmarshalJoinCommand creates a JoinCommand from Grafana's frontend query.
+func UnmarshalJoinCommand(rn *rawNode) (*JoinCommand, error) {
+	jsonFromM, err := json.Marshal(rn.Query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remarshal join expression body: %w", err)
+	}
+	var cmd joinCommandJSON
+	if err = json.Unmarshal(jsonFromM, &cmd); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal remarshaled join expression body: %w", err)
+	}
+	if cmd.Expression == "" {
+		return nil, fmt.Errorf("no variable specified to reference for refId %v", rn.RefID)
+	}
+	return NewJoinCommand(rn.RefID,
+		strings.TrimPrefix(cmd.Expression, "$"),
+		strings.TrimPrefix(cmd.RightExpression, "$"),
+		cmd.On, cmd.Mode, cmd.Relabel, cmd.RightRelabel)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (jc *JoinCommand) NeedsVars() []string {
+	if jc.RightVar == "" {
+		return []string{jc.LeftVar}
+	}
+	return []string{jc.LeftVar, jc.RightVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (jc *JoinCommand) Execute(_ context.Context, _ time.Time, vars mathexp.Vars) (mathexp.Results, error) {
+	left, err := jc.relabel(vars[jc.LeftVar], jc.LeftRelabel)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	if jc.RightVar == "" {
+		return resultsOrNoData(left), nil
+	}
+	right, err := jc.relabel(vars[jc.RightVar], jc.RightRelabel)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+
+	rightByKey := make(map[string]mathexp.Value, len(right))
+	for _, r := range right {
+		key, ok := jc.joinKey(r.GetLabels())
+		if !ok {
+			continue
+		}
+		if _, exists := rightByKey[key]; exists {
+			return mathexp.Results{}, fmt.Errorf("found duplicate series for labels %s in %s, join labels must uniquely identify the right side", key, jc.RightVar)
+		}
+		rightByKey[key] = r
+	}
+
+	newRes := make([]mathexp.Value, 0, len(left))
+	matched := make(map[string]struct{}, len(rightByKey))
+	for _, l := range left {
+		key, ok := jc.joinKey(l.GetLabels())
+		r, found := rightByKey[key]
+		if !ok || !found {
+			if jc.Mode != JoinModeInner {
+				newRes = append(newRes, l)
+			}
+			continue
+		}
+		matched[key] = struct{}{}
+		labels := r.GetLabels().Copy()
+		for k, v := range l.GetLabels() {
+			labels[k] = v
+		}
+		l.SetLabels(labels)
+		newRes = append(newRes, l)
+	}
+
+	if jc.Mode == JoinModeOuter {
+		for _, r := range right {
+			key, ok := jc.joinKey(r.GetLabels())
+			if _, found := matched[key]; ok && found {
+				continue
+			}
+			newRes = append(newRes, r)
+		}
+	}
+	return resultsOrNoData(newRes), nil
+}
+
+// relabel returns copies of the series and numbers of a variable with the relabel rules applied.
+// NoData values are dropped.
+func (jc *JoinCommand) relabel(res mathexp.Results, rules []RelabelRule) ([]mathexp.Value, error) {
+	values := make([]mathexp.Value, 0, len(res.Values))
+	for _, val := range res.Values {
+		labels := val.GetLabels()
+		for _, rule := range rules {
+			labels = rule.Apply(labels)
+		}
+		switch v := val.(type) {
+		case mathexp.Series:
+			s := mathexp.NewSeries(jc.refID, labels, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				s.SetPoint(i, t, f)
+			}
+			values = append(values, s)
+		case mathexp.Number:
+			n := mathexp.NewNumber(jc.refID, labels)
+			n.SetValue(v.GetFloat64Value())
+			values = append(values, n)
+		case mathexp.NoData:
+			continue
+		default:
+			return nil, fmt.Errorf("can only join type series or number, got type %v", val.Type())
+		}
+	}
+	return values, nil
+}
+
+// joinKey returns the values of the labels to join on as a string. It returns false if any of the labels is missing.
+func (jc *JoinCommand) joinKey(labels data.Labels) (string, bool) {
+	keyLabels := make(data.Labels, len(jc.On))
+	for _, l := range jc.On {
+		v, ok := labels[l]
+		if !ok {
+			return "", false
+		}
+		keyLabels[l] = v
+	}
+	return keyLabels.String(), true
+}
+
+func resultsOrNoData(values []mathexp.Value) mathexp.Results {
+	if len(values) == 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NoData{}.New()}}
+	}
+	return mathexp.Results{Values: values}
+}
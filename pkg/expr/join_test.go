@@ -0,0 +1,245 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestUnmarshalJoinCommand(t *testing.T) {
+	cases := []struct {
+		description   string
+		query         string
+		expectedError string
+	}{
+		{
+			description: "unmarshal proper object",
+			query: `{
+				"expression": "$A",
+				"rightExpression": "B",
+				"type": "join",
+				"on": ["host"],
+				"mode": "left",
+				"relabel": [{"action": "rename", "source": "instance", "target": "host"}],
+				"rightRelabel": [{"action": "replace", "source": "addr", "target": "host", "regex": "(.*):\\d+"}]
+			}`,
+		},
+		{
+			description: "unmarshal relabel only",
+			query: `{
+				"expression": "A",
+				"type": "join",
+				"relabel": [{"action": "drop", "labels": ["job"]}]
+			}`,
+		},
+		{
+			description: "unmarshal without join labels should error",
+			query: `{
+				"expression": "A",
+				"rightExpression": "B",
+				"type": "join"
+			}`,
+			expectedError: "at least one label to join on",
+		},
+		{
+			description: "unmarshal with unsupported mode should error",
+			query: `{
+				"expression": "A",
+				"rightExpression": "B",
+				"type": "join",
+				"on": ["host"],
+				"mode": "cross"
+			}`,
+			expectedError: "expected join mode to be one of",
+		},
+		{
+			description: "unmarshal with unsupported relabel action should error",
+			query: `{
+				"expression": "A",
+				"type": "join",
+				"relabel": [{"action": "foo"}]
+			}`,
+			expectedError: "expected relabel action to be one of",
+		},
+		{
+			description: "unmarshal with invalid regex should error",
+			query: `{
+				"expression": "A",
+				"type": "join",
+				"relabel": [{"action": "replace", "source": "a", "target": "b", "regex": "("}]
+			}`,
+			expectedError: "failed to parse regex",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			q := make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(tc.query), &q))
+
+			cmd, err := UnmarshalJoinCommand(&rawNode{RefID: "C", Query: q})
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, cmd)
+		})
+	}
+}
+
+func TestRelabelRuleApply(t *testing.T) {
+	labels := data.Labels{"instance": "web-01:9100", "job": "node", "dc": "eu"}
+
+	cases := []struct {
+		description string
+		rule        RelabelRule
+		expected    data.Labels
+	}{
+		{
+			description: "rename",
+			rule:        RelabelRule{Action: RelabelActionRename, Source: "instance", Target: "host"},
+			expected:    data.Labels{"host": "web-01:9100", "job": "node", "dc": "eu"},
+		},
+		{
+			description: "drop",
+			rule:        RelabelRule{Action: RelabelActionDrop, Labels: []string{"job", "dc"}},
+			expected:    data.Labels{"instance": "web-01:9100"},
+		},
+		{
+			description: "keep",
+			rule:        RelabelRule{Action: RelabelActionKeep, Labels: []string{"dc"}},
+			expected:    data.Labels{"dc": "eu"},
+		},
+		{
+			description: "replace with regex",
+			rule:        RelabelRule{Action: RelabelActionReplace, Source: "instance", Target: "host", Regex: `(.*):\d+`},
+			expected:    data.Labels{"instance": "web-01:9100", "host": "web-01", "job": "node", "dc": "eu"},
+		},
+		{
+			description: "replace with replacement",
+			rule:        RelabelRule{Action: RelabelActionReplace, Source: "dc", Target: "region", Replacement: ptr.String("region-$1")},
+			expected:    data.Labels{"instance": "web-01:9100", "region": "region-eu", "job": "node", "dc": "eu"},
+		},
+		{
+			description: "replace does nothing if regex does not match",
+			rule:        RelabelRule{Action: RelabelActionReplace, Source: "dc", Target: "region", Regex: "us"},
+			expected:    labels,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			rule := tc.rule
+			require.NoError(t, rule.validate())
+			require.Equal(t, tc.expected, rule.Apply(labels))
+			require.Len(t, labels, 3, "input labels should not be modified")
+		})
+	}
+}
+
+func TestJoinExecute(t *testing.T) {
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			makeJoinNumber("A", data.Labels{"instance": "web-01", "job": "node"}, 1),
+			makeJoinNumber("A", data.Labels{"instance": "web-02", "job": "node"}, 2),
+		}},
+		"B": mathexp.Results{Values: mathexp.Values{
+			makeJoinNumber("B", data.Labels{"host": "web-01", "app": "loki"}, 10),
+			makeJoinNumber("B", data.Labels{"host": "web-03", "app": "loki"}, 30),
+		}},
+	}
+	rename := []RelabelRule{{Action: RelabelActionRename, Source: "instance", Target: "host"}}
+
+	cases := []struct {
+		mode     string
+		expected mathexp.Values
+	}{
+		{
+			mode: JoinModeInner,
+			expected: mathexp.Values{
+				makeJoinNumber("C", data.Labels{"host": "web-01", "job": "node", "app": "loki"}, 1),
+			},
+		},
+		{
+			mode: JoinModeLeft,
+			expected: mathexp.Values{
+				makeJoinNumber("C", data.Labels{"host": "web-01", "job": "node", "app": "loki"}, 1),
+				makeJoinNumber("C", data.Labels{"host": "web-02", "job": "node"}, 2),
+			},
+		},
+		{
+			mode: JoinModeOuter,
+			expected: mathexp.Values{
+				makeJoinNumber("C", data.Labels{"host": "web-01", "job": "node", "app": "loki"}, 1),
+				makeJoinNumber("C", data.Labels{"host": "web-02", "job": "node"}, 2),
+				makeJoinNumber("C", data.Labels{"host": "web-03", "app": "loki"}, 30),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.mode, func(t *testing.T) {
+			cmd, err := NewJoinCommand("C", "A", "B", []string{"host"}, tc.mode, rename, nil)
+			require.NoError(t, err)
+
+			res, err := cmd.Execute(context.Background(), time.Now(), vars)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, res.Values)
+		})
+	}
+
+	t.Run("result can be combined with right side in math", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", "A", "B", []string{"host"}, JoinModeInner, rename, nil)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+
+		math, err := NewMathCommand("D", "$C + $B")
+		require.NoError(t, err)
+		mathRes, err := math.Execute(context.Background(), time.Now(), mathexp.Vars{"B": vars["B"], "C": res})
+		require.NoError(t, err)
+		require.Len(t, mathRes.Values, 1)
+		require.Equal(t, 11.0, *mathRes.Values[0].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("inner join without matches returns no data", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", "A", "B", []string{"host"}, JoinModeInner, nil, nil)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars)
+		require.NoError(t, err)
+		require.Equal(t, mathexp.Values{mathexp.NoData{}.New()}, res.Values)
+	})
+
+	t.Run("duplicate join labels on right side should error", func(t *testing.T) {
+		cmd, err := NewJoinCommand("C", "B", "A", []string{"job"}, JoinModeInner, nil, nil)
+		require.NoError(t, err)
+		_, err = cmd.Execute(context.Background(), time.Now(), vars)
+		require.ErrorContains(t, err, "duplicate series")
+	})
+
+	t.Run("input series are not modified", func(t *testing.T) {
+		series := mathexp.NewSeries("A", data.Labels{"instance": "web-01"}, 1)
+		series.SetPoint(0, time.Unix(0, 0), ptr.Float64(1))
+		cmd, err := NewJoinCommand("C", "A", "", nil, "", rename, nil)
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{series}}})
+		require.NoError(t, err)
+		require.Equal(t, data.Labels{"host": "web-01"}, res.Values[0].GetLabels())
+		require.Equal(t, data.Labels{"instance": "web-01"}, series.GetLabels())
+	})
+}
+
+func makeJoinNumber(refID string, labels data.Labels, f float64) mathexp.Number {
+	n := mathexp.NewNumber(refID, labels)
+	n.SetValue(&f)
+	return n
+}
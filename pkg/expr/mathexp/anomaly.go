@@ -0,0 +1,108 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// BaselineStdDev computes the baseline of a point as the mean and standard deviation of the points before it.
+	BaselineStdDev = "stddev"
+	// BaselineMAD computes the baseline of a point as the median and median absolute deviation of the points before it.
+	// It is less sensitive to outliers in the window than BaselineStdDev.
+	BaselineMAD = "mad"
+)
+
+// madScale scales the median absolute deviation so that it estimates the standard deviation of normally
+// distributed data, which makes scores of both baselines comparable.
+const madScale = 1.4826
+
+// minBaselinePoints is the minimum number of values in the window required to compute a score.
+const minBaselinePoints = 2
+
+// IsSupportedBaseline returns true if the baseline method is supported by AnomalyScores.
+func IsSupportedBaseline(method string) bool {
+	return method == BaselineStdDev || method == BaselineMAD
+}
+
+// AnomalyScores returns a Series with the anomaly score of each point of the Series. The score is the distance
+// of the value from the baseline computed from the values in the window before the point, in standard deviations.
+// The score of a point is null if the value is null or NaN, or if the window has fewer than two values.
+func (s Series) AnomalyScores(refID string, window time.Duration, method string) (Series, error) {
+	if !IsSupportedBaseline(method) {
+		return Series{}, fmt.Errorf("unsupported baseline method %s", method)
+	}
+	if window <= 0 {
+		return Series{}, fmt.Errorf("window must be positive")
+	}
+
+	// The input is not sorted in place, since it can be referenced by other expressions.
+	idx := make([]int, s.Len())
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return s.GetTime(idx[i]).Before(s.GetTime(idx[j]))
+	})
+
+	scores := NewSeries(refID, s.GetLabels(), len(idx))
+	start := 0
+	for i, pointIdx := range idx {
+		t, v := s.GetPoint(pointIdx)
+		for start < i && !s.GetTime(idx[start]).After(t.Add(-window)) {
+			start++
+		}
+		if v == nil || math.IsNaN(*v) {
+			scores.SetPoint(i, t, nil)
+			continue
+		}
+		values := make([]float64, 0, i-start)
+		for _, j := range idx[start:i] {
+			if f := s.GetValue(j); f != nil && !math.IsNaN(*f) {
+				values = append(values, *f)
+			}
+		}
+		if len(values) < minBaselinePoints {
+			scores.SetPoint(i, t, nil)
+			continue
+		}
+		center, spread := baseline(values, method)
+		score := anomalyScore(*v, center, spread)
+		scores.SetPoint(i, t, &score)
+	}
+	return scores, nil
+}
+
+// baseline returns the center and the spread of values. values is modified.
+func baseline(values []float64, method string) (float64, float64) {
+	if method == BaselineMAD {
+		median := percentileOf(values, 50)
+		for i, v := range values {
+			values[i] = math.Abs(v - median)
+		}
+		return median, madScale * percentileOf(values, 50)
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values)), stdDevOf(values)
+}
+
+// anomalyScore returns the number of spreads v is away from center. If the spread is zero, any deviation
+// is infinitely anomalous.
+func anomalyScore(v, center, spread float64) float64 {
+	if spread == 0 {
+		switch {
+		case v > center:
+			return math.Inf(1)
+		case v < center:
+			return math.Inf(-1)
+		default:
+			return 0
+		}
+	}
+	return (v - center) / spread
+}
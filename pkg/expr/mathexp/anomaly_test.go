@@ -0,0 +1,93 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+)
+
+func TestSeriesAnomalyScores(t *testing.T) {
+	newSeries := func(values ...*float64) Series {
+		s := NewSeries("A", data.Labels{"host": "a"}, len(values))
+		// Points are set in reverse order to make sure the input does not need to be sorted.
+		for i, v := range values {
+			idx := len(values) - 1 - i
+			s.SetPoint(idx, time.Unix(int64(i*60), 0), v)
+		}
+		return s
+	}
+
+	cases := []struct {
+		description string
+		series      Series
+		window      time.Duration
+		method      string
+		expected    []*float64
+	}{
+		{
+			description: "stddev baseline over the window before each point",
+			series:      newSeries(ptr.Float64(1), ptr.Float64(3), ptr.Float64(2), ptr.Float64(8)),
+			window:      3 * time.Minute,
+			method:      BaselineStdDev,
+			// baseline of the last point: mean 2.5, population stddev 0.5
+			expected: []*float64{nil, nil, ptr.Float64(0), ptr.Float64(11)},
+		},
+		{
+			description: "points outside the window are not part of the baseline",
+			series:      newSeries(ptr.Float64(100), ptr.Float64(1), ptr.Float64(3), ptr.Float64(5)),
+			window:      3 * time.Minute,
+			method:      BaselineStdDev,
+			expected:    []*float64{nil, nil, ptr.Float64(-47.5 / 49.5), ptr.Float64(3)},
+		},
+		{
+			description: "mad baseline is not affected by an outlier",
+			series:      newSeries(ptr.Float64(1), ptr.Float64(1000), ptr.Float64(2), ptr.Float64(3), ptr.Float64(9)),
+			window:      time.Hour,
+			method:      BaselineMAD,
+			// baseline of the last point: median 2.5, MAD 1
+			expected: []*float64{nil, nil, ptr.Float64(-498.5 / (499.5 * madScale)), ptr.Float64(1 / madScale), ptr.Float64(6.5 / madScale)},
+		},
+		{
+			description: "null values are skipped and have null scores",
+			series:      newSeries(ptr.Float64(1), nil, ptr.Float64(3), nil, ptr.Float64(2)),
+			window:      time.Hour,
+			method:      BaselineStdDev,
+			expected:    []*float64{nil, nil, nil, nil, ptr.Float64(0)},
+		},
+		{
+			description: "deviation from a constant baseline is infinite",
+			series:      newSeries(ptr.Float64(1), ptr.Float64(1), ptr.Float64(1), ptr.Float64(0)),
+			window:      time.Hour,
+			method:      BaselineStdDev,
+			expected:    []*float64{nil, nil, ptr.Float64(0), ptr.Float64(math.Inf(-1))},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			scores, err := tc.series.AnomalyScores("B", tc.window, tc.method)
+			require.NoError(t, err)
+			require.Equal(t, tc.series.GetLabels(), scores.GetLabels())
+			require.Equal(t, len(tc.expected), scores.Len())
+			for i, expected := range tc.expected {
+				ts, v := scores.GetPoint(i)
+				require.Equal(t, time.Unix(int64(i*60), 0), ts)
+				if expected == nil {
+					require.Nil(t, v, "point %d", i)
+					continue
+				}
+				require.NotNil(t, v, "point %d", i)
+				require.InDelta(t, *expected, *v, 1e-9, "point %d", i)
+			}
+		})
+	}
+
+	t.Run("should error on unsupported method", func(t *testing.T) {
+		_, err := newSeries(ptr.Float64(1)).AnomalyScores("B", time.Hour, "foo")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,85 @@
+package mathexp
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// forecastPoint is a non-null value of a series with its time in seconds relative to a reference time.
+type forecastPoint struct {
+	x float64
+	y float64
+}
+
+// forecastPoints returns the non-null, non-NaN values of the series ordered by time.
+// Times are relative to ref to keep the precision of the least squares computation.
+func (s Series) forecastPoints(ref time.Time) []forecastPoint {
+	points := make([]forecastPoint, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, v := s.GetPoint(i)
+		if v == nil || math.IsNaN(*v) {
+			continue
+		}
+		points = append(points, forecastPoint{x: t.Sub(ref).Seconds(), y: *v})
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].x < points[j].x
+	})
+	return points
+}
+
+// PredictLinear returns the value of the series at the given time, predicted with a simple linear regression
+// of its values. It returns nil if the series does not have two values at different times.
+func (s Series) PredictLinear(at time.Time) *float64 {
+	points := s.forecastPoints(at)
+	if len(points) < 2 {
+		return nil
+	}
+	var sumX, sumY float64
+	for _, p := range points {
+		sumX += p.x
+		sumY += p.y
+	}
+	n := float64(len(points))
+	meanX, meanY := sumX/n, sumY/n
+	var covXY, varX float64
+	for _, p := range points {
+		covXY += (p.x - meanX) * (p.y - meanY)
+		varX += (p.x - meanX) * (p.x - meanX)
+	}
+	if varX == 0 {
+		return nil
+	}
+	slope := covXY / varX
+	// Times are relative to at, so the intercept is the prediction.
+	v := meanY - slope*meanX
+	return &v
+}
+
+// PredictHoltWinters returns the value of the series at the given time, predicted with double exponential
+// smoothing of its values. The smoothing factor weights the level and the trend factor weights the trend of
+// recent values; both must be between 0 and 1. The trend is extrapolated by the average interval of the series.
+// It returns nil if the series has fewer than two values.
+func (s Series) PredictHoltWinters(at time.Time, smoothingFactor, trendFactor float64) *float64 {
+	points := s.forecastPoints(at)
+	if len(points) < 2 {
+		return nil
+	}
+	last := points[len(points)-1]
+	interval := (last.x - points[0].x) / float64(len(points)-1)
+	if interval == 0 {
+		return nil
+	}
+
+	level := points[0].y
+	trend := points[1].y - points[0].y
+	for _, p := range points[1:] {
+		prevLevel := level
+		level = smoothingFactor*p.y + (1-smoothingFactor)*(level+trend)
+		trend = trendFactor*(level-prevLevel) + (1-trendFactor)*trend
+	}
+	// at is the reference time, so the distance to the last value is -last.x.
+	v := level + trend*(-last.x/interval)
+	return &v
+}
@@ -0,0 +1,82 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+)
+
+func TestSeriesForecast(t *testing.T) {
+	start := time.Unix(1000, 0)
+	newSeries := func(values ...*float64) Series {
+		s := NewSeries("A", data.Labels{"host": "a"}, len(values))
+		for i, v := range values {
+			s.SetPoint(i, start.Add(time.Duration(i)*time.Minute), v)
+		}
+		return s
+	}
+
+	t.Run("predict linear", func(t *testing.T) {
+		cases := []struct {
+			description string
+			series      Series
+			at          time.Time
+			expected    *float64
+		}{
+			{
+				description: "extrapolates a linear series",
+				series:      newSeries(ptr.Float64(10), ptr.Float64(12), ptr.Float64(14)),
+				at:          start.Add(10 * time.Minute),
+				expected:    ptr.Float64(30),
+			},
+			{
+				description: "fits noisy values with least squares",
+				series:      newSeries(ptr.Float64(0), ptr.Float64(2), ptr.Float64(1), ptr.Float64(3)),
+				at:          start.Add(4 * time.Minute),
+				// slope 0.8 per minute, intercept 0.3
+				expected: ptr.Float64(3.5),
+			},
+			{
+				description: "ignores null values",
+				series:      newSeries(ptr.Float64(10), nil, ptr.Float64(14)),
+				at:          start.Add(3 * time.Minute),
+				expected:    ptr.Float64(16),
+			},
+			{
+				description: "returns null for a single value",
+				series:      newSeries(ptr.Float64(10), nil),
+				at:          start,
+				expected:    nil,
+			},
+		}
+		for _, tc := range cases {
+			t.Run(tc.description, func(t *testing.T) {
+				v := tc.series.PredictLinear(tc.at)
+				if tc.expected == nil {
+					require.Nil(t, v)
+					return
+				}
+				require.NotNil(t, v)
+				require.InDelta(t, *tc.expected, *v, 1e-9)
+			})
+		}
+	})
+
+	t.Run("predict holt winters", func(t *testing.T) {
+		linear := newSeries(ptr.Float64(10), ptr.Float64(12), ptr.Float64(14), ptr.Float64(16))
+		v := linear.PredictHoltWinters(start.Add(10*time.Minute), 0.5, 0.5)
+		require.NotNil(t, v)
+		require.InDelta(t, 30, *v, 1e-9)
+
+		// The prediction follows recent values more with a higher smoothing factor.
+		changed := newSeries(ptr.Float64(10), ptr.Float64(10), ptr.Float64(10), ptr.Float64(20))
+		low := changed.PredictHoltWinters(start.Add(3*time.Minute), 0.1, 0.1)
+		high := changed.PredictHoltWinters(start.Add(3*time.Minute), 0.9, 0.1)
+		require.Less(t, *low, *high)
+
+		require.Nil(t, newSeries(ptr.Float64(10)).PredictHoltWinters(start, 0.5, 0.5))
+	})
+}
@@ -1,7 +1,12 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
@@ -68,6 +73,62 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"sqrt": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             sqrt,
+	},
+	"exp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             exp,
+	},
+	"pow": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             pow,
+	},
+	"clamp_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMin,
+	},
+	"clamp_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMax,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"increase": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      increase,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+		Check:  checkWindowArg,
+	},
+	"stddev": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeNumberSet,
+		F:      stddev,
+	},
+	"percentile": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeScalar},
+		Return: parse.TypeNumberSet,
+		F:      percentile,
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -303,3 +364,313 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// sqrt returns the square root for each result in NumberSet, SeriesSet, or Scalar
+func sqrt(e *State, varSet Results) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, math.Sqrt)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// exp returns e raised to the power of the value for each result in NumberSet, SeriesSet, or Scalar
+func exp(e *State, varSet Results) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, math.Exp)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// pow returns the value raised to the power of the scalar argument for each result in NumberSet, SeriesSet, or Scalar
+func pow(e *State, varSet Results, exponent Results) (Results, error) {
+	y, err := scalarArg("pow", exponent)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatResults(e, varSet, func(f float64) float64 {
+		return math.Pow(f, y)
+	})
+}
+
+// clampMin returns the value, or the scalar argument if the value is lower, for each result in NumberSet, SeriesSet, or Scalar
+func clampMin(e *State, varSet Results, minimum Results) (Results, error) {
+	m, err := scalarArg("clamp_min", minimum)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatResults(e, varSet, func(f float64) float64 {
+		return math.Max(f, m)
+	})
+}
+
+// clampMax returns the value, or the scalar argument if the value is greater, for each result in NumberSet, SeriesSet, or Scalar
+func clampMax(e *State, varSet Results, maximum Results) (Results, error) {
+	m, err := scalarArg("clamp_max", maximum)
+	if err != nil {
+		return Results{}, err
+	}
+	return perFloatResults(e, varSet, func(f float64) float64 {
+		return math.Min(f, m)
+	})
+}
+
+// delta returns, for each series in SeriesSet, a series of the differences between consecutive points.
+// The first point of each series is dropped since it has no predecessor.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeriesPair(e, varSet, func(prevT, curT time.Time, prev, cur float64) float64 {
+		return cur - prev
+	})
+}
+
+// increase is like delta, but treats any decrease in value as a counter reset.
+func increase(e *State, varSet Results) (Results, error) {
+	return perSeriesPair(e, varSet, counterIncrease)
+}
+
+// rate returns, for each series in SeriesSet, the per-second increase between consecutive points.
+// Like increase, any decrease in value is treated as a counter reset.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeriesPair(e, varSet, func(prevT, curT time.Time, prev, cur float64) float64 {
+		seconds := curT.Sub(prevT).Seconds()
+		if seconds <= 0 {
+			return math.NaN()
+		}
+		return counterIncrease(prevT, curT, prev, cur) / seconds
+	})
+}
+
+func counterIncrease(_, _ time.Time, prev, cur float64) float64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+// movingAvg returns, for each series in SeriesSet, a series where each point is the average of the
+// points within the window that ends at that point. Null points are ignored.
+func movingAvg(e *State, varSet Results, rawWindow string) (Results, error) {
+	window, err := gtime.ParseDuration(rawWindow)
+	if err != nil {
+		return Results{}, fmt.Errorf("failed to parse window of moving_avg: %w", err)
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		if res.Type() == parse.TypeNoData {
+			newRes.Values = append(newRes.Values, res)
+			continue
+		}
+		series, ok := res.(Series)
+		if !ok {
+			return newRes, fmt.Errorf("expected %v, got %v", parse.TypeSeriesSet, res.Type())
+		}
+		series = sortedByTime(series)
+		newSeries := NewSeries(e.RefID, series.GetLabels(), series.Len())
+		start := 0
+		for i := 0; i < series.Len(); i++ {
+			t := series.GetTime(i)
+			for start < i && !series.GetTime(start).After(t.Add(-window)) {
+				start++
+			}
+			var sum float64
+			var count int
+			for j := start; j <= i; j++ {
+				if v := series.GetValue(j); v != nil {
+					sum += *v
+					count++
+				}
+			}
+			var avg *float64
+			if count > 0 {
+				a := sum / float64(count)
+				avg = &a
+			}
+			newSeries.SetPoint(i, t, avg)
+		}
+		newRes.Values = append(newRes.Values, newSeries)
+	}
+	return newRes, nil
+}
+
+// stddev returns the population standard deviation of each series in SeriesSet as a NumberSet.
+// Like the reducers, the result is NaN if the series contains a null or NaN value.
+func stddev(e *State, varSet Results) (Results, error) {
+	return perSeriesNumber(e, varSet, stdDevOf)
+}
+
+// percentile returns the p-th percentile (0-100) of each series in SeriesSet as a NumberSet.
+// Like the reducers, the result is NaN if the series contains a null or NaN value.
+func percentile(e *State, varSet Results, p Results) (Results, error) {
+	pf, err := scalarArg("percentile", p)
+	if err != nil {
+		return Results{}, err
+	}
+	if pf < 0 || pf > 100 {
+		return Results{}, fmt.Errorf("percentile must be between 0 and 100, got %v", pf)
+	}
+	return perSeriesNumber(e, varSet, func(values []float64) float64 {
+		return percentileOf(values, pf)
+	})
+}
+
+// checkWindowArg validates at parse time that the window argument of a function is a duration.
+func checkWindowArg(_ *parse.Tree, f *parse.FuncNode) error {
+	if arg, ok := f.Args[1].(*parse.StringNode); ok {
+		window, err := gtime.ParseDuration(arg.Text)
+		if err != nil {
+			return fmt.Errorf("parse: invalid window for %s: %w", f.Name, err)
+		}
+		if window <= 0 {
+			return fmt.Errorf("parse: window for %s must be positive", f.Name)
+		}
+	}
+	return nil
+}
+
+// scalarArg returns the value of a function argument that must be a single non-null Scalar.
+func scalarArg(name string, res Results) (float64, error) {
+	if len(res.Values) != 1 || res.Values[0].Type() != parse.TypeScalar {
+		return 0, fmt.Errorf("expected a scalar argument for %s", name)
+	}
+	f := res.Values[0].(Scalar).GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("scalar argument for %s must not be null", name)
+	}
+	return *f, nil
+}
+
+// perFloatResults applies perFloat to each result in varSet.
+func perFloatResults(e *State, varSet Results, floatF func(x float64) float64) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, floatF)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// sortedByTime returns a copy of the series sorted by time. The series is not sorted in place, since it can be
+// referenced by other expressions.
+func sortedByTime(s Series) Series {
+	sorted := NewSeries(s.GetName(), s.GetLabels(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, v := s.GetPoint(i)
+		sorted.SetPoint(i, t, v)
+	}
+	sorted.SortByTime(false)
+	return sorted
+}
+
+// perSeriesPair creates a new series from each series in varSet by passing each pair of consecutive points to pairF.
+// The resulting point has the time of the later point. If either value is null, the resulting value is null.
+// NoData values are returned as is.
+func perSeriesPair(e *State, varSet Results, pairF func(prevT, curT time.Time, prev, cur float64) float64) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		if res.Type() == parse.TypeNoData {
+			newRes.Values = append(newRes.Values, res)
+			continue
+		}
+		series, ok := res.(Series)
+		if !ok {
+			return newRes, fmt.Errorf("expected %v, got %v", parse.TypeSeriesSet, res.Type())
+		}
+		series = sortedByTime(series)
+		size := series.Len() - 1
+		if size < 0 {
+			size = 0
+		}
+		newSeries := NewSeries(e.RefID, series.GetLabels(), size)
+		for i := 1; i < series.Len(); i++ {
+			prevT, prev := series.GetPoint(i - 1)
+			curT, cur := series.GetPoint(i)
+			var nF *float64
+			if prev != nil && cur != nil {
+				f := pairF(prevT, curT, *prev, *cur)
+				nF = &f
+			}
+			newSeries.SetPoint(i-1, curT, nF)
+		}
+		newRes.Values = append(newRes.Values, newSeries)
+	}
+	return newRes, nil
+}
+
+// perSeriesNumber reduces each series in varSet to a Number using numberF.
+// If any of the values of a series is null or NaN, the resulting Number is NaN.
+// NoData values are returned as is.
+func perSeriesNumber(e *State, varSet Results, numberF func(values []float64) float64) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		if res.Type() == parse.TypeNoData {
+			newRes.Values = append(newRes.Values, res)
+			continue
+		}
+		series, ok := res.(Series)
+		if !ok {
+			return newRes, fmt.Errorf("expected %v, got %v", parse.TypeSeriesSet, res.Type())
+		}
+		n := NewNumber(e.RefID, series.GetLabels())
+		values := make([]float64, 0, series.Len())
+		f := math.NaN()
+		for i := 0; i < series.Len(); i++ {
+			v := series.GetValue(i)
+			if v == nil || math.IsNaN(*v) {
+				values = nil
+				break
+			}
+			values = append(values, *v)
+		}
+		if len(values) > 0 {
+			f = numberF(values)
+		}
+		n.SetValue(&f)
+		newRes.Values = append(newRes.Values, n)
+	}
+	return newRes, nil
+}
+
+// stdDevOf returns the population standard deviation of values.
+func stdDevOf(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(squares / float64(len(values)))
+}
+
+// percentileOf returns the p-th percentile (0-100) of values, linearly interpolating between the closest ranks.
+// values is sorted in place.
+func percentileOf(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sort.Float64s(values)
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return values[lower]
+	}
+	return values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+}
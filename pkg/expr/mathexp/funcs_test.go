@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 )
 
@@ -159,3 +161,244 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestScalarArgFuncs(t *testing.T) {
+	var tests = []struct {
+		name    string
+		expr    string
+		vars    Vars
+		results Results
+	}{
+		{
+			name: "pow on number",
+			expr: "pow($A, 2)",
+			vars: Vars{
+				"A": Results{[]Value{makeNumber("", nil, float64Pointer(3))}},
+			},
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(9))}},
+		},
+		{
+			name:    "sqrt on scalar",
+			expr:    "sqrt(16)",
+			vars:    Vars{},
+			results: Results{[]Value{NewScalar("", float64Pointer(4))}},
+		},
+		{
+			name:    "exp on scalar",
+			expr:    "exp(0)",
+			vars:    Vars{},
+			results: Results{[]Value{NewScalar("", float64Pointer(1))}},
+		},
+		{
+			name: "clamp_min and clamp_max on series",
+			expr: "clamp_max(clamp_min($A, 0), 10)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil,
+							tp{time.Unix(5, 0), float64Pointer(-2)},
+							tp{time.Unix(10, 0), float64Pointer(5)},
+							tp{time.Unix(15, 0), float64Pointer(20)}),
+					},
+				},
+			},
+			results: Results{
+				[]Value{
+					makeSeries("", nil,
+						tp{time.Unix(5, 0), float64Pointer(0)},
+						tp{time.Unix(10, 0), float64Pointer(5)},
+						tp{time.Unix(15, 0), float64Pointer(10)}),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", tt.vars)
+			require.NoError(t, err)
+			require.Equal(t, tt.results, res)
+		})
+	}
+}
+
+func TestSeriesFuncs(t *testing.T) {
+	counter := Vars{
+		"A": Results{
+			[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(10, 0), float64Pointer(30)},
+					tp{time.Unix(20, 0), float64Pointer(5)},
+					tp{time.Unix(30, 0), nil},
+					tp{time.Unix(40, 0), float64Pointer(25)}),
+			},
+		},
+	}
+
+	var tests = []struct {
+		name    string
+		expr    string
+		vars    Vars
+		results Results
+	}{
+		{
+			name: "delta",
+			expr: "delta($A)",
+			vars: counter,
+			results: Results{
+				[]Value{
+					makeSeries("", nil,
+						tp{time.Unix(10, 0), float64Pointer(20)},
+						tp{time.Unix(20, 0), float64Pointer(-25)},
+						tp{time.Unix(30, 0), nil},
+						tp{time.Unix(40, 0), nil}),
+				},
+			},
+		},
+		{
+			name: "increase treats decrease as counter reset",
+			expr: "increase($A)",
+			vars: counter,
+			results: Results{
+				[]Value{
+					makeSeries("", nil,
+						tp{time.Unix(10, 0), float64Pointer(20)},
+						tp{time.Unix(20, 0), float64Pointer(5)},
+						tp{time.Unix(30, 0), nil},
+						tp{time.Unix(40, 0), nil}),
+				},
+			},
+		},
+		{
+			name: "rate",
+			expr: "rate($A)",
+			vars: counter,
+			results: Results{
+				[]Value{
+					makeSeries("", nil,
+						tp{time.Unix(10, 0), float64Pointer(2)},
+						tp{time.Unix(20, 0), float64Pointer(0.5)},
+						tp{time.Unix(30, 0), nil},
+						tp{time.Unix(40, 0), nil}),
+				},
+			},
+		},
+		{
+			name: "moving_avg ignores null values",
+			expr: `moving_avg($A, "15s")`,
+			vars: counter,
+			results: Results{
+				[]Value{
+					makeSeries("", nil,
+						tp{time.Unix(0, 0), float64Pointer(10)},
+						tp{time.Unix(10, 0), float64Pointer(20)},
+						tp{time.Unix(20, 0), float64Pointer(17.5)},
+						tp{time.Unix(30, 0), float64Pointer(5)},
+						tp{time.Unix(40, 0), float64Pointer(25)}),
+				},
+			},
+		},
+		{
+			name: "stddev",
+			expr: "stddev($A)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", data.Labels{"host": "a"},
+							tp{time.Unix(0, 0), float64Pointer(2)},
+							tp{time.Unix(10, 0), float64Pointer(4)},
+							tp{time.Unix(20, 0), float64Pointer(4)},
+							tp{time.Unix(30, 0), float64Pointer(4)},
+							tp{time.Unix(40, 0), float64Pointer(5)},
+							tp{time.Unix(50, 0), float64Pointer(5)},
+							tp{time.Unix(60, 0), float64Pointer(7)},
+							tp{time.Unix(70, 0), float64Pointer(9)}),
+					},
+				},
+			},
+			results: Results{[]Value{makeNumber("", data.Labels{"host": "a"}, float64Pointer(2))}},
+		},
+		{
+			name: "percentile interpolates between ranks",
+			expr: "percentile($A, 50)",
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil,
+							tp{time.Unix(0, 0), float64Pointer(4)},
+							tp{time.Unix(10, 0), float64Pointer(1)},
+							tp{time.Unix(20, 0), float64Pointer(3)},
+							tp{time.Unix(30, 0), float64Pointer(2)}),
+					},
+				},
+			},
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(2.5))}},
+		},
+		{
+			name:    "stddev of series with null value is NaN",
+			expr:    "stddev($A)",
+			vars:    counter,
+			results: Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+	}
+	opt := cmp.Comparer(func(x, y float64) bool {
+		return (math.IsNaN(x) && math.IsNaN(y)) || x == y
+	})
+	options := append([]cmp.Option{opt}, data.FrameTestCompareOptions()...)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", tt.vars)
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.results, res, options...); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSeriesFuncsDoNotSortInputInPlace(t *testing.T) {
+	for _, expr := range []string{"delta($A)", `moving_avg($A, "15s")`} {
+		t.Run(expr, func(t *testing.T) {
+			input := makeSeries("", nil,
+				tp{time.Unix(10, 0), float64Pointer(30)},
+				tp{time.Unix(0, 0), float64Pointer(10)})
+			e, err := New(expr)
+			require.NoError(t, err)
+			_, err = e.Execute("", Vars{"A": Results{[]Value{input}}})
+			require.NoError(t, err)
+			require.Equal(t, time.Unix(10, 0), input.GetTime(0))
+			require.Equal(t, time.Unix(0, 0), input.GetTime(1))
+		})
+	}
+}
+
+func TestFuncArgumentErrors(t *testing.T) {
+	t.Run("invalid moving_avg window fails to parse", func(t *testing.T) {
+		_, err := New(`moving_avg($A, "abc")`)
+		require.Error(t, err)
+	})
+
+	t.Run("too many arguments fails to parse", func(t *testing.T) {
+		_, err := New(`sqrt($A, 2)`)
+		require.Error(t, err)
+	})
+
+	t.Run("percentile out of range fails to execute", func(t *testing.T) {
+		e, err := New(`percentile($A, 101)`)
+		require.NoError(t, err)
+		_, err = e.Execute("", Vars{"A": Results{[]Value{makeSeries("", nil, tp{time.Unix(0, 0), float64Pointer(1)})}}})
+		require.Error(t, err)
+	})
+
+	t.Run("series function on number fails to execute", func(t *testing.T) {
+		e, err := New(`delta($A)`)
+		require.NoError(t, err)
+		_, err = e.Execute("", Vars{"A": Results{[]Value{makeNumber("", nil, float64Pointer(1))}}})
+		require.Error(t, err)
+	})
+}
@@ -3,6 +3,7 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -81,6 +82,82 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		if fv.GetValue(i) != nil {
+			f++
+		}
+	}
+	return &f
+}
+
+func Median(fv *Float64Field) *float64 {
+	return Percentile(50)(fv)
+}
+
+// Percentile returns a ReducerFunc that calculates the p-th percentile (0-100) of the values,
+// linearly interpolating between the closest ranks.
+func Percentile(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		f := math.NaN()
+		if values, ok := numberValues(fv); ok {
+			f = percentileOf(values, p)
+		}
+		return &f
+	}
+}
+
+func StdDev(fv *Float64Field) *float64 {
+	f := math.NaN()
+	if values, ok := numberValues(fv); ok {
+		f = stdDevOf(values)
+	}
+	return &f
+}
+
+func Range(fv *Float64Field) *float64 {
+	minimum, maximum := Min(fv), Max(fv)
+	f := *maximum - *minimum
+	return &f
+}
+
+func Diff(fv *Float64Field) *float64 {
+	first, last := First(fv), Last(fv)
+	if first == nil || last == nil {
+		nan := math.NaN()
+		return &nan
+	}
+	f := *last - *first
+	return &f
+}
+
+// numberValues returns the values of the field. It returns false if the field is empty
+// or any of the values is null or NaN.
+func numberValues(fv *Float64Field) ([]float64, bool) {
+	if fv.Len() == 0 {
+		return nil, false
+	}
+	values := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		values = append(values, *v)
+	}
+	return values, true
+}
+
 func GetReduceFunc(rFunc string) (ReducerFunc, error) {
 	switch strings.ToLower(rFunc) {
 	case "sum":
@@ -95,14 +172,42 @@ func GetReduceFunc(rFunc string) (ReducerFunc, error) {
 		return Count, nil
 	case "last":
 		return Last, nil
+	case "first":
+		return First, nil
+	case "count_non_null":
+		return CountNonNull, nil
+	case "median":
+		return Median, nil
+	case "stddev":
+		return StdDev, nil
+	case "range":
+		return Range, nil
+	case "diff":
+		return Diff, nil
 	default:
+		if p, ok := parsePercentileReducer(rFunc); ok {
+			return Percentile(p), nil
+		}
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
 }
 
-// GetSupportedReduceFuncs returns collection of supported function names
+// parsePercentileReducer parses percentile reducers in the form of pXX, e.g. p95 or p99.9.
+func parsePercentileReducer(rFunc string) (float64, bool) {
+	if len(rFunc) < 2 || (rFunc[0] != 'p' && rFunc[0] != 'P') {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(rFunc[1:], 64)
+	if err != nil || math.IsNaN(p) || p < 0 || p > 100 {
+		return 0, false
+	}
+	return p, true
+}
+
+// GetSupportedReduceFuncs returns collection of supported function names.
+// Besides these, any percentile can be used as reducer in the form of pXX, e.g. p99.9.
 func GetSupportedReduceFuncs() []string {
-	return []string{"sum", "mean", "min", "max", "count", "last"}
+	return []string{"sum", "mean", "min", "max", "count", "last", "first", "count_non_null", "median", "stddev", "range", "diff", "p90", "p95", "p99"}
 }
 
 // Reduce turns the Series into a Number based on the given reduction function
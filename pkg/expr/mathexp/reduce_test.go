@@ -514,3 +514,150 @@ func TestSeriesReduceReplaceNN(t *testing.T) {
 		})
 	}
 }
+
+var seriesLatencies = Vars{
+	"A": Results{
+		[]Value{
+			makeSeries("temp", nil,
+				tp{time.Unix(5, 0), float64Pointer(5)},
+				tp{time.Unix(10, 0), float64Pointer(1)},
+				tp{time.Unix(15, 0), float64Pointer(4)},
+				tp{time.Unix(20, 0), float64Pointer(2)},
+				tp{time.Unix(25, 0), float64Pointer(3)}),
+		},
+	},
+}
+
+func TestSeriesReduceStatistics(t *testing.T) {
+	var tests = []struct {
+		name    string
+		red     string
+		vars    Vars
+		mapper  ReduceMapper
+		results Results
+	}{
+		{
+			name:    "first series",
+			red:     "first",
+			vars:    seriesLatencies,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(5))}},
+		},
+		{
+			name:    "median series",
+			red:     "median",
+			vars:    seriesLatencies,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(3))}},
+		},
+		{
+			name:    "percentile series",
+			red:     "p90",
+			vars:    seriesLatencies,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(4.6))}},
+		},
+		{
+			name:    "fractional percentile series",
+			red:     "p12.5",
+			vars:    seriesLatencies,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(1.5))}},
+		},
+		{
+			name:    "stddev series",
+			red:     "stddev",
+			vars:    seriesLatencies,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(math.Sqrt(2)))}},
+		},
+		{
+			name:    "range series",
+			red:     "range",
+			vars:    seriesLatencies,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(4))}},
+		},
+		{
+			name:    "diff series",
+			red:     "diff",
+			vars:    seriesLatencies,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(-2))}},
+		},
+		{
+			name:    "count_non_null series with a nil value",
+			red:     "count_non_null",
+			vars:    seriesWithNil,
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(1))}},
+		},
+		{
+			name:    "median series with a nil value",
+			red:     "median",
+			vars:    seriesWithNil,
+			results: Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:    "diff series with a nil value",
+			red:     "diff",
+			vars:    seriesWithNil,
+			results: Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:    "stddev empty series",
+			red:     "stddev",
+			vars:    seriesEmpty,
+			results: Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:    "dropNN: median series with a nil value",
+			red:     "median",
+			vars:    seriesWithNil,
+			mapper:  DropNonNumber{},
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(2))}},
+		},
+		{
+			name:    "dropNN: range series that becomes empty after filtering non-number",
+			red:     "range",
+			vars:    seriesNonNumbers,
+			mapper:  DropNonNumber{},
+			results: Results{[]Value{makeNumber("", nil, nil)}},
+		},
+		{
+			name:    "replaceNN: diff series with a nil value",
+			red:     "diff",
+			vars:    seriesWithNil,
+			mapper:  ReplaceNonNumberWithValue{Value: 10},
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(8))}},
+		},
+		{
+			name:    "replaceNN: p50 empty series",
+			red:     "p50",
+			vars:    seriesEmpty,
+			mapper:  ReplaceNonNumberWithValue{Value: 10},
+			results: Results{[]Value{makeNumber("", nil, float64Pointer(10))}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Results{}
+			for _, series := range tt.vars["A"].Values {
+				ns, err := series.Value().(*Series).Reduce("", tt.red, tt.mapper)
+				require.NoError(t, err)
+				results.Values = append(results.Values, ns)
+			}
+			opt := cmp.Comparer(func(x, y float64) bool {
+				return (math.IsNaN(x) && math.IsNaN(y)) || math.Abs(x-y) < 1e-9
+			})
+			options := append([]cmp.Option{opt}, data.FrameTestCompareOptions()...)
+			if diff := cmp.Diff(tt.results, results, options...); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetReduceFuncPercentile(t *testing.T) {
+	for _, name := range []string{"p0", "p50", "P99", "p99.9", "p100"} {
+		_, err := GetReduceFunc(name)
+		require.NoErrorf(t, err, "reducer %s", name)
+	}
+	for _, name := range []string{"p", "p101", "p-1", "pNaN", "px"} {
+		_, err := GetReduceFunc(name)
+		require.Errorf(t, err, "reducer %s", name)
+	}
+}
@@ -7,6 +7,37 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
+const (
+	// ResampleAlignStart aligns the resampled points to the start of the time range.
+	ResampleAlignStart = "start"
+	// ResampleAlignEpoch aligns the resampled points to multiples of the interval since the Unix epoch, so that series
+	// resampled over different time ranges have the same timestamps.
+	ResampleAlignEpoch = "epoch"
+)
+
+// AlignResampleStart returns the time of the first resampled point for the given alignment.
+// For epoch alignment it is the first multiple of the interval since the Unix epoch that is not before from.
+func AlignResampleStart(from time.Time, interval time.Duration, alignment string) (time.Time, error) {
+	switch alignment {
+	case "", ResampleAlignStart:
+		return from, nil
+	case ResampleAlignEpoch:
+		if interval <= 0 {
+			return from, fmt.Errorf("interval must be positive")
+		}
+		offset := time.Duration(from.UnixNano() % int64(interval))
+		if offset == 0 {
+			return from, nil
+		}
+		if offset < 0 {
+			return from.Add(-offset), nil
+		}
+		return from.Add(interval - offset), nil
+	default:
+		return from, fmt.Errorf("alignment %v not implemented", alignment)
+	}
+}
+
 // Resample turns the Series into a Number based on the given reduction function
 func (s Series) Resample(refID string, interval time.Duration, downsampler string, upsampler string, from, to time.Time) (Series, error) {
 	newSeriesLength := int(float64(to.Sub(from).Nanoseconds()) / float64(interval.Nanoseconds()))
@@ -16,6 +47,7 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 	resampled := NewSeries(refID, s.GetLabels(), newSeriesLength+1)
 	bookmark := 0
 	var lastSeen *float64
+	var lastSeenTime time.Time
 	idx := 0
 	t := from
 	for !t.After(to) && idx <= newSeriesLength {
@@ -32,10 +64,14 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 			bookmark++
 			sIdx++
 			lastSeen = v
+			lastSeenTime = st
 			vals = append(vals, v)
 		}
 		var value *float64
-		if len(vals) == 0 { // upsampling
+		if downsampler == "count" { // count is the number of points in the window, even if there are none or one
+			count := float64(len(vals))
+			value = &count
+		} else if len(vals) == 0 { // upsampling
 			switch upsampler {
 			case "pad":
 				if lastSeen != nil {
@@ -51,6 +87,27 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 				}
 			case "fillna":
 				value = nil
+			case "linear":
+				if bookmark > 0 && sIdx < s.Len() {
+					nextTime, next := s.GetPoint(sIdx)
+					value = interpolate(lastSeenTime, lastSeen, nextTime, next, t)
+				}
+			case "nearest":
+				switch {
+				case bookmark == 0 && sIdx == s.Len():
+					value = nil
+				case bookmark == 0:
+					_, value = s.GetPoint(sIdx)
+				case sIdx == s.Len():
+					value = lastSeen
+				default:
+					nextTime, next := s.GetPoint(sIdx)
+					if nextTime.Sub(t) < t.Sub(lastSeenTime) {
+						value = next
+					} else {
+						value = lastSeen
+					}
+				}
 			default:
 				return s, fmt.Errorf("upsampling %v not implemented", upsampler)
 			}
@@ -71,6 +128,10 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 				tmp = Max(&ff)
 			case "last":
 				tmp = Last(&ff)
+			case "first":
+				tmp = First(&ff)
+			case "median":
+				tmp = Median(&ff)
 			default:
 				return s, fmt.Errorf("downsampling %v not implemented", downsampler)
 			}
@@ -82,3 +143,16 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 	}
 	return resampled, nil
 }
+
+// interpolate returns the value at t on the line between two points. It returns nil if either value is nil.
+func interpolate(prevTime time.Time, prev *float64, nextTime time.Time, next *float64, t time.Time) *float64 {
+	if prev == nil || next == nil {
+		return nil
+	}
+	span := nextTime.Sub(prevTime)
+	if span <= 0 {
+		return prev
+	}
+	v := *prev + (*next-*prev)*float64(t.Sub(prevTime))/float64(span)
+	return &v
+}
@@ -277,6 +277,132 @@ func TestResampleSeries(t *testing.T) {
 				time.Unix(9, 0), float64Pointer(0),
 			}),
 		},
+		{
+			name:        "resample series: upsampling (linear)",
+			interval:    time.Second * 2,
+			downsampler: "mean",
+			upsampler:   "linear",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(10, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(7, 0), float64Pointer(7),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(6),
+			}, tp{
+				time.Unix(8, 0), float64Pointer(7),
+			}, tp{
+				time.Unix(10, 0), nil,
+			}),
+		},
+		{
+			name:        "resample series: upsampling (nearest)",
+			interval:    time.Second * 2,
+			downsampler: "mean",
+			upsampler:   "nearest",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(10, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(7, 0), float64Pointer(7),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(7),
+			}, tp{
+				time.Unix(8, 0), float64Pointer(7),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(7),
+			}),
+		},
+		{
+			name:        "resample series: downsampling (count / fillna)",
+			interval:    time.Second * 5,
+			downsampler: "count",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(15, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(3),
+			}, tp{
+				time.Unix(7, 0), float64Pointer(1),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(5, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(15, 0), float64Pointer(0),
+			}),
+		},
+		{
+			name:        "resample series: downsampling (first / pad)",
+			interval:    time.Second * 5,
+			downsampler: "first",
+			upsampler:   "pad",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(10, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(3),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(5, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(3),
+			}),
+		},
+		{
+			name:        "resample series: downsampling (median / fillna)",
+			interval:    time.Second * 5,
+			downsampler: "median",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(5, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(1, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(2, 0), float64Pointer(10),
+			}, tp{
+				time.Unix(3, 0), float64Pointer(2),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(5, 0), float64Pointer(2),
+			}),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -290,3 +416,49 @@ func TestResampleSeries(t *testing.T) {
 		})
 	}
 }
+
+func TestAlignResampleStart(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      time.Time
+		alignment string
+		expected  time.Time
+	}{
+		{
+			name:      "start alignment keeps the start of the time range",
+			from:      time.Unix(1003, 0),
+			alignment: ResampleAlignStart,
+			expected:  time.Unix(1003, 0),
+		},
+		{
+			name:      "empty alignment keeps the start of the time range",
+			from:      time.Unix(1003, 0),
+			alignment: "",
+			expected:  time.Unix(1003, 0),
+		},
+		{
+			name:      "epoch alignment moves to the next multiple of the interval",
+			from:      time.Unix(1003, 0),
+			alignment: ResampleAlignEpoch,
+			expected:  time.Unix(1020, 0),
+		},
+		{
+			name:      "epoch alignment keeps an aligned start",
+			from:      time.Unix(1020, 0),
+			alignment: ResampleAlignEpoch,
+			expected:  time.Unix(1020, 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, err := AlignResampleStart(tt.from, time.Minute, tt.alignment)
+			require.NoError(t, err)
+			require.True(t, tt.expected.Equal(from), "expected %v, got %v", tt.expected, from)
+		})
+	}
+
+	t.Run("unsupported alignment should error", func(t *testing.T) {
+		_, err := AlignResampleStart(time.Unix(0, 0), time.Minute, "foo")
+		require.Error(t, err)
+	})
+}
@@ -121,6 +121,12 @@ func buildCMDNode(dp *simple.DirectedGraph, rn *rawNode) (*CMDNode, error) {
 		node.Command, err = classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
 	case TypeThreshold:
 		node.Command, err = UnmarshalThresholdCommand(rn)
+	case TypeJoin:
+		node.Command, err = UnmarshalJoinCommand(rn)
+	case TypeAnomaly:
+		node.Command, err = UnmarshalAnomalyCommand(rn)
+	case TypeForecast:
+		node.Command, err = UnmarshalForecastCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in expression '%v' not implemented", commandType, rn.RefID)
 	}
@@ -282,6 +288,16 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 				logger.Warn("ignoring InfluxDB data frame due to missing numeric fields", "frame", frame)
 				continue
 			}
+			// Long tables, for example returned by SQL data sources, have a time column and string columns that
+			// identify the series of each numeric column.
+			if frame.TimeSeriesSchema().Type == data.TimeSeriesTypeLong {
+				logger.Debug("expression datasource query (long seriesSet)", "query", refID)
+				wideFrame, err := longToWide(frame)
+				if err != nil {
+					return mathexp.Results{}, fmt.Errorf("failed to convert long table of query %s to time series, rows must be ordered by time: %w", refID, err)
+				}
+				frame = wideFrame
+			}
 			series, err := WideToMany(frame)
 			if err != nil {
 				return mathexp.Results{}, err
@@ -359,27 +375,41 @@ func isNumberTable(frame *data.Frame) bool {
 			otherCount++
 		}
 	}
-	return numericCount == 1 && otherCount == 0
+	return numericCount >= 1 && otherCount == 0
 }
 
+// tableColumnLabel is the label that is set to the name of the numeric column for numbers
+// extracted from a table with more than one numeric column.
+const tableColumnLabel = "column"
+
+// extractNumberSet converts a table to a number for each row and numeric column. The string columns of the table
+// are the labels of the numbers. If the table has more than one numeric column, the numbers also get the
+// tableColumnLabel label with the name of their column.
 func extractNumberSet(frame *data.Frame) ([]mathexp.Number, error) {
-	numericField := 0
+	numericFields := []int{}
 	stringFieldIdxs := []int{}
 	stringFieldNames := []string{}
 	for i, field := range frame.Fields {
 		fType := field.Type()
 		switch {
 		case fType.Numeric():
-			numericField = i
+			numericFields = append(numericFields, i)
 		case fType == data.FieldTypeString || fType == data.FieldTypeNullableString:
 			stringFieldIdxs = append(stringFieldIdxs, i)
 			stringFieldNames = append(stringFieldNames, field.Name)
 		}
 	}
-	numbers := make([]mathexp.Number, frame.Rows())
+	multipleColumns := len(numericFields) > 1
+	if multipleColumns {
+		for _, name := range stringFieldNames {
+			if name == tableColumnLabel {
+				return nil, fmt.Errorf("table with more than one numeric column must not have a string column named %q", tableColumnLabel)
+			}
+		}
+	}
+	numbers := make([]mathexp.Number, 0, frame.Rows()*len(numericFields))
 
 	for rowIdx := 0; rowIdx < frame.Rows(); rowIdx++ {
-		val, _ := frame.FloatAt(numericField, rowIdx)
 		var labels data.Labels
 		for i := 0; i < len(stringFieldIdxs); i++ {
 			if i == 0 {
@@ -387,20 +417,62 @@ func extractNumberSet(frame *data.Frame) ([]mathexp.Number, error) {
 			}
 			key := stringFieldNames[i] // TODO check for duplicate string column names
 			val, _ := frame.ConcreteAt(stringFieldIdxs[i], rowIdx)
-			labels[key] = val.(string) // TODO check assertion / return error
+			if val == nil { // null values of nullable string columns
+				val = ""
+			}
+			labels[key] = val.(string)
 		}
 
-		n := mathexp.NewNumber(frame.Fields[numericField].Name, labels)
+		for _, numericField := range numericFields {
+			val, _ := frame.FloatAt(numericField, rowIdx)
+			numberLabels := labels
+			if multipleColumns {
+				numberLabels = labels.Copy()
+				if numberLabels == nil {
+					numberLabels = make(data.Labels)
+				}
+				numberLabels[tableColumnLabel] = frame.Fields[numericField].Name
+			}
+
+			n := mathexp.NewNumber(frame.Fields[numericField].Name, numberLabels)
 
-		// The new value fields' configs gets pointed to the one in the original frame
-		n.Frame.Fields[0].Config = frame.Fields[numericField].Config
-		n.SetValue(&val)
+			// The new value fields' configs gets pointed to the one in the original frame
+			n.Frame.Fields[0].Config = frame.Fields[numericField].Config
+			n.SetValue(&val)
 
-		numbers[rowIdx] = n
+			numbers = append(numbers, n)
+		}
 	}
 	return numbers, nil
 }
 
+// longToWide converts a long frame to a wide frame. The string columns of the long frame become the labels of
+// the series. If the long frame has more than one numeric column, the series also get the tableColumnLabel label
+// with the name of their column.
+func longToWide(frame *data.Frame) (*data.Frame, error) {
+	multipleColumns := len(frame.TimeSeriesSchema().ValueIndices) > 1
+	wideFrame, err := data.LongToWide(frame, &data.FillMissing{Mode: data.FillModeNull})
+	if err != nil {
+		return nil, err
+	}
+	if !multipleColumns {
+		return wideFrame, nil
+	}
+	for _, field := range wideFrame.Fields {
+		if field.Type() == data.FieldTypeTime || field.Type() == data.FieldTypeNullableTime {
+			continue
+		}
+		if _, ok := field.Labels[tableColumnLabel]; ok {
+			return nil, fmt.Errorf("table with more than one numeric column must not have a string column named %q", tableColumnLabel)
+		}
+		if field.Labels == nil {
+			field.Labels = data.Labels{}
+		}
+		field.Labels[tableColumnLabel] = field.Name
+	}
+	return wideFrame, nil
+}
+
 // WideToMany converts a data package wide type Frame to one or multiple Series. A series
 // is created for each value type column of wide frame.
 //
@@ -93,6 +93,110 @@ func TestService(t *testing.T) {
 	}
 }
 
+func TestServiceTableInputs(t *testing.T) {
+	tests := []struct {
+		name     string
+		frame    *data.Frame
+		expected map[string]float64 // labels of the results of $A * 2 to their last non-null value
+	}{
+		{
+			name: "table with several numeric columns",
+			frame: data.NewFrame("",
+				data.NewField("host", nil, []string{"a", "b"}),
+				data.NewField("cpu", nil, []float64{1, 2}),
+				data.NewField("mem", nil, []*float64{fp(3), fp(4)})),
+			expected: map[string]float64{
+				`column=cpu, host=a`: 2,
+				`column=mem, host=a`: 6,
+				`column=cpu, host=b`: 4,
+				`column=mem, host=b`: 8,
+			},
+		},
+		{
+			name: "table with a single numeric column",
+			frame: data.NewFrame("",
+				data.NewField("host", nil, []*string{strPtr("a"), nil}),
+				data.NewField("cpu", nil, []int64{1, 2})),
+			expected: map[string]float64{
+				`host=a`: 2,
+				`host=`:  4,
+			},
+		},
+		{
+			// Missing values of a series are null instead of zero.
+			name: "long table with a single numeric column",
+			frame: data.NewFrame("",
+				data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(1, 0), time.Unix(2, 0)}),
+				data.NewField("host", nil, []string{"a", "b", "a"}),
+				data.NewField("cpu", nil, []float64{1, 2, 3})),
+			expected: map[string]float64{
+				`host=a`: 6,
+				`host=b`: 4,
+			},
+		},
+		{
+			name: "long table",
+			frame: data.NewFrame("",
+				data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(1, 0), time.Unix(2, 0), time.Unix(2, 0)}),
+				data.NewField("host", nil, []string{"a", "b", "a", "b"}),
+				data.NewField("cpu", nil, []float64{1, 2, 3, 4}),
+				data.NewField("mem", nil, []float64{5, 6, 7, 8})),
+			expected: map[string]float64{
+				`column=cpu, host=a`: 6,
+				`column=cpu, host=b`: 8,
+				`column=mem, host=a`: 14,
+				`column=mem, host=b`: 16,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Service{
+				cfg:               setting.NewCfg(),
+				dataService:       &mockEndpoint{Frames: []*data.Frame{tt.frame}},
+				dataSourceService: &datafakes.FakeDataSourceService{},
+			}
+			req := &Request{Queries: []Query{
+				{
+					RefID:      "A",
+					DataSource: &datasources.DataSource{OrgId: 1, Uid: "test", Type: "mysql"},
+					JSON:       json.RawMessage(`{ "datasource": { "uid": "test" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+					TimeRange:  AbsoluteTimeRange{},
+				},
+				{
+					RefID:      "B",
+					DataSource: DataSourceModel(),
+					JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A * 2" }`),
+				},
+			}}
+
+			pl, err := s.BuildPipeline(req)
+			require.NoError(t, err)
+			res, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+			require.NoError(t, err)
+
+			actual := map[string]float64{}
+			for _, frame := range res.Responses["B"].Frames {
+				valueField := frame.Fields[len(frame.Fields)-1]
+				for i := valueField.Len() - 1; i >= 0; i-- {
+					v, err := valueField.NullableFloatAt(i)
+					require.NoError(t, err)
+					if v != nil {
+						actual[valueField.Labels.String()] = *v
+						break
+					}
+				}
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
 func fp(f float64) *float64 {
 	return &f
 }
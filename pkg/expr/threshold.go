@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
 
@@ -15,6 +17,13 @@ type ThresholdCommand struct {
 	RefID         string
 	ThresholdFunc string
 	Conditions    []float64
+
+	// UnloadThresholdFunc and UnloadConditions describe the optional recovery threshold.
+	// A dimension that is loaded (firing) keeps firing until the recovery threshold is met.
+	UnloadThresholdFunc string
+	UnloadConditions    []float64
+	// LoadedDimensions are the labels of the dimensions that were firing at the previous evaluation.
+	LoadedDimensions []data.Labels
 }
 
 const (
@@ -24,6 +33,10 @@ const (
 	ThresholdIsOutsideRange = "outside_range"
 )
 
+// ThresholdLoadedDimensionsKey is the key of the query model property that contains the labels of the
+// dimensions that were firing at the previous evaluation of a threshold expression with a recovery threshold.
+const ThresholdLoadedDimensionsKey = "loadedDimensions"
+
 var (
 	supportedThresholdFuncs = []string{ThresholdIsAbove, ThresholdIsBelow, ThresholdIsWithinRange, ThresholdIsOutsideRange}
 )
@@ -37,8 +50,23 @@ func NewThresholdCommand(refID, referenceVar, thresholdFunc string, conditions [
 	}, nil
 }
 
+// NewHysteresisCommand creates a ThresholdCommand with a recovery threshold. Dimensions with labels in
+// loadedDimensions are evaluated against the recovery threshold instead of the threshold.
+func NewHysteresisCommand(refID, referenceVar, thresholdFunc string, conditions []float64, unloadThresholdFunc string, unloadConditions []float64, loadedDimensions []data.Labels) (*ThresholdCommand, error) {
+	cmd, err := NewThresholdCommand(refID, referenceVar, thresholdFunc, conditions)
+	if err != nil {
+		return nil, err
+	}
+	cmd.UnloadThresholdFunc = unloadThresholdFunc
+	cmd.UnloadConditions = unloadConditions
+	cmd.LoadedDimensions = loadedDimensions
+	return cmd, nil
+}
+
 type ThresholdConditionJSON struct {
 	Evaluator ConditionEvalJSON `json:"evaluator"`
+	// UnloadEvaluator is the optional recovery threshold.
+	UnloadEvaluator *ConditionEvalJSON `json:"unloadEvaluator,omitempty"`
 }
 
 type ConditionEvalJSON struct {
@@ -69,8 +97,13 @@ func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
 	}
 
 	for _, condition := range conditions {
-		if !IsSupportedThresholdFunc(condition.Evaluator.Type) {
-			return nil, fmt.Errorf("expected threshold function to be one of %s, got %s", strings.Join(supportedThresholdFuncs, ", "), condition.Evaluator.Type)
+		if err := validateConditionEval(condition.Evaluator); err != nil {
+			return nil, err
+		}
+		if condition.UnloadEvaluator != nil {
+			if err := validateConditionEval(*condition.UnloadEvaluator); err != nil {
+				return nil, fmt.Errorf("invalid recovery threshold: %w", err)
+			}
 		}
 	}
 
@@ -80,7 +113,69 @@ func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
 	}
 	firstCondition := conditions[0]
 
-	return NewThresholdCommand(rn.RefID, referenceVar, firstCondition.Evaluator.Type, firstCondition.Evaluator.Params)
+	if firstCondition.UnloadEvaluator == nil {
+		return NewThresholdCommand(rn.RefID, referenceVar, firstCondition.Evaluator.Type, firstCondition.Evaluator.Params)
+	}
+
+	var loadedDimensions []data.Labels
+	if rawLoaded, ok := rawQuery[ThresholdLoadedDimensionsKey]; ok && rawLoaded != nil {
+		jsonFromM, err := json.Marshal(rawLoaded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remarshal loaded dimensions of threshold expression: %w", err)
+		}
+		if err = json.Unmarshal(jsonFromM, &loadedDimensions); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal loaded dimensions of threshold expression: %w", err)
+		}
+	}
+
+	return NewHysteresisCommand(rn.RefID, referenceVar,
+		firstCondition.Evaluator.Type, firstCondition.Evaluator.Params,
+		firstCondition.UnloadEvaluator.Type, firstCondition.UnloadEvaluator.Params,
+		loadedDimensions)
+}
+
+func validateConditionEval(eval ConditionEvalJSON) error {
+	if !IsSupportedThresholdFunc(eval.Type) {
+		return fmt.Errorf("expected threshold function to be one of %s, got %s", strings.Join(supportedThresholdFuncs, ", "), eval.Type)
+	}
+	required := 1
+	if eval.Type == ThresholdIsWithinRange || eval.Type == ThresholdIsOutsideRange {
+		required = 2
+	}
+	if len(eval.Params) < required {
+		return fmt.Errorf("threshold function %s requires %d parameters, got %d", eval.Type, required, len(eval.Params))
+	}
+	return nil
+}
+
+// IsHysteresisExpression returns true if the query model is a threshold expression with a recovery threshold.
+func IsHysteresisExpression(query map[string]interface{}) bool {
+	if t, _ := query["type"].(string); t != "threshold" {
+		return false
+	}
+	conditions, ok := query["conditions"].([]interface{})
+	if !ok || len(conditions) != 1 {
+		return false
+	}
+	condition, ok := conditions[0].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	unload, ok := condition["unloadEvaluator"]
+	return ok && unload != nil
+}
+
+// SetLoadedDimensionsToHysteresisCommand sets the labels of the dimensions that were firing at the previous
+// evaluation to the query model of a threshold expression with a recovery threshold.
+func SetLoadedDimensionsToHysteresisCommand(query map[string]interface{}, loadedDimensions []data.Labels) error {
+	if !IsHysteresisExpression(query) {
+		return fmt.Errorf("query is not a threshold expression with a recovery threshold")
+	}
+	if loadedDimensions == nil {
+		loadedDimensions = []data.Labels{}
+	}
+	query[ThresholdLoadedDimensionsKey] = loadedDimensions
+	return nil
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -90,12 +185,65 @@ func (tc *ThresholdCommand) NeedsVars() []string {
 }
 
 func (tc *ThresholdCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars) (mathexp.Results, error) {
-	mathExpression, err := createMathExpression(tc.ReferenceVar, tc.ThresholdFunc, tc.Conditions)
+	loadResults, err := executeThreshold(ctx, now, vars, tc.ReferenceVar, tc.ThresholdFunc, tc.Conditions)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	if tc.UnloadThresholdFunc == "" || len(tc.LoadedDimensions) == 0 {
+		return loadResults, nil
+	}
+
+	unloadResults, err := executeThreshold(ctx, now, vars, tc.ReferenceVar, tc.UnloadThresholdFunc, tc.UnloadConditions)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	unloadByLabels := make(map[string]mathexp.Value, len(unloadResults.Values))
+	for _, v := range unloadResults.Values {
+		unloadByLabels[v.GetLabels().String()] = v
+	}
+
+	for i, v := range loadResults.Values {
+		if !tc.isLoaded(v.GetLabels()) {
+			continue
+		}
+		unload, ok := unloadByLabels[v.GetLabels().String()]
+		if !ok {
+			continue
+		}
+		// The unload results are not shared with other commands, so they can be changed in place.
+		switch u := unload.(type) {
+		case mathexp.Number:
+			u.SetValue(notValue(u.GetFloat64Value()))
+			loadResults.Values[i] = u
+		case mathexp.Series:
+			for j := 0; j < u.Len(); j++ {
+				t, f := u.GetPoint(j)
+				u.SetPoint(j, t, notValue(f))
+			}
+			loadResults.Values[i] = u
+		}
+	}
+	return loadResults, nil
+}
+
+// isLoaded returns true if the dimension was firing at the previous evaluation. The loaded dimensions
+// are the labels of alert instances, which can have more labels than the dimension itself.
+func (tc *ThresholdCommand) isLoaded(labels data.Labels) bool {
+	for _, loaded := range tc.LoadedDimensions {
+		if loaded.Contains(labels) {
+			return true
+		}
+	}
+	return false
+}
+
+func executeThreshold(ctx context.Context, now time.Time, vars mathexp.Vars, referenceVar, thresholdFunc string, conditions []float64) (mathexp.Results, error) {
+	mathExpression, err := createMathExpression(referenceVar, thresholdFunc, conditions)
 	if err != nil {
 		return mathexp.Results{}, err
 	}
 
-	mathCommand, err := NewMathCommand(tc.ReferenceVar, mathExpression)
+	mathCommand, err := NewMathCommand(referenceVar, mathExpression)
 	if err != nil {
 		return mathexp.Results{}, err
 	}
@@ -103,6 +251,18 @@ func (tc *ThresholdCommand) Execute(ctx context.Context, now time.Time, vars mat
 	return mathCommand.Execute(ctx, now, vars)
 }
 
+// notValue negates the result of a threshold: a dimension keeps firing while the recovery threshold is not met.
+func notValue(f *float64) *float64 {
+	if f == nil {
+		return nil
+	}
+	var v float64
+	if *f == 0 {
+		v = 1
+	}
+	return &v
+}
+
 // createMathExpression converts all the info we have about a "threshold" expression in to a Math expression
 func createMathExpression(referenceVar string, thresholdFunc string, args []float64) (string, error) {
 	switch thresholdFunc {
@@ -1,10 +1,16 @@
 package expr
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
+	ptr "github.com/xorcare/pointer"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
 
 func TestNewThresholdCommand(t *testing.T) {
@@ -71,6 +77,59 @@ func TestUnmarshalThresholdCommand(t *testing.T) {
 			shouldError:   true,
 			expectedError: "expected threshold function to be one of",
 		},
+		{
+			description: "unmarshal with recovery threshold and loaded dimensions",
+			query: `{
+				"expression" : "A",
+				"type": "threshold",
+				"conditions": [{
+					"evaluator": {
+						"type": "gt",
+						"params": [90]
+					},
+					"unloadEvaluator": {
+						"type": "lt",
+						"params": [80]
+					}
+				}],
+				"loadedDimensions": [{"host": "a"}]
+			}`,
+			shouldError: false,
+		},
+		{
+			description: "unmarshal with unsupported recovery threshold function",
+			query: `{
+				"expression" : "A",
+				"type": "threshold",
+				"conditions": [{
+					"evaluator": {
+						"type": "gt",
+						"params": [90]
+					},
+					"unloadEvaluator": {
+						"type": "foo",
+						"params": [80]
+					}
+				}]
+			}`,
+			shouldError:   true,
+			expectedError: "invalid recovery threshold",
+		},
+		{
+			description: "unmarshal range function with missing parameter",
+			query: `{
+				"expression" : "A",
+				"type": "threshold",
+				"conditions": [{
+					"evaluator": {
+						"type": "within_range",
+						"params": [20]
+					}
+				}]
+			}`,
+			shouldError:   true,
+			expectedError: "requires 2 parameters",
+		},
 		{
 			description: "unmarshal with bad expression",
 			query: `{
@@ -107,6 +166,115 @@ func TestUnmarshalThresholdCommand(t *testing.T) {
 	}
 }
 
+func TestUnmarshalHysteresisCommand(t *testing.T) {
+	var qmap map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"expression" : "A",
+		"type": "threshold",
+		"conditions": [{
+			"evaluator": {"type": "gt", "params": [90]},
+			"unloadEvaluator": {"type": "lt", "params": [80]}
+		}]
+	}`), &qmap))
+
+	require.True(t, IsHysteresisExpression(qmap))
+	require.NoError(t, SetLoadedDimensionsToHysteresisCommand(qmap, []data.Labels{{"host": "a"}}))
+
+	// The query model is sent to the expression service as JSON.
+	b, err := json.Marshal(qmap)
+	require.NoError(t, err)
+	qmap = nil
+	require.NoError(t, json.Unmarshal(b, &qmap))
+
+	cmd, err := UnmarshalThresholdCommand(&rawNode{RefID: "B", Query: qmap})
+	require.NoError(t, err)
+	require.Equal(t, ThresholdIsBelow, cmd.UnloadThresholdFunc)
+	require.Equal(t, []float64{80}, cmd.UnloadConditions)
+	require.Equal(t, []data.Labels{{"host": "a"}}, cmd.LoadedDimensions)
+
+	t.Run("threshold without recovery threshold is not a hysteresis expression", func(t *testing.T) {
+		var qmap map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"expression" : "A",
+			"type": "threshold",
+			"conditions": [{"evaluator": {"type": "gt", "params": [90]}}]
+		}`), &qmap))
+		require.False(t, IsHysteresisExpression(qmap))
+		require.Error(t, SetLoadedDimensionsToHysteresisCommand(qmap, nil))
+	})
+}
+
+func TestHysteresisExecute(t *testing.T) {
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			makeThresholdNumber(data.Labels{"host": "a"}, 85),
+			makeThresholdNumber(data.Labels{"host": "b"}, 85),
+			makeThresholdNumber(data.Labels{"host": "c"}, 75),
+			makeThresholdNumber(data.Labels{"host": "d"}, 95),
+		}},
+	}
+
+	cases := []struct {
+		description string
+		loaded      []data.Labels
+		expected    map[string]float64
+	}{
+		{
+			description: "without loaded dimensions only the threshold is used",
+			expected:    map[string]float64{"a": 0, "b": 0, "c": 0, "d": 1},
+		},
+		{
+			description: "loaded dimensions keep firing until the recovery threshold is met",
+			loaded: []data.Labels{
+				{"host": "a", "alertname": "test"},
+				{"host": "c", "alertname": "test"},
+			},
+			expected: map[string]float64{"a": 1, "b": 0, "c": 0, "d": 1},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			cmd, err := NewHysteresisCommand("B", "A", ThresholdIsAbove, []float64{90}, ThresholdIsBelow, []float64{80}, tc.loaded)
+			require.NoError(t, err)
+
+			res, err := cmd.Execute(context.Background(), time.Now(), vars)
+			require.NoError(t, err)
+			require.Len(t, res.Values, len(tc.expected))
+			for _, v := range res.Values {
+				n, ok := v.(mathexp.Number)
+				require.True(t, ok)
+				require.Equal(t, tc.expected[n.GetLabels()["host"]], *n.GetFloat64Value(), "host %s", n.GetLabels()["host"])
+			}
+		})
+	}
+
+	t.Run("loaded series keep firing per point", func(t *testing.T) {
+		series := mathexp.NewSeries("A", data.Labels{"host": "a"}, 3)
+		series.SetPoint(0, time.Unix(0, 0), ptr.Float64(95))
+		series.SetPoint(1, time.Unix(1, 0), ptr.Float64(85))
+		series.SetPoint(2, time.Unix(2, 0), ptr.Float64(75))
+
+		cmd, err := NewHysteresisCommand("B", "A", ThresholdIsAbove, []float64{90}, ThresholdIsBelow, []float64{80}, []data.Labels{{"host": "a"}})
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{series}}})
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+
+		s := res.Values[0].(mathexp.Series)
+		for i, expected := range []float64{1, 1, 0} {
+			_, f := s.GetPoint(i)
+			require.Equal(t, expected, *f)
+		}
+	})
+}
+
+func makeThresholdNumber(labels data.Labels, f float64) mathexp.Number {
+	n := mathexp.NewNumber("A", labels)
+	n.SetValue(&f)
+	return n
+}
+
 func TestThresholdCommandVars(t *testing.T) {
 	cmd, err := NewThresholdCommand("B", "A", "is_above", []float64{})
 	require.Nil(t, err)
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -159,6 +160,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	channelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, nil)
 
 	var managedStreamRunner *managedstream.Runner
+	historyEnabled := g.Cfg.LiveManagedStreamHistoryMaxFrames > 0 || g.Cfg.LiveManagedStreamHistoryMaxAge > 0
 	if g.IsHA() {
 		redisClient := redis.NewClient(&redis.Options{
 			Addr: g.Cfg.LiveHAEngineAddress,
@@ -167,22 +169,33 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		if _, err := cmd.Result(); err != nil {
 			return nil, fmt.Errorf("error pinging Redis: %v", err)
 		}
+		var frameHistory managedstream.FrameHistory
+		if historyEnabled {
+			frameHistory = managedstream.NewRedisFrameHistory(redisClient, g.Cfg.LiveManagedStreamHistoryMaxFrames, g.Cfg.LiveManagedStreamHistoryMaxAge)
+		}
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
 			managedstream.NewRedisFrameCache(redisClient),
+			frameHistory,
 		)
 	} else {
+		var frameHistory managedstream.FrameHistory
+		if historyEnabled {
+			frameHistory = managedstream.NewMemoryFrameHistory(g.Cfg.LiveManagedStreamHistoryMaxFrames, g.Cfg.LiveManagedStreamHistoryMaxAge)
+		}
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
 			managedstream.NewMemoryFrameCache(),
+			frameHistory,
 		)
 	}
 
 	g.ManagedStreamRunner = managedStreamRunner
 	if g.Features.IsEnabled(featuremgmt.FlagLivePipeline) {
 		var builder pipeline.RuleBuilder
+		var changeNotifier *pipeline.NodeChangeNotifier
 		if os.Getenv("GF_LIVE_DEV_BUILDER") != "" {
 			builder = &pipeline.DevRuleBuilder{
 				Node:                 node,
@@ -191,21 +204,35 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 				ChannelHandlerGetter: g,
 			}
 		} else {
-			storage := &pipeline.FileStorage{
-				DataPath:       cfg.DataPath,
-				SecretsService: g.SecretsService,
+			var storage pipeline.Storage
+			if cfg.LivePipelineStorage == "database" {
+				// Rules are shared by all Grafana server instances, so every change
+				// must reach the rule caches of all of them.
+				changeNotifier = pipeline.NewNodeChangeNotifier(node)
+				storage = pipeline.NewSQLStorage(g.SQLStore, g.SecretsService, changeNotifier)
+			} else {
+				storage = &pipeline.FileStorage{
+					DataPath:       cfg.DataPath,
+					SecretsService: g.SecretsService,
+				}
 			}
 			g.pipelineStorage = storage
+			g.archiveStorage = pipeline.NewArchiveStorage(filepath.Join(cfg.DataPath, "live", "archive"))
 			builder = &pipeline.StorageRuleBuilder{
 				Node:                 node,
 				ManagedStream:        g.ManagedStreamRunner,
 				FrameStorage:         pipeline.NewFrameStorage(),
+				WindowStorage:        pipeline.NewWindowStorage(),
+				ArchiveStorage:       g.archiveStorage,
 				Storage:              storage,
 				ChannelHandlerGetter: g,
 				SecretsService:       g.SecretsService,
 			}
 		}
 		channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
+		if changeNotifier != nil {
+			changeNotifier.OnChange(channelRuleGetter.Invalidate)
+		}
 
 		// Pre-build/validate channel rules for all organizations on start.
 		// This can be unreasonable to have in production scenario with many
@@ -428,6 +455,7 @@ type GrafanaLive struct {
 	ManagedStreamRunner *managedstream.Runner
 	Pipeline            *pipeline.Pipeline
 	pipelineStorage     pipeline.Storage
+	archiveStorage      *pipeline.ArchiveStorage
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
@@ -465,6 +493,15 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 		}
 	})
 
+	if g.archiveStorage != nil {
+		eGroup.Go(func() error {
+			if err := g.archiveStorage.Run(ctx); err != nil {
+				logger.Error("Error closing pipeline archive files", "error", err)
+			}
+			return ctx.Err()
+		})
+	}
+
 	if g.runStreamManager != nil {
 		// Only run stream manager if GrafanaLive properly initialized.
 		eGroup.Go(func() error {
@@ -1139,6 +1176,8 @@ func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *models.ReqContext) respon
 		Node:                 g.node,
 		ManagedStream:        g.ManagedStreamRunner,
 		FrameStorage:         pipeline.NewFrameStorage(),
+		WindowStorage:        pipeline.NewWindowStorage(),
+		ArchiveStorage:       g.archiveStorage,
 		Storage:              storage,
 		ChannelHandlerGetter: g,
 	}
@@ -0,0 +1,80 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// FrameHistory keeps the recent frames of managed stream channels, so that
+// panels subscribing to a channel can backfill the data pushed before they
+// subscribed.
+type FrameHistory interface {
+	// Add appends a frame to the history of a channel in org. The history is
+	// reset when the schema of the frame changed.
+	Add(ctx context.Context, orgID int64, channel string, frameJson data.FrameJSONCache, schemaUpdated bool) error
+	// GetFrames returns the full JSON frames in the history of a channel in
+	// org, oldest first.
+	GetFrames(ctx context.Context, orgID int64, channel string) ([]json.RawMessage, error)
+}
+
+// defaultHistoryMaxFrames is the maximum number of frames kept in the
+// history of a channel when no maximum is configured.
+const defaultHistoryMaxFrames = 1000
+
+// historyEntry is a frame in the history of a channel.
+type historyEntry struct {
+	// Time is the unix time in milliseconds the frame was added at.
+	Time  int64           `json:"time"`
+	Frame json.RawMessage `json:"frame"`
+	// ID makes entries with the same frame and time distinct, it is only set
+	// for entries stored in Redis sorted sets.
+	ID string `json:"id,omitempty"`
+}
+
+// mergeHistoryFrames merges the rows of the frames in history into a single
+// full JSON frame with the schema of the last frame. Frames with another
+// schema are skipped.
+func mergeHistoryFrames(frames []json.RawMessage) (json.RawMessage, error) {
+	if len(frames) == 0 {
+		return nil, nil
+	}
+	var last data.Frame
+	if err := json.Unmarshal(frames[len(frames)-1], &last); err != nil {
+		return nil, fmt.Errorf("error decoding frame: %w", err)
+	}
+	if len(frames) == 1 {
+		return frames[0], nil
+	}
+
+	merged := last.EmptyCopy()
+	for _, frameJSON := range frames {
+		var frame data.Frame
+		if err := json.Unmarshal(frameJSON, &frame); err != nil {
+			return nil, fmt.Errorf("error decoding frame: %w", err)
+		}
+		if !sameFields(merged, &frame) {
+			continue
+		}
+		for i, field := range frame.Fields {
+			for row := 0; row < field.Len(); row++ {
+				merged.Fields[i].Append(field.At(row))
+			}
+		}
+	}
+	return data.FrameToJSON(merged, data.IncludeAll)
+}
+
+func sameFields(a, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() {
+			return false
+		}
+	}
+	return true
+}
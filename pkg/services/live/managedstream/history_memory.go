@@ -0,0 +1,116 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// historySweepInterval is how often the memory history removes the channels
+// whose frames all expired.
+const historySweepInterval = time.Minute
+
+// MemoryFrameHistory keeps the history of channels in memory. It keeps at most
+// maxFrames frames and the frames of the last maxAge per channel. Zero maxFrames
+// keeps at most defaultHistoryMaxFrames frames, zero maxAge disables the age
+// limit.
+type MemoryFrameHistory struct {
+	mu        sync.RWMutex
+	maxFrames int
+	maxAge    time.Duration
+	frames    map[string][]historyEntry
+	lastSweep time.Time
+	nowFunc   func() time.Time
+}
+
+// NewMemoryFrameHistory ...
+func NewMemoryFrameHistory(maxFrames int, maxAge time.Duration) *MemoryFrameHistory {
+	if maxFrames <= 0 {
+		maxFrames = defaultHistoryMaxFrames
+	}
+	return &MemoryFrameHistory{
+		maxFrames: maxFrames,
+		maxAge:    maxAge,
+		frames:    map[string][]historyEntry{},
+		nowFunc:   time.Now,
+	}
+}
+
+func (h *MemoryFrameHistory) Add(_ context.Context, orgID int64, channel string, frameJson data.FrameJSONCache, schemaUpdated bool) error {
+	key := orgchannel.PrependOrgID(orgID, channel)
+	now := h.nowFunc()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entries := h.frames[key]
+	if schemaUpdated {
+		entries = nil
+	}
+	entries = append(entries, historyEntry{
+		Time:  now.UnixNano() / int64(time.Millisecond),
+		Frame: frameJson.Bytes(data.IncludeAll),
+	})
+	if len(entries) > h.maxFrames {
+		entries = entries[len(entries)-h.maxFrames:]
+	}
+	if h.maxAge > 0 {
+		minTime := now.Add(-h.maxAge).UnixNano() / int64(time.Millisecond)
+		entries = dropExpired(entries, minTime)
+		if now.Sub(h.lastSweep) >= historySweepInterval {
+			h.sweep(minTime)
+			h.lastSweep = now
+		}
+	}
+	h.frames[key] = entries
+	return nil
+}
+
+// sweep removes the channels that have no frame added after minTime, so that
+// channels nobody pushes into anymore do not stay in memory. Must be called
+// with the lock held.
+func (h *MemoryFrameHistory) sweep(minTime int64) {
+	for key, entries := range h.frames {
+		entries = dropExpired(entries, minTime)
+		if len(entries) == 0 {
+			delete(h.frames, key)
+			continue
+		}
+		h.frames[key] = entries
+	}
+}
+
+// dropExpired returns the entries added at or after minTime.
+func dropExpired(entries []historyEntry, minTime int64) []historyEntry {
+	for len(entries) > 0 && entries[0].Time < minTime {
+		entries = entries[1:]
+	}
+	return entries
+}
+
+func (h *MemoryFrameHistory) GetFrames(_ context.Context, orgID int64, channel string) ([]json.RawMessage, error) {
+	key := orgchannel.PrependOrgID(orgID, channel)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return historyFrames(h.frames[key], h.nowFunc(), h.maxAge), nil
+}
+
+// historyFrames returns the frames of the entries that are not older than
+// maxAge.
+func historyFrames(entries []historyEntry, now time.Time, maxAge time.Duration) []json.RawMessage {
+	var minTime int64
+	if maxAge > 0 {
+		minTime = now.Add(-maxAge).UnixNano() / int64(time.Millisecond)
+	}
+	frames := make([]json.RawMessage, 0, len(entries))
+	for _, e := range entries {
+		if e.Time >= minTime {
+			frames = append(frames, e.Frame)
+		}
+	}
+	return frames
+}
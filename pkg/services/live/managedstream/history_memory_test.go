@@ -0,0 +1,145 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/stretchr/testify/require"
+)
+
+func historyTestFrame(t *testing.T, values ...float64) data.FrameJSONCache {
+	t.Helper()
+	frameJsonCache, err := data.FrameToJSONCache(data.NewFrame("test", data.NewField("value", nil, values)))
+	require.NoError(t, err)
+	return frameJsonCache
+}
+
+func historyFrameValues(t *testing.T, frames []json.RawMessage) [][]float64 {
+	t.Helper()
+	values := make([][]float64, 0, len(frames))
+	for _, frameJSON := range frames {
+		var f data.Frame
+		require.NoError(t, json.Unmarshal(frameJSON, &f))
+		frameValues := make([]float64, 0, f.Rows())
+		for i := 0; i < f.Rows(); i++ {
+			frameValues = append(frameValues, f.Fields[0].At(i).(float64))
+		}
+		values = append(values, frameValues)
+	}
+	return values
+}
+
+// testFrameHistory expects a history of at most 3 frames.
+func testFrameHistory(t *testing.T, h FrameHistory) {
+	ctx := context.Background()
+
+	frames, err := h.GetFrames(ctx, 1, "stream/test/history")
+	require.NoError(t, err)
+	require.Empty(t, frames)
+
+	for i := 1; i <= 4; i++ {
+		require.NoError(t, h.Add(ctx, 1, "stream/test/history", historyTestFrame(t, float64(i)), i == 1))
+	}
+	frames, err = h.GetFrames(ctx, 1, "stream/test/history")
+	require.NoError(t, err)
+	require.Equal(t, [][]float64{{2}, {3}, {4}}, historyFrameValues(t, frames))
+
+	// Other orgs have their own history.
+	frames, err = h.GetFrames(ctx, 2, "stream/test/history")
+	require.NoError(t, err)
+	require.Empty(t, frames)
+
+	// A schema change resets the history.
+	require.NoError(t, h.Add(ctx, 1, "stream/test/history", historyTestFrame(t, 5, 6), true))
+	frames, err = h.GetFrames(ctx, 1, "stream/test/history")
+	require.NoError(t, err)
+	require.Equal(t, [][]float64{{5, 6}}, historyFrameValues(t, frames))
+
+	// Identical frames are all kept.
+	require.NoError(t, h.Add(ctx, 1, "stream/test/history", historyTestFrame(t, 5, 6), false))
+	require.NoError(t, h.Add(ctx, 1, "stream/test/history", historyTestFrame(t, 5, 6), false))
+	frames, err = h.GetFrames(ctx, 1, "stream/test/history")
+	require.NoError(t, err)
+	require.Equal(t, [][]float64{{5, 6}, {5, 6}, {5, 6}}, historyFrameValues(t, frames))
+}
+
+func TestMemoryFrameHistory(t *testing.T) {
+	h := NewMemoryFrameHistory(3, 0)
+	require.NotNil(t, h)
+	testFrameHistory(t, h)
+}
+
+func TestMemoryFrameHistory_MaxAge(t *testing.T) {
+	now := time.Now()
+	h := NewMemoryFrameHistory(0, time.Minute)
+	h.nowFunc = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, h.Add(ctx, 1, "stream/test/history", historyTestFrame(t, 1), true))
+	now = now.Add(40 * time.Second)
+	require.NoError(t, h.Add(ctx, 1, "stream/test/history", historyTestFrame(t, 2), false))
+	frames, err := h.GetFrames(ctx, 1, "stream/test/history")
+	require.NoError(t, err)
+	require.Equal(t, [][]float64{{1}, {2}}, historyFrameValues(t, frames))
+
+	now = now.Add(40 * time.Second)
+	frames, err = h.GetFrames(ctx, 1, "stream/test/history")
+	require.NoError(t, err)
+	require.Equal(t, [][]float64{{2}}, historyFrameValues(t, frames))
+
+	now = now.Add(time.Minute)
+	require.NoError(t, h.Add(ctx, 1, "stream/test/history", historyTestFrame(t, 3), false))
+	require.Len(t, h.frames["1/stream/test/history"], 1)
+}
+
+func TestMemoryFrameHistory_DefaultMaxFrames(t *testing.T) {
+	h := NewMemoryFrameHistory(0, time.Hour)
+	ctx := context.Background()
+	for i := 0; i <= defaultHistoryMaxFrames; i++ {
+		require.NoError(t, h.Add(ctx, 1, "stream/test/history", historyTestFrame(t, float64(i)), i == 0))
+	}
+	frames, err := h.GetFrames(ctx, 1, "stream/test/history")
+	require.NoError(t, err)
+	require.Len(t, frames, defaultHistoryMaxFrames)
+}
+
+func TestMemoryFrameHistory_RemovesExpiredChannels(t *testing.T) {
+	now := time.Now()
+	h := NewMemoryFrameHistory(0, time.Minute)
+	h.nowFunc = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, h.Add(ctx, 1, "stream/test/idle", historyTestFrame(t, 1), true))
+	require.NoError(t, h.Add(ctx, 1, "stream/test/active", historyTestFrame(t, 1), true))
+	now = now.Add(2 * historySweepInterval)
+	require.NoError(t, h.Add(ctx, 1, "stream/test/active", historyTestFrame(t, 2), false))
+
+	require.NotContains(t, h.frames, "1/stream/test/idle")
+	require.Len(t, h.frames["1/stream/test/active"], 1)
+}
+
+func TestMergeHistoryFrames(t *testing.T) {
+	var frames []json.RawMessage
+	for _, frame := range []*data.Frame{
+		data.NewFrame("test", data.NewField("value", nil, []float64{1, 2})),
+		data.NewFrame("test", data.NewField("other", nil, []float64{10})),
+		data.NewFrame("test", data.NewField("value", nil, []float64{3})),
+	} {
+		frameJSON, err := data.FrameToJSON(frame, data.IncludeAll)
+		require.NoError(t, err)
+		frames = append(frames, frameJSON)
+	}
+
+	merged, err := mergeHistoryFrames(frames)
+	require.NoError(t, err)
+	// The frame with another schema is skipped.
+	require.Equal(t, [][]float64{{1, 2, 3}}, historyFrameValues(t, []json.RawMessage{merged}))
+
+	merged, err = mergeHistoryFrames(nil)
+	require.NoError(t, err)
+	require.Nil(t, merged)
+}
@@ -0,0 +1,95 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+	"github.com/grafana/grafana/pkg/util"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// RedisFrameHistory keeps the history of channels in Redis sorted sets scored by
+// the time frames were added at, so that it is shared by all Grafana servers in HA setup. It keeps at most maxFrames
+// frames and the frames of the last maxAge per channel. Zero maxFrames keeps
+// at most defaultHistoryMaxFrames frames, zero maxAge disables the age limit.
+type RedisFrameHistory struct {
+	redisClient *redis.Client
+	maxFrames   int
+	maxAge      time.Duration
+	nowFunc     func() time.Time
+}
+
+// NewRedisFrameHistory ...
+func NewRedisFrameHistory(redisClient *redis.Client, maxFrames int, maxAge time.Duration) *RedisFrameHistory {
+	if maxFrames <= 0 {
+		maxFrames = defaultHistoryMaxFrames
+	}
+	return &RedisFrameHistory{
+		redisClient: redisClient,
+		maxFrames:   maxFrames,
+		maxAge:      maxAge,
+		nowFunc:     time.Now,
+	}
+}
+
+func (h *RedisFrameHistory) Add(ctx context.Context, orgID int64, channel string, frameJson data.FrameJSONCache, schemaUpdated bool) error {
+	now := h.nowFunc()
+	nowMillis := now.UnixNano() / int64(time.Millisecond)
+	// Members of a sorted set are unique, the ID keeps identical frames added
+	// in the same millisecond.
+	entry, err := json.Marshal(historyEntry{
+		Time:  nowMillis,
+		Frame: frameJson.Bytes(data.IncludeAll),
+		ID:    util.GenerateShortUID(),
+	})
+	if err != nil {
+		return err
+	}
+
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+
+	pipe := h.redisClient.TxPipeline()
+	defer func() { _ = pipe.Close() }()
+
+	if schemaUpdated {
+		pipe.Del(ctx, key)
+	}
+	pipe.ZAdd(ctx, key, &redis.Z{Score: float64(nowMillis), Member: entry})
+	pipe.ZRemRangeByRank(ctx, key, 0, int64(-h.maxFrames-1))
+	ttl := frameCacheTTL
+	if h.maxAge > 0 {
+		minMillis := now.Add(-h.maxAge).UnixNano() / int64(time.Millisecond)
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(minMillis, 10))
+		ttl = h.maxAge
+	}
+	pipe.Expire(ctx, key, ttl)
+
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (h *RedisFrameHistory) GetFrames(ctx context.Context, orgID int64, channel string) ([]json.RawMessage, error) {
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+	result, err := h.redisClient.ZRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]historyEntry, 0, len(result))
+	for _, r := range result {
+		var e historyEntry
+		if err := json.Unmarshal([]byte(r), &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return historyFrames(entries, h.nowFunc(), h.maxAge), nil
+}
+
+func getHistoryKey(channelID string) string {
+	return "gf_live.managed_stream_history." + channelID
+}
@@ -0,0 +1,40 @@
+//go:build redis
+// +build redis
+
+package managedstream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisFrameHistory(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	require.NoError(t, redisClient.Del(context.Background(), getHistoryKey("1/stream/test/history")).Err())
+	h := NewRedisFrameHistory(redisClient, 3, 0)
+	require.NotNil(t, h)
+	testFrameHistory(t, h)
+}
+
+func TestRedisFrameHistory_IdenticalFramesInSameMillisecond(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	require.NoError(t, redisClient.Del(context.Background(), getHistoryKey("1/stream/test/identical")).Err())
+	h := NewRedisFrameHistory(redisClient, 3, 0)
+	now := time.Now()
+	h.nowFunc = func() time.Time { return now }
+
+	ctx := context.Background()
+	require.NoError(t, h.Add(ctx, 1, "stream/test/identical", historyTestFrame(t, 1), true))
+	require.NoError(t, h.Add(ctx, 1, "stream/test/identical", historyTestFrame(t, 1), false))
+	frames, err := h.GetFrames(ctx, 1, "stream/test/identical")
+	require.NoError(t, err)
+	require.Equal(t, [][]float64{{1}, {1}}, historyFrameValues(t, frames))
+}
@@ -0,0 +1,262 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	archiveFileExt           = ".ndjson"
+	archiveFileTimeFormat    = "20060102T150405.000000000Z"
+	defaultArchiveFileSize   = 64 * 1024 * 1024
+	defaultArchiveRotateTime = time.Hour
+	// archiveSweepInterval is how often idle files are closed, and files are
+	// rotated and removed without waiting for the next frame.
+	archiveSweepInterval = time.Minute
+	// archiveIdleTimeout is how long a file stays open without writes.
+	archiveIdleTimeout = 5 * time.Minute
+)
+
+// ArchiveStorage keeps the files of archive frame outputs open, so that files
+// survive rebuilding channel rules. Files are kept in a directory per
+// organization under the archive path.
+type ArchiveStorage struct {
+	path    string
+	mu      sync.Mutex
+	writers map[string]*archiveWriter
+}
+
+func NewArchiveStorage(path string) *ArchiveStorage {
+	return &ArchiveStorage{
+		path:    path,
+		writers: map[string]*archiveWriter{},
+	}
+}
+
+// writer returns the writer of the archive directory in org, creating it if
+// there is none yet. The directory must be relative and stay inside the
+// archive directory of the org.
+func (s *ArchiveStorage) writer(orgID int64, directory string) (*archiveWriter, error) {
+	directory, err := cleanArchiveDirectory(directory)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(s.path, strconv.FormatInt(orgID, 10), directory)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.writers[dir]
+	if !ok {
+		w = &archiveWriter{dir: dir}
+		s.writers[dir] = w
+	}
+	return w, nil
+}
+
+// Run periodically closes idle and expired archive files and removes old
+// files until the context is cancelled, then closes all open files.
+func (s *ArchiveStorage) Run(ctx context.Context) error {
+	ticker := time.NewTicker(archiveSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.sweep(time.Now())
+		case <-ctx.Done():
+			return s.Close()
+		}
+	}
+}
+
+func (s *ArchiveStorage) sweep(now time.Time) {
+	s.mu.Lock()
+	writers := make([]*archiveWriter, 0, len(s.writers))
+	for _, w := range s.writers {
+		writers = append(writers, w)
+	}
+	s.mu.Unlock()
+
+	for _, w := range writers {
+		w.sweep(now)
+	}
+}
+
+// Close closes all open archive files.
+func (s *ArchiveStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var lastErr error
+	for _, w := range s.writers {
+		w.mu.Lock()
+		if err := w.close(); err != nil {
+			lastErr = err
+		}
+		w.mu.Unlock()
+	}
+	return lastErr
+}
+
+func cleanArchiveDirectory(directory string) (string, error) {
+	directory = filepath.Clean(filepath.FromSlash(directory))
+	if filepath.IsAbs(directory) || directory == ".." || strings.HasPrefix(directory, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive directory must be inside the archive path: %s", directory)
+	}
+	return directory, nil
+}
+
+// archiveWriter appends lines to the current file of an archive directory,
+// starting a new file when the current one gets too large or too old.
+type archiveWriter struct {
+	mu     sync.Mutex
+	dir    string
+	file   *os.File
+	size   int64
+	opened time.Time
+	// config and lastWrite are those of the last write, they are used to
+	// sweep the directory between writes.
+	config    ArchiveOutputConfig
+	lastWrite time.Time
+}
+
+func (w *archiveWriter) write(line []byte, config ArchiveOutputConfig, now time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.config = config
+	w.lastWrite = now
+
+	maxSize := config.MaxFileSizeBytes
+	if maxSize <= 0 {
+		maxSize = defaultArchiveFileSize
+	}
+
+	if w.file != nil && ((w.size > 0 && w.size+int64(len(line)) > maxSize) || now.Sub(w.opened) >= archiveRotateTime(config)) {
+		if err := w.close(); err != nil {
+			return err
+		}
+	}
+	if w.file == nil {
+		if err := w.open(now); err != nil {
+			return err
+		}
+		if err := w.removeOldFiles(config, now); err != nil {
+			logger.Error("Error removing old archive files", "dir", w.dir, "error", err)
+		}
+	}
+
+	n, err := w.file.Write(line)
+	w.size += int64(n)
+	return err
+}
+
+// sweep closes the current file if it was not written to recently or is due
+// for rotation, and removes old files.
+func (w *archiveWriter) sweep(now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.lastWrite.IsZero() {
+		return
+	}
+	if w.file != nil && (now.Sub(w.lastWrite) >= archiveIdleTimeout || now.Sub(w.opened) >= archiveRotateTime(w.config)) {
+		if err := w.close(); err != nil {
+			logger.Error("Error closing archive file", "dir", w.dir, "error", err)
+		}
+	}
+	if err := w.removeOldFiles(w.config, now); err != nil {
+		logger.Error("Error removing old archive files", "dir", w.dir, "error", err)
+	}
+}
+
+func archiveRotateTime(config ArchiveOutputConfig) time.Duration {
+	if config.RotateSeconds <= 0 {
+		return defaultArchiveRotateTime
+	}
+	return time.Duration(config.RotateSeconds) * time.Second
+}
+
+func (w *archiveWriter) open(now time.Time) error {
+	if err := os.MkdirAll(w.dir, 0750); err != nil {
+		return fmt.Errorf("error creating archive directory: %w", err)
+	}
+	// File names sort in the order the files were started.
+	name := filepath.Join(w.dir, now.UTC().Format(archiveFileTimeFormat)+archiveFileExt)
+	// Safe to ignore gosec warning G304.
+	// nolint:gosec
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("error opening archive file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	w.opened = now
+	return nil
+}
+
+func (w *archiveWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// removeOldFiles removes the files older than the retention time, and the
+// oldest files exceeding the max number of files. The current file is kept.
+func (w *archiveWriter) removeOldFiles(config ArchiveOutputConfig, now time.Time) error {
+	if config.MaxFiles <= 0 && config.RetentionSeconds <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return err
+	}
+	var current string
+	open := 0
+	if w.file != nil {
+		current = w.file.Name()
+		open = 1
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), archiveFileExt) && filepath.Join(w.dir, e.Name()) != current {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var remove []string
+	if config.MaxFiles > 0 && len(names)+open > config.MaxFiles {
+		n := len(names) + open - config.MaxFiles
+		remove, names = names[:n], names[n:]
+	}
+	if config.RetentionSeconds > 0 {
+		minTime := now.Add(-time.Duration(config.RetentionSeconds) * time.Second)
+		for _, name := range names {
+			info, err := os.Stat(filepath.Join(w.dir, name))
+			if err != nil {
+				return err
+			}
+			if info.ModTime().Before(minTime) {
+				remove = append(remove, name)
+			}
+		}
+	}
+	for _, name := range remove {
+		if err := os.Remove(filepath.Join(w.dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
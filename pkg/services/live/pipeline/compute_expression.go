@@ -0,0 +1,253 @@
+package pipeline
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// computeExpression is a compiled math expression over the fields of a frame
+// row. It returns false if a field it refers to has no value.
+type computeExpression func(value func(field string) (float64, bool)) (float64, bool)
+
+// computeFunctions are the functions that compute expressions can call, by
+// name and number of arguments.
+var computeFunctions = map[string]struct {
+	args int
+	fn   func(args []float64) float64
+}{
+	"abs":   {1, func(a []float64) float64 { return math.Abs(a[0]) }},
+	"ceil":  {1, func(a []float64) float64 { return math.Ceil(a[0]) }},
+	"exp":   {1, func(a []float64) float64 { return math.Exp(a[0]) }},
+	"floor": {1, func(a []float64) float64 { return math.Floor(a[0]) }},
+	"log":   {1, func(a []float64) float64 { return math.Log(a[0]) }},
+	"max":   {2, func(a []float64) float64 { return math.Max(a[0], a[1]) }},
+	"min":   {2, func(a []float64) float64 { return math.Min(a[0], a[1]) }},
+	"pow":   {2, func(a []float64) float64 { return math.Pow(a[0], a[1]) }},
+	"round": {1, func(a []float64) float64 { return math.Round(a[0]) }},
+	"sqrt":  {1, func(a []float64) float64 { return math.Sqrt(a[0]) }},
+}
+
+// parseComputeExpression compiles an expression with numbers, the operators
+// + - * / % ^, parentheses, the functions of computeFunctions and fields. Fields
+// are referred to by name, or as ${name} if the name has other characters than
+// letters, digits, underscores and dots.
+func parseComputeExpression(expression string) (computeExpression, error) {
+	p := &computeParser{input: expression}
+	expr, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos:], p.pos)
+	}
+	return expr, nil
+}
+
+type computeParser struct {
+	input string
+	pos   int
+}
+
+func (p *computeParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// consume skips spaces and the next character if it is one of chars.
+func (p *computeParser) consume(chars string) (byte, bool) {
+	p.skipSpaces()
+	if p.pos < len(p.input) && strings.IndexByte(chars, p.input[p.pos]) >= 0 {
+		p.pos++
+		return p.input[p.pos-1], true
+	}
+	return 0, false
+}
+
+func (p *computeParser) parseSum() (computeExpression, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.consume("+-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryComputeExpression(op, left, right)
+	}
+}
+
+func (p *computeParser) parseProduct() (computeExpression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.consume("*/%")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryComputeExpression(op, left, right)
+	}
+}
+
+func (p *computeParser) parseUnary() (computeExpression, error) {
+	if _, ok := p.consume("-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(value func(string) (float64, bool)) (float64, bool) {
+			v, ok := operand(value)
+			return -v, ok
+		}, nil
+	}
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.consume("^"); ok {
+		// Exponentiation is right associative.
+		exponent, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return binaryComputeExpression('^', base, exponent), nil
+	}
+	return base, nil
+}
+
+func (p *computeParser) parsePrimary() (computeExpression, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	start := p.pos
+	c := p.input[p.pos]
+	switch {
+	case c == '(':
+		p.pos++
+		expr, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.consume(")"); !ok {
+			return nil, fmt.Errorf("missing ) for ( at position %d", start)
+		}
+		return expr, nil
+	case strings.HasPrefix(p.input[p.pos:], "${"):
+		end := strings.IndexByte(p.input[p.pos:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("missing } for ${ at position %d", start)
+		}
+		p.pos += end + 1
+		return fieldComputeExpression(p.input[start+2 : start+end]), nil
+	case c == '.' || (c >= '0' && c <= '9'):
+		for p.pos < len(p.input) && (p.input[p.pos] == '.' || (p.input[p.pos] >= '0' && p.input[p.pos] <= '9') ||
+			p.input[p.pos] == 'e' || p.input[p.pos] == 'E' ||
+			((p.input[p.pos] == '+' || p.input[p.pos] == '-') && (p.input[p.pos-1] == 'e' || p.input[p.pos-1] == 'E'))) {
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", p.input[start:p.pos])
+		}
+		return func(func(string) (float64, bool)) (float64, bool) { return v, true }, nil
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for p.pos < len(p.input) && (p.input[p.pos] == '_' || p.input[p.pos] == '.' ||
+			unicode.IsLetter(rune(p.input[p.pos])) || unicode.IsDigit(rune(p.input[p.pos]))) {
+			p.pos++
+		}
+		name := p.input[start:p.pos]
+		if _, ok := p.consume("("); ok {
+			return p.parseCall(name, start)
+		}
+		return fieldComputeExpression(name), nil
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", c, start)
+	}
+}
+
+func (p *computeParser) parseCall(name string, start int) (computeExpression, error) {
+	function, ok := computeFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at position %d", name, start)
+	}
+	var args []computeExpression
+	if _, ok := p.consume(")"); !ok {
+		for {
+			arg, err := p.parseSum()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			sep, ok := p.consume(",)")
+			if !ok {
+				return nil, fmt.Errorf("missing ) for call of %s at position %d", name, start)
+			}
+			if sep == ')' {
+				break
+			}
+		}
+	}
+	if len(args) != function.args {
+		return nil, fmt.Errorf("function %s takes %d arguments, got %d", name, function.args, len(args))
+	}
+	return func(value func(string) (float64, bool)) (float64, bool) {
+		values := make([]float64, len(args))
+		for i, arg := range args {
+			v, ok := arg(value)
+			if !ok {
+				return 0, false
+			}
+			values[i] = v
+		}
+		return function.fn(values), true
+	}, nil
+}
+
+func fieldComputeExpression(name string) computeExpression {
+	return func(value func(string) (float64, bool)) (float64, bool) {
+		return value(name)
+	}
+}
+
+func binaryComputeExpression(op byte, left, right computeExpression) computeExpression {
+	return func(value func(string) (float64, bool)) (float64, bool) {
+		l, ok := left(value)
+		if !ok {
+			return 0, false
+		}
+		r, ok := right(value)
+		if !ok {
+			return 0, false
+		}
+		switch op {
+		case '+':
+			return l + r, true
+		case '-':
+			return l - r, true
+		case '*':
+			return l * r, true
+		case '/':
+			return l / r, true
+		case '%':
+			return math.Mod(l, r), true
+		default:
+			return math.Pow(l, r), true
+		}
+	}
+}
@@ -34,11 +34,13 @@ type ChannelRule struct {
 }
 
 type ConverterConfig struct {
-	Type                      string                     `json:"type" ts_type:"Omit<keyof ConverterConfig, 'type'>"`
-	AutoJsonConverterConfig   *AutoJsonConverterConfig   `json:"jsonAuto,omitempty"`
-	ExactJsonConverterConfig  *ExactJsonConverterConfig  `json:"jsonExact,omitempty"`
-	AutoInfluxConverterConfig *AutoInfluxConverterConfig `json:"influxAuto,omitempty"`
-	JsonFrameConverterConfig  *JsonFrameConverterConfig  `json:"jsonFrame,omitempty"`
+	Type                          string                         `json:"type" ts_type:"Omit<keyof ConverterConfig, 'type'>"`
+	AutoJsonConverterConfig       *AutoJsonConverterConfig       `json:"jsonAuto,omitempty"`
+	ExactJsonConverterConfig      *ExactJsonConverterConfig      `json:"jsonExact,omitempty"`
+	AutoInfluxConverterConfig     *AutoInfluxConverterConfig     `json:"influxAuto,omitempty"`
+	JsonFrameConverterConfig      *JsonFrameConverterConfig      `json:"jsonFrame,omitempty"`
+	AutoPrometheusConverterConfig *AutoPrometheusConverterConfig `json:"prometheusAuto,omitempty"`
+	AutoOTLPConverterConfig       *AutoOTLPConverterConfig       `json:"otlpAuto,omitempty"`
 }
 
 type DropFieldsFrameProcessorConfig struct {
@@ -49,11 +51,39 @@ type KeepFieldsFrameProcessorConfig struct {
 	FieldNames []string `json:"fieldNames"`
 }
 
+type WindowFrameProcessorConfig struct {
+	// SizeSeconds is the length of the windows.
+	SizeSeconds int64 `json:"sizeSeconds"`
+	// SlideSeconds is the time between the ends of consecutive windows, which
+	// overlap if it is less than the size. Default is the size, which makes
+	// tumbling windows.
+	SlideSeconds int64 `json:"slideSeconds,omitempty"`
+	// TimeField is the name of the time field of the rows. Default is the first
+	// time field. Rows of frames without time field get the time they arrive.
+	TimeField string `json:"timeField,omitempty"`
+	// Aggregations of the numeric fields, any of "mean", "min", "max", "last"
+	// and "count". Default is "mean".
+	Aggregations []string `json:"aggregations,omitempty"`
+}
+
+type ComputedFieldConfig struct {
+	Name string `json:"name"`
+	// Expression over the numeric fields, like "(temperature - 32) / 1.8".
+	Expression string            `json:"expression"`
+	Config     *data.FieldConfig `json:"config,omitempty" ts_type:"FieldConfig"`
+}
+
+type ComputeFieldsFrameProcessorConfig struct {
+	Fields []ComputedFieldConfig `json:"fields"`
+}
+
 type FrameProcessorConfig struct {
-	Type                      string                          `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
-	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
-	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
-	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	Type                         string                             `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
+	DropFieldsProcessorConfig    *DropFieldsFrameProcessorConfig    `json:"dropFields,omitempty"`
+	KeepFieldsProcessorConfig    *KeepFieldsFrameProcessorConfig    `json:"keepFields,omitempty"`
+	MultipleProcessorConfig      *MultipleFrameProcessorConfig      `json:"multiple,omitempty"`
+	WindowProcessorConfig        *WindowFrameProcessorConfig        `json:"window,omitempty"`
+	ComputeFieldsProcessorConfig *ComputeFieldsFrameProcessorConfig `json:"computeFields,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
@@ -78,6 +108,25 @@ type LokiOutputConfig struct {
 	UID string `json:"uid"`
 }
 
+type ArchiveOutputConfig struct {
+	// Directory of the archive files, relative to the archive directory of the
+	// organization. Default is the channel of the frames, so that every channel
+	// is archived into its own files.
+	Directory string `json:"directory,omitempty"`
+	// MaxFileSizeBytes is the size after which a new file is started. Default
+	// is 64 MiB.
+	MaxFileSizeBytes int64 `json:"maxFileSizeBytes,omitempty"`
+	// RotateSeconds is the age after which a new file is started. Default is
+	// one hour.
+	RotateSeconds int64 `json:"rotateSeconds,omitempty"`
+	// RetentionSeconds is the age after which files are removed. Default is to
+	// keep files forever.
+	RetentionSeconds int64 `json:"retentionSeconds,omitempty"`
+	// MaxFiles is the number of files to keep in the directory. Default is no
+	// limit.
+	MaxFiles int `json:"maxFiles,omitempty"`
+}
+
 type MultipleSubscriberConfig struct {
 	Subscribers []SubscriberConfig `json:"subscribers"`
 }
@@ -108,6 +157,7 @@ type FrameOutputterConfig struct {
 	RemoteWriteOutputConfig *RemoteWriteOutputConfig   `json:"remoteWrite,omitempty"`
 	LokiOutputConfig        *LokiOutputConfig          `json:"loki,omitempty"`
 	ChangeLogOutputConfig   *ChangeLogOutputConfig     `json:"changeLog,omitempty"`
+	ArchiveOutputConfig     *ArchiveOutputConfig       `json:"archive,omitempty"`
 }
 
 type MultipleFrameConditionCheckerConfig struct {
@@ -151,4 +201,17 @@ type AutoInfluxConverterConfig struct {
 
 type JsonFrameConverterConfig struct{}
 
+type AutoPrometheusConverterConfig struct {
+	// FrameFormat is "labels_column" (default) or "wide".
+	FrameFormat string `json:"frameFormat,omitempty"`
+}
+
+type AutoOTLPConverterConfig struct {
+	// FrameFormat is "labels_column" (default) or "wide".
+	FrameFormat string `json:"frameFormat,omitempty"`
+	// ResourceAttributes lists the resource attributes that are added to the
+	// labels of all data points of the resource.
+	ResourceAttributes []string `json:"resourceAttributes,omitempty"`
+}
+
 type ManagedStreamOutputConfig struct{}
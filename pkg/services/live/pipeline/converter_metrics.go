@@ -0,0 +1,118 @@
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Frame formats of metric converters. Both put each metric into its own frame.
+// In the wide format every series of the metric is a value field with the
+// labels of the series, and every timestamp is a row. In the labels column
+// format every sample is a row with the labels of its series in a labels field.
+const (
+	MetricFrameFormatWide         = "wide"
+	MetricFrameFormatLabelsColumn = "labels_column"
+)
+
+// metricSample is a sample of a metric series decoded by a metric converter.
+type metricSample struct {
+	Name   string
+	Labels data.Labels
+	Time   time.Time
+	Value  float64
+}
+
+// metricSamplesToChannelFrames groups samples by metric and returns a frame
+// per metric. The frame of a metric is sent into a channel with the metric name
+// added to the path of the original channel, so every metric can be handled by
+// its own channel rule.
+func metricSamplesToChannelFrames(channel string, frameFormat string, samples []metricSample) ([]*ChannelFrame, error) {
+	if frameFormat == "" {
+		frameFormat = MetricFrameFormatLabelsColumn
+	}
+	if frameFormat != MetricFrameFormatWide && frameFormat != MetricFrameFormatLabelsColumn {
+		return nil, fmt.Errorf("unsupported frame format: %s", frameFormat)
+	}
+
+	// Maintain the order of metrics as they appear in input.
+	var names []string
+	metricSamples := map[string][]metricSample{}
+	for _, s := range samples {
+		if _, ok := metricSamples[s.Name]; !ok {
+			names = append(names, s.Name)
+		}
+		metricSamples[s.Name] = append(metricSamples[s.Name], s)
+	}
+
+	channelFrames := make([]*ChannelFrame, 0, len(names))
+	for _, name := range names {
+		var frame *data.Frame
+		if frameFormat == MetricFrameFormatWide {
+			frame = wideMetricFrame(name, metricSamples[name])
+		} else {
+			frame = labelsColumnMetricFrame(name, metricSamples[name])
+		}
+		channelFrames = append(channelFrames, &ChannelFrame{
+			Channel: channel + "/" + metricChannelPath(name),
+			Frame:   frame,
+		})
+	}
+	return channelFrames, nil
+}
+
+func labelsColumnMetricFrame(name string, samples []metricSample) *data.Frame {
+	labelsField := data.NewField("labels", nil, make([]string, 0, len(samples)))
+	timeField := data.NewField("time", nil, make([]time.Time, 0, len(samples)))
+	valueField := data.NewField("value", nil, make([]float64, 0, len(samples)))
+	for _, s := range samples {
+		labelsField.Append(s.Labels.String())
+		timeField.Append(s.Time)
+		valueField.Append(s.Value)
+	}
+	return data.NewFrame(name, labelsField, timeField, valueField)
+}
+
+func wideMetricFrame(name string, samples []metricSample) *data.Frame {
+	var times []time.Time
+	rows := map[time.Time]int{}
+	for _, s := range samples {
+		if _, ok := rows[s.Time]; !ok {
+			rows[s.Time] = 0
+			times = append(times, s.Time)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	for i, t := range times {
+		rows[t] = i
+	}
+
+	fields := []*data.Field{data.NewField("time", nil, times)}
+	seriesFields := map[string]*data.Field{}
+	for _, s := range samples {
+		key := s.Labels.String()
+		field, ok := seriesFields[key]
+		if !ok {
+			field = data.NewField("value", s.Labels, make([]*float64, len(times)))
+			seriesFields[key] = field
+			fields = append(fields, field)
+		}
+		value := s.Value
+		field.Set(rows[s.Time], &value)
+	}
+	return data.NewFrame(name, fields...)
+}
+
+// metricChannelPath replaces the characters of the metric name that are not
+// allowed in channel paths with underscores.
+func metricChannelPath(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-' || r == '=' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,172 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
+	metricsv1 "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// AutoOTLPConverter decodes OTLP/JSON metrics input, as sent to the
+// /v1/metrics endpoint of OTLP/HTTP receivers, and transforms it to several
+// ChannelFrame objects where Channel is constructed from original channel +
+// / + <metric_name>. The attributes of data points become labels. Histograms
+// and summaries are split into _bucket, _sum and _count metrics like in
+// Prometheus.
+type AutoOTLPConverter struct {
+	config      AutoOTLPConverterConfig
+	nowTimeFunc func() time.Time
+}
+
+// NewAutoOTLPConverter creates new AutoOTLPConverter.
+func NewAutoOTLPConverter(config AutoOTLPConverterConfig) *AutoOTLPConverter {
+	return &AutoOTLPConverter{config: config}
+}
+
+const ConverterTypeOTLPAuto = "otlpAuto"
+
+func (c *AutoOTLPConverter) Type() string {
+	return ConverterTypeOTLPAuto
+}
+
+func (c *AutoOTLPConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	var metricsData metricsv1.MetricsData
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, &metricsData); err != nil {
+		return nil, fmt.Errorf("error parsing metrics: %w", err)
+	}
+
+	// Data points without timestamp get the time the input was received.
+	nowTimeFunc := c.nowTimeFunc
+	if nowTimeFunc == nil {
+		nowTimeFunc = time.Now
+	}
+	now := nowTimeFunc()
+	var samples []metricSample
+	for _, rm := range metricsData.GetResourceMetrics() {
+		resourceLabels := data.Labels{}
+		for _, attr := range rm.GetResource().GetAttributes() {
+			for _, name := range c.config.ResourceAttributes {
+				if attr.GetKey() == name {
+					resourceLabels[name] = otlpAttributeValue(attr.GetValue())
+				}
+			}
+		}
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				samples = append(samples, otlpSamples(m, resourceLabels, now)...)
+			}
+		}
+	}
+	return metricSamplesToChannelFrames(vars.Channel, c.config.FrameFormat, samples)
+}
+
+type otlpDataPoint interface {
+	GetAttributes() []*commonv1.KeyValue
+	GetTimeUnixNano() uint64
+	GetFlags() uint32
+}
+
+func otlpSamples(m *metricsv1.Metric, resourceLabels data.Labels, now time.Time) []metricSample {
+	var samples []metricSample
+	add := func(dp otlpDataPoint, name string, labels data.Labels, value float64) {
+		t := now
+		if dp.GetTimeUnixNano() > 0 {
+			t = time.Unix(0, int64(dp.GetTimeUnixNano()))
+		}
+		samples = append(samples, metricSample{Name: name, Labels: labels, Time: t, Value: value})
+	}
+	numberDataPoints := m.GetGauge().GetDataPoints()
+	if m.GetSum() != nil {
+		numberDataPoints = m.GetSum().GetDataPoints()
+	}
+
+	name := m.GetName()
+	for _, dp := range numberDataPoints {
+		if skipOTLPDataPoint(dp) {
+			continue
+		}
+		value := dp.GetAsDouble()
+		if _, ok := dp.GetValue().(*metricsv1.NumberDataPoint_AsInt); ok {
+			value = float64(dp.GetAsInt())
+		}
+		add(dp, name, otlpLabels(resourceLabels, dp.GetAttributes()), value)
+	}
+	for _, dp := range m.GetHistogram().GetDataPoints() {
+		if skipOTLPDataPoint(dp) {
+			continue
+		}
+		labels := otlpLabels(resourceLabels, dp.GetAttributes())
+		var cumulativeCount uint64
+		for i, count := range dp.GetBucketCounts() {
+			cumulativeCount += count
+			le := "+Inf"
+			if i < len(dp.GetExplicitBounds()) {
+				le = strconv.FormatFloat(dp.GetExplicitBounds()[i], 'g', -1, 64)
+			}
+			bucketLabels := labels.Copy()
+			bucketLabels["le"] = le
+			add(dp, name+"_bucket", bucketLabels, float64(cumulativeCount))
+		}
+		add(dp, name+"_sum", labels, dp.GetSum())
+		add(dp, name+"_count", labels, float64(dp.GetCount()))
+	}
+	for _, dp := range m.GetExponentialHistogram().GetDataPoints() {
+		if skipOTLPDataPoint(dp) {
+			continue
+		}
+		labels := otlpLabels(resourceLabels, dp.GetAttributes())
+		add(dp, name+"_sum", labels, dp.GetSum())
+		add(dp, name+"_count", labels, float64(dp.GetCount()))
+	}
+	for _, dp := range m.GetSummary().GetDataPoints() {
+		if skipOTLPDataPoint(dp) {
+			continue
+		}
+		labels := otlpLabels(resourceLabels, dp.GetAttributes())
+		for _, q := range dp.GetQuantileValues() {
+			add(dp, name, labelsWith(labels, "quantile", q.GetQuantile()), q.GetValue())
+		}
+		add(dp, name+"_sum", labels, dp.GetSum())
+		add(dp, name+"_count", labels, float64(dp.GetCount()))
+	}
+	return samples
+}
+
+// skipOTLPDataPoint returns true for data points that are flagged to have no
+// recorded value, which are sent to mark series as stale.
+func skipOTLPDataPoint(dp otlpDataPoint) bool {
+	return dp.GetFlags()&uint32(metricsv1.DataPointFlags_FLAG_NO_RECORDED_VALUE) != 0
+}
+
+// otlpLabels returns the labels of the resource with the attributes of the
+// data point.
+func otlpLabels(resourceLabels data.Labels, attrs []*commonv1.KeyValue) data.Labels {
+	labels := resourceLabels.Copy()
+	for _, attr := range attrs {
+		labels[attr.GetKey()] = otlpAttributeValue(attr.GetValue())
+	}
+	return labels
+}
+
+func otlpAttributeValue(v *commonv1.AnyValue) string {
+	switch value := v.GetValue().(type) {
+	case *commonv1.AnyValue_StringValue:
+		return value.StringValue
+	case *commonv1.AnyValue_BoolValue:
+		return strconv.FormatBool(value.BoolValue)
+	case *commonv1.AnyValue_IntValue:
+		return strconv.FormatInt(value.IntValue, 10)
+	case *commonv1.AnyValue_DoubleValue:
+		return strconv.FormatFloat(value.DoubleValue, 'g', -1, 64)
+	default:
+		// Arrays, key-value lists and bytes have no natural string form, so
+		// they are encoded as JSON.
+		b, _ := protojson.Marshal(v)
+		return string(b)
+	}
+}
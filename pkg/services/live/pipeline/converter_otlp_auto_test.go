@@ -0,0 +1,114 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+const otlpTestInput = `{
+  "resourceMetrics": [{
+    "resource": {
+      "attributes": [
+        {"key": "service.name", "value": {"stringValue": "sensor-agent"}},
+        {"key": "host.name", "value": {"stringValue": "device-1"}}
+      ]
+    },
+    "scopeMetrics": [{
+      "scope": {"name": "agent"},
+      "metrics": [{
+        "name": "system.cpu.utilization",
+        "gauge": {
+          "dataPoints": [
+            {"timeUnixNano": "1609503132000000000", "asDouble": 0.25, "attributes": [{"key": "cpu", "value": {"intValue": "0"}}]},
+            {"timeUnixNano": "1609503132000000000", "asDouble": 0.5, "attributes": [{"key": "cpu", "value": {"intValue": "1"}}]}
+          ]
+        }
+      }, {
+        "name": "requests",
+        "sum": {
+          "aggregationTemporality": 2,
+          "isMonotonic": true,
+          "dataPoints": [
+            {"asInt": "42", "attributes": [{"key": "ok", "value": {"boolValue": true}}]},
+            {"asInt": "1", "flags": 1}
+          ]
+        }
+      }, {
+        "name": "latency",
+        "histogram": {
+          "aggregationTemporality": 2,
+          "dataPoints": [
+            {"timeUnixNano": "1609503132000000000", "count": "3", "sum": 0.6, "bucketCounts": ["2", "1"], "explicitBounds": [0.1]}
+          ]
+        }
+      }]
+    }]
+  }]
+}`
+
+func TestAutoOTLPConverter_Convert(t *testing.T) {
+	now := time.Date(2021, 01, 01, 12, 12, 12, 0, time.UTC)
+	convert := func(t *testing.T, config AutoOTLPConverterConfig) []*ChannelFrame {
+		t.Helper()
+		converter := NewAutoOTLPConverter(config)
+		converter.nowTimeFunc = func() time.Time { return now }
+		channelFrames, err := converter.Convert(context.Background(), Vars{Channel: "stream/devices/otlp"}, []byte(otlpTestInput))
+		require.NoError(t, err)
+		return channelFrames
+	}
+
+	t.Run("labels column", func(t *testing.T) {
+		channelFrames := convert(t, AutoOTLPConverterConfig{ResourceAttributes: []string{"service.name"}})
+		channels := make([]string, 0, len(channelFrames))
+		for _, cf := range channelFrames {
+			channels = append(channels, cf.Channel)
+		}
+		require.Equal(t, []string{
+			"stream/devices/otlp/system.cpu.utilization",
+			"stream/devices/otlp/requests",
+			"stream/devices/otlp/latency_bucket",
+			"stream/devices/otlp/latency_sum",
+			"stream/devices/otlp/latency_count",
+		}, channels)
+
+		frame := channelFrames[0].Frame
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, "cpu=0, service.name=sensor-agent", frame.Fields[0].At(0))
+		require.Equal(t, time.Unix(1609503132, 0), frame.Fields[1].At(0))
+		require.Equal(t, 0.25, frame.Fields[2].At(0))
+
+		// Data points without value are skipped and data points without time get the current time.
+		requestsFrame := channelFrames[1].Frame
+		require.Equal(t, 1, requestsFrame.Rows())
+		require.Equal(t, "ok=true, service.name=sensor-agent", requestsFrame.Fields[0].At(0))
+		require.Equal(t, now, requestsFrame.Fields[1].At(0))
+		require.Equal(t, 42.0, requestsFrame.Fields[2].At(0))
+
+		bucketFrame := channelFrames[2].Frame
+		require.Equal(t, []string{"le=0.1, service.name=sensor-agent", "le=+Inf, service.name=sensor-agent"}, []string{
+			bucketFrame.Fields[0].At(0).(string), bucketFrame.Fields[0].At(1).(string),
+		})
+		require.Equal(t, []float64{2, 3}, []float64{bucketFrame.Fields[2].At(0).(float64), bucketFrame.Fields[2].At(1).(float64)})
+	})
+
+	t.Run("wide", func(t *testing.T) {
+		channelFrames := convert(t, AutoOTLPConverterConfig{FrameFormat: "wide"})
+		frame := channelFrames[0].Frame
+		require.Equal(t, 1, frame.Rows())
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, data.Labels{"cpu": "1"}, frame.Fields[2].Labels)
+		v, ok := frame.Fields[2].ConcreteAt(0)
+		require.True(t, ok)
+		require.Equal(t, 0.5, v)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		converter := NewAutoOTLPConverter(AutoOTLPConverterConfig{})
+		_, err := converter.Convert(context.Background(), Vars{}, []byte(`{"resourceMetrics": {}}`))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,112 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// AutoPrometheusConverter decodes Prometheus text exposition format input and
+// transforms it to several ChannelFrame objects where Channel is constructed
+// from original channel + / + <metric_name>. Histograms and summaries are
+// split into the _bucket, _sum and _count metrics of the exposition format.
+type AutoPrometheusConverter struct {
+	config      AutoPrometheusConverterConfig
+	nowTimeFunc func() time.Time
+}
+
+// NewAutoPrometheusConverter creates new AutoPrometheusConverter.
+func NewAutoPrometheusConverter(config AutoPrometheusConverterConfig) *AutoPrometheusConverter {
+	return &AutoPrometheusConverter{config: config}
+}
+
+const ConverterTypePrometheusAuto = "prometheusAuto"
+
+func (c *AutoPrometheusConverter) Type() string {
+	return ConverterTypePrometheusAuto
+}
+
+func (c *AutoPrometheusConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metrics: %w", err)
+	}
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Samples without timestamp get the time the input was received.
+	nowTimeFunc := c.nowTimeFunc
+	if nowTimeFunc == nil {
+		nowTimeFunc = time.Now
+	}
+	now := nowTimeFunc()
+	var samples []metricSample
+	for _, name := range names {
+		family := families[name]
+		for _, m := range family.GetMetric() {
+			labels := data.Labels{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			t := now
+			if m.TimestampMs != nil {
+				t = time.UnixMilli(m.GetTimestampMs())
+			}
+			samples = append(samples, prometheusSamples(name, family.GetType(), m, labels, t)...)
+		}
+	}
+	return metricSamplesToChannelFrames(vars.Channel, c.config.FrameFormat, samples)
+}
+
+func prometheusSamples(name string, metricType dto.MetricType, m *dto.Metric, labels data.Labels, t time.Time) []metricSample {
+	sample := func(name string, labels data.Labels, value float64) metricSample {
+		return metricSample{Name: name, Labels: labels, Time: t, Value: value}
+	}
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		return []metricSample{sample(name, labels, m.GetCounter().GetValue())}
+	case dto.MetricType_GAUGE:
+		return []metricSample{sample(name, labels, m.GetGauge().GetValue())}
+	case dto.MetricType_SUMMARY:
+		summary := m.GetSummary()
+		var samples []metricSample
+		for _, q := range summary.GetQuantile() {
+			samples = append(samples, sample(name, labelsWith(labels, "quantile", q.GetQuantile()), q.GetValue()))
+		}
+		return append(samples,
+			sample(name+"_sum", labels, summary.GetSampleSum()),
+			sample(name+"_count", labels, float64(summary.GetSampleCount())),
+		)
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		histogram := m.GetHistogram()
+		var samples []metricSample
+		for _, b := range histogram.GetBucket() {
+			samples = append(samples, sample(name+"_bucket", labelsWith(labels, "le", b.GetUpperBound()), float64(b.GetCumulativeCount())))
+		}
+		return append(samples,
+			sample(name+"_sum", labels, histogram.GetSampleSum()),
+			sample(name+"_count", labels, float64(histogram.GetSampleCount())),
+		)
+	default:
+		return []metricSample{sample(name, labels, m.GetUntyped().GetValue())}
+	}
+}
+
+// labelsWith returns a copy of the labels with an additional label, like the
+// le label of histogram buckets.
+func labelsWith(labels data.Labels, name string, value float64) data.Labels {
+	result := labels.Copy()
+	result[name] = strconv.FormatFloat(value, 'g', -1, 64)
+	return result
+}
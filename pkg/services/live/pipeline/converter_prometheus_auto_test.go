@@ -0,0 +1,103 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+const prometheusTestInput = `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027 1609503132000
+http_requests_total{method="post",code="400"} 3 1609503132000
+http_requests_total{method="post",code="200"} 1030 1609503133000
+# TYPE temperature gauge
+temperature{device="sensor:1"} 21.5
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 2
+request_duration_seconds_bucket{le="+Inf"} 3
+request_duration_seconds_sum 0.6
+request_duration_seconds_count 3
+`
+
+func TestAutoPrometheusConverter_Convert(t *testing.T) {
+	now := time.Date(2021, 01, 01, 12, 12, 12, 0, time.UTC)
+	convert := func(t *testing.T, frameFormat string) []*ChannelFrame {
+		t.Helper()
+		converter := NewAutoPrometheusConverter(AutoPrometheusConverterConfig{FrameFormat: frameFormat})
+		converter.nowTimeFunc = func() time.Time { return now }
+		channelFrames, err := converter.Convert(context.Background(), Vars{Channel: "stream/devices/metrics"}, []byte(prometheusTestInput))
+		require.NoError(t, err)
+		return channelFrames
+	}
+
+	t.Run("labels column", func(t *testing.T) {
+		channelFrames := convert(t, "")
+		channels := make([]string, 0, len(channelFrames))
+		for _, cf := range channelFrames {
+			channels = append(channels, cf.Channel)
+		}
+		require.Equal(t, []string{
+			"stream/devices/metrics/http_requests_total",
+			"stream/devices/metrics/request_duration_seconds_bucket",
+			"stream/devices/metrics/request_duration_seconds_sum",
+			"stream/devices/metrics/request_duration_seconds_count",
+			"stream/devices/metrics/temperature",
+		}, channels)
+
+		frame := channelFrames[0].Frame
+		require.Equal(t, "http_requests_total", frame.Name)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, "code=200, method=post", frame.Fields[0].At(0))
+		require.Equal(t, time.UnixMilli(1609503132000), frame.Fields[1].At(0))
+		require.Equal(t, 1027.0, frame.Fields[2].At(0))
+		require.Equal(t, "code=400, method=post", frame.Fields[0].At(1))
+
+		bucketFrame := channelFrames[1].Frame
+		require.Equal(t, "le=+Inf", bucketFrame.Fields[0].At(1))
+		require.Equal(t, 3.0, bucketFrame.Fields[2].At(1))
+
+		temperatureFrame := channelFrames[4].Frame
+		require.Equal(t, now, temperatureFrame.Fields[1].At(0))
+	})
+
+	t.Run("wide", func(t *testing.T) {
+		channelFrames := convert(t, "wide")
+		frame := channelFrames[0].Frame
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, []time.Time{time.UnixMilli(1609503132000), time.UnixMilli(1609503133000)}, []time.Time{
+			frame.Fields[0].At(0).(time.Time), frame.Fields[0].At(1).(time.Time),
+		})
+		require.Equal(t, data.Labels{"method": "post", "code": "200"}, frame.Fields[1].Labels)
+		v, ok := frame.Fields[1].ConcreteAt(1)
+		require.True(t, ok)
+		require.Equal(t, 1030.0, v)
+		require.Equal(t, data.Labels{"method": "post", "code": "400"}, frame.Fields[2].Labels)
+		_, ok = frame.Fields[2].ConcreteAt(1)
+		require.False(t, ok)
+	})
+
+	t.Run("routes metrics with invalid channel characters", func(t *testing.T) {
+		converter := NewAutoPrometheusConverter(AutoPrometheusConverterConfig{})
+		channelFrames, err := converter.Convert(context.Background(), Vars{Channel: "stream/devices/metrics"}, []byte("job:errors:rate5m 1\n"))
+		require.NoError(t, err)
+		require.Len(t, channelFrames, 1)
+		require.Equal(t, "stream/devices/metrics/job_errors_rate5m", channelFrames[0].Channel)
+		require.Equal(t, "job:errors:rate5m", channelFrames[0].Frame.Name)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		converter := NewAutoPrometheusConverter(AutoPrometheusConverterConfig{})
+		_, err := converter.Convert(context.Background(), Vars{}, []byte("invalid metric{"))
+		require.Error(t, err)
+	})
+
+	t.Run("unsupported frame format", func(t *testing.T) {
+		converter := NewAutoPrometheusConverter(AutoPrometheusConverterConfig{FrameFormat: "long"})
+		_, err := converter.Convert(context.Background(), Vars{}, []byte(prometheusTestInput))
+		require.EqualError(t, err, "unsupported frame format: long")
+	})
+}
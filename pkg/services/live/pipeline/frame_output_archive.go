@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ArchiveFrameOutput appends frames to NDJSON files in the data path, one line
+// per frame, so that pushed data can be kept for audits or replayed later.
+// Files are rotated by size and age, and removed after the retention time.
+type ArchiveFrameOutput struct {
+	storage *ArchiveStorage
+	config  ArchiveOutputConfig
+	nowFunc func() time.Time
+}
+
+func NewArchiveFrameOutput(storage *ArchiveStorage, config ArchiveOutputConfig) (*ArchiveFrameOutput, error) {
+	if storage == nil {
+		return nil, fmt.Errorf("archive storage required")
+	}
+	if config.Directory != "" {
+		if _, err := cleanArchiveDirectory(config.Directory); err != nil {
+			return nil, err
+		}
+	}
+	if config.MaxFileSizeBytes < 0 || config.RotateSeconds < 0 || config.RetentionSeconds < 0 || config.MaxFiles < 0 {
+		return nil, fmt.Errorf("archive limits must not be negative")
+	}
+	return &ArchiveFrameOutput{storage: storage, config: config, nowFunc: time.Now}, nil
+}
+
+const FrameOutputTypeArchive = "archive"
+
+func (out *ArchiveFrameOutput) Type() string {
+	return FrameOutputTypeArchive
+}
+
+// archiveRecord is a line of an archive file.
+type archiveRecord struct {
+	Time    time.Time       `json:"time"`
+	Channel string          `json:"channel"`
+	Frame   json.RawMessage `json:"frame"`
+}
+
+func (out *ArchiveFrameOutput) OutputFrame(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	directory := out.config.Directory
+	if directory == "" {
+		directory = vars.Channel
+	}
+	w, err := out.storage.writer(vars.OrgID, directory)
+	if err != nil {
+		return nil, err
+	}
+	frameJSON, err := data.FrameToJSON(frame, data.IncludeAll)
+	if err != nil {
+		return nil, err
+	}
+	now := out.nowFunc()
+	line, err := json.Marshal(archiveRecord{Time: now.UTC(), Channel: vars.Channel, Frame: frameJSON})
+	if err != nil {
+		return nil, err
+	}
+	line = append(line, '\n')
+	if err := w.write(line, out.config, now); err != nil {
+		return nil, fmt.Errorf("error writing to archive: %w", err)
+	}
+	return nil, nil
+}
@@ -0,0 +1,193 @@
+package pipeline
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func archiveFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+archiveFileExt))
+	require.NoError(t, err)
+	return matches
+}
+
+func archiveRecords(t *testing.T, file string) []archiveRecord {
+	t.Helper()
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	var records []archiveRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r archiveRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func TestNewArchiveFrameOutput(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config ArchiveOutputConfig
+		expErr string
+	}{
+		{name: "defaults", config: ArchiveOutputConfig{}},
+		{name: "directory", config: ArchiveOutputConfig{Directory: "sensors/room1"}},
+		{name: "absolute directory", config: ArchiveOutputConfig{Directory: "/etc"}, expErr: "archive directory must be inside the archive path: /etc"},
+		{name: "directory outside archive path", config: ArchiveOutputConfig{Directory: "sensors/../../etc"}, expErr: "archive directory must be inside the archive path: ../etc"},
+		{name: "negative limit", config: ArchiveOutputConfig{MaxFiles: -1}, expErr: "archive limits must not be negative"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewArchiveFrameOutput(NewArchiveStorage(t.TempDir()), tc.config)
+			if tc.expErr != "" {
+				require.EqualError(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestArchiveFrameOutput(t *testing.T) {
+	path := t.TempDir()
+	storage := NewArchiveStorage(path)
+	t.Cleanup(func() { _ = storage.Close() })
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	newOutput := func(config ArchiveOutputConfig) *ArchiveFrameOutput {
+		out, err := NewArchiveFrameOutput(storage, config)
+		require.NoError(t, err)
+		out.nowFunc = func() time.Time { return now }
+		return out
+	}
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{now}),
+		data.NewField("value", nil, []float64{1}),
+	)
+	vars := Vars{OrgID: 1, Channel: "stream/test/archive"}
+
+	t.Run("appends frames to the file of the channel", func(t *testing.T) {
+		out := newOutput(ArchiveOutputConfig{})
+		for i := 0; i < 2; i++ {
+			channelFrames, err := out.OutputFrame(context.Background(), vars, frame)
+			require.NoError(t, err)
+			require.Nil(t, channelFrames)
+		}
+		// Outputs of rebuilt channel rules append to the same file.
+		_, err := newOutput(ArchiveOutputConfig{}).OutputFrame(context.Background(), vars, frame)
+		require.NoError(t, err)
+
+		files := archiveFiles(t, filepath.Join(path, "1", "stream", "test", "archive"))
+		require.Len(t, files, 1)
+		require.Equal(t, "20221001T120000.000000000Z.ndjson", filepath.Base(files[0]))
+		records := archiveRecords(t, files[0])
+		require.Len(t, records, 3)
+		require.Equal(t, "stream/test/archive", records[0].Channel)
+		require.Equal(t, now, records[0].Time)
+
+		var f data.Frame
+		require.NoError(t, json.Unmarshal(records[0].Frame, &f))
+		require.Equal(t, "test", f.Name)
+		require.Equal(t, 1.0, f.Fields[1].At(0))
+	})
+
+	t.Run("rotates files by size and age and removes old files", func(t *testing.T) {
+		dir := filepath.Join(path, "2", "sensors")
+		out := newOutput(ArchiveOutputConfig{
+			Directory:        "sensors",
+			MaxFileSizeBytes: 1,
+			RotateSeconds:    60,
+			MaxFiles:         3,
+			RetentionSeconds: 3600,
+		})
+		vars := Vars{OrgID: 2, Channel: "stream/test/archive"}
+
+		// Every frame exceeds the max size, so it starts a new file.
+		for i := 0; i < 2; i++ {
+			_, err := out.OutputFrame(context.Background(), vars, frame)
+			require.NoError(t, err)
+			now = now.Add(time.Millisecond)
+		}
+		require.Len(t, archiveFiles(t, dir), 2)
+
+		// The last file is used until it gets too old.
+		out.config.MaxFileSizeBytes = 0
+		_, err := out.OutputFrame(context.Background(), vars, frame)
+		require.NoError(t, err)
+		now = now.Add(30 * time.Second)
+		_, err = out.OutputFrame(context.Background(), vars, frame)
+		require.NoError(t, err)
+		files := archiveFiles(t, dir)
+		require.Len(t, files, 2)
+		require.Len(t, archiveRecords(t, files[1]), 3)
+
+		now = now.Add(30 * time.Second)
+		_, err = out.OutputFrame(context.Background(), vars, frame)
+		require.NoError(t, err)
+		files = archiveFiles(t, dir)
+		require.Len(t, files, 3)
+
+		// The oldest file is removed to keep 3 files.
+		now = now.Add(time.Minute)
+		_, err = out.OutputFrame(context.Background(), vars, frame)
+		require.NoError(t, err)
+		rotatedFiles := archiveFiles(t, dir)
+		require.Len(t, rotatedFiles, 3)
+		require.Equal(t, files[1:], rotatedFiles[:2])
+
+		// Files older than the retention time are removed, in addition to the
+		// oldest file exceeding the max number of files.
+		old := now.Add(-2 * time.Hour)
+		require.NoError(t, os.Chtimes(rotatedFiles[1], old, old))
+		now = now.Add(time.Minute)
+		_, err = out.OutputFrame(context.Background(), vars, frame)
+		require.NoError(t, err)
+		files = archiveFiles(t, dir)
+		require.Len(t, files, 2)
+		require.Equal(t, rotatedFiles[2], files[0])
+	})
+}
+
+func TestArchiveStorage_Sweep(t *testing.T) {
+	path := t.TempDir()
+	storage := NewArchiveStorage(path)
+	t.Cleanup(func() { _ = storage.Close() })
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	out, err := NewArchiveFrameOutput(storage, ArchiveOutputConfig{Directory: "sweep", RetentionSeconds: 3600})
+	require.NoError(t, err)
+	out.nowFunc = func() time.Time { return now }
+	frame := data.NewFrame("test", data.NewField("value", nil, []float64{1}))
+	_, err = out.OutputFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/archive"}, frame)
+	require.NoError(t, err)
+
+	w, err := storage.writer(1, "sweep")
+	require.NoError(t, err)
+
+	// A file that was written to recently stays open.
+	storage.sweep(now.Add(time.Minute))
+	require.NotNil(t, w.file)
+
+	// An idle file is closed and removed once it is older than the retention time.
+	storage.sweep(now.Add(archiveIdleTimeout))
+	require.Nil(t, w.file)
+	files := archiveFiles(t, filepath.Join(path, "1", "sweep"))
+	require.Len(t, files, 1)
+
+	old := now.Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(files[0], old, old))
+	storage.sweep(now.Add(2 * archiveIdleTimeout))
+	require.Empty(t, archiveFiles(t, filepath.Join(path, "1", "sweep")))
+}
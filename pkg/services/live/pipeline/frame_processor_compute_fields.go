@@ -0,0 +1,85 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ComputeFieldsFrameProcessor adds fields that are computed with math
+// expressions over the numeric fields of each row. Computed fields replace
+// fields with the same name, and can be used in the expressions of the fields
+// computed after them. A computed value is null if a field of its expression is
+// missing or null, or if the result is not a finite number.
+type ComputeFieldsFrameProcessor struct {
+	config      ComputeFieldsFrameProcessorConfig
+	expressions []computeExpression
+}
+
+func NewComputeFieldsFrameProcessor(config ComputeFieldsFrameProcessorConfig) (*ComputeFieldsFrameProcessor, error) {
+	expressions := make([]computeExpression, 0, len(config.Fields))
+	for _, f := range config.Fields {
+		if f.Name == "" {
+			return nil, fmt.Errorf("computed field name required")
+		}
+		expr, err := parseComputeExpression(f.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression of field %s: %w", f.Name, err)
+		}
+		expressions = append(expressions, expr)
+	}
+	return &ComputeFieldsFrameProcessor{config: config, expressions: expressions}, nil
+}
+
+const FrameProcessorTypeComputeFields = "computeFields"
+
+func (p *ComputeFieldsFrameProcessor) Type() string {
+	return FrameProcessorTypeComputeFields
+}
+
+func (p *ComputeFieldsFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	rows := frame.Rows()
+	for i, f := range p.config.Fields {
+		fields := map[string]*data.Field{}
+		for _, field := range frame.Fields {
+			if _, ok := fields[field.Name]; !ok && field.Type().Numeric() {
+				fields[field.Name] = field
+			}
+		}
+
+		computed := data.NewField(f.Name, nil, make([]*float64, rows))
+		computed.Config = f.Config
+		for row := 0; row < rows; row++ {
+			v, ok := p.expressions[i](func(name string) (float64, bool) {
+				field, ok := fields[name]
+				if !ok {
+					return 0, false
+				}
+				v, err := field.NullableFloatAt(row)
+				if err != nil || v == nil {
+					return 0, false
+				}
+				return *v, true
+			})
+			if ok && !math.IsNaN(v) && !math.IsInf(v, 0) {
+				computed.Set(row, &v)
+			}
+		}
+
+		replaced := false
+		for j, field := range frame.Fields {
+			if field.Name == f.Name {
+				computed.Labels = field.Labels
+				frame.Fields[j] = computed
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			frame.Fields = append(frame.Fields, computed)
+		}
+	}
+	return frame, nil
+}
@@ -0,0 +1,124 @@
+package pipeline
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseComputeExpression(t *testing.T) {
+	values := map[string]float64{
+		"a":          6,
+		"b":          4,
+		"cpu.user":   0.25,
+		"temp (°C)":  20,
+		"nan_value":  math.NaN(),
+		"zero_value": 0,
+	}
+	value := func(name string) (float64, bool) {
+		v, ok := values[name]
+		return v, ok
+	}
+
+	testCases := []struct {
+		expression string
+		expValue   float64
+		expMissing bool
+	}{
+		{expression: "1 + 2 * 3", expValue: 7},
+		{expression: "(1 + 2) * 3", expValue: 9},
+		{expression: "a - b - 1", expValue: 1},
+		{expression: "a / b", expValue: 1.5},
+		{expression: "a % b", expValue: 2},
+		{expression: "2 ^ 3 ^ 2", expValue: 512},
+		{expression: "-2 ^ 2", expValue: -4},
+		{expression: "--a", expValue: 6},
+		{expression: "1.5e2 + .5", expValue: 150.5},
+		{expression: "cpu.user * 100", expValue: 25},
+		{expression: "${temp (°C)} * 9 / 5 + 32", expValue: 68},
+		{expression: "max(a, b) - min(a, b)", expValue: 2},
+		{expression: "round(sqrt(a * b * 6))", expValue: 12},
+		{expression: "abs(floor(-1.5)) + ceil(0.2) + pow(b, 0.5)", expValue: 5},
+		{expression: "a + missing", expMissing: true},
+		{expression: "max(missing, 1)", expMissing: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			expr, err := parseComputeExpression(tc.expression)
+			require.NoError(t, err)
+			v, ok := expr(value)
+			require.Equal(t, !tc.expMissing, ok)
+			if !tc.expMissing {
+				require.InDelta(t, tc.expValue, v, 1e-9)
+			}
+		})
+	}
+}
+
+func TestParseComputeExpression_Errors(t *testing.T) {
+	testCases := []struct {
+		expression string
+		expErr     string
+	}{
+		{expression: "", expErr: "unexpected end of expression"},
+		{expression: "1 +", expErr: "unexpected end of expression"},
+		{expression: "(1 + 2", expErr: "missing ) for ( at position 0"},
+		{expression: "1 2", expErr: `unexpected "2" at position 2`},
+		{expression: "${a", expErr: "missing } for ${ at position 0"},
+		{expression: "1..2", expErr: `invalid number "1..2"`},
+		{expression: "a # b", expErr: `unexpected "# b" at position 2`},
+		{expression: "median(a)", expErr: "unknown function median at position 0"},
+		{expression: "max(a)", expErr: "function max takes 2 arguments, got 1"},
+		{expression: "abs(a", expErr: "missing ) for call of abs at position 0"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			_, err := parseComputeExpression(tc.expression)
+			require.EqualError(t, err, tc.expErr)
+		})
+	}
+}
+
+func TestNewComputeFieldsFrameProcessor(t *testing.T) {
+	_, err := NewComputeFieldsFrameProcessor(ComputeFieldsFrameProcessorConfig{
+		Fields: []ComputedFieldConfig{{Expression: "a + 1"}},
+	})
+	require.EqualError(t, err, "computed field name required")
+
+	_, err = NewComputeFieldsFrameProcessor(ComputeFieldsFrameProcessorConfig{
+		Fields: []ComputedFieldConfig{{Name: "b", Expression: "a +"}},
+	})
+	require.EqualError(t, err, "invalid expression of field b: unexpected end of expression")
+}
+
+func TestComputeFieldsFrameProcessor(t *testing.T) {
+	p, err := NewComputeFieldsFrameProcessor(ComputeFieldsFrameProcessorConfig{
+		Fields: []ComputedFieldConfig{
+			{Name: "total", Expression: "user + system", Config: &data.FieldConfig{Unit: "percent"}},
+			{Name: "ratio", Expression: "user / total"},
+			{Name: "user", Expression: "user * 100"},
+		},
+	})
+	require.NoError(t, err)
+
+	frame := data.NewFrame("cpu",
+		data.NewField("host", nil, []string{"a", "b", "c"}),
+		data.NewField("user", data.Labels{"cpu": "0"}, []float64{1, 3, 0}),
+		data.NewField("system", nil, []*float64{float64Ptr(3), nil, float64Ptr(0)}),
+	)
+	frame, err = p.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+
+	require.Len(t, frame.Fields, 5)
+	require.Equal(t, "user", frame.Fields[1].Name)
+	require.Equal(t, data.Labels{"cpu": "0"}, frame.Fields[1].Labels)
+	require.Equal(t, []interface{}{100.0, 300.0, 0.0}, windowFieldValues(t, frame, "user"))
+	// The total of the second row is null because system is null, and the ratio
+	// of the third row is null because dividing by zero is not a finite number.
+	require.Equal(t, []interface{}{4.0, nil, 0.0}, windowFieldValues(t, frame, "total"))
+	require.Equal(t, "percent", frame.Fields[3].Config.Unit)
+	require.Equal(t, []interface{}{0.25, nil, nil}, windowFieldValues(t, frame, "ratio"))
+}
@@ -26,6 +26,11 @@ func (p *MultipleFrameProcessor) ProcessFrame(ctx context.Context, vars Vars, fr
 			logger.Error("Error processing frame", "error", err)
 			return nil, err
 		}
+		if frame == nil {
+			// The processor stopped processing, e.g. window aggregation
+			// waiting for a window to close.
+			return nil, nil
+		}
 	}
 	return frame, nil
 }
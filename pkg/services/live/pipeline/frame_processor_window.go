@@ -0,0 +1,360 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Aggregations of WindowFrameProcessor.
+const (
+	WindowAggregationMean  = "mean"
+	WindowAggregationMin   = "min"
+	WindowAggregationMax   = "max"
+	WindowAggregationLast  = "last"
+	WindowAggregationCount = "count"
+)
+
+// WindowFrameProcessor aggregates the numeric fields of the frames of a channel
+// over time windows, and only passes a frame on when windows close. Rows are
+// grouped by the values of their string fields, so every label set of a frame
+// in labels column format is aggregated separately. Numeric fields in wide
+// format carry their labels, so they are aggregated separately anyway.
+//
+// A window closes when a row with a time after the end of the window arrives.
+// The frame of closed windows has a row per window and group with the end of
+// the window as time.
+type WindowFrameProcessor struct {
+	storage *WindowStorage
+	config  WindowFrameProcessorConfig
+	size    time.Duration
+	slide   time.Duration
+}
+
+func NewWindowFrameProcessor(storage *WindowStorage, config WindowFrameProcessorConfig) (*WindowFrameProcessor, error) {
+	if storage == nil {
+		return nil, fmt.Errorf("window storage required")
+	}
+	if config.SizeSeconds <= 0 {
+		return nil, fmt.Errorf("window size must be positive")
+	}
+	if config.SlideSeconds < 0 || config.SlideSeconds > config.SizeSeconds {
+		return nil, fmt.Errorf("window slide must be between 0 and the window size")
+	}
+	for _, aggregation := range config.Aggregations {
+		switch aggregation {
+		case WindowAggregationMean, WindowAggregationMin, WindowAggregationMax, WindowAggregationLast, WindowAggregationCount:
+		default:
+			return nil, fmt.Errorf("unknown aggregation: %s", aggregation)
+		}
+	}
+	if len(config.Aggregations) == 0 {
+		config.Aggregations = []string{WindowAggregationMean}
+	}
+	p := &WindowFrameProcessor{
+		storage: storage,
+		config:  config,
+		size:    time.Duration(config.SizeSeconds) * time.Second,
+		slide:   time.Duration(config.SlideSeconds) * time.Second,
+	}
+	if p.slide == 0 {
+		p.slide = p.size
+	}
+	return p, nil
+}
+
+const FrameProcessorTypeWindow = "window"
+
+func (p *WindowFrameProcessor) Type() string {
+	return FrameProcessorTypeWindow
+}
+
+func (p *WindowFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	state := p.storage.get(vars.OrgID, vars.Channel, p.size)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.add(frame, p.config.TimeField, p.size, p.slide)
+	return state.closeWindows(frame.Name, p.size, p.slide, p.config.Aggregations), nil
+}
+
+// windowState holds the rows of the windows of a channel that did not close
+// yet.
+type windowState struct {
+	mu sync.Mutex
+	// groupFields and numberFields are the fields of all frames seen so far.
+	groupFields  []string
+	numberFields []windowField
+	rows         []windowRow
+	// end is the end of the last window that closed.
+	end time.Time
+	// lastUsed and idleTimeout are guarded by the mutex of WindowStorage.
+	lastUsed    time.Time
+	idleTimeout time.Duration
+}
+
+type windowField struct {
+	key    string
+	name   string
+	labels data.Labels
+	config *data.FieldConfig
+}
+
+type windowRow struct {
+	time   time.Time
+	group  []string
+	values []*float64
+}
+
+// add adds the rows of the frame to the state. Rows that are too late for the
+// windows that did not close yet are dropped.
+func (s *windowState) add(frame *data.Frame, timeFieldName string, size, slide time.Duration) {
+	timeField := -1
+	groupIndex := make([]int, len(frame.Fields))
+	numberIndex := make([]int, len(frame.Fields))
+	for i, f := range frame.Fields {
+		groupIndex[i], numberIndex[i] = -1, -1
+		switch {
+		case f.Type().Time():
+			if timeField < 0 && (timeFieldName == "" || timeFieldName == f.Name) {
+				timeField = i
+			}
+		case f.Type() == data.FieldTypeString || f.Type() == data.FieldTypeNullableString:
+			groupIndex[i] = s.groupFieldIndex(f.Name)
+		case f.Type().Numeric():
+			numberIndex[i] = s.numberFieldIndex(f)
+		}
+	}
+
+	now := time.Now()
+	for row := 0; row < frame.Rows(); row++ {
+		t := now
+		if timeField >= 0 {
+			v, ok := frame.Fields[timeField].ConcreteAt(row)
+			if !ok {
+				continue
+			}
+			t = v.(time.Time)
+		}
+		if s.end.IsZero() {
+			// The first window ends after the first row.
+			s.end = t.Truncate(slide)
+		}
+		if t.Before(s.end.Add(slide - size)) {
+			continue
+		}
+
+		r := windowRow{
+			time:   t,
+			group:  make([]string, len(s.groupFields)),
+			values: make([]*float64, len(s.numberFields)),
+		}
+		for i, f := range frame.Fields {
+			if groupIndex[i] >= 0 {
+				if v, ok := f.ConcreteAt(row); ok {
+					r.group[groupIndex[i]] = v.(string)
+				}
+			}
+			if numberIndex[i] >= 0 {
+				if v, err := f.NullableFloatAt(row); err == nil && v != nil && !math.IsNaN(*v) {
+					r.values[numberIndex[i]] = v
+				}
+			}
+		}
+		s.rows = append(s.rows, r)
+	}
+}
+
+func (s *windowState) groupFieldIndex(name string) int {
+	for i, groupField := range s.groupFields {
+		if groupField == name {
+			return i
+		}
+	}
+	s.groupFields = append(s.groupFields, name)
+	return len(s.groupFields) - 1
+}
+
+func (s *windowState) numberFieldIndex(f *data.Field) int {
+	key := f.Name + f.Labels.String()
+	for i, numberField := range s.numberFields {
+		if numberField.key == key {
+			return i
+		}
+	}
+	s.numberFields = append(s.numberFields, windowField{key: key, name: f.Name, labels: f.Labels, config: f.Config})
+	return len(s.numberFields) - 1
+}
+
+// closeWindows returns a frame with the aggregations of the windows that
+// closed, or nil if no window closed.
+func (s *windowState) closeWindows(name string, size, slide time.Duration, aggregations []string) *data.Frame {
+	if len(s.rows) == 0 {
+		return nil
+	}
+	latest := s.rows[0].time
+	for _, r := range s.rows {
+		if r.time.After(latest) {
+			latest = r.time
+		}
+	}
+
+	out := newWindowOutput(s, aggregations)
+	for end := s.end.Add(slide); !end.After(latest); end = end.Add(slide) {
+		start := end.Add(-size)
+		groups := map[string]*windowGroup{}
+		var groupOrder []*windowGroup
+		next := latest
+		for _, r := range s.rows {
+			if !r.time.Before(end) && r.time.Before(next) {
+				next = r.time
+			}
+			if r.time.Before(start) || !r.time.Before(end) {
+				continue
+			}
+			// Rows added before a group field was seen have no value for it.
+			group := r.group
+			for len(group) < len(s.groupFields) {
+				group = append(group, "")
+			}
+			key := strings.Join(group, "\x00")
+			g, ok := groups[key]
+			if !ok {
+				g = &windowGroup{group: group, values: make([]windowAggregate, len(s.numberFields))}
+				groups[key] = g
+				groupOrder = append(groupOrder, g)
+			}
+			for i, v := range r.values {
+				if v != nil {
+					g.values[i].add(r.time, *v)
+				}
+			}
+		}
+		for _, g := range groupOrder {
+			out.append(end, g)
+		}
+		s.end = end
+		if len(groupOrder) == 0 {
+			// Skip the empty windows up to the window of the next row.
+			s.end = next.Truncate(slide)
+			end = s.end
+		}
+	}
+
+	// Only keep the rows of the windows that did not close yet.
+	start := s.end.Add(slide - size)
+	rows := s.rows[:0]
+	for _, r := range s.rows {
+		if !r.time.Before(start) {
+			rows = append(rows, r)
+		}
+	}
+	s.rows = rows
+
+	if out.rows == 0 {
+		return nil
+	}
+	return data.NewFrame(name, out.fields...)
+}
+
+type windowGroup struct {
+	group  []string
+	values []windowAggregate
+}
+
+type windowAggregate struct {
+	count    int
+	sum      float64
+	min      float64
+	max      float64
+	last     float64
+	lastTime time.Time
+}
+
+func (a *windowAggregate) add(t time.Time, v float64) {
+	if a.count == 0 || v < a.min {
+		a.min = v
+	}
+	if a.count == 0 || v > a.max {
+		a.max = v
+	}
+	if a.count == 0 || !t.Before(a.lastTime) {
+		a.last, a.lastTime = v, t
+	}
+	a.count++
+	a.sum += v
+}
+
+func (a *windowAggregate) value(aggregation string) *float64 {
+	var v float64
+	switch aggregation {
+	case WindowAggregationCount:
+		v = float64(a.count)
+		return &v
+	case WindowAggregationMean:
+		v = a.sum / float64(a.count)
+	case WindowAggregationMin:
+		v = a.min
+	case WindowAggregationMax:
+		v = a.max
+	case WindowAggregationLast:
+		v = a.last
+	}
+	if a.count == 0 {
+		return nil
+	}
+	return &v
+}
+
+// windowOutput builds the frame of closed windows. It has a time field, the
+// group fields and a field per aggregation of every numeric field. The fields
+// keep their names if there is only one aggregation, otherwise the name of the
+// aggregation is appended to the names.
+type windowOutput struct {
+	aggregations []string
+	fields       []*data.Field
+	groupFields  int
+	rows         int
+}
+
+func newWindowOutput(s *windowState, aggregations []string) *windowOutput {
+	out := &windowOutput{aggregations: aggregations, groupFields: len(s.groupFields)}
+	out.fields = append(out.fields, data.NewField("time", nil, []time.Time{}))
+	for _, name := range s.groupFields {
+		out.fields = append(out.fields, data.NewField(name, nil, []string{}))
+	}
+	for _, f := range s.numberFields {
+		for _, aggregation := range aggregations {
+			name := f.name
+			if len(aggregations) > 1 {
+				name += "_" + aggregation
+			}
+			field := data.NewField(name, f.labels, []*float64{})
+			if aggregation != WindowAggregationCount {
+				field.Config = f.config
+			}
+			out.fields = append(out.fields, field)
+		}
+	}
+	return out
+}
+
+func (out *windowOutput) append(end time.Time, g *windowGroup) {
+	out.fields[0].Append(end)
+	for i := 0; i < out.groupFields; i++ {
+		out.fields[1+i].Append(g.group[i])
+	}
+	next := 1 + out.groupFields
+	for i := range g.values {
+		for _, aggregation := range out.aggregations {
+			out.fields[next].Append(g.values[i].value(aggregation))
+			next++
+		}
+	}
+	out.rows++
+}
@@ -0,0 +1,206 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func windowTestTime(sec int64) time.Time {
+	return time.Unix(sec, 0).UTC()
+}
+
+func windowTestFrame(times []int64, values []float64) *data.Frame {
+	timeValues := make([]time.Time, 0, len(times))
+	for _, sec := range times {
+		timeValues = append(timeValues, windowTestTime(sec))
+	}
+	return data.NewFrame("test",
+		data.NewField("time", nil, timeValues),
+		data.NewField("value", nil, values),
+	)
+}
+
+func windowFieldValues(t *testing.T, frame *data.Frame, name string) []interface{} {
+	t.Helper()
+	field, idx := frame.FieldByName(name)
+	require.GreaterOrEqual(t, idx, 0, "no field %s", name)
+	values := make([]interface{}, 0, field.Len())
+	for i := 0; i < field.Len(); i++ {
+		v, ok := field.ConcreteAt(i)
+		if !ok {
+			v = nil
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+func TestNewWindowFrameProcessor(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config WindowFrameProcessorConfig
+		expErr string
+	}{
+		{name: "tumbling", config: WindowFrameProcessorConfig{SizeSeconds: 10}},
+		{name: "sliding", config: WindowFrameProcessorConfig{SizeSeconds: 10, SlideSeconds: 5, Aggregations: []string{"min", "max"}}},
+		{name: "missing size", config: WindowFrameProcessorConfig{}, expErr: "window size must be positive"},
+		{name: "slide larger than size", config: WindowFrameProcessorConfig{SizeSeconds: 10, SlideSeconds: 20}, expErr: "window slide must be between 0 and the window size"},
+		{name: "unknown aggregation", config: WindowFrameProcessorConfig{SizeSeconds: 10, Aggregations: []string{"median"}}, expErr: "unknown aggregation: median"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewWindowFrameProcessor(NewWindowStorage(), tc.config)
+			if tc.expErr != "" {
+				require.EqualError(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWindowFrameProcessor_Tumbling(t *testing.T) {
+	storage := NewWindowStorage()
+	vars := Vars{OrgID: 1, Channel: "stream/test/window"}
+	config := WindowFrameProcessorConfig{SizeSeconds: 10}
+
+	p, err := NewWindowFrameProcessor(storage, config)
+	require.NoError(t, err)
+	frame, err := p.ProcessFrame(context.Background(), vars, windowTestFrame([]int64{1000, 1003, 1007}, []float64{1, 2, 6}))
+	require.NoError(t, err)
+	require.Nil(t, frame, "the window did not close yet")
+
+	// Windows survive rebuilding the processor, as happens when channel rules are reloaded.
+	p, err = NewWindowFrameProcessor(storage, config)
+	require.NoError(t, err)
+	frame, err = p.ProcessFrame(context.Background(), vars, windowTestFrame([]int64{1012}, []float64{10}))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Equal(t, "test", frame.Name)
+	require.Equal(t, []interface{}{windowTestTime(1010)}, windowFieldValues(t, frame, "time"))
+	require.Equal(t, []interface{}{3.0}, windowFieldValues(t, frame, "value"))
+
+	// Empty windows are skipped and rows of closed windows are dropped.
+	frame, err = p.ProcessFrame(context.Background(), vars, windowTestFrame([]int64{1045, 1005}, []float64{20, 100}))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Equal(t, []interface{}{windowTestTime(1020)}, windowFieldValues(t, frame, "time"))
+	require.Equal(t, []interface{}{10.0}, windowFieldValues(t, frame, "value"))
+
+	frame, err = p.ProcessFrame(context.Background(), vars, windowTestFrame([]int64{1050}, []float64{0}))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Equal(t, []interface{}{windowTestTime(1050)}, windowFieldValues(t, frame, "time"))
+	require.Equal(t, []interface{}{20.0}, windowFieldValues(t, frame, "value"))
+
+	// Other channels have their own windows.
+	frame, err = p.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/other"}, windowTestFrame([]int64{1060}, []float64{0}))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+}
+
+func TestWindowFrameProcessor_Sliding(t *testing.T) {
+	p, err := NewWindowFrameProcessor(NewWindowStorage(), WindowFrameProcessorConfig{
+		SizeSeconds:  10,
+		SlideSeconds: 5,
+		Aggregations: []string{WindowAggregationMean, WindowAggregationMin, WindowAggregationMax, WindowAggregationLast, WindowAggregationCount},
+	})
+	require.NoError(t, err)
+
+	frame, err := p.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/window"},
+		windowTestFrame([]int64{1000, 1004, 1008, 1011}, []float64{1, 2, 6, 4}))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+
+	require.Equal(t, []interface{}{windowTestTime(1005), windowTestTime(1010)}, windowFieldValues(t, frame, "time"))
+	require.Equal(t, []interface{}{1.5, 3.0}, windowFieldValues(t, frame, "value_mean"))
+	require.Equal(t, []interface{}{1.0, 1.0}, windowFieldValues(t, frame, "value_min"))
+	require.Equal(t, []interface{}{2.0, 6.0}, windowFieldValues(t, frame, "value_max"))
+	require.Equal(t, []interface{}{2.0, 6.0}, windowFieldValues(t, frame, "value_last"))
+	require.Equal(t, []interface{}{2.0, 3.0}, windowFieldValues(t, frame, "value_count"))
+}
+
+func TestWindowFrameProcessor_GroupsByStringFields(t *testing.T) {
+	p, err := NewWindowFrameProcessor(NewWindowStorage(), WindowFrameProcessorConfig{
+		SizeSeconds:  10,
+		Aggregations: []string{WindowAggregationMean, WindowAggregationCount},
+	})
+	require.NoError(t, err)
+	vars := Vars{OrgID: 1, Channel: "stream/test/window"}
+
+	frame, err := p.ProcessFrame(context.Background(), vars, data.NewFrame("cpu",
+		data.NewField("labels", nil, []string{"host=a", "host=b", "host=a"}),
+		data.NewField("time", nil, []time.Time{windowTestTime(1000), windowTestTime(1001), windowTestTime(1002)}),
+		data.NewField("value", nil, []*float64{float64Ptr(1), float64Ptr(10), nil}),
+		data.NewField("other", nil, []float64{5, 5, 7}),
+	))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+
+	frame, err = p.ProcessFrame(context.Background(), vars, data.NewFrame("cpu",
+		data.NewField("labels", nil, []string{"host=a"}),
+		data.NewField("time", nil, []time.Time{windowTestTime(1010)}),
+		data.NewField("value", nil, []*float64{float64Ptr(0)}),
+		data.NewField("other", nil, []float64{0}),
+	))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+
+	require.Equal(t, "cpu", frame.Name)
+	require.Equal(t, []interface{}{windowTestTime(1010), windowTestTime(1010)}, windowFieldValues(t, frame, "time"))
+	require.Equal(t, []interface{}{"host=a", "host=b"}, windowFieldValues(t, frame, "labels"))
+	require.Equal(t, []interface{}{1.0, 10.0}, windowFieldValues(t, frame, "value_mean"))
+	require.Equal(t, []interface{}{1.0, 1.0}, windowFieldValues(t, frame, "value_count"))
+	require.Equal(t, []interface{}{6.0, 5.0}, windowFieldValues(t, frame, "other_mean"))
+	require.Equal(t, []interface{}{2.0, 1.0}, windowFieldValues(t, frame, "other_count"))
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func TestWindowFrameProcessor_InMultiple(t *testing.T) {
+	window, err := NewWindowFrameProcessor(NewWindowStorage(), WindowFrameProcessorConfig{SizeSeconds: 10})
+	require.NoError(t, err)
+	computeFields, err := NewComputeFieldsFrameProcessor(ComputeFieldsFrameProcessorConfig{
+		Fields: []ComputedFieldConfig{{Name: "double", Expression: "value * 2"}},
+	})
+	require.NoError(t, err)
+	p := NewMultipleFrameProcessor(window, computeFields, NewDropFieldsFrameProcessor(DropFieldsFrameProcessorConfig{FieldNames: []string{"value"}}))
+	vars := Vars{OrgID: 1, Channel: "stream/test/window"}
+
+	// Processing stops until a window closes.
+	frame, err := p.ProcessFrame(context.Background(), vars, windowTestFrame([]int64{1000, 1004}, []float64{1, 3}))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+
+	frame, err = p.ProcessFrame(context.Background(), vars, windowTestFrame([]int64{1010}, []float64{0}))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Len(t, frame.Fields, 2)
+	require.Equal(t, []interface{}{4.0}, windowFieldValues(t, frame, "double"))
+}
+
+func TestWindowStorage_RemovesIdleStates(t *testing.T) {
+	now := time.Now()
+	storage := NewWindowStorage()
+	storage.nowFunc = func() time.Time { return now }
+
+	state := storage.get(1, "stream/test/idle", time.Minute)
+	state.rows = append(state.rows, windowRow{time: now})
+	storage.get(1, "stream/test/long", time.Hour)
+
+	now = now.Add(windowStateIdleTimeout / 2)
+	require.Same(t, state, storage.get(1, "stream/test/idle", time.Minute))
+
+	now = now.Add(windowStateIdleTimeout + time.Second)
+	storage.get(1, "stream/test/other", time.Minute)
+	require.NotContains(t, storage.states, "1/stream/test/idle")
+	// Windows longer than half the idle timeout are kept for twice their size.
+	require.Contains(t, storage.states, "1/stream/test/long")
+	require.NotSame(t, state, storage.get(1, "stream/test/idle", time.Minute))
+}
@@ -48,6 +48,14 @@ var FrameOutputsRegistry = []EntityInfo{
 		Type:        FrameOutputTypeLoki,
 		Description: "output frame as JSON to Loki",
 	},
+	{
+		Type:        FrameOutputTypeArchive,
+		Description: "append frames to rotating NDJSON files in the data path",
+		Example: ArchiveOutputConfig{
+			RotateSeconds:    3600,
+			RetentionSeconds: 7 * 24 * 3600,
+		},
+	},
 }
 
 var ConvertersRegistry = []EntityInfo{
@@ -66,6 +74,21 @@ var ConvertersRegistry = []EntityInfo{
 		Type:        ConverterTypeJsonFrame,
 		Description: "JSON-encoded Grafana data frame",
 	},
+	{
+		Type:        ConverterTypePrometheusAuto,
+		Description: "accept Prometheus text exposition format",
+		Example: AutoPrometheusConverterConfig{
+			FrameFormat: "labels_column",
+		},
+	},
+	{
+		Type:        ConverterTypeOTLPAuto,
+		Description: "accept OTLP/JSON metrics",
+		Example: AutoOTLPConverterConfig{
+			FrameFormat:        "labels_column",
+			ResourceAttributes: []string{"service.name"},
+		},
+	},
 }
 
 var FrameProcessorsRegistry = []EntityInfo{
@@ -79,6 +102,21 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeWindow,
+		Description: "aggregate numeric fields over time windows",
+		Example: WindowFrameProcessorConfig{
+			SizeSeconds:  10,
+			Aggregations: []string{WindowAggregationMean, WindowAggregationMax},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeComputeFields,
+		Description: "add fields computed with math expressions over other fields",
+		Example: ComputeFieldsFrameProcessorConfig{
+			Fields: []ComputedFieldConfig{{Name: "celsius", Expression: "(fahrenheit - 32) / 1.8"}},
+		},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
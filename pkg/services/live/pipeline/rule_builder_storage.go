@@ -13,6 +13,8 @@ type StorageRuleBuilder struct {
 	Node                 *centrifuge.Node
 	ManagedStream        *managedstream.Runner
 	FrameStorage         *FrameStorage
+	WindowStorage        *WindowStorage
+	ArchiveStorage       *ArchiveStorage
 	Storage              Storage
 	ChannelHandlerGetter ChannelHandlerGetter
 	SecretsService       secrets.Service
@@ -68,6 +70,16 @@ func (f *StorageRuleBuilder) extractConverter(config *ConverterConfig) (Converte
 			return nil, missingConfiguration
 		}
 		return NewAutoInfluxConverter(*config.AutoInfluxConverterConfig), nil
+	case ConverterTypePrometheusAuto:
+		if config.AutoPrometheusConverterConfig == nil {
+			config.AutoPrometheusConverterConfig = &AutoPrometheusConverterConfig{}
+		}
+		return NewAutoPrometheusConverter(*config.AutoPrometheusConverterConfig), nil
+	case ConverterTypeOTLPAuto:
+		if config.AutoOTLPConverterConfig == nil {
+			config.AutoOTLPConverterConfig = &AutoOTLPConverterConfig{}
+		}
+		return NewAutoOTLPConverter(*config.AutoOTLPConverterConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown converter type: %s", config.Type)
 	}
@@ -103,6 +115,16 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 			processors = append(processors, proc)
 		}
 		return NewMultipleFrameProcessor(processors...), nil
+	case FrameProcessorTypeWindow:
+		if config.WindowProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewWindowFrameProcessor(f.WindowStorage, *config.WindowProcessorConfig)
+	case FrameProcessorTypeComputeFields:
+		if config.ComputeFieldsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewComputeFieldsFrameProcessor(*config.ComputeFieldsProcessorConfig)
 	default:
 		return nil, fmt.Errorf("unknown processor type: %s", config.Type)
 	}
@@ -248,6 +270,11 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 			return nil, missingConfiguration
 		}
 		return NewChangeLogFrameOutput(f.FrameStorage, *config.ChangeLogOutputConfig), nil
+	case FrameOutputTypeArchive:
+		if config.ArchiveOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewArchiveFrameOutput(f.ArchiveStorage, *config.ArchiveOutputConfig)
 	default:
 		return nil, fmt.Errorf("unknown output type: %s", config.Type)
 	}
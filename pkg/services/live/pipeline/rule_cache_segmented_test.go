@@ -53,6 +53,33 @@ func TestStorage_Get(t *testing.T) {
 	require.Equal(t, "stream/boom:er", rule.Pattern)
 }
 
+type countingBuilder struct {
+	testBuilder
+	builds int
+}
+
+func (b *countingBuilder) BuildRules(ctx context.Context, orgID int64) ([]*LiveChannelRule, error) {
+	b.builds++
+	return b.testBuilder.BuildRules(ctx, orgID)
+}
+
+func TestStorage_Invalidate(t *testing.T) {
+	builder := &countingBuilder{}
+	s := NewCacheSegmentedTree(builder)
+	_, ok, err := s.Get(1, "stream/telegraf/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+	_, _, err = s.Get(1, "stream/telegraf/mem")
+	require.NoError(t, err)
+	require.Equal(t, 1, builder.builds)
+
+	s.Invalidate(1)
+	_, ok, err = s.Get(1, "stream/telegraf/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 2, builder.builds)
+}
+
 func BenchmarkRuleGet(b *testing.B) {
 	s := NewCacheSegmentedTree(&testBuilder{})
 	for i := 0; i < b.N; i++ {
@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/centrifugal/centrifuge"
+)
+
+// ChangeNotifier propagates changes of the stored pipeline data of an
+// organization, so that all Grafana server instances stop using stale rules.
+type ChangeNotifier interface {
+	NotifyChanged(orgID int64) error
+}
+
+// changeNotificationOp is the Centrifuge notification op of pipeline changes.
+const changeNotificationOp = "live_pipeline_changed"
+
+type changeNotification struct {
+	OrgID int64 `json:"orgId"`
+}
+
+// NodeChangeNotifier sends changes as notifications of the Centrifuge node.
+// With the Redis broker of the HA mode the notifications reach the nodes of
+// all Grafana server instances, otherwise only the local node. Received
+// notifications are passed to the handlers registered with OnChange.
+//
+// NodeChangeNotifier registers the notification handler of the node, so the
+// node can have only one NodeChangeNotifier.
+type NodeChangeNotifier struct {
+	node       *centrifuge.Node
+	handlersMu sync.RWMutex
+	handlers   []func(orgID int64)
+}
+
+func NewNodeChangeNotifier(node *centrifuge.Node) *NodeChangeNotifier {
+	n := &NodeChangeNotifier{node: node}
+	node.OnNotification(n.handleNotification)
+	return n
+}
+
+// OnChange registers a handler that is called on every node with the
+// organization which pipeline data changed.
+func (n *NodeChangeNotifier) OnChange(handler func(orgID int64)) {
+	n.handlersMu.Lock()
+	defer n.handlersMu.Unlock()
+	n.handlers = append(n.handlers, handler)
+}
+
+func (n *NodeChangeNotifier) NotifyChanged(orgID int64) error {
+	data, err := json.Marshal(changeNotification{OrgID: orgID})
+	if err != nil {
+		return err
+	}
+	return n.node.Notify(changeNotificationOp, data, "")
+}
+
+func (n *NodeChangeNotifier) handleNotification(e centrifuge.NotificationEvent) {
+	if e.Op != changeNotificationOp {
+		return
+	}
+	var notification changeNotification
+	if err := json.Unmarshal(e.Data, &notification); err != nil {
+		logger.Error("Error decoding pipeline change notification", "error", err, "fromNodeId", e.FromNodeID)
+		return
+	}
+	n.handlersMu.RLock()
+	defer n.handlersMu.RUnlock()
+	for _, handler := range n.handlers {
+		handler(notification.OrgID)
+	}
+}
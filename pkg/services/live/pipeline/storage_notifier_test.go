@@ -0,0 +1,31 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeChangeNotifier(t *testing.T) {
+	node, err := centrifuge.New(centrifuge.Config{})
+	require.NoError(t, err)
+	require.NoError(t, node.Run())
+	t.Cleanup(func() { _ = node.Shutdown(context.Background()) })
+
+	notifier := NewNodeChangeNotifier(node)
+	changes := make(chan int64, 1)
+	notifier.OnChange(func(orgID int64) {
+		changes <- orgID
+	})
+
+	require.NoError(t, notifier.NotifyChanged(2))
+	select {
+	case orgID := <-changes:
+		require.Equal(t, int64(2), orgID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("change not received")
+	}
+}
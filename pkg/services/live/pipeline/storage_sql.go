@@ -0,0 +1,336 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// SQLStorage keeps channel rules and write configs in the Grafana database, so
+// they are shared by all Grafana server instances. Secure settings of write
+// configs are encrypted with the secrets service. Every change is passed to the
+// ChangeNotifier so that instances rebuild the cached rules of the organization.
+type SQLStorage struct {
+	store          db.DB
+	secretsService secrets.Service
+	notifier       ChangeNotifier
+}
+
+func NewSQLStorage(store db.DB, secretsService secrets.Service, notifier ChangeNotifier) *SQLStorage {
+	return &SQLStorage{store: store, secretsService: secretsService, notifier: notifier}
+}
+
+type channelRuleRow struct {
+	Id       int64
+	OrgId    int64
+	Pattern  string
+	Settings string
+	Created  time.Time
+	Updated  time.Time
+}
+
+func (r channelRuleRow) TableName() string { return "live_channel_rule" }
+
+func (r channelRuleRow) toChannelRule() (ChannelRule, error) {
+	rule := ChannelRule{OrgId: r.OrgId, Pattern: r.Pattern}
+	if err := json.Unmarshal([]byte(r.Settings), &rule.Settings); err != nil {
+		return ChannelRule{}, fmt.Errorf("can't unmarshal settings of channel rule %s: %w", r.Pattern, err)
+	}
+	return rule, nil
+}
+
+type writeConfigRow struct {
+	Id             int64
+	OrgId          int64
+	UID            string `xorm:"uid"`
+	Settings       string
+	SecureSettings string
+	Created        time.Time
+	Updated        time.Time
+}
+
+func (r writeConfigRow) TableName() string { return "live_write_config" }
+
+func (r writeConfigRow) toWriteConfig() (WriteConfig, error) {
+	writeConfig := WriteConfig{OrgId: r.OrgId, UID: r.UID}
+	if err := json.Unmarshal([]byte(r.Settings), &writeConfig.Settings); err != nil {
+		return WriteConfig{}, fmt.Errorf("can't unmarshal settings of write config %s: %w", r.UID, err)
+	}
+	if r.SecureSettings != "" {
+		if err := json.Unmarshal([]byte(r.SecureSettings), &writeConfig.SecureSettings); err != nil {
+			return WriteConfig{}, fmt.Errorf("can't unmarshal secure settings of write config %s: %w", r.UID, err)
+		}
+	}
+	return writeConfig, nil
+}
+
+func (s *SQLStorage) ListWriteConfigs(ctx context.Context, orgID int64) ([]WriteConfig, error) {
+	var rows []writeConfigRow
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("uid").Find(&rows)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't read write configs: %w", err)
+	}
+	var writeConfigs []WriteConfig
+	for _, row := range rows {
+		writeConfig, err := row.toWriteConfig()
+		if err != nil {
+			return nil, err
+		}
+		writeConfigs = append(writeConfigs, writeConfig)
+	}
+	return writeConfigs, nil
+}
+
+func (s *SQLStorage) GetWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigGetCmd) (WriteConfig, bool, error) {
+	var row writeConfigRow
+	var exists bool
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Get(&row)
+		return err
+	})
+	if err != nil {
+		return WriteConfig{}, false, fmt.Errorf("can't read write configs: %w", err)
+	}
+	if !exists {
+		return WriteConfig{}, false, nil
+	}
+	writeConfig, err := row.toWriteConfig()
+	return writeConfig, err == nil, err
+}
+
+func (s *SQLStorage) CreateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigCreateCmd) (WriteConfig, error) {
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+	return s.saveWriteConfig(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings, false)
+}
+
+func (s *SQLStorage) UpdateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigUpdateCmd) (WriteConfig, error) {
+	return s.saveWriteConfig(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings, true)
+}
+
+// saveWriteConfig inserts the write config, or updates an existing write config
+// with the same UID if update is true.
+func (s *SQLStorage) saveWriteConfig(ctx context.Context, orgID int64, uid string, settings WriteSettings, secureSettings map[string]string, update bool) (WriteConfig, error) {
+	backend, row, err := s.buildWriteConfig(ctx, orgID, uid, settings, secureSettings)
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, backend.UID).Exist(&writeConfigRow{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			if !update {
+				return fmt.Errorf("backend already exists in org: %s", backend.UID)
+			}
+			_, err = sess.Where("org_id = ? AND uid = ?", orgID, backend.UID).Cols("settings", "secure_settings", "updated").Update(&row)
+			return err
+		}
+		row.Created = row.Updated
+		_, err = sess.Insert(&row)
+		return err
+	})
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	s.notifyChanged(orgID)
+	return backend, nil
+}
+
+func (s *SQLStorage) DeleteWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigDeleteCmd) error {
+	var deleted int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		deleted, err = sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Delete(&writeConfigRow{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return fmt.Errorf("write config not found")
+	}
+	s.notifyChanged(orgID)
+	return nil
+}
+
+// buildWriteConfig validates the write config and encrypts its secure settings.
+func (s *SQLStorage) buildWriteConfig(ctx context.Context, orgID int64, uid string, settings WriteSettings, secureSettings map[string]string) (WriteConfig, writeConfigRow, error) {
+	encryptedSettings, err := s.secretsService.EncryptJsonData(ctx, secureSettings, secrets.WithoutScope())
+	if err != nil {
+		return WriteConfig{}, writeConfigRow{}, fmt.Errorf("error encrypting data: %w", err)
+	}
+	backend := WriteConfig{
+		OrgId:          orgID,
+		UID:            uid,
+		Settings:       settings,
+		SecureSettings: encryptedSettings,
+	}
+	ok, reason := backend.Valid()
+	if !ok {
+		return WriteConfig{}, writeConfigRow{}, fmt.Errorf("invalid write config: %s", reason)
+	}
+
+	settingsJSON, err := json.Marshal(backend.Settings)
+	if err != nil {
+		return WriteConfig{}, writeConfigRow{}, fmt.Errorf("can't marshal write config settings: %w", err)
+	}
+	secureSettingsJSON, err := json.Marshal(backend.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, writeConfigRow{}, fmt.Errorf("can't marshal write config secure settings: %w", err)
+	}
+	return backend, writeConfigRow{
+		OrgId:          orgID,
+		UID:            uid,
+		Settings:       string(settingsJSON),
+		SecureSettings: string(secureSettingsJSON),
+		Updated:        time.Now(),
+	}, nil
+}
+
+func (s *SQLStorage) ListChannelRules(ctx context.Context, orgID int64) ([]ChannelRule, error) {
+	var rules []ChannelRule
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		rules, err = listChannelRules(sess, orgID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't read channel rules: %w", err)
+	}
+	return rules, nil
+}
+
+func listChannelRules(sess *db.Session, orgID int64) ([]ChannelRule, error) {
+	var rows []channelRuleRow
+	if err := sess.Where("org_id = ?", orgID).Asc("pattern").Find(&rows); err != nil {
+		return nil, err
+	}
+	var rules []ChannelRule
+	for _, row := range rows {
+		rule, err := row.toChannelRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (s *SQLStorage) CreateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error) {
+	return s.saveChannelRule(ctx, orgID, cmd.Pattern, cmd.Settings, false)
+}
+
+func (s *SQLStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleUpdateCmd) (ChannelRule, error) {
+	return s.saveChannelRule(ctx, orgID, cmd.Pattern, cmd.Settings, true)
+}
+
+// saveChannelRule inserts the channel rule, or updates an existing rule with
+// the same pattern if update is true. The rule must not conflict with the other
+// rules of the organization.
+func (s *SQLStorage) saveChannelRule(ctx context.Context, orgID int64, pattern string, settings ChannelRuleSettings, update bool) (ChannelRule, error) {
+	rule, row, err := buildChannelRule(orgID, pattern, settings)
+	if err != nil {
+		return rule, err
+	}
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		rules, err := listChannelRules(sess, orgID)
+		if err != nil {
+			return fmt.Errorf("can't read channel rules: %w", err)
+		}
+		index := -1
+		for i, existingRule := range rules {
+			if existingRule.Pattern == rule.Pattern {
+				index = i
+				break
+			}
+		}
+		if index > -1 {
+			if !update {
+				return fmt.Errorf("pattern already exists in org: %s", rule.Pattern)
+			}
+			rules[index] = rule
+		} else {
+			rules = append(rules, rule)
+		}
+		if ok, reason := checkRulesValid(orgID, rules); !ok {
+			return errors.New(reason)
+		}
+
+		if index > -1 {
+			_, err = sess.Where("org_id = ? AND pattern = ?", orgID, rule.Pattern).Cols("settings", "updated").Update(&row)
+			return err
+		}
+		row.Created = row.Updated
+		_, err = sess.Insert(&row)
+		return err
+	})
+	if err != nil {
+		return rule, err
+	}
+	s.notifyChanged(orgID)
+	return rule, nil
+}
+
+func (s *SQLStorage) DeleteChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleDeleteCmd) error {
+	var deleted int64
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		deleted, err = sess.Where("org_id = ? AND pattern = ?", orgID, cmd.Pattern).Delete(&channelRuleRow{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return fmt.Errorf("rule not found")
+	}
+	s.notifyChanged(orgID)
+	return nil
+}
+
+// buildChannelRule validates the channel rule.
+func buildChannelRule(orgID int64, pattern string, settings ChannelRuleSettings) (ChannelRule, channelRuleRow, error) {
+	rule := ChannelRule{
+		OrgId:    orgID,
+		Pattern:  pattern,
+		Settings: settings,
+	}
+	ok, reason := rule.Valid()
+	if !ok {
+		return rule, channelRuleRow{}, fmt.Errorf("invalid channel rule: %s", reason)
+	}
+	settingsJSON, err := json.Marshal(rule.Settings)
+	if err != nil {
+		return rule, channelRuleRow{}, fmt.Errorf("can't marshal channel rule settings: %w", err)
+	}
+	return rule, channelRuleRow{
+		OrgId:    orgID,
+		Pattern:  pattern,
+		Settings: string(settingsJSON),
+		Updated:  time.Now(),
+	}, nil
+}
+
+// notifyChanged propagates a committed change. A failed notification is only
+// logged since the change is already stored, and the periodic rebuild of the
+// rule cache picks it up eventually.
+func (s *SQLStorage) notifyChanged(orgID int64) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.NotifyChanged(orgID); err != nil {
+		logger.Error("Error notifying about pipeline change", "error", err, "orgId", orgID)
+	}
+}
@@ -0,0 +1,169 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+)
+
+type fakeChangeNotifier struct {
+	mu      sync.Mutex
+	changes []int64
+}
+
+func (n *fakeChangeNotifier) NotifyChanged(orgID int64) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.changes = append(n.changes, orgID)
+	return nil
+}
+
+func setupSQLStorage(t *testing.T) (*SQLStorage, *fakeChangeNotifier) {
+	t.Helper()
+	notifier := &fakeChangeNotifier{}
+	return NewSQLStorage(db.InitTestDB(t), fakes.NewFakeSecretsService(), notifier), notifier
+}
+
+func TestIntegrationSQLStorage_ChannelRules(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s, notifier := setupSQLStorage(t)
+
+	rule, err := s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{
+		Pattern:  "stream/telegraf/:metric",
+		Settings: ChannelRuleSettings{Converter: &ConverterConfig{Type: ConverterTypeInfluxAuto}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rule.OrgId)
+
+	_, err = s.CreateChannelRule(ctx, 2, ChannelRuleCreateCmd{Pattern: "stream/telegraf/:metric"})
+	require.NoError(t, err)
+
+	t.Run("rejects existing patterns", func(t *testing.T) {
+		_, err := s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/telegraf/:metric"})
+		require.EqualError(t, err, "pattern already exists in org: stream/telegraf/:metric")
+	})
+
+	t.Run("rejects invalid rules", func(t *testing.T) {
+		_, err := s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{
+			Pattern:  "stream/telegraf/cpu",
+			Settings: ChannelRuleSettings{Converter: &ConverterConfig{Type: "unknown"}},
+		})
+		require.EqualError(t, err, "invalid channel rule: unknown converter type: unknown")
+	})
+
+	t.Run("rejects patterns that conflict with rules of the org", func(t *testing.T) {
+		_, err := s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/telegraf/:name"})
+		require.Error(t, err)
+	})
+
+	t.Run("lists rules of the org", func(t *testing.T) {
+		rules, err := s.ListChannelRules(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, []ChannelRule{rule}, rules)
+	})
+
+	t.Run("updates rules and creates missing ones", func(t *testing.T) {
+		updated, err := s.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{
+			Pattern:  "stream/telegraf/:metric",
+			Settings: ChannelRuleSettings{Converter: &ConverterConfig{Type: ConverterTypeJsonAuto}},
+		})
+		require.NoError(t, err)
+		created, err := s.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{Pattern: "stream/other"})
+		require.NoError(t, err)
+
+		rules, err := s.ListChannelRules(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, []ChannelRule{created, updated}, rules)
+	})
+
+	t.Run("deletes rules", func(t *testing.T) {
+		require.NoError(t, s.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/other"}))
+		require.EqualError(t, s.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/other"}), "rule not found")
+
+		rules, err := s.ListChannelRules(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+	})
+
+	require.Equal(t, []int64{1, 2, 1, 1, 1}, notifier.changes)
+}
+
+func TestIntegrationSQLStorage_WriteConfigs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s, notifier := setupSQLStorage(t)
+
+	writeConfig, err := s.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{
+		Settings:       WriteSettings{Endpoint: "http://localhost:9090/api/v1/write", BasicAuth: &BasicAuth{User: "admin"}},
+		SecureSettings: map[string]string{"basicAuthPassword": "secret"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, writeConfig.UID)
+	require.Equal(t, map[string][]byte{"basicAuthPassword": []byte("secret")}, writeConfig.SecureSettings)
+
+	t.Run("rejects existing UIDs", func(t *testing.T) {
+		_, err := s.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{
+			UID:      writeConfig.UID,
+			Settings: WriteSettings{Endpoint: "http://localhost:9090/api/v1/write"},
+		})
+		require.EqualError(t, err, "backend already exists in org: "+writeConfig.UID)
+	})
+
+	t.Run("rejects invalid write configs", func(t *testing.T) {
+		_, err := s.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{UID: "invalid"})
+		require.EqualError(t, err, "invalid write config: endpoint required")
+	})
+
+	t.Run("gets write configs of the org with the encrypted secure settings", func(t *testing.T) {
+		existing, ok, err := s.GetWriteConfig(ctx, 1, WriteConfigGetCmd{UID: writeConfig.UID})
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, writeConfig, existing)
+
+		_, ok, err = s.GetWriteConfig(ctx, 2, WriteConfigGetCmd{UID: writeConfig.UID})
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("updates write configs and creates missing ones", func(t *testing.T) {
+		updated, err := s.UpdateWriteConfig(ctx, 1, WriteConfigUpdateCmd{
+			UID:      writeConfig.UID,
+			Settings: WriteSettings{Endpoint: "http://localhost:9091/api/v1/write"},
+		})
+		require.NoError(t, err)
+		require.Empty(t, updated.SecureSettings)
+		created, err := s.UpdateWriteConfig(ctx, 2, WriteConfigUpdateCmd{
+			UID:      writeConfig.UID,
+			Settings: WriteSettings{Endpoint: "http://localhost:9092/api/v1/write"},
+		})
+		require.NoError(t, err)
+
+		configs, err := s.ListWriteConfigs(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, []WriteConfig{updated}, configs)
+		configs, err = s.ListWriteConfigs(ctx, 2)
+		require.NoError(t, err)
+		require.Equal(t, []WriteConfig{created}, configs)
+	})
+
+	t.Run("deletes write configs", func(t *testing.T) {
+		require.NoError(t, s.DeleteWriteConfig(ctx, 1, WriteConfigDeleteCmd{UID: writeConfig.UID}))
+		require.EqualError(t, s.DeleteWriteConfig(ctx, 1, WriteConfigDeleteCmd{UID: writeConfig.UID}), "write config not found")
+
+		configs, err := s.ListWriteConfigs(ctx, 1)
+		require.NoError(t, err)
+		require.Empty(t, configs)
+	})
+
+	require.Equal(t, []int64{1, 1, 2, 1}, notifier.changes)
+}
@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+)
+
+const (
+	// windowStateIdleTimeout is the minimum time after which the state of a
+	// channel that stopped pushing is dropped, together with its rows in
+	// windows that did not close.
+	windowStateIdleTimeout     = 10 * time.Minute
+	windowStorageSweepInterval = time.Minute
+)
+
+// WindowStorage keeps the state of window frame processors in memory, so that
+// windows survive rebuilding channel rules. States of channels that stopped
+// pushing, or lost their window processor, are dropped after being idle for
+// twice the window size or windowStateIdleTimeout, whichever is longer. Not
+// usable in HA setup.
+type WindowStorage struct {
+	mu        sync.Mutex
+	states    map[string]*windowState
+	lastSweep time.Time
+	nowFunc   func() time.Time
+}
+
+func NewWindowStorage() *WindowStorage {
+	return &WindowStorage{
+		states:  map[string]*windowState{},
+		nowFunc: time.Now,
+	}
+}
+
+// get returns the state of the windows of the channel, creating an empty state
+// if there is none yet.
+func (s *WindowStorage) get(orgID int64, channel string, size time.Duration) *windowState {
+	key := orgchannel.PrependOrgID(orgID, channel)
+	now := s.nowFunc()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) >= windowStorageSweepInterval {
+		s.removeIdle(now)
+		s.lastSweep = now
+	}
+	state, ok := s.states[key]
+	if !ok {
+		state = &windowState{}
+		s.states[key] = state
+	}
+	state.lastUsed = now
+	state.idleTimeout = windowStateIdleTimeout
+	if 2*size > state.idleTimeout {
+		state.idleTimeout = 2 * size
+	}
+	return state
+}
+
+func (s *WindowStorage) removeIdle(now time.Time) {
+	for key, state := range s.states {
+		if now.Sub(state.lastUsed) > state.idleTimeout {
+			delete(s.states, key)
+		}
+	}
+}
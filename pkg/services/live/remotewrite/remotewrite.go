@@ -26,6 +26,19 @@ func SerializeLabelsColumn(frames ...*data.Frame) ([]byte, error) {
 
 // TimeSeriesFromFrames converts frames to slice of Prometheus TimeSeries.
 func TimeSeriesFromFrames(frames ...*data.Frame) []prompb.TimeSeries {
+	return timeSeriesFromFrames(makeMetricName, frames...)
+}
+
+// TimeSeriesFromFramesWithName converts frames to slice of Prometheus TimeSeries in which every
+// numeric field is named after the given metric instead of the names of the frame and the field.
+// Fields are told apart by their labels.
+func TimeSeriesFromFramesWithName(name string, frames ...*data.Frame) []prompb.TimeSeries {
+	return timeSeriesFromFrames(func(*data.Frame, *data.Field) string {
+		return name
+	}, frames...)
+}
+
+func timeSeriesFromFrames(metricName func(*data.Frame, *data.Field) string, frames ...*data.Frame) []prompb.TimeSeries {
 	var entries = make(map[metricKey]prompb.TimeSeries)
 	var keys []metricKey // sorted keys.
 
@@ -39,8 +52,7 @@ func TimeSeriesFromFrames(frames ...*data.Frame) []prompb.TimeSeries {
 			if !field.Type().Numeric() {
 				continue
 			}
-			metricName := makeMetricName(frame, field)
-			metricName, ok := sanitizeMetricName(metricName)
+			metricName, ok := sanitizeMetricName(metricName(frame, field))
 			if !ok {
 				continue
 			}
@@ -77,6 +89,12 @@ func TimeSeriesFromFrames(frames ...*data.Frame) []prompb.TimeSeries {
 				Name:  "__name__",
 				Value: metricName,
 			})
+			if existing, ok := entries[key]; ok {
+				// Fields with the same name and labels are the same series.
+				existing.Samples = append(existing.Samples, samples...)
+				entries[key] = existing
+				continue
+			}
 			promTimeSeries := prompb.TimeSeries{Labels: labelsCopy, Samples: samples}
 			entries[key] = promTimeSeries
 			keys = append(keys, key)
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
 	"github.com/stretchr/testify/require"
 )
 
@@ -85,3 +86,26 @@ func TestSerialize(t *testing.T) {
 	_, err := Serialize(frame)
 	require.NoError(t, err)
 }
+
+func TestTsFromFramesWithName(t *testing.T) {
+	t1 := time.Now()
+	t2 := time.Now().Add(time.Second)
+	frame1 := data.NewFrame("frame1",
+		data.NewField("time", nil, []time.Time{t1}),
+		data.NewField("value", map[string]string{"host": "a"}, []float64{1.0}),
+	)
+	frame2 := data.NewFrame("frame2",
+		data.NewField("time", nil, []time.Time{t1, t2}),
+		data.NewField("value", map[string]string{"host": "b"}, []float64{2.0, 3.0}),
+	)
+	frame3 := data.NewFrame("frame3",
+		data.NewField("time", nil, []time.Time{t2}),
+		data.NewField("other", map[string]string{"host": "a"}, []float64{4.0}),
+	)
+	ts := TimeSeriesFromFramesWithName("recorded:value", frame1, frame2, frame3)
+	require.Len(t, ts, 2)
+	require.Equal(t, []prompb.Label{{Name: "host", Value: "a"}, {Name: "__name__", Value: "recorded:value"}}, ts[0].Labels)
+	require.Equal(t, []prompb.Sample{{Timestamp: toSampleTime(t1), Value: 1.0}, {Timestamp: toSampleTime(t2), Value: 4.0}}, ts[0].Samples)
+	require.Equal(t, []prompb.Label{{Name: "host", Value: "b"}, {Name: "__name__", Value: "recorded:value"}}, ts[1].Labels)
+	require.Len(t, ts[1].Samples, 2)
+}
@@ -5,6 +5,8 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/prometheus/common/model"
+
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -53,6 +55,10 @@ type Alertmanager interface {
 	// Receivers
 	GetReceivers(ctx context.Context) []apimodels.Receiver
 	TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigBodyParams) (*notifier.TestReceiversResult, error)
+	GetNotificationDeliveries(ctx context.Context, query models.NotificationDeliveryQuery) ([]models.NotificationDelivery, error)
+
+	// Routing
+	TestRouting(labels model.LabelSet, at time.Time) apimodels.TestRoutingResult
 }
 
 type AlertingStore interface {
@@ -75,6 +81,7 @@ type API struct {
 	DataProxy            *datasourceproxy.DataSourceProxyService
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	StateManager         *state.Manager
+	Historian            state.HistoryReader
 	AccessControl        accesscontrol.AccessControl
 	Policies             *provisioning.NotificationPolicyService
 	ContactPointService  *provisioning.ContactPointService
@@ -132,9 +139,23 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			accessControl:   api.AccessControl,
 			evaluator:       api.EvaluatorFactory,
 			cfg:             &api.Cfg.UnifiedAlerting,
-			backtesting:     backtesting.NewEngine(api.AppUrl, api.EvaluatorFactory),
+			backtesting:     backtesting.NewEngine(api.AppUrl, api.EvaluatorFactory, api.Cfg.UnifiedAlerting.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel)),
 			featureManager:  api.FeatureManager,
+			store:           api.RuleStore,
+			simulatorFor: func(orgID int64) (backtesting.NotificationSimulator, error) {
+				am, err := api.MultiOrgAlertmanager.AlertmanagerFor(orgID)
+				if err != nil {
+					return nil, err
+				}
+				return am, nil
+			},
 		}), m)
+	api.RegisterHistoryApiEndpoints(NewStateHistoryApi(&HistorySrv{
+		logger: logger,
+		hist:   api.Historian,
+		store:  api.RuleStore,
+		ac:     api.AccessControl,
+	}), m)
 	api.RegisterConfigurationApiEndpoints(NewConfiguration(
 		&ConfigSrv{
 			datasourceService:    api.DatasourceService,
@@ -151,6 +172,8 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		templates:           api.Templates,
 		muteTimings:         api.MuteTimings,
 		alertRules:          api.AlertRules,
+		namespaces:          api.RuleStore,
+		ac:                  api.AccessControl,
 	}), m)
 }
 
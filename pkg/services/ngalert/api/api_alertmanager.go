@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/util"
@@ -257,6 +258,70 @@ func (srv AlertmanagerSrv) RouteGetReceivers(c *models.ReqContext) response.Resp
 	return response.JSON(http.StatusOK, rcvs)
 }
 
+func (srv AlertmanagerSrv) RouteGetNotificationDeliveries(c *models.ReqContext) response.Response {
+	status := c.Query("status")
+	if status != "" && status != ngmodels.NotificationDeliverySuccess && status != ngmodels.NotificationDeliveryFailed {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("status must be %s or %s", ngmodels.NotificationDeliverySuccess, ngmodels.NotificationDeliveryFailed), "")
+	}
+	from, err := parseEpochMillis(c.Query("from"))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid from")
+	}
+	to, err := parseEpochMillis(c.Query("to"))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid to")
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return ErrResp(http.StatusBadRequest, errors.New("from cannot be greater than to"), "")
+	}
+	limit := c.QueryInt("limit")
+	if limit < 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("limit must not be negative"), "")
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	deliveries, err := am.GetNotificationDeliveries(c.Req.Context(), ngmodels.NotificationDeliveryQuery{
+		Receiver:         c.Query("receiver"),
+		IntegrationUID:   c.Query("integrationUID"),
+		Status:           status,
+		AlertFingerprint: c.Query("fingerprint"),
+		From:             from,
+		To:               to,
+		Limit:            limit,
+	})
+	if err != nil {
+		if errors.Is(err, notifier.ErrDeliveryLogDisabled) {
+			return ErrResp(http.StatusNotImplemented, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification deliveries")
+	}
+
+	result := make(apimodels.NotificationDeliveries, 0, len(deliveries))
+	for _, d := range deliveries {
+		result = append(result, apimodels.NotificationDelivery{
+			Receiver:          d.Receiver,
+			IntegrationUID:    d.IntegrationUID,
+			IntegrationName:   d.IntegrationName,
+			IntegrationType:   d.IntegrationType,
+			GroupKey:          d.GroupKey,
+			AlertFingerprints: d.AlertFingerprints,
+			Firing:            d.Firing,
+			Resolved:          d.Resolved,
+			Attempt:           d.Attempt,
+			Status:            d.Status,
+			StatusCode:        d.StatusCode,
+			Error:             d.Error,
+			Duration:          d.Duration.Milliseconds(),
+			SentAt:            time.UnixMilli(d.SentAt).UTC(),
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
 func (srv AlertmanagerSrv) RoutePostTestReceivers(c *models.ReqContext, body apimodels.TestReceiversConfigBodyParams) response.Response {
 	if err := srv.crypto.LoadSecureSettings(c.Req.Context(), c.OrgID, body.Receivers); err != nil {
 		var unknownReceiverError UnknownReceiverError
@@ -296,6 +361,23 @@ func (srv AlertmanagerSrv) RoutePostTestReceivers(c *models.ReqContext, body api
 	return response.JSON(statusForTestReceivers(result.Receivers), newTestReceiversResult(result))
 }
 
+func (srv AlertmanagerSrv) RoutePostTestRouting(c *models.ReqContext, body apimodels.TestRoutingConfigBodyParams) response.Response {
+	if err := body.Labels.Validate(); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid labels")
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	at := body.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return response.JSON(http.StatusOK, am.TestRouting(body.Labels, at))
+}
+
 // contextWithTimeoutFromRequest returns a context with a deadline set from the
 // Request-Timeout header in the HTTP request. If the header is absent then the
 // context will use the default timeout. The timeout in the Request-Timeout
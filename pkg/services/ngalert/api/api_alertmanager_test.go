@@ -11,6 +11,7 @@ import (
 	"github.com/go-openapi/strfmt"
 	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/api/response"
@@ -290,6 +291,109 @@ func TestAlertmanagerConfig(t *testing.T) {
 	})
 }
 
+func TestRoutePostTestRouting(t *testing.T) {
+	sut := createSut(t, nil)
+
+	t.Run("assert 400 Bad Request when labels are invalid", func(t *testing.T) {
+		response := sut.RoutePostTestRouting(createRequestCtxInOrg(1), apimodels.TestRoutingConfigBodyParams{
+			Labels: model.LabelSet{"invalid-name": "value"},
+		})
+
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("assert 404 Not Found when org does not exist", func(t *testing.T) {
+		response := sut.RoutePostTestRouting(createRequestCtxInOrg(12), apimodels.TestRoutingConfigBodyParams{
+			Labels: model.LabelSet{"team": "a"},
+		})
+
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("assert 200 with the receiver of the matching route", func(t *testing.T) {
+		now := time.Date(2023, 1, 9, 10, 0, 0, 0, time.UTC)
+		response := sut.RoutePostTestRouting(createRequestCtxInOrg(1), apimodels.TestRoutingConfigBodyParams{
+			Labels: model.LabelSet{"team": "a"},
+			Time:   now,
+		})
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var result apimodels.TestRoutingResult
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Equal(t, now, result.Time)
+		require.Equal(t, []string{"grafana-default-email"}, result.Receivers)
+		require.Len(t, result.Routes, 1)
+		require.Empty(t, result.Routes[0].Path)
+	})
+}
+
+func TestRouteGetNotificationDeliveries(t *testing.T) {
+	sut := createSut(t, nil)
+
+	t.Run("assert 400 Bad Request when the query is invalid", func(t *testing.T) {
+		testCases := map[string]string{
+			"invalid status": "status=sent",
+			"invalid from":   "from=yesterday",
+			"from after to":  "from=2000&to=1000",
+			"negative limit": "limit=-1",
+		}
+		for name, query := range testCases {
+			t.Run(name, func(t *testing.T) {
+				response := sut.RouteGetNotificationDeliveries(createRequestCtxWithQuery(t, 1, query))
+				require.Equal(t, http.StatusBadRequest, response.Status())
+			})
+		}
+	})
+
+	t.Run("assert 501 Not Implemented when the delivery log is disabled", func(t *testing.T) {
+		response := sut.RouteGetNotificationDeliveries(createRequestCtxWithQuery(t, 1, ""))
+		require.Equal(t, http.StatusNotImplemented, response.Status())
+	})
+
+	am, err := sut.mam.AlertmanagerFor(1)
+	require.NoError(t, err)
+	am.Settings.UnifiedAlerting.NotificationDeliveryLog.Enabled = true
+	sentAt := time.UnixMilli(time.Now().UnixMilli())
+	for _, receiver := range []string{"team-a", "team-b"} {
+		require.NoError(t, am.Store.SaveNotificationDelivery(context.Background(), &ngmodels.NotificationDelivery{
+			OrgID:             1,
+			Receiver:          receiver,
+			AlertFingerprints: []string{"aaaa"},
+			Firing:            1,
+			Attempt:           1,
+			Status:            ngmodels.NotificationDeliveryFailed,
+			StatusCode:        http.StatusBadGateway,
+			Error:             "webhook response status 502",
+			Duration:          1500 * time.Millisecond,
+			SentAt:            sentAt.UnixMilli(),
+		}))
+	}
+
+	t.Run("assert 404 Not Found when org does not exist", func(t *testing.T) {
+		response := sut.RouteGetNotificationDeliveries(createRequestCtxWithQuery(t, 12, ""))
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("assert 200 with the deliveries of the receiver", func(t *testing.T) {
+		response := sut.RouteGetNotificationDeliveries(createRequestCtxWithQuery(t, 1, "receiver=team-b"))
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var result apimodels.NotificationDeliveries
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Equal(t, apimodels.NotificationDeliveries{{
+			Receiver:          "team-b",
+			AlertFingerprints: []string{"aaaa"},
+			Firing:            1,
+			Attempt:           1,
+			Status:            ngmodels.NotificationDeliveryFailed,
+			StatusCode:        http.StatusBadGateway,
+			Error:             "webhook response status 502",
+			Duration:          1500,
+			SentAt:            sentAt.UTC(),
+		}}, result)
+	})
+}
+
 func TestSilenceCreate(t *testing.T) {
 	makeSilence := func(comment string, createdBy string,
 		startsAt, endsAt strfmt.DateTime, matchers amv2.Matchers) amv2.Silence {
@@ -632,6 +736,20 @@ func createRequestCtxInOrg(org int64) *models.ReqContext {
 	}
 }
 
+func createRequestCtxWithQuery(t *testing.T, org int64, query string) *models.ReqContext {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, "/?"+query, nil)
+	require.NoError(t, err)
+	return &models.ReqContext{
+		Context: &web.Context{
+			Req: req,
+		},
+		SignedInUser: &user.SignedInUser{
+			OrgID: org,
+		},
+	}
+}
+
 // setRouteProvenance marks an org's routing tree as provisioned.
 func setRouteProvenance(t *testing.T, orgID int64, ps provisioning.ProvisioningStore) {
 	t.Helper()
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
+)
+
+// HistorySrv serves the alert state history recorded by the configured state historian.
+type HistorySrv struct {
+	logger log.Logger
+	hist   state.HistoryReader
+	store  RuleStore
+	ac     accesscontrol.AccessControl
+}
+
+func (srv *HistorySrv) RouteQueryStateHistory(c *models.ReqContext) response.Response {
+	if srv.hist == nil {
+		return ErrResp(http.StatusNotImplemented, errors.New("the configured state history backend does not support queries"), "")
+	}
+
+	ruleUID := c.Query("ruleUID")
+	if ruleUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("ruleUID is required"), "")
+	}
+	from, err := parseEpochMillis(c.Query("from"))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid from")
+	}
+	to, err := parseEpochMillis(c.Query("to"))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid to")
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return ErrResp(http.StatusBadRequest, errors.New("from cannot be greater than to"), "")
+	}
+	labels, err := parseLabelFilters(c.QueryStrings("labels"))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid labels")
+	}
+	limit := c.QueryInt("limit")
+	if limit < 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("limit must not be negative"), "")
+	}
+
+	ruleQuery := ngmodels.GetAlertRuleByUIDQuery{UID: ruleUID, OrgID: c.OrgID}
+	if err := srv.store.GetAlertRuleByUID(c.Req.Context(), &ruleQuery); err != nil && !errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rule")
+	}
+	rule := ruleQuery.Result
+	if rule == nil {
+		return ErrResp(http.StatusNotFound, errors.New("alert rule not found"), "")
+	}
+	if err := srv.authorizeAccessToRule(c, rule); err != nil {
+		return errorToResponse(err)
+	}
+
+	frame, err := srv.hist.QueryStates(c.Req.Context(), ngmodels.HistoryQuery{
+		RuleUID:      ruleUID,
+		OrgID:        c.OrgID,
+		Labels:       labels,
+		States:       c.QueryStrings("state"),
+		From:         from,
+		To:           to,
+		Limit:        limit,
+		SignedInUser: c.SignedInUser,
+	})
+	if err != nil {
+		if errors.Is(err, historian.ErrQueryNotSupported) {
+			return ErrResp(http.StatusNotImplemented, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to query state history")
+	}
+
+	body, err := data.FrameToJSON(frame, data.IncludeAll)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to convert frame to JSON")
+	}
+	return response.JSON(http.StatusOK, body)
+}
+
+// authorizeAccessToRule checks that the user can read the folder of the rule and query all of its data sources.
+func (srv *HistorySrv) authorizeAccessToRule(c *models.ReqContext, rule *ngmodels.AlertRule) error {
+	namespaces, err := srv.store.GetUserVisibleNamespaces(c.Req.Context(), c.OrgID, c.SignedInUser)
+	if err != nil {
+		return fmt.Errorf("failed to get namespaces visible to the user: %w", err)
+	}
+	if _, ok := namespaces[rule.NamespaceUID]; !ok {
+		return fmt.Errorf("%w to access the folder of the rule", ErrAuthorization)
+	}
+	if !authorizeDatasourceAccessForRule(rule, func(evaluator accesscontrol.Evaluator) bool {
+		return accesscontrol.HasAccess(srv.ac, c)(accesscontrol.ReqSignedIn, evaluator)
+	}) {
+		return fmt.Errorf("%w to query one or many data sources used by the rule", ErrAuthorization)
+	}
+	return nil
+}
+
+// parseEpochMillis parses a time in Unix epoch milliseconds. An empty string returns the zero time.
+func parseEpochMillis(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected Unix epoch milliseconds, got %q", s)
+	}
+	return time.UnixMilli(ms), nil
+}
+
+// parseLabelFilters parses label filters in the form name=value.
+func parseLabelFilters(filters []string) (map[string]string, error) {
+	labels := make(map[string]string, len(filters))
+	for _, f := range filters {
+		name, value, ok := strings.Cut(f, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("expected a label in the form name=value, got %q", f)
+		}
+		labels[name] = value
+	}
+	return labels, nil
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestRouteQueryStateHistory(t *testing.T) {
+	orgID := int64(1)
+	rule := ngmodels.AlertRuleGen(withOrgID(orgID))()
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.PutRule(context.Background(), rule)
+
+	var permissions []accesscontrol.Permission
+	for _, q := range rule.Data {
+		permissions = append(permissions, accesscontrol.Permission{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID(q.DatasourceUID)})
+	}
+
+	request := func(t *testing.T, url string) *models.ReqContext {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		return &models.ReqContext{Context: &web.Context{Req: req}, SignedInUser: &user.SignedInUser{OrgID: orgID}}
+	}
+
+	t.Run("should query the historian with the filters of the request", func(t *testing.T) {
+		hist := &fakeHistoryReader{frame: data.NewFrame("State history")}
+		srv := &HistorySrv{logger: log.NewNopLogger(), hist: hist, store: ruleStore, ac: acmock.New().WithPermissions(permissions)}
+
+		resp := srv.RouteQueryStateHistory(request(t, "/api/v1/rules/history?ruleUID="+rule.UID+"&from=1000&to=2000&labels=job=node&labels=instance=a&state=Alerting&limit=10"))
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.Len(t, hist.queries, 1)
+		require.Equal(t, ngmodels.HistoryQuery{
+			RuleUID:      rule.UID,
+			OrgID:        orgID,
+			Labels:       map[string]string{"job": "node", "instance": "a"},
+			States:       []string{"Alerting"},
+			From:         time.UnixMilli(1000),
+			To:           time.UnixMilli(2000),
+			Limit:        10,
+			SignedInUser: &user.SignedInUser{OrgID: orgID},
+		}, hist.queries[0])
+	})
+
+	t.Run("should return 400 for invalid requests", func(t *testing.T) {
+		srv := &HistorySrv{logger: log.NewNopLogger(), hist: &fakeHistoryReader{}, store: ruleStore, ac: acmock.New().WithPermissions(permissions)}
+
+		for _, url := range []string{
+			"/api/v1/rules/history",
+			"/api/v1/rules/history?ruleUID=" + rule.UID + "&from=now",
+			"/api/v1/rules/history?ruleUID=" + rule.UID + "&from=2000&to=1000",
+			"/api/v1/rules/history?ruleUID=" + rule.UID + "&labels=job",
+		} {
+			require.Equal(t, http.StatusBadRequest, srv.RouteQueryStateHistory(request(t, url)).Status(), url)
+		}
+	})
+
+	t.Run("should return 404 if the rule does not exist", func(t *testing.T) {
+		srv := &HistorySrv{logger: log.NewNopLogger(), hist: &fakeHistoryReader{}, store: ruleStore, ac: acmock.New().WithPermissions(permissions)}
+
+		resp := srv.RouteQueryStateHistory(request(t, "/api/v1/rules/history?ruleUID=unknown"))
+		require.Equal(t, http.StatusNotFound, resp.Status())
+	})
+
+	t.Run("should return 401 if user cannot query a data source of the rule", func(t *testing.T) {
+		hist := &fakeHistoryReader{}
+		srv := &HistorySrv{logger: log.NewNopLogger(), hist: hist, store: ruleStore, ac: acmock.New()}
+
+		resp := srv.RouteQueryStateHistory(request(t, "/api/v1/rules/history?ruleUID="+rule.UID))
+		require.Equal(t, http.StatusUnauthorized, resp.Status())
+		require.Empty(t, hist.queries)
+	})
+
+	t.Run("should return 501 if the historian cannot be queried", func(t *testing.T) {
+		srv := &HistorySrv{logger: log.NewNopLogger(), store: ruleStore, ac: acmock.New().WithPermissions(permissions)}
+
+		resp := srv.RouteQueryStateHistory(request(t, "/api/v1/rules/history?ruleUID="+rule.UID))
+		require.Equal(t, http.StatusNotImplemented, resp.Status())
+	})
+
+	t.Run("should return 501 if the backend does not support queries", func(t *testing.T) {
+		srv := &HistorySrv{logger: log.NewNopLogger(), hist: historian.NewRemoteLokiBackend(), store: ruleStore, ac: acmock.New().WithPermissions(permissions)}
+
+		resp := srv.RouteQueryStateHistory(request(t, "/api/v1/rules/history?ruleUID="+rule.UID))
+		require.Equal(t, http.StatusNotImplemented, resp.Status())
+	})
+
+	t.Run("should return 500 if the query fails", func(t *testing.T) {
+		hist := &fakeHistoryReader{err: errors.New("boom")}
+		srv := &HistorySrv{logger: log.NewNopLogger(), hist: hist, store: ruleStore, ac: acmock.New().WithPermissions(permissions)}
+
+		resp := srv.RouteQueryStateHistory(request(t, "/api/v1/rules/history?ruleUID="+rule.UID))
+		require.Equal(t, http.StatusInternalServerError, resp.Status())
+	})
+}
+
+type fakeHistoryReader struct {
+	frame   *data.Frame
+	err     error
+	queries []ngmodels.HistoryQuery
+}
+
+func (f *fakeHistoryReader) QueryStates(_ context.Context, query ngmodels.HistoryQuery) (*data.Frame, error) {
+	f.queries = append(f.queries, query)
+	return f.frame, f.err
+}
@@ -2,12 +2,19 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
@@ -24,6 +31,8 @@ type ProvisioningSrv struct {
 	templates           TemplateService
 	muteTimings         MuteTimingService
 	alertRules          AlertRuleService
+	namespaces          NamespaceStore
+	ac                  accesscontrol.AccessControl
 }
 
 type ContactPointService interface {
@@ -62,6 +71,11 @@ type AlertRuleService interface {
 	ReplaceRuleGroup(ctx context.Context, orgID int64, group alerting_models.AlertRuleGroup, userID int64, provenance alerting_models.Provenance) error
 }
 
+// NamespaceStore represents the ability to fetch the folders that contain alert rules.
+type NamespaceStore interface {
+	GetNamespacesByUID(ctx context.Context, orgID int64, uids ...string) (map[string]*folder.Folder, error)
+}
+
 func (srv *ProvisioningSrv) RouteGetPolicyTree(c *models.ReqContext) response.Response {
 	policies, err := srv.policies.GetPolicyTree(c.Req.Context(), c.OrgID)
 	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
@@ -360,3 +374,102 @@ func determineProvenance(ctx *models.ReqContext) alerting_models.Provenance {
 	}
 	return alerting_models.ProvenanceAPI
 }
+
+func (srv *ProvisioningSrv) RouteGetAlertingExport(c *models.ReqContext) response.Response {
+	format := strings.ToLower(c.Query("format"))
+	if format == "" {
+		format = "yaml"
+	}
+	if format != "yaml" && format != "json" {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("unsupported export format '%s', expected yaml or json", format), "")
+	}
+	decrypt := c.QueryBool("decrypt")
+	if decrypt && !accesscontrol.HasAccess(srv.ac, c)(accesscontrol.ReqOrgAdmin, accesscontrol.EvalPermission(accesscontrol.ActionAlertingProvisioningWrite)) {
+		return ErrResp(http.StatusForbidden, errors.New("exporting decrypted secure settings requires permission to write provisioned resources"), "")
+	}
+
+	export, err := srv.exportAlerting(c, decrypt)
+	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to export alerting resources")
+	}
+
+	var body []byte
+	contentType := "application/json"
+	if format == "yaml" {
+		body, err = yaml.Marshal(export)
+		contentType = "application/yaml"
+	} else {
+		body, err = json.MarshalIndent(export, "", "  ")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to encode export")
+	}
+	resp := response.Respond(http.StatusOK, body).SetHeader("Content-Type", contentType)
+	if c.QueryBool("download") {
+		resp.SetHeader("Content-Disposition", fmt.Sprintf("attachment;filename=alerting.%s", format))
+	}
+	return resp
+}
+
+func (srv *ProvisioningSrv) exportAlerting(c *models.ReqContext, decrypt bool) (definitions.AlertingFileExport, error) {
+	ctx := c.Req.Context()
+	export := definitions.AlertingFileExport{APIVersion: 1}
+
+	rules, err := srv.alertRules.GetAlertRules(ctx, c.OrgID)
+	if err != nil {
+		return export, err
+	}
+	if len(rules) > 0 {
+		// The export is org-scoped and requires the permission to read provisioning, so the folders of all rules
+		// are exported, not only those visible to the user.
+		uids := make([]string, 0, len(rules))
+		seen := make(map[string]struct{}, len(rules))
+		for _, rule := range rules {
+			if _, ok := seen[rule.NamespaceUID]; !ok {
+				seen[rule.NamespaceUID] = struct{}{}
+				uids = append(uids, rule.NamespaceUID)
+			}
+		}
+		namespaces, err := srv.namespaces.GetNamespacesByUID(ctx, c.OrgID, uids...)
+		if err != nil {
+			return export, err
+		}
+		if export.Groups, err = exportAlertRuleGroups(c.OrgID, rules, namespaces); err != nil {
+			return export, err
+		}
+	}
+
+	cps, err := srv.contactPointService.GetContactPoints(ctx, provisioning.ContactPointQuery{OrgID: c.OrgID, Decrypt: decrypt})
+	if err != nil {
+		return export, err
+	}
+	if export.ContactPoints, err = exportContactPoints(c.OrgID, cps); err != nil {
+		return export, err
+	}
+
+	tree, err := srv.policies.GetPolicyTree(ctx, c.OrgID)
+	if err != nil {
+		return export, err
+	}
+	policy, err := exportNotificationPolicy(c.OrgID, tree)
+	if err != nil {
+		return export, err
+	}
+	export.Policies = []definitions.NotificationPolicyExport{policy}
+
+	timings, err := srv.muteTimings.GetMuteTimings(ctx, c.OrgID)
+	if err != nil {
+		return export, err
+	}
+	export.MuteTimings = exportMuteTimings(c.OrgID, timings)
+
+	templates, err := srv.templates.GetTemplates(ctx, c.OrgID)
+	if err != nil {
+		return export, err
+	}
+	export.Templates = exportTemplates(c.OrgID, templates)
+	return export, nil
+}
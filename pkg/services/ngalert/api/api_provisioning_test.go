@@ -5,22 +5,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
 	prometheus "github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/timeinterval"
 	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 
+	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	gfcore "github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	secrets_fakes "github.com/grafana/grafana/pkg/services/secrets/fakes"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -370,6 +377,103 @@ func TestProvisioningApi(t *testing.T) {
 			})
 		})
 	})
+
+	t.Run("export", func(t *testing.T) {
+		t.Run("GET returns 200 with all resources in YAML", func(t *testing.T) {
+			sut := createExportSut(t)
+			rc := createTestRequestCtx()
+
+			resp := sut.RouteGetAlertingExport(&rc)
+
+			require.Equal(t, 200, resp.Status())
+			require.Equal(t, "application/yaml", resp.(*response.NormalResponse).Header().Get("Content-Type"))
+			var export definitions.AlertingFileExport
+			require.NoError(t, yaml.Unmarshal(resp.Body(), &export))
+			require.Equal(t, int64(1), export.APIVersion)
+			require.Len(t, export.Groups, 1)
+			require.Equal(t, "Exported folder", export.Groups[0].Folder)
+			require.Equal(t, "my-cool-group", export.Groups[0].Name)
+			require.Len(t, export.Groups[0].Rules, 1)
+			require.Equal(t, "exported-rule", export.Groups[0].Rules[0].UID)
+			require.Len(t, export.ContactPoints, 1)
+			require.Equal(t, "email receiver", export.ContactPoints[0].Name)
+			require.Equal(t, "email-uid", export.ContactPoints[0].Receivers[0].UID)
+			require.Len(t, export.Policies, 1)
+			require.Equal(t, "some-receiver", export.Policies[0].Policy["receiver"])
+			require.Len(t, export.MuteTimings, 1)
+			require.Equal(t, "interval", export.MuteTimings[0].Name)
+			require.Len(t, export.Templates, 1)
+			require.Equal(t, "a", export.Templates[0].Name)
+		})
+
+		t.Run("GET returns 200 with JSON and sets the file name when downloading", func(t *testing.T) {
+			sut := createExportSut(t)
+			rc := createTestRequestCtx()
+			rc.Req.URL = &url.URL{RawQuery: "format=json&download=true"}
+
+			resp := sut.RouteGetAlertingExport(&rc)
+
+			require.Equal(t, 200, resp.Status())
+			header := resp.(*response.NormalResponse).Header()
+			require.Equal(t, "application/json", header.Get("Content-Type"))
+			require.Equal(t, "attachment;filename=alerting.json", header.Get("Content-Disposition"))
+			var export definitions.AlertingFileExport
+			require.NoError(t, json.Unmarshal(resp.Body(), &export))
+			require.Len(t, export.Groups, 1)
+		})
+
+		t.Run("GET returns 400 on unknown format", func(t *testing.T) {
+			sut := createExportSut(t)
+			rc := createTestRequestCtx()
+			rc.Req.URL = &url.URL{RawQuery: "format=xml"}
+
+			resp := sut.RouteGetAlertingExport(&rc)
+
+			require.Equal(t, 400, resp.Status())
+		})
+
+		t.Run("GET returns 404 if the org has no Alertmanager configuration", func(t *testing.T) {
+			sut := createExportSut(t)
+			rc := createTestRequestCtx()
+			rc.OrgID = 2
+
+			resp := sut.RouteGetAlertingExport(&rc)
+
+			require.Equal(t, 404, resp.Status())
+		})
+
+		t.Run("GET with decrypt requires permission to write", func(t *testing.T) {
+			sut := createExportSut(t)
+			rc := createTestRequestCtx()
+			rc.Req.URL = &url.URL{RawQuery: "decrypt=true"}
+
+			resp := sut.RouteGetAlertingExport(&rc)
+			require.Equal(t, 403, resp.Status())
+
+			sut.ac = acmock.New().WithPermissions([]accesscontrol.Permission{{Action: accesscontrol.ActionAlertingProvisioningWrite}})
+			resp = sut.RouteGetAlertingExport(&rc)
+			require.Equal(t, 200, resp.Status())
+		})
+	})
+}
+
+func createExportSut(t *testing.T) ProvisioningSrv {
+	t.Helper()
+
+	env := createTestEnv(t)
+	prov := &provisioning.MockProvisioningStore{}
+	prov.EXPECT().GetReturns(models.ProvenanceNone)
+	prov.EXPECT().GetProvenances(mock.Anything, mock.Anything, mock.Anything).Return(map[string]models.Provenance{}, nil)
+	env.prov = prov
+	sut := createProvisioningSrvSutFromEnv(t, &env)
+	ruleStore := fakes.NewRuleStore(t)
+	rule := exportTestRule("exported-rule", "folder-uid", "my-cool-group", 0)
+	ruleStore.PutRule(context.Background(), rule)
+	ruleStore.Folders[1][0].Title = "Exported folder"
+	sut.alertRules = provisioning.NewAlertRuleService(ruleStore, env.prov, env.quotas, env.xact, 60, 10, env.log)
+	sut.namespaces = ruleStore
+	sut.ac = acmock.New()
+	return sut
 }
 
 // testEnvironment binds together common dependencies for testing alerting APIs.
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// RouteImportPrometheusRules converts Prometheus rule groups to Grafana-managed rule groups that query the data source
+// and creates or updates them in the folder. The converted rules are matched to the rules of the folder by title, so
+// importing the same rule groups again updates the rules that were imported before.
+// All rule groups are validated before any of them is saved, and all of them are saved in one transaction.
+// If dryRun is true, the converted rule groups are validated and returned but not saved.
+func (srv RulerSrv) RouteImportPrometheusRules(c *models.ReqContext, file apimodels.PrometheusRuleFile, namespaceTitle string, ds *datasources.DataSource, dryRun bool) response.Response {
+	namespace, err := srv.store.GetNamespaceByTitle(c.Req.Context(), namespaceTitle, c.SignedInUser.OrgID, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	groups, err := prom.ConvertRuleGroups(prom.Config{
+		DatasourceUID:  ds.Uid,
+		DatasourceType: ds.Type,
+		BaseInterval:   srv.cfg.BaseInterval,
+	}, file.Groups)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to convert Prometheus rule groups")
+	}
+
+	q := ngmodels.ListAlertRulesQuery{
+		OrgID:         c.SignedInUser.OrgID,
+		NamespaceUIDs: []string{namespace.UID},
+	}
+	if err := srv.store.ListAlertRules(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the alert rules of the folder")
+	}
+	uids := make(map[string]string, len(q.Result))
+	for _, rule := range q.Result {
+		uids[rule.Title] = rule.UID
+	}
+
+	rules := make([][]*ngmodels.AlertRule, len(groups))
+	for i := range groups {
+		for _, node := range groups[i].Rules {
+			node.GrafanaManagedAlert.UID = uids[node.GrafanaManagedAlert.Title]
+		}
+		rules[i], err = validateRuleGroup(&groups[i], c.SignedInUser.OrgID, namespace, func(condition ngmodels.Condition) error {
+			return srv.conditionValidator.Validate(eval.Context(c.Req.Context(), c.SignedInUser), condition)
+		}, srv.cfg)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid rule group '%s': %w", groups[i].Name, err), "")
+		}
+	}
+
+	if dryRun {
+		return response.JSON(http.StatusOK, apimodels.PrometheusImportResponse{Groups: groups, DryRun: true})
+	}
+
+	changes := make([]*store.GroupDelta, 0, len(groups))
+	err = srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
+		for i, group := range groups {
+			groupKey := ngmodels.AlertRuleGroupKey{
+				OrgID:        c.SignedInUser.OrgID,
+				NamespaceUID: namespace.UID,
+				RuleGroup:    group.Name,
+			}
+			groupChanges, err := srv.updateRuleGroupInTransaction(tranCtx, c, groupKey, rules[i])
+			if err != nil {
+				return fmt.Errorf("failed to import rule group '%s': %w", group.Name, err)
+			}
+			changes = append(changes, groupChanges)
+		}
+		return nil
+	})
+	if err != nil {
+		return toUpdateRuleGroupErrorResponse(err)
+	}
+
+	for _, groupChanges := range changes {
+		srv.updateScheduledRules(c, groupChanges)
+	}
+	return response.JSON(http.StatusAccepted, apimodels.PrometheusImportResponse{Groups: groups})
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	acMock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRouteImportPrometheusRules(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	ds := &datasources.DataSource{Uid: "prometheus-uid", Type: PrometheusDatasourceType}
+	forDuration := prommodel.Duration(5 * time.Minute)
+	file := apimodels.PrometheusRuleFile{
+		Groups: []apimodels.PrometheusRuleGroup{{
+			Name: "node",
+			Rules: []apimodels.ApiRuleNode{{
+				Alert:       "InstanceDown",
+				Expr:        "up == 0",
+				For:         &forDuration,
+				Annotations: map[string]string{"summary": "{{ $labels.instance }} is down"},
+			}, {
+				Alert: "HighDiskUsage",
+				Expr:  "node_filesystem_usage > 90",
+			}},
+		}},
+	}
+
+	// InstanceDown was imported before.
+	existing := models.AlertRuleGen(withOrgID(orgID), withNamespace(folder), withGroup("node"), func(rule *models.AlertRule) {
+		rule.Title = "InstanceDown"
+	})()
+
+	createImportService := func(t *testing.T, validator ConditionValidator) (*RulerSrv, *fakes.RuleStore) {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		ruleStore.PutRule(context.Background(), existing)
+
+		scheduler := &schedule.FakeScheduleService{}
+		scheduler.On("UpdateAlertRule", mock.Anything, mock.Anything).Return()
+		scheduler.On("DeleteAlertRule", mock.Anything).Return()
+
+		srv := createService(acMock.New().WithDisabled(), ruleStore, scheduler)
+		srv.cfg = &setting.UnifiedAlertingSettings{
+			BaseInterval:                  10 * time.Second,
+			DefaultRuleEvaluationInterval: time.Minute,
+		}
+		srv.conditionValidator = validator
+		srv.QuotaService = quotatest.New(false, nil)
+		return srv, ruleStore
+	}
+
+	getInserted := func(ruleStore *fakes.RuleStore) []models.AlertRule {
+		var inserted []models.AlertRule
+		for _, cmd := range ruleStore.GetRecordedCommands(func(cmd interface{}) (interface{}, bool) {
+			rules, ok := cmd.([]models.AlertRule)
+			return rules, ok
+		}) {
+			inserted = append(inserted, cmd.([]models.AlertRule)...)
+		}
+		return inserted
+	}
+
+	getUpdated := func(ruleStore *fakes.RuleStore) []models.UpdateRule {
+		var updated []models.UpdateRule
+		for _, cmd := range ruleStore.GetRecordedCommands(func(cmd interface{}) (interface{}, bool) {
+			rules, ok := cmd.([]models.UpdateRule)
+			return rules, ok
+		}) {
+			updated = append(updated, cmd.([]models.UpdateRule)...)
+		}
+		return updated
+	}
+
+	t.Run("should create new rules and update the rules with the same title", func(t *testing.T) {
+		srv, ruleStore := createImportService(t, fakeConditionValidator{})
+
+		response := srv.RouteImportPrometheusRules(createRequestContext(orgID, org.RoleEditor, nil), file, folder.Title, ds, false)
+		require.Equalf(t, 202, response.Status(), "Expected 202 but got %d: %v", response.Status(), string(response.Body()))
+
+		inserted := getInserted(ruleStore)
+		require.Len(t, inserted, 1)
+		require.Equal(t, "HighDiskUsage", inserted[0].Title)
+		require.Equal(t, folder.UID, inserted[0].NamespaceUID)
+		require.Equal(t, "node", inserted[0].RuleGroup)
+		require.Equal(t, "prometheus-uid", inserted[0].Data[0].DatasourceUID)
+
+		updated := getUpdated(ruleStore)
+		require.Len(t, updated, 1)
+		require.Equal(t, existing.UID, updated[0].New.UID)
+		require.Equal(t, "InstanceDown", updated[0].New.Title)
+		require.Equal(t, 5*time.Minute, updated[0].New.For)
+		require.Equal(t, "{{$labels.instance}} is down", updated[0].New.Annotations["summary"])
+
+		var result apimodels.PrometheusImportResponse
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.False(t, result.DryRun)
+		require.Len(t, result.Groups, 1)
+		require.Len(t, result.Groups[0].Rules, 2)
+	})
+
+	t.Run("should not save the rules on a dry run", func(t *testing.T) {
+		srv, ruleStore := createImportService(t, fakeConditionValidator{})
+
+		response := srv.RouteImportPrometheusRules(createRequestContext(orgID, org.RoleEditor, nil), file, folder.Title, ds, true)
+		require.Equalf(t, 200, response.Status(), "Expected 200 but got %d: %v", response.Status(), string(response.Body()))
+
+		require.Empty(t, getInserted(ruleStore))
+		require.Empty(t, getUpdated(ruleStore))
+
+		var result apimodels.PrometheusImportResponse
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.True(t, result.DryRun)
+		require.Len(t, result.Groups, 1)
+		require.Equal(t, existing.UID, result.Groups[0].Rules[0].GrafanaManagedAlert.UID)
+		require.Empty(t, result.Groups[0].Rules[1].GrafanaManagedAlert.UID)
+	})
+
+	t.Run("should return 400 if the rules cannot be converted", func(t *testing.T) {
+		srv, ruleStore := createImportService(t, fakeConditionValidator{})
+		invalid := apimodels.PrometheusRuleFile{
+			Groups: []apimodels.PrometheusRuleGroup{{
+				Name:  "node",
+				Rules: []apimodels.ApiRuleNode{{Alert: "InstanceDown"}},
+			}},
+		}
+
+		response := srv.RouteImportPrometheusRules(createRequestContext(orgID, org.RoleEditor, nil), invalid, folder.Title, ds, false)
+		require.Equalf(t, 400, response.Status(), "Expected 400 but got %d: %v", response.Status(), string(response.Body()))
+		require.Empty(t, getInserted(ruleStore))
+	})
+
+	t.Run("should return 400 and save nothing if a rule group is invalid", func(t *testing.T) {
+		srv, ruleStore := createImportService(t, fakeConditionValidator{err: errors.New("invalid query")})
+
+		response := srv.RouteImportPrometheusRules(createRequestContext(orgID, org.RoleEditor, nil), file, folder.Title, ds, false)
+		require.Equalf(t, 400, response.Status(), "Expected 400 but got %d: %v", response.Status(), string(response.Body()))
+		require.Contains(t, string(response.Body()), "invalid rule group 'node'")
+		require.Empty(t, getInserted(ruleStore))
+		require.Empty(t, getUpdated(ruleStore))
+	})
+
+	t.Run("should save all rule groups in one transaction and notify the scheduler after it", func(t *testing.T) {
+		srv, ruleStore := createImportService(t, fakeConditionValidator{})
+		xact := &fakeTransactionManager{}
+		srv.xactManager = xact
+		scheduler := &schedule.FakeScheduleService{}
+		srv.scheduleService = scheduler
+		// The second rule group cannot be saved.
+		ruleStore.Hook = func(cmd interface{}) error {
+			if rules, ok := cmd.([]models.AlertRule); ok && len(rules) > 0 && rules[0].RuleGroup == "disk" {
+				return errors.New("failed to save")
+			}
+			return nil
+		}
+		groups := apimodels.PrometheusRuleFile{
+			Groups: append(file.Groups, apimodels.PrometheusRuleGroup{
+				Name:  "disk",
+				Rules: []apimodels.ApiRuleNode{{Alert: "DiskFull", Expr: "node_filesystem_avail == 0"}},
+			}),
+		}
+
+		response := srv.RouteImportPrometheusRules(createRequestContext(orgID, org.RoleEditor, nil), groups, folder.Title, ds, false)
+		require.Equalf(t, 500, response.Status(), "Expected 500 but got %d: %v", response.Status(), string(response.Body()))
+		require.Contains(t, string(response.Body()), "failed to import rule group 'disk'")
+		require.Equal(t, 1, xact.calls)
+		// The update of the first rule group is rolled back, so the scheduler is not notified about it.
+		scheduler.AssertNotCalled(t, "UpdateAlertRule", mock.Anything, mock.Anything)
+	})
+}
+
+// fakeTransactionManager counts the transactions.
+type fakeTransactionManager struct {
+	calls int
+}
+
+func (f *fakeTransactionManager) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.calls++
+	return fn(ctx)
+}
+
+type fakeConditionValidator struct {
+	err error
+}
+
+func (v fakeConditionValidator) Validate(eval.EvaluationContext, models.Condition) error {
+	return v.err
+}
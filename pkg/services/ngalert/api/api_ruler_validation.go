@@ -66,6 +66,33 @@ func validateRuleNode(
 		}
 	}
 
+	var record ngmodels.Record
+	if ruleNode.GrafanaManagedAlert.Record != nil {
+		record = *ruleNode.GrafanaManagedAlert.Record
+		if ruleNode.GrafanaManagedAlert.Condition != "" {
+			return nil, fmt.Errorf("%w: recording rule cannot have a condition", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if err := record.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, d := range ruleNode.GrafanaManagedAlert.Dependencies {
+		if err := d.Validate(); err != nil {
+			return nil, err
+		}
+		if ruleNode.GrafanaManagedAlert.UID != "" && d.RuleUID == ruleNode.GrafanaManagedAlert.UID {
+			return nil, fmt.Errorf("%w: rule cannot depend on itself", ngmodels.ErrAlertRuleFailedValidation)
+		}
+	}
+	if !record.IsZero() && len(ruleNode.GrafanaManagedAlert.Dependencies) > 0 {
+		return nil, fmt.Errorf("%w: recording rule cannot have dependencies", ngmodels.ErrAlertRuleFailedValidation)
+	}
+	// The state of a rule that is depended on is only known to the instance that evaluates it
+	if cfg.HAEvaluationSharding && len(ruleNode.GrafanaManagedAlert.Dependencies) > 0 {
+		return nil, fmt.Errorf("%w: rule dependencies are not supported when the evaluation of alert rules is sharded between high availability instances", ngmodels.ErrAlertRuleFailedValidation)
+	}
+
 	if len(ruleNode.GrafanaManagedAlert.Data) == 0 {
 		if canPatch {
 			if ruleNode.GrafanaManagedAlert.Condition != "" {
@@ -81,6 +108,9 @@ func validateRuleNode(
 			Condition: ruleNode.GrafanaManagedAlert.Condition,
 			Data:      ruleNode.GrafanaManagedAlert.Data,
 		}
+		if !record.IsZero() {
+			cond.Condition = record.From
+		}
 		if err = conditionValidator(cond); err != nil {
 			return nil, fmt.Errorf("failed to validate condition of alert rule %s: %w", ruleNode.GrafanaManagedAlert.Title, err)
 		}
@@ -97,6 +127,8 @@ func validateRuleNode(
 		RuleGroup:       groupName,
 		NoDataState:     noDataState,
 		ExecErrState:    errorState,
+		Record:          record,
+		Dependencies:    ruleNode.GrafanaManagedAlert.Dependencies,
 	}
 
 	newAlertRule.For, err = validateForInterval(ruleNode)
@@ -75,6 +75,13 @@ func validRule() apimodels.PostableExtendedRuleNode {
 	}
 }
 
+func validRecordingRule() apimodels.PostableExtendedRuleNode {
+	r := validRule()
+	r.GrafanaManagedAlert.Record = &models.Record{Metric: "test:recorded", From: r.GrafanaManagedAlert.Condition}
+	r.GrafanaManagedAlert.Condition = ""
+	return r
+}
+
 func validGroup(cfg *setting.UnifiedAlertingSettings, rules ...apimodels.PostableExtendedRuleNode) apimodels.PostableRuleGroupConfig {
 	return apimodels.PostableRuleGroupConfig{
 		Name:     "TEST-ALERTS-" + util.GenerateShortUID(),
@@ -298,6 +305,30 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 				require.Equal(t, int64(panelId), *alert.PanelID)
 			},
 		},
+		{
+			name: "converts recording rule",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRecordingRule()
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.True(t, alert.IsRecordingRule())
+				require.Equal(t, *api.GrafanaManagedAlert.Record, alert.Record)
+				require.Empty(t, alert.Condition)
+				require.Equal(t, "A", alert.GetEvalCondition().Condition)
+			},
+		},
+		{
+			name: "converts dependencies",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Dependencies = []models.RuleDependency{{RuleUID: "dc-down", Equal: []string{"datacenter"}}}
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, api.GrafanaManagedAlert.Dependencies, alert.Dependencies)
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -438,6 +469,54 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 				return &r
 			},
 		},
+		{
+			name: "fail if recording rule has a condition",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRecordingRule()
+				r.GrafanaManagedAlert.Condition = "A"
+				return &r
+			},
+		},
+		{
+			name: "fail if metric of recording rule is not valid",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRecordingRule()
+				r.GrafanaManagedAlert.Record.Metric = "invalid metric"
+				return &r
+			},
+		},
+		{
+			name: "fail if dependency does not specify a rule",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Dependencies = []models.RuleDependency{{Equal: []string{"datacenter"}}}
+				return &r
+			},
+		},
+		{
+			name: "fail if dependency has an invalid label name",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Dependencies = []models.RuleDependency{{RuleUID: "dc-down", Equal: []string{"data-center"}}}
+				return &r
+			},
+		},
+		{
+			name: "fail if recording rule has dependencies",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRecordingRule()
+				r.GrafanaManagedAlert.Dependencies = []models.RuleDependency{{RuleUID: "dc-down"}}
+				return &r
+			},
+		},
+		{
+			name: "fail if recording rule does not specify what to record",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRecordingRule()
+				r.GrafanaManagedAlert.Record.From = ""
+				return &r
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -465,6 +544,24 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 	}
 }
 
+func TestValidateRuleNode_DependenciesWithHAEvaluationSharding(t *testing.T) {
+	cfg := config(t)
+	cfg.HAEvaluationSharding = true
+	successValidation := func(condition models.Condition) error {
+		return nil
+	}
+
+	r := validRule()
+	r.GrafanaManagedAlert.UID = ""
+	r.GrafanaManagedAlert.Dependencies = []models.RuleDependency{{RuleUID: "dc-down"}}
+	_, err := validateRuleNode(&r, "", cfg.BaseInterval, rand.Int63(), randFolder(), successValidation, cfg)
+	require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+	r.GrafanaManagedAlert.Dependencies = nil
+	_, err = validateRuleNode(&r, "", cfg.BaseInterval, rand.Int63(), randFolder(), successValidation, cfg)
+	require.NoError(t, err)
+}
+
 func TestValidateRuleNode_UID(t *testing.T) {
 	orgId := rand.Int63()
 	folder := randFolder()
@@ -589,6 +686,15 @@ func TestValidateRuleNodeFailures_UID(t *testing.T) {
 				return &r
 			},
 		},
+		{
+			name: "fail if rule depends on itself",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.UID = util.GenerateShortUID()
+				r.GrafanaManagedAlert.Dependencies = []models.RuleDependency{{RuleUID: r.GrafanaManagedAlert.UID}}
+				return &r
+			},
+		},
 		{
 			name: "fail if title is too long",
 			rule: func() *apimodels.PostableExtendedRuleNode {
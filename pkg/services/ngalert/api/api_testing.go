@@ -16,10 +16,12 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -33,6 +35,9 @@ type TestingApiSrv struct {
 	cfg             *setting.UnifiedAlertingSettings
 	backtesting     *backtesting.Engine
 	featureManager  featuremgmt.FeatureToggles
+	store           RuleStore
+	// simulatorFor returns the notification simulator of the Alertmanager of the organization.
+	simulatorFor func(orgID int64) (backtesting.NotificationSimulator, error)
 }
 
 func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *models.ReqContext, body apimodels.TestRulePayload) response.Response {
@@ -146,40 +151,107 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 }
 
 func (srv TestingApiSrv) BacktestAlertRule(c *models.ReqContext, cmd apimodels.BacktestConfig) response.Response {
+	rule, folderTitle, errResp := srv.backtestingRule(c, cmd)
+	if errResp != nil {
+		return errResp
+	}
+
+	result, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, folderTitle, cmd.From, cmd.To)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(400, err, "Failed to evaluate")
+		}
+		return ErrResp(500, err, "Failed to evaluate")
+	}
+
+	body, err := data.FrameToJSON(result, data.IncludeAll)
+	if err != nil {
+		return ErrResp(500, err, "Failed to convert frame to JSON")
+	}
+	return response.JSON(http.StatusOK, body)
+}
+
+// BacktestNotifications tests the rule like BacktestAlertRule, and simulates the notifications that the notification
+// policies and mute timings of the organization would have sent for the resulting alerts.
+func (srv TestingApiSrv) BacktestNotifications(c *models.ReqContext, cmd apimodels.BacktestConfig) response.Response {
+	rule, folderTitle, errResp := srv.backtestingRule(c, cmd)
+	if errResp != nil {
+		return errResp
+	}
+
+	simulator, err := srv.simulatorFor(c.OrgID)
+	if err != nil {
+		if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		if errors.Is(err, notifier.ErrAlertmanagerNotReady) {
+			return ErrResp(http.StatusConflict, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "unable to obtain org's Alertmanager")
+	}
+
+	result, notifications, err := srv.backtesting.TestNotifications(c.Req.Context(), c.SignedInUser, rule, folderTitle, cmd.From, cmd.To, simulator)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(400, err, "Failed to evaluate")
+		}
+		return ErrResp(500, err, "Failed to evaluate")
+	}
+
+	states, err := data.FrameToJSON(result, data.IncludeAll)
+	if err != nil {
+		return ErrResp(500, err, "Failed to convert frame to JSON")
+	}
+	return response.JSON(http.StatusOK, apimodels.BacktestNotificationsResult{
+		States:        states,
+		Notifications: notifications,
+	})
+}
+
+// backtestingRule validates the configuration of the backtesting and returns the alert rule to test and the title of
+// its folder.
+func (srv TestingApiSrv) backtestingRule(c *models.ReqContext, cmd apimodels.BacktestConfig) (*ngmodels.AlertRule, string, response.Response) {
 	if !srv.featureManager.IsEnabled(featuremgmt.FlagAlertingBacktesting) {
-		return ErrResp(http.StatusNotFound, nil, "Backgtesting API is not enabled")
+		return nil, "", response.Error(http.StatusNotFound, "Backtesting API is not enabled", nil)
 	}
 
 	if cmd.From.After(cmd.To) {
-		return ErrResp(400, nil, "From cannot be greater than To")
+		return nil, "", response.Error(http.StatusBadRequest, "From cannot be greater than To", nil)
 	}
 
 	noDataState, err := ngmodels.NoDataStateFromString(string(cmd.NoDataState))
 
 	if err != nil {
-		return ErrResp(400, err, "")
+		return nil, "", ErrResp(400, err, "")
 	}
 	forInterval := time.Duration(cmd.For)
 	if forInterval < 0 {
-		return ErrResp(400, nil, "Bad For interval")
+		return nil, "", response.Error(http.StatusBadRequest, "Bad For interval", nil)
 	}
 
 	intervalSeconds, err := validateInterval(srv.cfg, time.Duration(cmd.Interval))
 	if err != nil {
-		return ErrResp(400, err, "")
+		return nil, "", ErrResp(400, err, "")
 	}
 
 	if !authorizeDatasourceAccessForRule(&ngmodels.AlertRule{Data: cmd.Data}, func(evaluator accesscontrol.Evaluator) bool {
 		return accesscontrol.HasAccess(srv.accessControl, c)(accesscontrol.ReqSignedIn, evaluator)
 	}) {
-		return errorToResponse(fmt.Errorf("%w to query one or many data sources used by the rule", ErrAuthorization))
+		return nil, "", errorToResponse(fmt.Errorf("%w to query one or many data sources used by the rule", ErrAuthorization))
 	}
 
-	rule := &ngmodels.AlertRule{
+	namespace := &folder.Folder{}
+	if cmd.Namespace != "" {
+		namespace, err = srv.store.GetNamespaceByTitle(c.Req.Context(), cmd.Namespace, c.SignedInUser.OrgID, c.SignedInUser, false)
+		if err != nil {
+			return nil, "", toNamespaceErrorResponse(err)
+		}
+	}
+
+	return &ngmodels.AlertRule{
 		// ID:             0,
 		// Updated:        time.Time{},
 		// Version:        0,
-		// NamespaceUID:   "",
 		// DashboardUID:   nil,
 		// PanelID:        nil,
 		// RuleGroup:      "",
@@ -189,6 +261,7 @@ func (srv TestingApiSrv) BacktestAlertRule(c *models.ReqContext, cmd apimodels.B
 		// prefix backtesting- is to distinguish between executions of regular rule and backtesting in logs (like expression engine, evaluator, state manager etc)
 		UID:             "backtesting-" + util.GenerateShortUID(),
 		OrgID:           c.OrgID,
+		NamespaceUID:    namespace.UID,
 		Condition:       cmd.Condition,
 		Data:            cmd.Data,
 		IntervalSeconds: intervalSeconds,
@@ -196,19 +269,5 @@ func (srv TestingApiSrv) BacktestAlertRule(c *models.ReqContext, cmd apimodels.B
 		For:             forInterval,
 		Annotations:     cmd.Annotations,
 		Labels:          cmd.Labels,
-	}
-
-	result, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To)
-	if err != nil {
-		if errors.Is(err, backtesting.ErrInvalidInputData) {
-			return ErrResp(400, err, "Failed to evaluate")
-		}
-		return ErrResp(500, err, "Failed to evaluate")
-	}
-
-	body, err := data.FrameToJSON(result, data.IncludeAll)
-	if err != nil {
-		return ErrResp(500, err, "Failed to convert frame to JSON")
-	}
-	return response.JSON(http.StatusOK, body)
+	}, namespace.Title, nil
 }
@@ -1,11 +1,15 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/types"
+	prommodel "github.com/prometheus/common/model"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
@@ -14,11 +18,17 @@ import (
 	acMock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	fakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	ngfakes "github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -288,3 +298,119 @@ func createTestingApiSrv(ds *fakes.FakeCacheService, ac *acMock.Mock, evaluator
 		evaluator:       evaluator,
 	}
 }
+
+func TestBacktestNotifications(t *testing.T) {
+	from := time.Unix(0, 0).UTC()
+	frame := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{from, from.Add(time.Minute), from.Add(2 * time.Minute)}),
+		data.NewField("value", data.Labels{"instance": "1"}, []float64{1, 1, 0}),
+	)
+	model, err := json.Marshal(map[string]interface{}{"data": frame})
+	require.NoError(t, err)
+	cmd := definitions.BacktestConfig{
+		From:      from,
+		To:        from.Add(3 * time.Minute),
+		Interval:  prommodel.Duration(time.Minute),
+		Condition: "A",
+		Data: []models.AlertQuery{{
+			RefID:         "A",
+			DatasourceUID: "__data__",
+			Model:         model,
+		}},
+		Title:       "HighCPU",
+		NoDataState: definitions.NoData,
+	}
+
+	rc := &models2.ReqContext{
+		Context: &web.Context{
+			Req: &http.Request{},
+		},
+		IsSignedIn: true,
+		SignedInUser: &user.SignedInUser{
+			OrgID: 1,
+		},
+	}
+
+	ruleStore := ngfakes.NewRuleStore(t)
+	ruleStore.Folders[1] = []*folder.Folder{{UID: "folder-uid", Title: "Folder"}}
+
+	createBacktestingSrv := func(features featuremgmt.FeatureToggles, simulator backtesting.NotificationSimulator, simulatorErr error) *TestingApiSrv {
+		srv := createTestingApiSrv(nil, nil, nil)
+		srv.cfg = &setting.UnifiedAlertingSettings{BaseInterval: 10 * time.Second}
+		srv.featureManager = features
+		srv.backtesting = backtesting.NewEngine(nil, nil, false)
+		srv.store = ruleStore
+		srv.simulatorFor = func(orgID int64) (backtesting.NotificationSimulator, error) {
+			return simulator, simulatorErr
+		}
+		return srv
+	}
+
+	t.Run("should return the states and the simulated notifications", func(t *testing.T) {
+		simulator := &fakeNotificationSimulator{
+			notifications: []definitions.SimulatedNotification{{Time: from.Add(time.Minute), Receiver: "default"}},
+		}
+		srv := createBacktestingSrv(featuremgmt.WithFeatures(featuremgmt.FlagAlertingBacktesting), simulator, nil)
+
+		response := srv.BacktestNotifications(rc, cmd)
+		require.Equalf(t, http.StatusOK, response.Status(), "Expected 200 but got %d: %v", response.Status(), string(response.Body()))
+
+		var result definitions.BacktestNotificationsResult
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Equal(t, simulator.notifications[0].Receiver, result.Notifications[0].Receiver)
+		require.NotEmpty(t, result.States)
+
+		require.Len(t, simulator.alerts, 1)
+		require.Equal(t, prommodel.LabelValue("1"), simulator.alerts[0].Labels["instance"])
+		require.Equal(t, from, simulator.alerts[0].StartsAt)
+		require.Equal(t, from.Add(2*time.Minute), simulator.alerts[0].EndsAt)
+	})
+
+	t.Run("should add the labels of the rule and its folder to the alerts", func(t *testing.T) {
+		simulator := &fakeNotificationSimulator{}
+		srv := createBacktestingSrv(featuremgmt.WithFeatures(featuremgmt.FlagAlertingBacktesting), simulator, nil)
+
+		cmd := cmd
+		cmd.Namespace = "Folder"
+		response := srv.BacktestNotifications(rc, cmd)
+		require.Equalf(t, http.StatusOK, response.Status(), "Expected 200 but got %d: %v", response.Status(), string(response.Body()))
+
+		require.Len(t, simulator.alerts, 1)
+		labels := simulator.alerts[0].Labels
+		require.Equal(t, prommodel.LabelValue("HighCPU"), labels[prommodel.AlertNameLabel])
+		require.Equal(t, prommodel.LabelValue("Folder"), labels[models.FolderTitleLabel])
+		require.Equal(t, prommodel.LabelValue("folder-uid"), labels["__alert_rule_namespace_uid__"])
+		require.NotEmpty(t, labels["__alert_rule_uid__"])
+	})
+
+	t.Run("should return 404 if backtesting is not enabled", func(t *testing.T) {
+		srv := createBacktestingSrv(featuremgmt.WithFeatures(), &fakeNotificationSimulator{}, nil)
+
+		response := srv.BacktestNotifications(rc, cmd)
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should return 404 if the organization has no Alertmanager", func(t *testing.T) {
+		srv := createBacktestingSrv(featuremgmt.WithFeatures(featuremgmt.FlagAlertingBacktesting), nil, notifier.ErrNoAlertmanagerForOrg)
+
+		response := srv.BacktestNotifications(rc, cmd)
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should return 409 if the Alertmanager is not ready", func(t *testing.T) {
+		srv := createBacktestingSrv(featuremgmt.WithFeatures(featuremgmt.FlagAlertingBacktesting), nil, notifier.ErrAlertmanagerNotReady)
+
+		response := srv.BacktestNotifications(rc, cmd)
+		require.Equal(t, http.StatusConflict, response.Status())
+	})
+}
+
+type fakeNotificationSimulator struct {
+	alerts        []*types.Alert
+	notifications []definitions.SimulatedNotification
+}
+
+func (f *fakeNotificationSimulator) SimulateNotifications(alerts []*types.Alert, _ time.Time) []definitions.SimulatedNotification {
+	f.alerts = alerts
+	return f.notifications
+}
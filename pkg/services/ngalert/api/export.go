@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// The file provisioning reader expands environment variables in most string fields, so any '$' in the
+// exported values is escaped as '$$' to be read back unchanged. Annotations and query models are read
+// without interpolation and are therefore exported as they are.
+
+// exportAlertRuleGroups converts rules to rule groups in the format of file provisioning. The groups are
+// sorted by folder title and name and the rules of each group keep their order within the group.
+func exportAlertRuleGroups(orgID int64, rules []*ngmodels.AlertRule, namespaces map[string]*folder.Folder) ([]definitions.AlertRuleGroupExport, error) {
+	type groupKey struct {
+		namespaceUID string
+		group        string
+	}
+	grouped := make(map[groupKey][]*ngmodels.AlertRule)
+	for _, rule := range rules {
+		key := groupKey{namespaceUID: rule.NamespaceUID, group: rule.RuleGroup}
+		grouped[key] = append(grouped[key], rule)
+	}
+
+	groups := make([]definitions.AlertRuleGroupExport, 0, len(grouped))
+	for key, groupRules := range grouped {
+		namespace, ok := namespaces[key.namespaceUID]
+		if !ok {
+			return nil, fmt.Errorf("folder with uid '%s' of rule group '%s' not found", key.namespaceUID, key.group)
+		}
+		sort.SliceStable(groupRules, func(i, j int) bool {
+			return groupRules[i].RuleGroupIndex < groupRules[j].RuleGroupIndex
+		})
+		group := definitions.AlertRuleGroupExport{
+			OrgID:    orgID,
+			Name:     escapeInterpolation(key.group),
+			Folder:   escapeInterpolation(namespace.Title),
+			Interval: model.Duration(time.Duration(groupRules[0].IntervalSeconds) * time.Second),
+			Rules:    make([]definitions.AlertRuleExport, 0, len(groupRules)),
+		}
+		for _, rule := range groupRules {
+			exported, err := exportAlertRule(rule)
+			if err != nil {
+				return nil, err
+			}
+			group.Rules = append(group.Rules, exported)
+		}
+		groups = append(groups, group)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Folder != groups[j].Folder {
+			return groups[i].Folder < groups[j].Folder
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups, nil
+}
+
+func exportAlertRule(rule *ngmodels.AlertRule) (definitions.AlertRuleExport, error) {
+	result := definitions.AlertRuleExport{
+		UID:          escapeInterpolation(rule.UID),
+		Title:        escapeInterpolation(rule.Title),
+		Condition:    escapeInterpolation(rule.Condition),
+		Data:         make([]definitions.AlertQueryExport, 0, len(rule.Data)),
+		NoDataState:  rule.NoDataState,
+		ExecErrState: rule.ExecErrState,
+		For:          model.Duration(rule.For),
+		Annotations:  rule.Annotations,
+		Labels:       escapeInterpolationMap(rule.Labels),
+	}
+	if rule.DashboardUID != nil {
+		result.DashboardUID = escapeInterpolation(*rule.DashboardUID)
+	}
+	if rule.PanelID != nil {
+		result.PanelID = *rule.PanelID
+	}
+	if rule.IsRecordingRule() {
+		result.Record = &definitions.AlertRuleRecordExport{
+			Metric:              escapeInterpolation(rule.Record.Metric),
+			From:                escapeInterpolation(rule.Record.From),
+			TargetDatasourceUID: escapeInterpolation(rule.Record.TargetDatasourceUID),
+		}
+	}
+	for _, d := range rule.Dependencies {
+		result.Dependencies = append(result.Dependencies, definitions.AlertRuleDependencyExport{
+			RuleUID: escapeInterpolation(d.RuleUID),
+			Equal:   d.Equal,
+		})
+	}
+	for _, query := range rule.Data {
+		var queryModel map[string]interface{}
+		if err := json.Unmarshal(query.Model, &queryModel); err != nil {
+			return definitions.AlertRuleExport{}, fmt.Errorf("failed to parse the model of query '%s' of rule '%s': %w", query.RefID, rule.UID, err)
+		}
+		result.Data = append(result.Data, definitions.AlertQueryExport{
+			RefID:             escapeInterpolation(query.RefID),
+			QueryType:         escapeInterpolation(query.QueryType),
+			RelativeTimeRange: query.RelativeTimeRange,
+			DatasourceUID:     escapeInterpolation(query.DatasourceUID),
+			Model:             queryModel,
+		})
+	}
+	return result, nil
+}
+
+// exportContactPoints groups the integrations of contact points by the name of the contact point. The order of
+// contact points is preserved.
+func exportContactPoints(orgID int64, contactPoints []definitions.EmbeddedContactPoint) ([]definitions.ContactPointExport, error) {
+	result := make([]definitions.ContactPointExport, 0, len(contactPoints))
+	byName := make(map[string]int, len(contactPoints))
+	for _, cp := range contactPoints {
+		settings := map[string]interface{}{}
+		if cp.Settings != nil {
+			m, err := cp.Settings.Map()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the settings of contact point '%s': %w", cp.Name, err)
+			}
+			settings = escapeInterpolationValue(m).(map[string]interface{})
+		}
+		receiver := definitions.ReceiverExport{
+			UID:                   escapeInterpolation(cp.UID),
+			Type:                  escapeInterpolation(cp.Type),
+			Settings:              settings,
+			DisableResolveMessage: cp.DisableResolveMessage,
+		}
+		idx, ok := byName[cp.Name]
+		if !ok {
+			idx = len(result)
+			byName[cp.Name] = idx
+			result = append(result, definitions.ContactPointExport{
+				OrgID: orgID,
+				Name:  escapeInterpolation(cp.Name),
+			})
+		}
+		result[idx].Receivers = append(result[idx].Receivers, receiver)
+	}
+	return result, nil
+}
+
+// exportNotificationPolicy converts the policy tree to a map in the JSON representation of a route without provenance.
+func exportNotificationPolicy(orgID int64, tree definitions.Route) (definitions.NotificationPolicyExport, error) {
+	clearRouteProvenance(&tree)
+	b, err := json.Marshal(tree)
+	if err != nil {
+		return definitions.NotificationPolicyExport{}, err
+	}
+	var policy map[string]interface{}
+	if err := json.Unmarshal(b, &policy); err != nil {
+		return definitions.NotificationPolicyExport{}, err
+	}
+	return definitions.NotificationPolicyExport{
+		OrgID:  orgID,
+		Policy: escapeInterpolationValue(policy).(map[string]interface{}),
+	}, nil
+}
+
+func clearRouteProvenance(route *definitions.Route) {
+	route.Provenance = ""
+	for _, child := range route.Routes {
+		clearRouteProvenance(child)
+	}
+}
+
+func exportMuteTimings(orgID int64, timings []definitions.MuteTimeInterval) []definitions.MuteTimeIntervalExport {
+	result := make([]definitions.MuteTimeIntervalExport, 0, len(timings))
+	for _, timing := range timings {
+		result = append(result, definitions.MuteTimeIntervalExport{
+			OrgID:            orgID,
+			MuteTimeInterval: timing.MuteTimeInterval,
+		})
+	}
+	return result
+}
+
+func exportTemplates(orgID int64, templates map[string]string) []definitions.MessageTemplateExport {
+	result := make([]definitions.MessageTemplateExport, 0, len(templates))
+	for name, tmpl := range templates {
+		result = append(result, definitions.MessageTemplateExport{
+			OrgID:    orgID,
+			Name:     name,
+			Template: tmpl,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func escapeInterpolation(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+func escapeInterpolationMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = escapeInterpolation(v)
+	}
+	return result
+}
+
+// escapeInterpolationValue returns a copy of v with every string in nested maps and slices escaped.
+func escapeInterpolationValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case string:
+		return escapeInterpolation(value)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			result[k] = escapeInterpolationValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			result = append(result, escapeInterpolationValue(item))
+		}
+		return result
+	default:
+		return v
+	}
+}
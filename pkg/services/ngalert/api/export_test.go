@@ -0,0 +1,250 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	prometheus "github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting"
+)
+
+func TestExportAlertRuleGroups(t *testing.T) {
+	namespaces := map[string]*folder.Folder{
+		"uid-b": {UID: "uid-b", Title: "B folder"},
+		"uid-a": {UID: "uid-a", Title: "A folder"},
+	}
+	rules := []*ngmodels.AlertRule{
+		exportTestRule("rule-3", "uid-b", "group", 0),
+		exportTestRule("rule-2", "uid-a", "group-2", 1),
+		exportTestRule("rule-1", "uid-a", "group-2", 0),
+		exportTestRule("rule-4", "uid-a", "group-1", 0),
+	}
+
+	groups, err := exportAlertRuleGroups(1, rules, namespaces)
+	require.NoError(t, err)
+
+	require.Len(t, groups, 3)
+	require.Equal(t, "A folder", groups[0].Folder)
+	require.Equal(t, "group-1", groups[0].Name)
+	require.Equal(t, "A folder", groups[1].Folder)
+	require.Equal(t, "group-2", groups[1].Name)
+	require.Len(t, groups[1].Rules, 2)
+	require.Equal(t, "rule-1", groups[1].Rules[0].UID)
+	require.Equal(t, "rule-2", groups[1].Rules[1].UID)
+	require.Equal(t, "B folder", groups[2].Folder)
+	require.Equal(t, model.Duration(time.Minute), groups[2].Interval)
+
+	t.Run("fails if the folder of a group is not found", func(t *testing.T) {
+		_, err := exportAlertRuleGroups(1, []*ngmodels.AlertRule{exportTestRule("rule", "unknown", "group", 0)}, namespaces)
+		require.ErrorContains(t, err, "folder with uid 'unknown'")
+	})
+}
+
+func TestExportIsReadByFileProvisioning(t *testing.T) {
+	rule := exportTestRule("rule-uid", "folder-uid", "Cost in $USD", 0)
+	rule.Title = "Spent $$ on ${CLOUD}"
+	rule.Labels = map[string]string{"team": "$team"}
+	rule.Annotations = map[string]string{"summary": "Value is {{ $value }}"}
+	rule.Data[0].Model = json.RawMessage(`{"rawSql":"SELECT $__time(t) WHERE $__timeFilter(t)","intervalMs":1000}`)
+	dashboardUID, panelID := "dashboard", int64(3)
+	rule.DashboardUID, rule.PanelID = &dashboardUID, &panelID
+	groups, err := exportAlertRuleGroups(1, []*ngmodels.AlertRule{rule}, map[string]*folder.Folder{
+		"folder-uid": {UID: "folder-uid", Title: "$HOME"},
+	})
+	require.NoError(t, err)
+
+	contactPoints, err := exportContactPoints(1, []definitions.EmbeddedContactPoint{
+		{UID: "slack-1", Name: "team", Type: "slack", Settings: simplejson.NewFromAny(map[string]interface{}{
+			"recipient": "#alerts",
+			"token":     "xoxb-token",
+			"text":      `{{ template "slack.text" . }} costs $5`,
+		})},
+		{UID: "email-1", Name: "team", Type: "email", DisableResolveMessage: true, Settings: simplejson.NewFromAny(map[string]interface{}{
+			"addresses": "team@example.com",
+		})},
+	})
+	require.NoError(t, err)
+
+	groupWait := model.Duration(45 * time.Second)
+	matcher, err := labels.NewMatcher(labels.MatchEqual, "team", "$team")
+	require.NoError(t, err)
+	policy, err := exportNotificationPolicy(1, definitions.Route{
+		Receiver:   "team",
+		GroupByStr: []string{"alertname"},
+		GroupWait:  &groupWait,
+		Provenance: ngmodels.ProvenanceAPI,
+		Routes: []*definitions.Route{
+			{Receiver: "team", ObjectMatchers: definitions.ObjectMatchers{matcher}, Provenance: ngmodels.ProvenanceAPI},
+		},
+	})
+	require.NoError(t, err)
+
+	muteTiming := definitions.MuteTimeInterval{MuteTimeInterval: prometheus.MuteTimeInterval{
+		Name: "weekends",
+		TimeIntervals: []timeinterval.TimeInterval{{
+			Weekdays: []timeinterval.WeekdayRange{{InclusiveRange: timeinterval.InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange: timeinterval.InclusiveRange{Begin: 6, End: 6}}},
+			Times:    []timeinterval.TimeRange{{StartMinute: 60, EndMinute: 120}},
+		}},
+	}}
+
+	export := definitions.AlertingFileExport{
+		APIVersion:    1,
+		Groups:        groups,
+		ContactPoints: contactPoints,
+		Policies:      []definitions.NotificationPolicyExport{policy},
+		MuteTimings:   exportMuteTimings(1, []definitions.MuteTimeInterval{muteTiming}),
+		Templates:     exportTemplates(1, map[string]string{"b": "{{ define \"b\" }}$b{{ end }}", "a": "a"}),
+	}
+
+	testCases := []struct {
+		name    string
+		marshal func(interface{}) ([]byte, error)
+	}{
+		{name: "yaml", marshal: yaml.Marshal},
+		{name: "json", marshal: json.Marshal},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("USD", "EUR")
+			t.Setenv("team", "other-team")
+
+			b, err := tc.marshal(export)
+			require.NoError(t, err)
+			// The file provisioning reader reads both YAML and JSON files with the YAML decoder.
+			var fileV1 alerting.AlertingFileV1
+			require.NoError(t, yaml.Unmarshal(b, &fileV1))
+			file, err := fileV1.MapToModel()
+			require.NoError(t, err)
+
+			require.Len(t, file.Groups, 1)
+			group := file.Groups[0]
+			require.Equal(t, "Cost in $USD", group.Name)
+			require.Equal(t, "$HOME", group.Folder)
+			require.Equal(t, time.Minute, group.Interval)
+			require.Len(t, group.Rules, 1)
+			r := group.Rules[0]
+			require.Equal(t, rule.UID, r.UID)
+			require.Equal(t, rule.Title, r.Title)
+			require.Equal(t, rule.Condition, r.Condition)
+			require.Equal(t, rule.For, r.For)
+			require.Equal(t, rule.NoDataState, r.NoDataState)
+			require.Equal(t, rule.ExecErrState, r.ExecErrState)
+			require.Equal(t, rule.Labels, r.Labels)
+			require.Equal(t, rule.Annotations, r.Annotations)
+			require.Equal(t, dashboardUID, *r.DashboardUID)
+			require.Equal(t, panelID, *r.PanelID)
+			require.Len(t, r.Data, 1)
+			require.Equal(t, rule.Data[0].RefID, r.Data[0].RefID)
+			require.Equal(t, rule.Data[0].DatasourceUID, r.Data[0].DatasourceUID)
+			require.Equal(t, rule.Data[0].RelativeTimeRange, r.Data[0].RelativeTimeRange)
+			require.JSONEq(t, string(rule.Data[0].Model), string(r.Data[0].Model))
+
+			require.Len(t, file.ContactPoints, 1)
+			require.Len(t, file.ContactPoints[0].ContactPoints, 2)
+			slack := file.ContactPoints[0].ContactPoints[0]
+			require.Equal(t, "team", slack.Name)
+			require.Equal(t, "slack-1", slack.UID)
+			require.Equal(t, "slack", slack.Type)
+			require.Equal(t, `{{ template "slack.text" . }} costs $5`, slack.Settings.Get("text").MustString())
+			require.True(t, file.ContactPoints[0].ContactPoints[1].DisableResolveMessage)
+
+			require.Len(t, file.Policies, 1)
+			tree := file.Policies[0].Policy
+			require.Equal(t, "team", tree.Receiver)
+			require.Equal(t, []string{"alertname"}, tree.GroupByStr)
+			require.Equal(t, groupWait, *tree.GroupWait)
+			require.Empty(t, tree.Provenance)
+			require.Len(t, tree.Routes, 1)
+			require.Empty(t, tree.Routes[0].Provenance)
+			require.Equal(t, "$team", tree.Routes[0].ObjectMatchers[0].Value)
+
+			require.Len(t, file.MuteTimes, 1)
+			require.Equal(t, muteTiming.MuteTimeInterval, file.MuteTimes[0].MuteTime.MuteTimeInterval)
+
+			require.Len(t, file.Templates, 2)
+			require.Equal(t, "a", file.Templates[0].Data.Name)
+			require.Equal(t, "{{ define \"b\" }}$b{{ end }}", file.Templates[1].Data.Template)
+		})
+	}
+}
+
+func TestExportedRecordingRuleIsReadByFileProvisioning(t *testing.T) {
+	rule := exportTestRule("rule-uid", "folder-uid", "group", 0)
+	rule.Condition = ""
+	rule.Record = ngmodels.Record{Metric: "job:up:sum", From: "A", TargetDatasourceUID: "prometheus"}
+	groups, err := exportAlertRuleGroups(1, []*ngmodels.AlertRule{rule}, map[string]*folder.Folder{
+		"folder-uid": {UID: "folder-uid", Title: "folder"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &definitions.AlertRuleRecordExport{Metric: "job:up:sum", From: "A", TargetDatasourceUID: "prometheus"}, groups[0].Rules[0].Record)
+
+	b, err := yaml.Marshal(definitions.AlertingFileExport{APIVersion: 1, Groups: groups})
+	require.NoError(t, err)
+	var fileV1 alerting.AlertingFileV1
+	require.NoError(t, yaml.Unmarshal(b, &fileV1))
+	file, err := fileV1.MapToModel()
+	require.NoError(t, err)
+
+	require.Len(t, file.Groups, 1)
+	require.Len(t, file.Groups[0].Rules, 1)
+	r := file.Groups[0].Rules[0]
+	require.True(t, r.IsRecordingRule())
+	require.Equal(t, rule.Record, r.Record)
+	require.Empty(t, r.Condition)
+}
+
+func TestExportedRuleDependenciesAreReadByFileProvisioning(t *testing.T) {
+	rule := exportTestRule("rule-uid", "folder-uid", "group", 0)
+	rule.Dependencies = []ngmodels.RuleDependency{{RuleUID: "dependency-uid", Equal: []string{"instance"}}}
+	groups, err := exportAlertRuleGroups(1, []*ngmodels.AlertRule{rule}, map[string]*folder.Folder{
+		"folder-uid": {UID: "folder-uid", Title: "folder"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []definitions.AlertRuleDependencyExport{{RuleUID: "dependency-uid", Equal: []string{"instance"}}}, groups[0].Rules[0].Dependencies)
+
+	b, err := yaml.Marshal(definitions.AlertingFileExport{APIVersion: 1, Groups: groups})
+	require.NoError(t, err)
+	var fileV1 alerting.AlertingFileV1
+	require.NoError(t, yaml.Unmarshal(b, &fileV1))
+	file, err := fileV1.MapToModel()
+	require.NoError(t, err)
+
+	require.Len(t, file.Groups, 1)
+	require.Len(t, file.Groups[0].Rules, 1)
+	require.Equal(t, rule.Dependencies, file.Groups[0].Rules[0].Dependencies)
+}
+
+func exportTestRule(uid, namespaceUID, group string, index int) *ngmodels.AlertRule {
+	return &ngmodels.AlertRule{
+		OrgID:           1,
+		UID:             uid,
+		Title:           uid,
+		Condition:       "A",
+		NamespaceUID:    namespaceUID,
+		RuleGroup:       group,
+		RuleGroupIndex:  index,
+		IntervalSeconds: 60,
+		For:             5 * time.Minute,
+		NoDataState:     ngmodels.NoData,
+		ExecErrState:    ngmodels.AlertingErrState,
+		Data: []ngmodels.AlertQuery{
+			{
+				RefID:             "A",
+				DatasourceUID:     "datasource",
+				RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(10 * time.Minute)},
+				Model:             json.RawMessage(`{"expr":"up"}`),
+			},
+		},
+	}
+}
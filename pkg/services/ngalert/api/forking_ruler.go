@@ -1,6 +1,9 @@
 package api
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -101,6 +104,21 @@ func (f *RulerApiHandler) handleRoutePostNameGrafanaRulesConfig(ctx *models.ReqC
 	return f.GrafanaRuler.RoutePostNameRulesConfig(ctx, conf, namespace)
 }
 
+func (f *RulerApiHandler) handleRouteImportPrometheusRules(ctx *models.ReqContext, conf apimodels.PrometheusRuleFile, namespace string) response.Response {
+	datasourceUID := ctx.Query("datasourceUID")
+	if datasourceUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("datasourceUID is required"), "")
+	}
+	ds, err := f.DatasourceCache.GetDatasourceByUID(ctx.Req.Context(), datasourceUID, ctx.SignedInUser, ctx.SkipCache)
+	if err != nil {
+		return errorToResponse(err)
+	}
+	if ds.Type != PrometheusDatasourceType {
+		return errorToResponse(unexpectedDatasourceTypeError(ds.Type, PrometheusDatasourceType))
+	}
+	return f.GrafanaRuler.RouteImportPrometheusRules(ctx, conf, namespace, ds, ctx.QueryBool("dryRun"))
+}
+
 func (f *RulerApiHandler) getService(ctx *models.ReqContext) (*LotexRuler, error) {
 	_, err := getDatasourceByUID(ctx, f.DatasourceCache, apimodels.LoTexRulerBackend)
 	if err != nil {
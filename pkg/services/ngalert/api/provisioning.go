@@ -80,6 +80,10 @@ func (f *ProvisioningApiHandler) handleRouteGetAlertRules(ctx *models.ReqContext
 	return f.svc.RouteGetAlertRules(ctx)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetAlertingExport(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetAlertingExport(ctx)
+}
+
 func (f *ProvisioningApiHandler) handleRouteGetAlertRule(ctx *models.ReqContext, UID string) response.Response {
 	return f.svc.RouteRouteGetAlertRule(ctx, UID)
 }
@@ -32,3 +32,7 @@ func (f *TestingApiHandler) handleRouteEvalQueries(c *models.ReqContext, body ap
 func (f *TestingApiHandler) handleBacktestingConfig(ctx *models.ReqContext, conf apimodels.BacktestConfig) response.Response {
 	return f.svc.BacktestAlertRule(ctx, conf)
 }
+
+func (f *TestingApiHandler) handleBacktestNotificationsConfig(ctx *models.ReqContext, conf apimodels.BacktestConfig) response.Response {
+	return f.svc.BacktestNotifications(ctx, conf)
+}
@@ -140,6 +140,17 @@ import (
 //     Responses:
 //       200: receiversResponse
 
+// swagger:route GET /api/alertmanager/grafana/config/api/v1/receivers/deliveries alertmanager RouteGetGrafanaNotificationDeliveries
+//
+// Get the attempts to deliver notifications to contact points, the most recent first.
+//
+//     Responses:
+//       200: NotificationDeliveries
+//       400: ValidationError
+//       404: NotFound
+//       409: AlertManagerNotReady
+//       501: Failure
+
 // swagger:route POST /api/alertmanager/grafana/config/api/v1/receivers/test alertmanager RoutePostTestGrafanaReceivers
 //
 // Test Grafana managed receivers without saving them.
@@ -154,6 +165,16 @@ import (
 //       408: Failure
 //       409: AlertManagerNotReady
 
+// swagger:route POST /api/alertmanager/grafana/config/api/v1/routing/test alertmanager RoutePostTestGrafanaRouting
+//
+// Test which notification policies, mute timings and contact points are used for an alert with the given labels.
+//
+//     Responses:
+//       200: TestRoutingResult
+//       400: ValidationError
+//       404: NotFound
+//       409: AlertManagerNotReady
+
 // swagger:route GET /api/alertmanager/grafana/api/v2/silences alertmanager RouteGetGrafanaSilences
 //
 // get silences
@@ -272,6 +293,99 @@ type TestReceiverConfigResult struct {
 	Error  string `json:"error,omitempty"`
 }
 
+// swagger:parameters RouteGetGrafanaNotificationDeliveries
+type NotificationDeliveriesParams struct {
+	// The name of the contact point
+	// in:query
+	Receiver string `json:"receiver"`
+	// The UID of an integration of the contact point
+	// in:query
+	IntegrationUID string `json:"integrationUID"`
+	// The status of the attempts, either success or failed
+	// in:query
+	Status string `json:"status"`
+	// The fingerprint of an alert in the notification
+	// in:query
+	Fingerprint string `json:"fingerprint"`
+	// Start of the time range as Unix epoch milliseconds
+	// in:query
+	From int64 `json:"from"`
+	// End of the time range as Unix epoch milliseconds
+	// in:query
+	To int64 `json:"to"`
+	// The maximum number of attempts to return
+	// in:query
+	Limit int64 `json:"limit"`
+}
+
+// swagger:model
+type NotificationDeliveries []NotificationDelivery
+
+// NotificationDelivery is an attempt to deliver a notification to an integration of a contact point.
+// swagger:model
+type NotificationDelivery struct {
+	Receiver          string   `json:"receiver"`
+	IntegrationUID    string   `json:"integrationUID"`
+	IntegrationName   string   `json:"integrationName"`
+	IntegrationType   string   `json:"integrationType"`
+	GroupKey          string   `json:"groupKey"`
+	AlertFingerprints []string `json:"alertFingerprints"`
+	Firing            int      `json:"firing"`
+	Resolved          int      `json:"resolved"`
+	// The number of the attempt, starting at 1
+	Attempt int `json:"attempt"`
+	// Either success or failed
+	Status string `json:"status"`
+	// The status code of the HTTP response of the integration, if any
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// The duration of the attempt in milliseconds
+	Duration int64     `json:"duration"`
+	SentAt   time.Time `json:"sentAt"`
+}
+
+// swagger:parameters RoutePostTestGrafanaRouting
+type TestRoutingConfigParams struct {
+	// in:body
+	Body TestRoutingConfigBodyParams
+}
+
+type TestRoutingConfigBodyParams struct {
+	// Labels of the alert to route.
+	Labels model.LabelSet `yaml:"labels" json:"labels"`
+	// Time at which mute timings are checked. Defaults to the current time.
+	Time time.Time `yaml:"time,omitempty" json:"time,omitempty"`
+}
+
+// swagger:model
+type TestRoutingResult struct {
+	Labels model.LabelSet `json:"labels"`
+	Time   time.Time      `json:"time"`
+	// Routes are the notification policies that match the labels, in the order in which they are matched.
+	Routes []TestRoutingRoute `json:"routes"`
+	// Receivers are the contact points that would be notified, unless their route is muted.
+	Receivers []string `json:"receivers"`
+}
+
+// swagger:model
+type TestRoutingRoute struct {
+	// Path is the position of the route in the notification policy tree, as the indices of the nested routes
+	// from the root. The root route has an empty path.
+	Path           []int          `json:"path"`
+	Matchers       []string       `json:"matchers,omitempty"`
+	Receiver       string         `json:"receiver"`
+	GroupBy        []string       `json:"group_by"`
+	GroupWait      model.Duration `json:"group_wait"`
+	GroupInterval  model.Duration `json:"group_interval"`
+	RepeatInterval model.Duration `json:"repeat_interval"`
+	Continue       bool           `json:"continue"`
+	// MuteTimeIntervals are all the mute timings of the route, and ActiveMuteTimeIntervals are
+	// the ones that mute the route at the time of the test.
+	MuteTimeIntervals       []string `json:"mute_time_intervals,omitempty"`
+	ActiveMuteTimeIntervals []string `json:"active_mute_time_intervals,omitempty"`
+	Muted                   bool     `json:"muted"`
+}
+
 // swagger:parameters RouteCreateSilence RouteCreateGrafanaSilence
 type CreateSilenceParams struct {
 	// in:body
@@ -10,6 +10,8 @@ import (
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/common/model"
 	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/templates"
 )
 
 // Validate normalizes a possibly nested Route r, and returns errors if r is invalid.
@@ -66,6 +68,7 @@ func (t *MessageTemplate) Validate() error {
 	}
 
 	tmpl := tmplhtml.New("").Option("missingkey=zero")
+	tmpl.Funcs(tmplhtml.FuncMap(templates.FuncMap))
 	tmpl.Funcs(tmplhtml.FuncMap(template.DefaultFuncs))
 	_, err := tmpl.Parse(t.Template)
 	if err != nil {
@@ -374,23 +374,29 @@ type PostableGrafanaRule struct {
 	UID          string              `json:"uid" yaml:"uid"`
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	// Record makes the rule a recording rule that writes the result of a query or expression as a series. A recording rule does not have a condition.
+	Record *models.Record `json:"record,omitempty" yaml:"record,omitempty"`
+	// Dependencies are the rules whose firing alerts inhibit the alerts of this rule. An inhibited alert is Normal with the reason Inhibited.
+	Dependencies []models.RuleDependency `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
 }
 
 // swagger:model
 type GettableGrafanaRule struct {
-	ID              int64               `json:"id" yaml:"id"`
-	OrgID           int64               `json:"orgId" yaml:"orgId"`
-	Title           string              `json:"title" yaml:"title"`
-	Condition       string              `json:"condition" yaml:"condition"`
-	Data            []models.AlertQuery `json:"data" yaml:"data"`
-	Updated         time.Time           `json:"updated" yaml:"updated"`
-	IntervalSeconds int64               `json:"intervalSeconds" yaml:"intervalSeconds"`
-	Version         int64               `json:"version" yaml:"version"`
-	UID             string              `json:"uid" yaml:"uid"`
-	NamespaceUID    string              `json:"namespace_uid" yaml:"namespace_uid"`
-	NamespaceID     int64               `json:"namespace_id" yaml:"namespace_id"`
-	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
-	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
-	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
-	Provenance      models.Provenance   `json:"provenance,omitempty" yaml:"provenance,omitempty"`
+	ID              int64                   `json:"id" yaml:"id"`
+	OrgID           int64                   `json:"orgId" yaml:"orgId"`
+	Title           string                  `json:"title" yaml:"title"`
+	Condition       string                  `json:"condition" yaml:"condition"`
+	Data            []models.AlertQuery     `json:"data" yaml:"data"`
+	Updated         time.Time               `json:"updated" yaml:"updated"`
+	IntervalSeconds int64                   `json:"intervalSeconds" yaml:"intervalSeconds"`
+	Version         int64                   `json:"version" yaml:"version"`
+	UID             string                  `json:"uid" yaml:"uid"`
+	NamespaceUID    string                  `json:"namespace_uid" yaml:"namespace_uid"`
+	NamespaceID     int64                   `json:"namespace_id" yaml:"namespace_id"`
+	RuleGroup       string                  `json:"rule_group" yaml:"rule_group"`
+	NoDataState     NoDataState             `json:"no_data_state" yaml:"no_data_state"`
+	ExecErrState    ExecutionErrorState     `json:"exec_err_state" yaml:"exec_err_state"`
+	Provenance      models.Provenance       `json:"provenance,omitempty" yaml:"provenance,omitempty"`
+	Record          *models.Record          `json:"record,omitempty" yaml:"record,omitempty"`
+	Dependencies    []models.RuleDependency `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
 }
@@ -0,0 +1,42 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// swagger:route GET /api/v1/rules/history history RouteGetStateHistory
+//
+// Query the state history of a Grafana managed alert rule from the configured state historian
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistory
+//       400: ValidationError
+
+// swagger:parameters RouteGetStateHistory
+type StateHistoryParams struct {
+	// The UID of the alert rule
+	// in:query
+	// required:true
+	RuleUID string `json:"ruleUID"`
+	// Start of the time range as Unix epoch milliseconds
+	// in:query
+	From int64 `json:"from"`
+	// End of the time range as Unix epoch milliseconds
+	// in:query
+	To int64 `json:"to"`
+	// A list of labels in the form name=value that alert instances must have
+	// in:query
+	Labels []string `json:"labels"`
+	// A list of states, such as Alerting or Normal, to filter the history by
+	// in:query
+	State []string `json:"state"`
+	// The maximum number of state transitions to read
+	// in:query
+	Limit int64 `json:"limit"`
+}
+
+// swagger:model
+type StateHistory data.Frame
@@ -0,0 +1,57 @@
+package definitions
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route POST /api/ruler/grafana/api/v1/import/prometheus/{Namespace} ruler RouteImportPrometheusRules
+//
+// Converts Prometheus rule groups to Grafana-managed rule groups that query a Prometheus data source, and creates or updates them in the folder.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: PrometheusImportResponse
+//       202: PrometheusImportResponse
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:parameters RouteImportPrometheusRules
+type ImportPrometheusRulesParams struct {
+	// in:path
+	Namespace string
+	// The UID of the Prometheus data source the imported rules query
+	// in:query
+	// required:true
+	DatasourceUID string `json:"datasourceUID"`
+	// Convert the rule groups without saving them
+	// in:query
+	DryRun bool `json:"dryRun"`
+	// in:body
+	Body PrometheusRuleFile
+}
+
+// PrometheusRuleFile is the content of a Prometheus rule file.
+// swagger:model
+type PrometheusRuleFile struct {
+	Groups []PrometheusRuleGroup `yaml:"groups" json:"groups"`
+}
+
+// swagger:model
+type PrometheusRuleGroup struct {
+	Name     string         `yaml:"name" json:"name"`
+	Interval model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Rules    []ApiRuleNode  `yaml:"rules" json:"rules"`
+}
+
+// swagger:model
+type PrometheusImportResponse struct {
+	// Groups are the Grafana-managed rule groups the Prometheus rule groups are converted to.
+	Groups []PostableRuleGroupConfig `json:"groups"`
+	// DryRun is true if the rule groups were converted but not saved.
+	DryRun bool `json:"dryRun"`
+}
@@ -109,11 +109,19 @@ type ProvisionedAlertRule struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// example: {"team": "sre-team-1"}
 	Labels map[string]string `json:"labels,omitempty"`
+	// Record makes the rule a recording rule that writes the result of a query or expression as a series. A recording rule does not have a condition.
+	Record *models.Record `json:"record,omitempty"`
+	// Dependencies are the rules whose firing alerts inhibit the alerts of this rule. An inhibited alert is Normal with the reason Inhibited.
+	Dependencies []models.RuleDependency `json:"dependencies,omitempty"`
 	// readonly: true
 	Provenance models.Provenance `json:"provenance,omitempty"`
 }
 
 func (a *ProvisionedAlertRule) UpstreamModel() (models.AlertRule, error) {
+	var record models.Record
+	if a.Record != nil {
+		record = *a.Record
+	}
 	return models.AlertRule{
 		ID:           a.ID,
 		UID:          a.UID,
@@ -129,10 +137,16 @@ func (a *ProvisionedAlertRule) UpstreamModel() (models.AlertRule, error) {
 		For:          time.Duration(a.For),
 		Annotations:  a.Annotations,
 		Labels:       a.Labels,
+		Record:       record,
+		Dependencies: a.Dependencies,
 	}, nil
 }
 
 func NewAlertRule(rule models.AlertRule, provenance models.Provenance) ProvisionedAlertRule {
+	var record *models.Record
+	if rule.IsRecordingRule() {
+		record = &rule.Record
+	}
 	return ProvisionedAlertRule{
 		ID:           rule.ID,
 		UID:          rule.UID,
@@ -148,6 +162,8 @@ func NewAlertRule(rule models.AlertRule, provenance models.Provenance) Provision
 		ExecErrState: rule.ExecErrState,
 		Annotations:  rule.Annotations,
 		Labels:       rule.Labels,
+		Record:       record,
+		Dependencies: rule.Dependencies,
 		Provenance:   provenance,
 	}
 }
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 func TestToModel(t *testing.T) {
@@ -33,3 +35,25 @@ func TestToModel(t *testing.T) {
 		require.Len(t, tm.Rules, 1)
 	})
 }
+
+func TestProvisionedAlertRuleRecord(t *testing.T) {
+	rule := models.AlertRule{UID: "1", Record: models.Record{Metric: "job:up:sum", From: "A"}}
+	provisioned := NewAlertRule(rule, models.ProvenanceAPI)
+	require.Equal(t, &rule.Record, provisioned.Record)
+
+	upstream, err := provisioned.UpstreamModel()
+	require.NoError(t, err)
+	require.Equal(t, rule.Record, upstream.Record)
+
+	require.Nil(t, NewAlertRule(models.AlertRule{UID: "2", Condition: "A"}, models.ProvenanceAPI).Record)
+}
+
+func TestProvisionedAlertRuleDependencies(t *testing.T) {
+	rule := models.AlertRule{UID: "1", Condition: "A", Dependencies: []models.RuleDependency{{RuleUID: "2", Equal: []string{"instance"}}}}
+	provisioned := NewAlertRule(rule, models.ProvenanceAPI)
+	require.Equal(t, rule.Dependencies, provisioned.Dependencies)
+
+	upstream, err := provisioned.UpstreamModel()
+	require.NoError(t, err)
+	require.Equal(t, rule.Dependencies, upstream.Dependencies)
+}
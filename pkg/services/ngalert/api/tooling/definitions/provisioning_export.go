@@ -0,0 +1,146 @@
+package definitions
+
+import (
+	"encoding/json"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route GET /api/v1/provisioning/export provisioning stable RouteGetAlertingExport
+//
+// Export the alert rules, contact points, notification policies, mute timings and templates in the file provisioning format.
+//
+//     Produces:
+//     - application/yaml
+//     - application/json
+//
+//     Responses:
+//       200: AlertingFileExport
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:parameters RouteGetAlertingExport
+type ExportQueryParams struct {
+	// Format of the exported file, either yaml or json
+	// in:query
+	// required:false
+	// default:yaml
+	Format string `json:"format"`
+	// Whether to set the Content-Disposition header so the file is downloaded
+	// in:query
+	// required:false
+	// default:false
+	Download bool `json:"download"`
+	// Whether to include the values of secure settings of contact points instead of redacting them
+	// in:query
+	// required:false
+	// default:false
+	Decrypt bool `json:"decrypt"`
+}
+
+// AlertingFileExport is the content of an alerting provisioning file.
+// swagger:model
+type AlertingFileExport struct {
+	APIVersion    int64                      `json:"apiVersion" yaml:"apiVersion"`
+	Groups        []AlertRuleGroupExport     `json:"groups,omitempty" yaml:"groups,omitempty"`
+	ContactPoints []ContactPointExport       `json:"contactPoints,omitempty" yaml:"contactPoints,omitempty"`
+	Policies      []NotificationPolicyExport `json:"policies,omitempty" yaml:"policies,omitempty"`
+	MuteTimings   []MuteTimeIntervalExport   `json:"muteTimes,omitempty" yaml:"muteTimes,omitempty"`
+	Templates     []MessageTemplateExport    `json:"templates,omitempty" yaml:"templates,omitempty"`
+}
+
+// AlertRuleGroupExport is the provisioned file export of models.AlertRuleGroup.
+type AlertRuleGroupExport struct {
+	OrgID    int64             `json:"orgId" yaml:"orgId"`
+	Name     string            `json:"name" yaml:"name"`
+	Folder   string            `json:"folder" yaml:"folder"`
+	Interval model.Duration    `json:"interval" yaml:"interval"`
+	Rules    []AlertRuleExport `json:"rules" yaml:"rules"`
+}
+
+// AlertRuleExport is the provisioned file export of models.AlertRule.
+type AlertRuleExport struct {
+	UID          string                      `json:"uid" yaml:"uid"`
+	Title        string                      `json:"title" yaml:"title"`
+	Condition    string                      `json:"condition" yaml:"condition"`
+	Data         []AlertQueryExport          `json:"data" yaml:"data"`
+	DashboardUID string                      `json:"dashboardUid,omitempty" yaml:"dashboardUid,omitempty"`
+	PanelID      int64                       `json:"panelId,omitempty" yaml:"panelId,omitempty"`
+	NoDataState  models.NoDataState          `json:"noDataState" yaml:"noDataState"`
+	ExecErrState models.ExecutionErrorState  `json:"execErrState" yaml:"execErrState"`
+	For          model.Duration              `json:"for" yaml:"for"`
+	Annotations  map[string]string           `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Labels       map[string]string           `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Record       *AlertRuleRecordExport      `json:"record,omitempty" yaml:"record,omitempty"`
+	Dependencies []AlertRuleDependencyExport `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+}
+
+// AlertRuleRecordExport is the provisioned file export of models.Record.
+type AlertRuleRecordExport struct {
+	Metric              string `json:"metric" yaml:"metric"`
+	From                string `json:"from" yaml:"from"`
+	TargetDatasourceUID string `json:"targetDatasourceUid,omitempty" yaml:"targetDatasourceUid,omitempty"`
+}
+
+// AlertRuleDependencyExport is the provisioned file export of models.RuleDependency.
+type AlertRuleDependencyExport struct {
+	RuleUID string   `json:"ruleUid" yaml:"ruleUid"`
+	Equal   []string `json:"equal,omitempty" yaml:"equal,omitempty"`
+}
+
+// AlertQueryExport is the provisioned file export of models.AlertQuery.
+type AlertQueryExport struct {
+	RefID             string                   `json:"refId" yaml:"refId"`
+	QueryType         string                   `json:"queryType,omitempty" yaml:"queryType,omitempty"`
+	RelativeTimeRange models.RelativeTimeRange `json:"relativeTimeRange" yaml:"relativeTimeRange"`
+	DatasourceUID     string                   `json:"datasourceUid" yaml:"datasourceUid"`
+	Model             map[string]interface{}   `json:"model" yaml:"model"`
+}
+
+// ContactPointExport is the provisioned file export of a receiver with all of its integrations.
+type ContactPointExport struct {
+	OrgID     int64            `json:"orgId" yaml:"orgId"`
+	Name      string           `json:"name" yaml:"name"`
+	Receivers []ReceiverExport `json:"receivers" yaml:"receivers"`
+}
+
+// ReceiverExport is the provisioned file export of an integration of a contact point.
+type ReceiverExport struct {
+	UID                   string                 `json:"uid" yaml:"uid"`
+	Type                  string                 `json:"type" yaml:"type"`
+	Settings              map[string]interface{} `json:"settings" yaml:"settings"`
+	DisableResolveMessage bool                   `json:"disableResolveMessage" yaml:"disableResolveMessage"`
+}
+
+// NotificationPolicyExport is the provisioned file export of the notification policy tree of an organization.
+// The policy is kept as a map in the JSON representation of Route, which is the shape the file reader expects.
+type NotificationPolicyExport struct {
+	OrgID  int64                  `json:"orgId" yaml:"orgId"`
+	Policy map[string]interface{} `json:"-" yaml:",inline"`
+}
+
+// MarshalJSON implements json.Marshaler for NotificationPolicyExport by inlining the policy next to the orgId.
+func (p NotificationPolicyExport) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(p.Policy)+1)
+	for k, v := range p.Policy {
+		m[k] = v
+	}
+	m["orgId"] = p.OrgID
+	return json.Marshal(m)
+}
+
+// MuteTimeIntervalExport is the provisioned file export of a mute timing.
+type MuteTimeIntervalExport struct {
+	OrgID                   int64 `json:"orgId" yaml:"orgId"`
+	config.MuteTimeInterval `json:",inline" yaml:",inline"`
+}
+
+// MessageTemplateExport is the provisioned file export of a message template.
+type MessageTemplateExport struct {
+	OrgID    int64  `json:"orgId" yaml:"orgId"`
+	Name     string `json:"name" yaml:"name"`
+	Template string `json:"template" yaml:"template"`
+}
@@ -68,6 +68,21 @@ import (
 //     Responses:
 //       200: BacktestResult
 
+// swagger:route Post /api/v1/rule/backtest/notifications testing BacktestNotificationsConfig
+//
+// Test rule and simulate the notifications of the resulting alerts
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestNotificationsResult
+//       404: NotFound
+//       409: AlertManagerNotReady
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -186,7 +201,10 @@ type BacktestConfig struct {
 	Data      []models.AlertQuery `json:"data"` // TODO yuri. Create API model for AlertQuery
 	For       model.Duration      `json:"for,omitempty"`
 
-	Title       string            `json:"title"`
+	Title string `json:"title"`
+	// Namespace is the title of the folder of the rule. Like for the rules in the folder, the UID and the title of
+	// the folder are added to the labels of the alerts.
+	Namespace   string            `json:"namespace,omitempty"`
 	Labels      map[string]string `json:"labels,omitempty"`
 	Annotations map[string]string `json:"annotations,omitempty"`
 
@@ -195,3 +213,39 @@ type BacktestConfig struct {
 
 // swagger:model
 type BacktestResult data.Frame
+
+// swagger:parameters BacktestNotificationsConfig
+type BacktestNotificationsRequest struct {
+	// in:body
+	Body BacktestConfig
+}
+
+// swagger:model
+type BacktestNotificationsResult struct {
+	// States is the frame of states of the alert rule per evaluation, as returned by the backtesting of the rule.
+	States json.RawMessage `json:"states"`
+	// Notifications are the notifications that would have been sent for the alerts of the rule, in the order of time.
+	Notifications []SimulatedNotification `json:"notifications"`
+}
+
+// swagger:model
+type SimulatedNotification struct {
+	// Time is when the notification would have been sent.
+	Time time.Time `json:"time"`
+	// Receiver is the contact point of the notification.
+	Receiver string `json:"receiver"`
+	// RoutePath is the path of the notification policy in the tree, as the indexes of the child policies
+	// starting from the default policy.
+	RoutePath []int `json:"routePath"`
+	// GroupLabels are the labels that the alerts of the notification are grouped by.
+	GroupLabels model.LabelSet `json:"groupLabels"`
+	// Firing are the labels of the firing alerts of the notification.
+	Firing []model.LabelSet `json:"firing"`
+	// Resolved are the labels of the resolved alerts of the notification.
+	Resolved []model.LabelSet `json:"resolved"`
+	// Repeat is true if nothing changed in the group since the last notification and it is sent again
+	// because the repeat interval has passed.
+	Repeat bool `json:"repeat"`
+	// MutedBy are the mute timings that muted the notification. A muted notification is not sent.
+	MutedBy []string `json:"mutedBy,omitempty"`
+}
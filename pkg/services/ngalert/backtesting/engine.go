@@ -9,8 +9,10 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	alertingModels "github.com/grafana/alerting/alerting/models"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/hashicorp/go-multierror"
+	prometheusModel "github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
@@ -37,13 +39,15 @@ type stateManager interface {
 }
 
 type Engine struct {
-	evalFactory        eval.EvaluatorFactory
-	createStateManager func() stateManager
+	evalFactory          eval.EvaluatorFactory
+	createStateManager   func() stateManager
+	disableGrafanaFolder bool
 }
 
-func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory) *Engine {
+func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, disableGrafanaFolder bool) *Engine {
 	return &Engine{
-		evalFactory: evalFactory,
+		evalFactory:          evalFactory,
+		disableGrafanaFolder: disableGrafanaFolder,
 		createStateManager: func() stateManager {
 			cfg := state.ManagerCfg{
 				Metrics:       nil,
@@ -58,7 +62,17 @@ func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory) *Engine {
 	}
 }
 
-func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, from, to time.Time) (*data.Frame, error) {
+type transitionsFunc = func(now time.Time, transitions []state.StateTransition)
+
+// Test evaluates the rule over the interval and returns the frame of states per evaluation. The folder title is
+// added to the labels of the states like the scheduler does for the rules in that folder.
+func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, folderTitle string, from, to time.Time) (*data.Frame, error) {
+	return e.test(ctx, user, rule, folderTitle, from, to, nil)
+}
+
+// test evaluates the rule over the interval and returns the frame of states per evaluation. If onTransitions is not
+// nil, it is called with the state transitions of every evaluation in the order of time.
+func (e *Engine) test(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, folderTitle string, from, to time.Time, onTransitions transitionsFunc) (*data.Frame, error) {
 	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
 	logger := logger.FromContext(ctx)
 
@@ -76,6 +90,7 @@ func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models
 	}
 
 	stateManager := e.createStateManager()
+	extraLabels := e.ruleExtraLabels(rule, folderTitle)
 
 	logger.Info("Start testing alert rule", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluations", length)
 
@@ -86,7 +101,10 @@ func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models
 
 	err = evaluator.Eval(ruleCtx, from, to, time.Duration(rule.IntervalSeconds)*time.Second, func(currentTime time.Time, results eval.Results) error {
 		idx := int(currentTime.Sub(from).Seconds()) / int(rule.IntervalSeconds)
-		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil)
+		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, extraLabels)
+		if onTransitions != nil {
+			onTransitions(currentTime, states)
+		}
 		tsField.Set(idx, currentTime)
 		for _, s := range states {
 			field, ok := valueFields[s.CacheID]
@@ -119,6 +137,20 @@ func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models
 	return result, nil
 }
 
+// ruleExtraLabels returns the labels that the scheduler adds to the states of the rule.
+func (e *Engine) ruleExtraLabels(rule *models.AlertRule, folderTitle string) data.Labels {
+	extraLabels := make(data.Labels, 4)
+
+	extraLabels[alertingModels.NamespaceUIDLabel] = rule.NamespaceUID
+	extraLabels[prometheusModel.AlertNameLabel] = rule.Title
+	extraLabels[alertingModels.RuleUIDLabel] = rule.UID
+
+	if !e.disableGrafanaFolder {
+		extraLabels[models.FolderTitleLabel] = folderTitle
+	}
+	return extraLabels
+}
+
 func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, user *user.SignedInUser, condition models.Condition) (backtestingEvaluator, error) {
 	for _, q := range condition.Data {
 		if q.DatasourceUID == "__data__" || q.QueryType == "__data__" {
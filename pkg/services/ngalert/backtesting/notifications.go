@@ -0,0 +1,99 @@
+package backtesting
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// NotificationSimulator returns the notifications that would have been sent for the alerts up to the given time.
+type NotificationSimulator interface {
+	SimulateNotifications(alerts []*types.Alert, until time.Time) []apimodels.SimulatedNotification
+}
+
+// TestNotifications evaluates the rule over the interval like Test, and feeds the alerts that the rule would have
+// sent to the Alertmanager through the simulator. It returns the frame of states per evaluation and the
+// notifications that would have been sent for them.
+func (e *Engine) TestNotifications(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, folderTitle string, from, to time.Time, simulator NotificationSimulator) (*data.Frame, []apimodels.SimulatedNotification, error) {
+	timeline := newAlertTimeline()
+	result, err := e.test(ctx, user, rule, folderTitle, from, to, timeline.add)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, simulator.SimulateNotifications(timeline.alerts, to), nil
+}
+
+// alertTimeline builds the alerts that the scheduler sends to the Alertmanager from the state transitions of the
+// evaluations. An alert starts when its state becomes Alerting, NoData or Error, and ends when the state changes
+// to any other state or the labels of the alert change.
+type alertTimeline struct {
+	alerts []*types.Alert
+	// open are the alerts that have not ended yet, by the cache ID of their state.
+	open map[string]*types.Alert
+}
+
+func newAlertTimeline() *alertTimeline {
+	return &alertTimeline{
+		open: make(map[string]*types.Alert),
+	}
+}
+
+func (t *alertTimeline) add(now time.Time, transitions []state.StateTransition) {
+	for _, s := range transitions {
+		labels := alertLabels(s.State)
+		alert, ok := t.open[s.CacheID]
+		if ok && (labels == nil || !alert.Labels.Equal(labels)) {
+			alert.EndsAt = now
+			delete(t.open, s.CacheID)
+			ok = false
+		}
+		if labels == nil || ok {
+			continue
+		}
+		alert = &types.Alert{
+			Alert: model.Alert{
+				Labels:   labels,
+				StartsAt: now,
+			},
+		}
+		t.open[s.CacheID] = alert
+		t.alerts = append(t.alerts, alert)
+	}
+}
+
+// alertLabels returns the labels of the alert of the state, or nil if the state is not sent to the Alertmanager.
+// Like the scheduler, the alerts of the NoData and Error states are renamed and keep the name of the rule in a label.
+func alertLabels(s *state.State) model.LabelSet {
+	var alertName string
+	switch s.State {
+	case eval.Alerting:
+	case eval.NoData:
+		alertName = schedule.NoDataAlertName
+	case eval.Error:
+		alertName = schedule.ErrorAlertName
+	default:
+		return nil
+	}
+
+	labels := make(model.LabelSet, len(s.Labels)+1)
+	for name, value := range s.Labels {
+		labels[model.LabelName(name)] = model.LabelValue(value)
+	}
+	if alertName != "" {
+		if name, ok := labels[model.AlertNameLabel]; ok {
+			labels[schedule.Rulename] = name
+		}
+		labels[model.AlertNameLabel] = model.LabelValue(alertName)
+	}
+	return labels
+}
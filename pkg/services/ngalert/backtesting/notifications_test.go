@@ -0,0 +1,210 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestEngineTestNotifications(t *testing.T) {
+	evaluator := &fakeBacktestingEvaluator{
+		evalCallback: func(now time.Time) (eval.Results, error) {
+			return eval.Results{}, nil
+		},
+	}
+	manager := &fakeStateManager{}
+
+	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user *user.SignedInUser, condition models.Condition) (backtestingEvaluator, error) {
+		return evaluator, nil
+	}
+	t.Cleanup(func() {
+		backtestingEvaluatorFactory = newBacktestingEvaluator
+	})
+
+	engine := &Engine{
+		createStateManager: func() stateManager {
+			return manager
+		},
+	}
+	rule := models.AlertRuleGen(models.WithInterval(time.Second), models.WithTitle("HighCPU"))()
+	folderTitle := "Folder"
+	interval := time.Duration(rule.IntervalSeconds) * time.Second
+	from := time.Unix(0, 0)
+	to := from.Add(6 * interval)
+
+	newTransition := func(cacheID string, s eval.State, labels data.Labels) state.StateTransition {
+		return state.StateTransition{State: &state.State{CacheID: cacheID, State: s, Labels: labels}}
+	}
+	cpu1 := data.Labels{"instance": "1"}
+	cpu2 := data.Labels{"instance": "2"}
+	statesByTime := map[time.Time][]state.StateTransition{
+		from:                   {newTransition("cpu1", eval.Pending, cpu1), newTransition("cpu2", eval.Normal, cpu2)},
+		from.Add(1 * interval): {newTransition("cpu1", eval.Alerting, cpu1), newTransition("cpu2", eval.NoData, cpu2)},
+		from.Add(2 * interval): {newTransition("cpu1", eval.Alerting, cpu1), newTransition("cpu2", eval.Alerting, cpu2)},
+		from.Add(3 * interval): {newTransition("cpu1", eval.Normal, cpu1), newTransition("cpu2", eval.Alerting, cpu2)},
+		from.Add(4 * interval): {newTransition("cpu1", eval.Error, cpu1), newTransition("cpu2", eval.Alerting, cpu2)},
+		from.Add(5 * interval): {newTransition("cpu1", eval.Normal, cpu1), newTransition("cpu2", eval.Alerting, cpu2)},
+	}
+	// Like the state manager, the extra labels are added to the labels of the states.
+	manager.stateCallback = func(now time.Time) []state.StateTransition {
+		var result []state.StateTransition
+		for _, s := range statesByTime[now] {
+			labels := manager.extraLabels.Copy()
+			for name, value := range s.Labels {
+				labels[name] = value
+			}
+			result = append(result, newTransition(s.CacheID, s.State.State, labels))
+		}
+		return result
+	}
+
+	ruleLabels := func(labels model.LabelSet) model.LabelSet {
+		labels["__alert_rule_namespace_uid__"] = model.LabelValue(rule.NamespaceUID)
+		labels["__alert_rule_uid__"] = model.LabelValue(rule.UID)
+		labels[models.FolderTitleLabel] = model.LabelValue(folderTitle)
+		return labels
+	}
+
+	t.Run("should simulate the notifications of the alerts of the rule", func(t *testing.T) {
+		simulator := &fakeNotificationSimulator{
+			notifications: []apimodels.SimulatedNotification{{Receiver: "default"}},
+		}
+
+		frame, notifications, err := engine.TestNotifications(context.Background(), nil, rule, folderTitle, from, to, simulator)
+		require.NoError(t, err)
+		require.NotNil(t, frame)
+		require.Equal(t, simulator.notifications, notifications)
+		require.Equal(t, to, simulator.until)
+
+		type alert struct {
+			labels   model.LabelSet
+			startsAt time.Time
+			endsAt   time.Time
+		}
+		var alerts []alert
+		for _, a := range simulator.alerts {
+			alerts = append(alerts, alert{labels: a.Labels, startsAt: a.StartsAt, endsAt: a.EndsAt})
+		}
+		require.ElementsMatch(t, []alert{
+			{
+				labels:   ruleLabels(model.LabelSet{"alertname": "HighCPU", "instance": "1"}),
+				startsAt: from.Add(1 * interval),
+				endsAt:   from.Add(3 * interval),
+			},
+			{
+				labels:   ruleLabels(model.LabelSet{"alertname": "DatasourceError", "rulename": "HighCPU", "instance": "1"}),
+				startsAt: from.Add(4 * interval),
+				endsAt:   from.Add(5 * interval),
+			},
+			{
+				labels:   ruleLabels(model.LabelSet{"alertname": "DatasourceNoData", "rulename": "HighCPU", "instance": "2"}),
+				startsAt: from.Add(1 * interval),
+				endsAt:   from.Add(2 * interval),
+			},
+			{
+				labels:   ruleLabels(model.LabelSet{"alertname": "HighCPU", "instance": "2"}),
+				startsAt: from.Add(2 * interval),
+			},
+		}, alerts)
+	})
+
+	t.Run("should route the alerts by the name of the rule", func(t *testing.T) {
+		matcher, err := labels.NewMatcher(labels.MatchEqual, model.AlertNameLabel, "HighCPU")
+		require.NoError(t, err)
+		simulator := &fakeRoutingSimulator{
+			route: dispatch.NewRoute(&config.Route{
+				Receiver: "default",
+				Routes: []*config.Route{
+					{Receiver: "cpu", Matchers: config.Matchers{matcher}},
+				},
+			}, nil),
+		}
+
+		_, notifications, err := engine.TestNotifications(context.Background(), nil, rule, folderTitle, from, to, simulator)
+		require.NoError(t, err)
+
+		receivers := make(map[model.LabelValue]string)
+		for _, n := range notifications {
+			receivers[n.Firing[0]["alertname"]] = n.Receiver
+		}
+		require.Equal(t, map[model.LabelValue]string{
+			"HighCPU":          "cpu",
+			"DatasourceError":  "default",
+			"DatasourceNoData": "default",
+		}, receivers)
+	})
+
+	t.Run("should not add the folder label if it is disabled", func(t *testing.T) {
+		engine := &Engine{
+			createStateManager: func() stateManager {
+				return manager
+			},
+			disableGrafanaFolder: true,
+		}
+		simulator := &fakeNotificationSimulator{}
+
+		_, _, err := engine.TestNotifications(context.Background(), nil, rule, folderTitle, from, to, simulator)
+		require.NoError(t, err)
+		require.NotEmpty(t, simulator.alerts)
+		for _, a := range simulator.alerts {
+			require.NotContains(t, a.Labels, model.LabelName(models.FolderTitleLabel))
+		}
+	})
+
+	t.Run("should not simulate notifications if evaluation fails", func(t *testing.T) {
+		expectedError := errors.New("test-error")
+		evaluator.evalCallback = func(now time.Time) (eval.Results, error) {
+			return nil, expectedError
+		}
+		simulator := &fakeNotificationSimulator{}
+
+		_, _, err := engine.TestNotifications(context.Background(), nil, rule, folderTitle, from, to, simulator)
+		require.ErrorIs(t, err, expectedError)
+		require.Nil(t, simulator.alerts)
+	})
+}
+
+type fakeNotificationSimulator struct {
+	alerts        []*types.Alert
+	until         time.Time
+	notifications []apimodels.SimulatedNotification
+}
+
+func (f *fakeNotificationSimulator) SimulateNotifications(alerts []*types.Alert, until time.Time) []apimodels.SimulatedNotification {
+	f.alerts = alerts
+	f.until = until
+	return f.notifications
+}
+
+// fakeRoutingSimulator sends a notification for every alert to the receiver of the first policy that matches it.
+type fakeRoutingSimulator struct {
+	route *dispatch.Route
+}
+
+func (f *fakeRoutingSimulator) SimulateNotifications(alerts []*types.Alert, _ time.Time) []apimodels.SimulatedNotification {
+	var result []apimodels.SimulatedNotification
+	for _, a := range alerts {
+		routes := f.route.Match(a.Labels)
+		result = append(result, apimodels.SimulatedNotification{
+			Time:     a.StartsAt,
+			Receiver: routes[0].RouteOpts.Receiver,
+			Firing:   []model.LabelSet{a.Labels},
+		})
+	}
+	return result
+}
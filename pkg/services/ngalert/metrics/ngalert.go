@@ -57,6 +57,9 @@ type Scheduler struct {
 	UpdateSchedulableAlertRulesDuration prometheus.Histogram
 	Ticker                              *ticker.Metrics
 	EvaluationMissed                    *prometheus.CounterVec
+	ShardMembers                        prometheus.Gauge
+	OwnedAlertRules                     prometheus.Gauge
+	ShardRebalances                     prometheus.Counter
 }
 
 type MultiOrgAlertmanager struct {
@@ -209,6 +212,30 @@ func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org", "name"},
 		),
+		ShardMembers: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_shard_members",
+				Help:      "The number of members of the cluster that share the evaluation of alert rules.",
+			},
+		),
+		OwnedAlertRules: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_owned_alert_rules",
+				Help:      "The number of alert rules that are assigned to this instance when the evaluation is shared between the members of the cluster.",
+			},
+		),
+		ShardRebalances: promauto.With(r).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_shard_rebalances_total",
+				Help:      "The total number of times the alert rules were rebalanced because the members of the cluster changed.",
+			},
+		),
 	}
 }
 
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/expr"
 )
 
@@ -112,6 +114,43 @@ func (aq *AlertQuery) IsExpression() (bool, error) {
 	return expr.IsDataSource(aq.DatasourceUID), nil
 }
 
+// IsHysteresisExpression returns true if the alert query is a threshold expression with a recovery threshold.
+// The model is unmarshalled into a local map rather than the cached model properties, so that the query is not
+// changed and can be shared by concurrent evaluations of the rule.
+func (aq *AlertQuery) IsHysteresisExpression() bool {
+	if !expr.IsDataSource(aq.DatasourceUID) {
+		return false
+	}
+	props := make(map[string]interface{})
+	if err := json.Unmarshal(aq.Model, &props); err != nil {
+		return false
+	}
+	return expr.IsHysteresisExpression(props)
+}
+
+// PatchHysteresisExpression sets the labels of the alert instances that were firing at the previous evaluation
+// to the model of a threshold expression with a recovery threshold.
+// The model is replaced rather than changed, so copies of the query are not affected.
+func (aq *AlertQuery) PatchHysteresisExpression(loadedDimensions []data.Labels) error {
+	props := make(map[string]interface{})
+	if err := json.Unmarshal(aq.Model, &props); err != nil {
+		return fmt.Errorf("failed to unmarshal query model: %w", err)
+	}
+	if !expr.IsDataSource(aq.DatasourceUID) || !expr.IsHysteresisExpression(props) {
+		return fmt.Errorf("query %s is not a threshold expression with a recovery threshold", aq.RefID)
+	}
+	if err := expr.SetLoadedDimensionsToHysteresisCommand(props, loadedDimensions); err != nil {
+		return err
+	}
+	model, err := json.Marshal(props)
+	if err != nil {
+		return fmt.Errorf("unable to marshal query model: %w", err)
+	}
+	aq.Model = model
+	aq.modelProps = props
+	return nil
+}
+
 // setMaxDatapoints sets the model maxDataPoints if it's missing or invalid
 func (aq *AlertQuery) setMaxDatapoints() error {
 	if aq.modelProps == nil {
@@ -11,6 +11,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheusModel "github.com/prometheus/common/model"
 
 	alertingModels "github.com/grafana/alerting/alerting/models"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -109,6 +111,9 @@ const (
 const (
 	StateReasonMissingSeries = "MissingSeries"
 	StateReasonError         = "Error"
+	// StateReasonInhibited is the reason of the Normal state of an alert instance that would be Pending or Alerting
+	// but is inhibited by a firing alert instance of a rule it depends on.
+	StateReasonInhibited = "Inhibited"
 )
 
 var (
@@ -156,6 +161,95 @@ type AlertRule struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// Record is set for recording rules, whose result is written as a series instead of being alerted on.
+	Record Record `xorm:"record"`
+	// Dependencies are the rules whose firing alert instances inhibit the alert instances of this rule.
+	Dependencies []RuleDependency
+}
+
+// RuleDependency is a rule of the same organization whose firing alert instances inhibit the alert instances
+// of the rule that depends on it. An inhibited alert instance is Normal with the reason Inhibited instead of
+// being Pending or Alerting.
+type RuleDependency struct {
+	// RuleUID is the UID of the rule that is depended on.
+	RuleUID string `json:"rule_uid" yaml:"rule_uid"`
+	// Equal are the labels that must have the same value in a firing alert instance of the rule that is depended
+	// on and in the alert instances it inhibits. If it is empty, any firing alert instance inhibits all of them.
+	Equal []string `json:"equal,omitempty" yaml:"equal,omitempty"`
+}
+
+// Validate checks that the dependency refers to a rule and that the labels to compare are valid label names.
+func (d RuleDependency) Validate() error {
+	if d.RuleUID == "" {
+		return fmt.Errorf("%w: dependency must specify the UID of a rule", ErrAlertRuleFailedValidation)
+	}
+	for _, name := range d.Equal {
+		if !prometheusModel.LabelName(name).IsValid() {
+			return fmt.Errorf("%w: '%s' is not a valid label name in the dependency on rule %s", ErrAlertRuleFailedValidation, name, d.RuleUID)
+		}
+	}
+	return nil
+}
+
+// Inhibits returns true if the labels of a firing alert instance of the rule that is depended on inhibit an alert
+// instance with the given labels. A label that is missing from both label sets counts as equal.
+func (d RuleDependency) Inhibits(firing, labels data.Labels) bool {
+	for _, name := range d.Equal {
+		if firing[name] != labels[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// Record contains the settings of a recording rule.
+type Record struct {
+	// Metric is the name of the series the result of the rule is written as.
+	Metric string `json:"metric" yaml:"metric"`
+	// From is the RefID of the query or expression whose result is written.
+	From string `json:"from" yaml:"from"`
+	// TargetDatasourceUID is the UID of the Prometheus data source the series is written to. If it is empty,
+	// the series is written to the remote write endpoint configured in [unified_alerting.recording_rules].
+	TargetDatasourceUID string `json:"target_datasource_uid,omitempty" yaml:"target_datasource_uid,omitempty"`
+}
+
+// IsZero returns true if the record is not set, i.e. the rule is not a recording rule.
+func (r Record) IsZero() bool {
+	return r == Record{}
+}
+
+// Validate checks that the metric name is a valid Prometheus metric name and that the result to record is set.
+func (r Record) Validate() error {
+	if !prometheusModel.IsValidMetricName(prometheusModel.LabelValue(r.Metric)) {
+		return fmt.Errorf("%w: '%s' is not a valid metric name for a recording rule", ErrAlertRuleFailedValidation, r.Metric)
+	}
+	if r.From == "" {
+		return fmt.Errorf("%w: recording rule must specify the query or expression to record", ErrAlertRuleFailedValidation)
+	}
+	return nil
+}
+
+// FromDB loads the record stored in the database as JSON.
+// FromDB is part of the xorm Conversion interface.
+func (r *Record) FromDB(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, r)
+}
+
+// ToDB stores the record as JSON. Alert rules that are not recording rules store nothing.
+// ToDB is part of the xorm Conversion interface.
+func (r *Record) ToDB() ([]byte, error) {
+	if r.IsZero() {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+// IsRecordingRule returns true if the rule is a recording rule.
+func (alertRule *AlertRule) IsRecordingRule() bool {
+	return !alertRule.Record.IsZero()
 }
 
 // GetDashboardUID returns the DashboardUID or "".
@@ -197,7 +291,14 @@ func (alertRule *AlertRule) GetLabels(opts ...LabelOption) map[string]string {
 	return labels
 }
 
+// GetEvalCondition returns the condition to evaluate. For recording rules, this is the query or expression to record.
 func (alertRule *AlertRule) GetEvalCondition() Condition {
+	if alertRule.IsRecordingRule() {
+		return Condition{
+			Condition: alertRule.Record.From,
+			Data:      alertRule.Data,
+		}
+	}
 	return Condition{
 		Condition: alertRule.Condition,
 		Data:      alertRule.Data,
@@ -332,9 +433,11 @@ type AlertRuleVersion struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
+	For          time.Duration
+	Annotations  map[string]string
+	Labels       map[string]string
+	Record       Record `xorm:"record"`
+	Dependencies []RuleDependency
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -426,18 +529,46 @@ func (c Condition) IsValid() bool {
 	return len(c.Data) != 0
 }
 
+// WithLoadedDimensions returns a copy of the condition in which every threshold expression with a recovery threshold
+// knows the labels of the alert instances that were firing at the previous evaluation.
+// The condition is returned unchanged if it does not have such an expression.
+func (c Condition) WithLoadedDimensions(loadedDimensions []data.Labels) (Condition, error) {
+	var queries []AlertQuery
+	for i := range c.Data {
+		if !c.Data[i].IsHysteresisExpression() {
+			continue
+		}
+		if queries == nil {
+			queries = make([]AlertQuery, len(c.Data))
+			copy(queries, c.Data)
+		}
+		if err := queries[i].PatchHysteresisExpression(loadedDimensions); err != nil {
+			return Condition{}, err
+		}
+	}
+	if queries == nil {
+		return c, nil
+	}
+	return Condition{Condition: c.Condition, Data: queries}, nil
+}
+
 // PatchPartialAlertRule patches `ruleToPatch` by `existingRule` following the rule that if a field of `ruleToPatch` is empty or has the default value, it is populated by the value of the corresponding field from `existingRule`.
 // There are several exceptions:
-// 1. Following fields are not patched and therefore will be ignored: AlertRule.ID, AlertRule.OrgID, AlertRule.Updated, AlertRule.Version, AlertRule.UID, AlertRule.DashboardUID, AlertRule.PanelID, AlertRule.Annotations and AlertRule.Labels
+// 1. Following fields are not patched and therefore will be ignored: AlertRule.ID, AlertRule.OrgID, AlertRule.Updated, AlertRule.Version, AlertRule.UID, AlertRule.DashboardUID, AlertRule.PanelID, AlertRule.Annotations, AlertRule.Labels and AlertRule.Dependencies
 // 2. There are fields that are patched together:
 //   - AlertRule.Condition and AlertRule.Data
 //
 // If either of the pair is specified, neither is patched.
+// 3. AlertRule.Record is patched if neither it nor AlertRule.Condition is specified. A recording rule does not have a condition,
+// so only AlertRule.Data is patched for it.
 func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRule) {
 	if ruleToPatch.Title == "" {
 		ruleToPatch.Title = existingRule.Title
 	}
-	if ruleToPatch.Condition == "" || len(ruleToPatch.Data) == 0 {
+	if !ruleToPatch.IsRecordingRule() && ruleToPatch.Condition == "" {
+		ruleToPatch.Record = existingRule.Record
+	}
+	if len(ruleToPatch.Data) == 0 || (ruleToPatch.Condition == "" && !ruleToPatch.IsRecordingRule()) {
 		ruleToPatch.Condition = existingRule.Condition
 		ruleToPatch.Data = existingRule.Data
 	}
@@ -10,10 +10,12 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -226,6 +228,23 @@ func TestPatchPartialAlertRule(t *testing.T) {
 		}
 	})
 
+	t.Run("patches the record of recording rules", func(t *testing.T) {
+		existing := AlertRuleGen(WithRecord("test:recorded"))()
+
+		patch := *existing
+		patch.Record = Record{}
+		patch.Data = nil
+		PatchPartialAlertRule(existing, &patch)
+		require.Equal(t, *existing, patch)
+
+		patch = *existing
+		patch.Record = Record{}
+		patch.Data = []AlertQuery{GenerateAlertQuery()}
+		PatchPartialAlertRule(existing, &patch)
+		require.Equal(t, existing.Record, patch.Record)
+		require.NotEqual(t, existing.Data, patch.Data)
+	})
+
 	t.Run("does not patch", func(t *testing.T) {
 		testCases := []struct {
 			name    string
@@ -310,6 +329,54 @@ func TestPatchPartialAlertRule(t *testing.T) {
 	})
 }
 
+func TestRecord(t *testing.T) {
+	t.Run("is stored as JSON", func(t *testing.T) {
+		record := Record{Metric: "test:recorded", From: "A", TargetDatasourceUID: "prom"}
+		b, err := record.ToDB()
+		require.NoError(t, err)
+		var loaded Record
+		require.NoError(t, loaded.FromDB(b))
+		require.Equal(t, record, loaded)
+	})
+
+	t.Run("is not stored for alerting rules", func(t *testing.T) {
+		var record Record
+		b, err := record.ToDB()
+		require.NoError(t, err)
+		require.Empty(t, b)
+		require.NoError(t, record.FromDB(b))
+		require.True(t, record.IsZero())
+	})
+
+	t.Run("validates the metric name", func(t *testing.T) {
+		require.NoError(t, Record{Metric: "job:up:sum", From: "A"}.Validate())
+		require.ErrorIs(t, Record{Metric: "1up", From: "A"}.Validate(), ErrAlertRuleFailedValidation)
+		require.ErrorIs(t, Record{Metric: "up"}.Validate(), ErrAlertRuleFailedValidation)
+	})
+}
+
+func TestRuleDependency(t *testing.T) {
+	t.Run("validates the rule UID and the label names", func(t *testing.T) {
+		require.NoError(t, RuleDependency{RuleUID: "dc-down", Equal: []string{"datacenter"}}.Validate())
+		require.ErrorIs(t, RuleDependency{Equal: []string{"datacenter"}}.Validate(), ErrAlertRuleFailedValidation)
+		require.ErrorIs(t, RuleDependency{RuleUID: "dc-down", Equal: []string{"data-center"}}.Validate(), ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("inhibits alert instances with the same values of the equal labels", func(t *testing.T) {
+		d := RuleDependency{RuleUID: "dc-down", Equal: []string{"datacenter", "zone"}}
+		firing := data.Labels{"alertname": "DatacenterDown", "datacenter": "eu-1"}
+
+		require.True(t, d.Inhibits(firing, data.Labels{"alertname": "HighLatency", "datacenter": "eu-1"}))
+		require.False(t, d.Inhibits(firing, data.Labels{"alertname": "HighLatency", "datacenter": "us-1"}))
+		require.False(t, d.Inhibits(firing, data.Labels{"alertname": "HighLatency", "datacenter": "eu-1", "zone": "a"}))
+	})
+
+	t.Run("inhibits all alert instances without equal labels", func(t *testing.T) {
+		d := RuleDependency{RuleUID: "dc-down"}
+		require.True(t, d.Inhibits(data.Labels{"datacenter": "eu-1"}, data.Labels{"datacenter": "us-1"}))
+	})
+}
+
 func TestDiff(t *testing.T) {
 	t.Run("should return nil if there is no diff", func(t *testing.T) {
 		rule1 := AlertRuleGen()()
@@ -677,3 +744,58 @@ func TestTimeRangeYAML(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, yamlRaw, string(serialized))
 }
+
+func TestConditionWithLoadedDimensions(t *testing.T) {
+	query := AlertQuery{
+		RefID:         "A",
+		DatasourceUID: "datasource",
+		Model:         json.RawMessage(`{"expr": "up"}`),
+	}
+	threshold := AlertQuery{
+		RefID:         "B",
+		DatasourceUID: expr.DatasourceUID,
+		Model: json.RawMessage(`{
+			"type": "threshold",
+			"expression": "A",
+			"conditions": [{
+				"evaluator": {"type": "gt", "params": [90]},
+				"unloadEvaluator": {"type": "lt", "params": [80]}
+			}]
+		}`),
+	}
+	loaded := []data.Labels{{"host": "a"}}
+
+	t.Run("should patch threshold expressions with a recovery threshold", func(t *testing.T) {
+		condition := Condition{Condition: "B", Data: []AlertQuery{query, threshold}}
+		patched, err := condition.WithLoadedDimensions(loaded)
+		require.NoError(t, err)
+		require.Equal(t, "B", patched.Condition)
+		require.Equal(t, query.Model, patched.Data[0].Model)
+
+		var model map[string]interface{}
+		require.NoError(t, json.Unmarshal(patched.Data[1].Model, &model))
+		require.Equal(t, []interface{}{map[string]interface{}{"host": "a"}}, model[expr.ThresholdLoadedDimensionsKey])
+
+		// The original condition is not changed.
+		require.NotContains(t, string(condition.Data[1].Model), expr.ThresholdLoadedDimensionsKey)
+		original, err := condition.Data[1].GetModel()
+		require.NoError(t, err)
+		require.NotContains(t, string(original), expr.ThresholdLoadedDimensionsKey)
+	})
+
+	t.Run("should return the condition unchanged if it has no recovery threshold", func(t *testing.T) {
+		condition := Condition{Condition: "A", Data: []AlertQuery{query}}
+		patched, err := condition.WithLoadedDimensions(loaded)
+		require.NoError(t, err)
+		require.Equal(t, condition, patched)
+	})
+
+	t.Run("should not write to the queries of the condition", func(t *testing.T) {
+		condition := Condition{Condition: "B", Data: []AlertQuery{query, threshold}}
+		_, err := condition.WithLoadedDimensions(loaded)
+		require.NoError(t, err)
+		// The queries are shared by concurrent evaluations of the rule, so the cached model must not be set.
+		require.Nil(t, condition.Data[0].modelProps)
+		require.Nil(t, condition.Data[1].modelProps)
+	})
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// HistoryQuery represents a query for alert state history.
+type HistoryQuery struct {
+	RuleUID string
+	OrgID   int64
+	// Labels restricts the results to instances that have all of the given labels.
+	Labels map[string]string
+	// States restricts the results to the periods in which instances were in one of the given states, such as Alerting or Normal.
+	States []string
+	From   time.Time
+	To     time.Time
+	// Limit is the maximum number of state transitions to read. Zero uses the default limit of the backend.
+	Limit        int
+	SignedInUser *user.SignedInUser
+}
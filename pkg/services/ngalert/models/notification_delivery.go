@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	NotificationDeliverySuccess = "success"
+	NotificationDeliveryFailed  = "failed"
+)
+
+// NotificationDelivery is an attempt to deliver a notification to an integration of a contact point.
+type NotificationDelivery struct {
+	ID              int64  `xorm:"pk autoincr 'id'"`
+	OrgID           int64  `xorm:"org_id"`
+	Receiver        string `xorm:"receiver"`
+	IntegrationUID  string `xorm:"integration_uid"`
+	IntegrationName string `xorm:"integration_name"`
+	IntegrationType string `xorm:"integration_type"`
+	GroupKey        string `xorm:"group_key"`
+	// AlertFingerprints are the fingerprints of the alerts in the notification.
+	AlertFingerprints []string `xorm:"alert_fingerprints"`
+	Firing            int      `xorm:"firing"`
+	Resolved          int      `xorm:"resolved"`
+	// Attempt is the number of the attempt, starting at 1. Failed attempts are retried until the notification is
+	// delivered, the error is not recoverable or the next notification is due.
+	Attempt int    `xorm:"attempt"`
+	Status  string `xorm:"status"`
+	// StatusCode is the status code of the HTTP response of the integration. It is zero if the integration does not
+	// send a webhook or if no response was received.
+	StatusCode int           `xorm:"status_code"`
+	Error      string        `xorm:"error"`
+	Duration   time.Duration `xorm:"duration"`
+	// SentAt is the time of the attempt in Unix epoch milliseconds.
+	SentAt int64 `xorm:"sent_at"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (d *NotificationDelivery) TableName() string {
+	return "alert_notification_delivery"
+}
+
+// NotificationDeliveryQuery filters the notification deliveries of an organization.
+type NotificationDeliveryQuery struct {
+	OrgID int64
+	// Receiver restricts the results to a single contact point if set.
+	Receiver string
+	// IntegrationUID restricts the results to a single integration of a contact point if set.
+	IntegrationUID string
+	// Status restricts the results to successful or failed attempts if set.
+	Status string
+	// AlertFingerprint restricts the results to the notifications that contain the alert if set.
+	AlertFingerprint string
+	From             time.Time
+	To               time.Time
+	// Limit is the maximum number of attempts to return. Zero uses the default limit.
+	Limit int
+}
@@ -164,6 +164,21 @@ func WithFor(duration time.Duration) AlertRuleMutator {
 	}
 }
 
+// WithRecord makes the rule a recording rule that records the result of its condition.
+func WithRecord(metric string) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.Record = Record{Metric: metric, From: rule.Condition}
+		rule.Condition = ""
+	}
+}
+
+// WithDependencies makes the alert instances of the rule inhibited by the firing alert instances of the given rules.
+func WithDependencies(dependencies ...RuleDependency) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.Dependencies = dependencies
+	}
+}
+
 func GenerateAlertLabels(count int, prefix string) data.Labels {
 	labels := make(data.Labels, count)
 	for i := 0; i < count; i++ {
@@ -256,6 +271,7 @@ func CopyRule(r *AlertRule) *AlertRule {
 		NoDataState:     r.NoDataState,
 		ExecErrState:    r.ExecErrState,
 		For:             r.For,
+		Record:          r.Record,
 	}
 
 	if r.DashboardUID != nil {
@@ -286,6 +302,13 @@ func CopyRule(r *AlertRule) *AlertRule {
 		}
 	}
 
+	for _, d := range r.Dependencies {
+		result.Dependencies = append(result.Dependencies, RuleDependency{
+			RuleUID: d.RuleUID,
+			Equal:   append([]string(nil), d.Equal...),
+		})
+	}
+
 	if r.Labels != nil {
 		result.Labels = make(map[string]string, len(r.Labels))
 		for s, s2 := range r.Labels {
@@ -36,6 +36,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
@@ -124,6 +125,7 @@ type AlertNG struct {
 	imageService        image.ImageService
 	schedule            schedule.ScheduleService
 	stateManager        *state.Manager
+	historian           state.Historian
 	folderService       folder.Service
 	dashboardService    dashboards.DashboardService
 
@@ -202,10 +204,18 @@ func (ng *AlertNG) init() error {
 		RuleStore:            store,
 		Metrics:              ng.Metrics.GetSchedulerMetrics(),
 		AlertSender:          alertsRouter,
+		RecordingWriter:      writer.NewPrometheusWriter(ng.Cfg.UnifiedAlerting.RecordingRules, ng.DataSourceService),
 		Tracer:               ng.tracer,
 	}
+	if ng.Cfg.UnifiedAlerting.HAEvaluationSharding {
+		if peer, ok := ng.MultiOrgAlertmanager.ClusterPeer(); ok {
+			schedCfg.ClusterMembership = peer
+		} else {
+			ng.Log.Warn("The evaluation of alert rules is not sharded because high availability is not configured. Configure ha_peers to enable it")
+		}
+	}
 
-	history, err := configureHistorianBackend(ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService)
+	history, err := configureHistorianBackend(ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, store, ng.SQLStore)
 	if err != nil {
 		return err
 	}
@@ -227,6 +237,7 @@ func (ng *AlertNG) init() error {
 
 	ng.stateManager = stateManager
 	ng.schedule = scheduler
+	ng.historian = history
 
 	// Provisioning
 	policyService := provisioning.NewNotificationPolicyService(store, store, store, ng.Cfg.UnifiedAlerting, ng.Log)
@@ -237,6 +248,9 @@ func (ng *AlertNG) init() error {
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()), ng.Log)
 
+	// State history queries are rejected if the configured historian cannot read it back.
+	historyReader, _ := ng.historian.(state.HistoryReader)
+
 	api := api.API{
 		Cfg:                  ng.Cfg,
 		DatasourceCache:      ng.DataSourceCache,
@@ -252,6 +266,7 @@ func (ng *AlertNG) init() error {
 		ProvenanceStore:      store,
 		MultiOrgAlertmanager: ng.MultiOrgAlertmanager,
 		StateManager:         ng.stateManager,
+		Historian:            historyReader,
 		AccessControl:        ng.accesscontrol,
 		Policies:             policyService,
 		ContactPointService:  contactPointService,
@@ -332,6 +347,12 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 		return ng.AlertsRouter.Run(subCtx)
 	})
 
+	if sqlHistorian, ok := ng.historian.(*historian.SqlBackend); ok {
+		children.Go(func() error {
+			return sqlHistorian.Run(subCtx)
+		})
+	}
+
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
 		children.Go(func() error {
 			return ng.schedule.Run(subCtx)
@@ -377,19 +398,19 @@ func readQuotaConfig(cfg *setting.Cfg) (*quota.Map, error) {
 	return limits, nil
 }
 
-func configureHistorianBackend(cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService) (state.Historian, error) {
+func configureHistorianBackend(cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, sqlStore db.DB) (state.Historian, error) {
 	if !cfg.Enabled {
 		return historian.NewNopHistorian(), nil
 	}
 
 	if cfg.Backend == "annotations" {
-		return historian.NewAnnotationBackend(ar, ds), nil
+		return historian.NewAnnotationBackend(ar, ds, rs), nil
 	}
 	if cfg.Backend == "loki" {
 		return historian.NewRemoteLokiBackend(), nil
 	}
 	if cfg.Backend == "sql" {
-		return historian.NewSqlBackend(), nil
+		return historian.NewSqlBackend(sqlStore, cfg.SQLRetention), nil
 	}
 
 	return nil, fmt.Errorf("unrecognized state history backend: %s", cfg.Backend)
@@ -41,6 +41,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels_config"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/templates"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/setting"
@@ -91,6 +92,7 @@ type ClusterPeer interface {
 type AlertingStore interface {
 	store.AlertingStore
 	store.ImageStore
+	store.NotificationDeliveryStore
 }
 
 type Alertmanager struct {
@@ -340,7 +342,7 @@ func (am *Alertmanager) getTemplate() (*template.Template, error) {
 }
 
 func (am *Alertmanager) templateFromPaths(paths []string) (*template.Template, error) {
-	tmpl, err := template.FromGlobs(paths)
+	tmpl, err := template.FromGlobs(paths, templates.WithFuncs)
 	if err != nil {
 		return nil, err
 	}
@@ -485,7 +487,11 @@ func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableAp
 		if err != nil {
 			return nil, err
 		}
-		integrations = append(integrations, notify.NewIntegration(n, n, r.Type, i))
+		var notifier notify.Notifier = n
+		if am.Settings.UnifiedAlerting.NotificationDeliveryLog.Enabled {
+			notifier = &deliveryRecorder{notifier: n, am: am, receiver: receiver.Name, config: r}
+		}
+		integrations = append(integrations, notify.NewIntegration(notifier, n, r.Type, i))
 	}
 	return integrations, nil
 }
@@ -690,6 +696,9 @@ func (am *Alertmanager) createReceiverStage(name string, integrations []*notify.
 		var s notify.MultiStage
 		s = append(s, notify.NewWaitStage(wait))
 		s = append(s, notify.NewDedupStage(integration, notificationLog, recv))
+		if am.Settings.UnifiedAlerting.NotificationDeliveryLog.Enabled {
+			s = append(s, deliveryAttemptStage{})
+		}
 		s = append(s, notify.NewRetryStage(integration, name, am.stageMetrics))
 		s = append(s, notify.NewSetNotifiesStage(notificationLog, recv))
 
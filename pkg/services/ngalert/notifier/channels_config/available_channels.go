@@ -4,6 +4,8 @@ import (
 	"os"
 
 	"github.com/grafana/alerting/alerting/notifier/channels"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations"
 )
 
 // GetAvailableNotifiers returns the metadata of all the notification channels that can be configured.
@@ -795,6 +797,304 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "http",
+			Name:        "HTTP",
+			Description: "Sends an HTTP request in which the method, URL, headers, query parameters and body are templates",
+			Heading:     "HTTP settings",
+			Info:        "The method, URL, headers, query parameters and body are templates of the notification. Use toJson and jsonEscape to write JSON.",
+			Options: []NotifierOption{
+				{
+					Label:        "URL",
+					Description:  "Templated URL of the request.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "HTTP Method",
+					Description:  "Templated method of the request. One of GET, POST, PUT, PATCH or DELETE.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "httpMethod",
+					Placeholder:  "POST",
+				},
+				{
+					Label:        "Headers",
+					Description:  "Templated headers of the request, one 'Name: value' per line. Headers with an empty value are not sent. Use Secure Headers for credentials.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "headers",
+				},
+				{
+					Label:        "Secure Headers",
+					Description:  "Headers of the request that contain credentials, such as Authorization or X-Api-Key, one 'Name: value' per line. They are stored encrypted and are not templated.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "secureHeaders",
+					Secure:       true,
+				},
+				{
+					Label:        "Query Parameters",
+					Description:  "Templated query parameters of the request, one 'name: value' per line. Parameters with an empty value are not sent.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "queryParams",
+				},
+				{
+					Label:        "Body",
+					Description:  "Templated body of the request. Defaults to the notification data as JSON.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "body",
+					Placeholder:  integrations.DefaultHTTPBody,
+				},
+				{
+					Label:        "OAuth2 - Client ID",
+					Description:  "Client ID of the OAuth2 client credentials grant.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "oauth2ClientId",
+				},
+				{
+					Label:        "OAuth2 - Client Secret",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "oauth2ClientSecret",
+					Secure:       true,
+				},
+				{
+					Label:        "OAuth2 - Token URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "oauth2TokenUrl",
+				},
+				{
+					Label:        "OAuth2 - Scopes",
+					Description:  "Comma separated list of scopes.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "oauth2Scopes",
+				},
+				{
+					Label:        "TLS - CA Certificate",
+					Description:  "PEM encoded certificate of the CA that signed the certificate of the server.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "tlsCACertificate",
+				},
+				{
+					Label:        "TLS - Client Certificate",
+					Description:  "PEM encoded client certificate for mutual TLS.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "tlsClientCertificate",
+				},
+				{
+					Label:        "TLS - Client Key",
+					Description:  "PEM encoded key of the client certificate.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "tlsClientKey",
+					Secure:       true,
+				},
+				{
+					Label:        "TLS - Server Name",
+					Description:  "Server name to verify the certificate of the server with, if it is different from the host of the URL.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "tlsServerName",
+				},
+				{
+					Label:        "TLS - Skip Verify",
+					Description:  "Do not verify the certificate of the server.",
+					Element:      ElementTypeCheckbox,
+					PropertyName: "tlsInsecureSkipVerify",
+				},
+			},
+		},
+		{
+			Type:        "snmp",
+			Name:        "SNMP",
+			Description: "Sends an SNMPv2c trap per alert",
+			Heading:     "SNMP settings",
+			Info:        "The OID of the trap and the values of the variable bindings are templates of the alert.",
+			Options: []NotifierOption{
+				{
+					Label:        "Address",
+					Description:  "Host and port of the trap receiver. The port defaults to 162.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "nms.example.com:162",
+					PropertyName: "address",
+					Required:     true,
+				},
+				{
+					Label:        "Community",
+					Description:  "Defaults to public.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "community",
+					Secure:       true,
+				},
+				{
+					Label:        "Trap OID",
+					Description:  "Templated OID of the trap, sent as snmpTrapOID.0.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "1.3.6.1.4.1.99999.0.1",
+					PropertyName: "trapOid",
+					Required:     true,
+				},
+				{
+					Label:        "Variable Bindings",
+					Description:  "Templated values of the variable bindings, one 'OID: value' per line. The values are sent as octet strings.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "varbinds",
+				},
+			},
+		},
+		{
+			Type:        "syslog",
+			Name:        "Syslog",
+			Description: "Sends an RFC 5424 syslog message per alert",
+			Heading:     "Syslog settings",
+			Info:        "The facility and the severity of the messages are taken from the labels of the alerts.",
+			Options: []NotifierOption{
+				{
+					Label:        "Address",
+					Description:  "Host and port of the syslog server.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "syslog.example.com:514",
+					PropertyName: "address",
+					Required:     true,
+				},
+				{
+					Label:   "Network",
+					Element: ElementTypeSelect,
+					SelectOptions: []SelectOption{
+						{
+							Value: "udp",
+							Label: "UDP",
+						},
+						{
+							Value: "tcp",
+							Label: "TCP",
+						},
+						{
+							Value: "tls",
+							Label: "TLS",
+						},
+					},
+					PropertyName: "network",
+				},
+				{
+					Label:        "Message",
+					Description:  "Templated message.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "message",
+					Placeholder:  channels.DefaultMessageTitleEmbed,
+				},
+				{
+					Label:        "Facility",
+					Description:  "Facility of the messages, like local0.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "local0",
+					PropertyName: "facility",
+				},
+				{
+					Label:        "Facility Label",
+					Description:  "Label whose value is the facility of the message. Alerts without a valid value use the facility above.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "facilityLabel",
+				},
+				{
+					Label:        "Severity Label",
+					Description:  "Label whose value is the severity of the message.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "severity",
+					PropertyName: "severityLabel",
+				},
+				{
+					Label:        "Severity Mapping",
+					Description:  "Syslog severities of the values of the severity label, one 'value: severity' per line, like 'P1: crit'.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "severityMapping",
+				},
+				{
+					Label:        "Default Severity",
+					Description:  "Severity of the alerts whose severity label is missing or not mapped.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "warning",
+					PropertyName: "defaultSeverity",
+				},
+				{
+					Label:        "Hostname",
+					Description:  "Hostname in the messages. Defaults to the hostname of the Grafana server.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "hostname",
+				},
+				{
+					Label:        "App Name",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "grafana",
+					PropertyName: "appName",
+				},
+				{
+					Label:        "TLS - CA Certificate",
+					Description:  "PEM encoded certificate of the CA that signed the certificate of the server.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "tlsCACertificate",
+					ShowWhen: ShowWhen{
+						Field: "network",
+						Is:    "tls",
+					},
+				},
+				{
+					Label:        "TLS - Client Certificate",
+					Description:  "PEM encoded client certificate for mutual TLS.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "tlsClientCertificate",
+					ShowWhen: ShowWhen{
+						Field: "network",
+						Is:    "tls",
+					},
+				},
+				{
+					Label:        "TLS - Client Key",
+					Description:  "PEM encoded key of the client certificate.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "tlsClientKey",
+					Secure:       true,
+					ShowWhen: ShowWhen{
+						Field: "network",
+						Is:    "tls",
+					},
+				},
+				{
+					Label:        "TLS - Server Name",
+					Description:  "Server name to verify the certificate of the server with, if it is different from the host of the address.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "tlsServerName",
+					ShowWhen: ShowWhen{
+						Field: "network",
+						Is:    "tls",
+					},
+				},
+				{
+					Label:        "TLS - Skip Verify",
+					Description:  "Do not verify the certificate of the server.",
+					Element:      ElementTypeCheckbox,
+					PropertyName: "tlsInsecureSkipVerify",
+					ShowWhen: ShowWhen{
+						Field: "network",
+						Is:    "tls",
+					},
+				},
+			},
+		},
 		{
 			Type:        "wecom",
 			Name:        "WeCom",
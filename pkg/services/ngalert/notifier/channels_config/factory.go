@@ -4,6 +4,8 @@ import (
 	"strings"
 
 	"github.com/grafana/alerting/alerting/notifier/channels"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations"
 )
 
 var receiverFactories = map[string]func(channels.FactoryConfig) (channels.NotificationChannel, error){
@@ -12,6 +14,7 @@ var receiverFactories = map[string]func(channels.FactoryConfig) (channels.Notifi
 	"discord":                 channels.DiscordFactory,
 	"email":                   channels.EmailFactory,
 	"googlechat":              channels.GoogleChatFactory,
+	"http":                    integrations.HTTPFactory,
 	"kafka":                   channels.KafkaFactory,
 	"line":                    channels.LineFactory,
 	"opsgenie":                channels.OpsgenieFactory,
@@ -19,6 +22,8 @@ var receiverFactories = map[string]func(channels.FactoryConfig) (channels.Notifi
 	"pushover":                channels.PushoverFactory,
 	"sensugo":                 channels.SensuGoFactory,
 	"slack":                   channels.SlackFactory,
+	"snmp":                    integrations.SNMPFactory,
+	"syslog":                  integrations.SyslogFactory,
 	"teams":                   channels.TeamsFactory,
 	"telegram":                channels.TelegramFactory,
 	"threema":                 channels.ThreemaFactory,
@@ -0,0 +1,134 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// deliverySaveTimeout is the timeout of saving an attempt to deliver a notification. The attempt is saved with its
+	// own timeout, so that attempts that fail because the notification pipeline has timed out are saved too.
+	deliverySaveTimeout = 5 * time.Second
+	// deliveryLogRetentionInterval controls how often the attempts older than the retention period are deleted.
+	deliveryLogRetentionInterval = 10 * time.Minute
+)
+
+// ErrDeliveryLogDisabled is returned when notification deliveries are queried but the delivery log is disabled.
+var ErrDeliveryLogDisabled = errors.New("the notification delivery log is disabled")
+
+type deliveryContextKey int
+
+const (
+	deliveryAttemptKey deliveryContextKey = iota
+	deliveryStatusCodeKey
+)
+
+// deliveryAttemptStage counts the attempts of the retry stage that follows it, so that every attempt can be
+// recorded with its number.
+type deliveryAttemptStage struct{}
+
+func (deliveryAttemptStage) Exec(ctx context.Context, _ log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	return context.WithValue(ctx, deliveryAttemptKey, new(int)), alerts, nil
+}
+
+// withStatusCodeRecorder wraps the validation of a webhook response so that the status code of the response is
+// recorded in the delivery log. It returns the validation unchanged if the attempt is not recorded.
+func withStatusCodeRecorder(ctx context.Context, validation func(body []byte, statusCode int) error) func(body []byte, statusCode int) error {
+	code, ok := ctx.Value(deliveryStatusCodeKey).(*int)
+	if !ok {
+		return validation
+	}
+	return func(body []byte, statusCode int) error {
+		*code = statusCode
+		if validation != nil {
+			return validation(body, statusCode)
+		}
+		return nil
+	}
+}
+
+// deliveryRecorder is a notifier that saves every attempt to deliver a notification in the delivery log.
+type deliveryRecorder struct {
+	notifier notify.Notifier
+	am       *Alertmanager
+	receiver string
+	config   *apimodels.PostableGrafanaReceiver
+}
+
+func (r *deliveryRecorder) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	attempt := 1
+	if n, ok := ctx.Value(deliveryAttemptKey).(*int); ok {
+		*n++
+		attempt = *n
+	}
+	var statusCode int
+	ctx = context.WithValue(ctx, deliveryStatusCodeKey, &statusCode)
+
+	start := time.Now()
+	retry, err := r.notifier.Notify(ctx, alerts...)
+
+	groupKey, _ := notify.GroupKey(ctx)
+	delivery := &models.NotificationDelivery{
+		OrgID:             r.am.orgID,
+		Receiver:          r.receiver,
+		IntegrationUID:    r.config.UID,
+		IntegrationName:   r.config.Name,
+		IntegrationType:   r.config.Type,
+		GroupKey:          groupKey,
+		AlertFingerprints: make([]string, 0, len(alerts)),
+		Attempt:           attempt,
+		Status:            models.NotificationDeliverySuccess,
+		StatusCode:        statusCode,
+		Duration:          time.Since(start),
+		SentAt:            start.UnixMilli(),
+	}
+	for _, a := range alerts {
+		delivery.AlertFingerprints = append(delivery.AlertFingerprints, a.Fingerprint().String())
+		if a.Resolved() {
+			delivery.Resolved++
+		} else {
+			delivery.Firing++
+		}
+	}
+	if err != nil {
+		delivery.Status = models.NotificationDeliveryFailed
+		delivery.Error = err.Error()
+	}
+
+	saveCtx, cancel := context.WithTimeout(context.Background(), deliverySaveTimeout)
+	defer cancel()
+	if saveErr := r.am.Store.SaveNotificationDelivery(saveCtx, delivery); saveErr != nil {
+		r.am.logger.Error("Failed to save notification delivery", "receiver", r.receiver, "integration", r.config.UID, "error", saveErr)
+	}
+
+	return retry, err
+}
+
+// GetNotificationDeliveries returns the attempts to deliver notifications of the organization that match the query.
+func (am *Alertmanager) GetNotificationDeliveries(ctx context.Context, query models.NotificationDeliveryQuery) ([]models.NotificationDelivery, error) {
+	if !am.Settings.UnifiedAlerting.NotificationDeliveryLog.Enabled {
+		return nil, ErrDeliveryLogDisabled
+	}
+	query.OrgID = am.orgID
+	return am.Store.GetNotificationDeliveries(ctx, query)
+}
+
+// deleteExpiredNotificationDeliveries deletes the attempts to deliver notifications of all organizations that are
+// older than the retention period.
+func (moa *MultiOrgAlertmanager) deleteExpiredNotificationDeliveries(ctx context.Context) {
+	retention := moa.settings.UnifiedAlerting.NotificationDeliveryLog.Retention
+	n, err := moa.configStore.DeleteNotificationDeliveriesBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		moa.logger.Error("Failed to delete expired notification deliveries", "error", err)
+		return
+	}
+	moa.logger.Debug("Deleted expired notification deliveries", "count", n)
+}
@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestDeliveryRecorder(t *testing.T) {
+	am := setupAMTest(t)
+
+	t.Run("the deliveries cannot be queried if the delivery log is disabled", func(t *testing.T) {
+		_, err := am.GetNotificationDeliveries(context.Background(), ngmodels.NotificationDeliveryQuery{})
+		require.ErrorIs(t, err, ErrDeliveryLogDisabled)
+	})
+
+	am.Settings.UnifiedAlerting.NotificationDeliveryLog.Enabled = true
+
+	firing := &types.Alert{Alert: model.Alert{
+		Labels:   model.LabelSet{"alertname": "firing"},
+		StartsAt: time.Now().Add(-time.Minute),
+	}}
+	resolved := &types.Alert{Alert: model.Alert{
+		Labels:   model.LabelSet{"alertname": "resolved"},
+		StartsAt: time.Now().Add(-time.Hour),
+		EndsAt:   time.Now().Add(-time.Minute),
+	}}
+
+	// the notifier fails with a server error the first time and succeeds the second time.
+	responses := []int{http.StatusInternalServerError, http.StatusOK}
+	n := notifierFunc(func(ctx context.Context, _ ...*types.Alert) (bool, error) {
+		code := responses[0]
+		responses = responses[1:]
+		if err := withStatusCodeRecorder(ctx, nil)(nil, code); err != nil {
+			return false, err
+		}
+		if code != http.StatusOK {
+			return true, errors.New("webhook response status 500")
+		}
+		return false, nil
+	})
+	recorder := &deliveryRecorder{
+		notifier: n,
+		am:       am,
+		receiver: "team-a",
+		config:   &apimodels.PostableGrafanaReceiver{UID: "webhook-uid", Name: "webhook", Type: "webhook"},
+	}
+
+	ctx := notify.WithGroupKey(context.Background(), "group")
+	ctx, _, err := deliveryAttemptStage{}.Exec(ctx, nil, firing, resolved)
+	require.NoError(t, err)
+
+	retry, err := recorder.Notify(ctx, firing, resolved)
+	require.True(t, retry)
+	require.Error(t, err)
+	retry, err = recorder.Notify(ctx, firing, resolved)
+	require.False(t, retry)
+	require.NoError(t, err)
+
+	deliveries, err := am.GetNotificationDeliveries(context.Background(), ngmodels.NotificationDeliveryQuery{Receiver: "team-a"})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+
+	t.Run("every attempt is recorded with its number", func(t *testing.T) {
+		require.Equal(t, 2, deliveries[0].Attempt)
+		require.Equal(t, ngmodels.NotificationDeliverySuccess, deliveries[0].Status)
+		require.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+		require.Empty(t, deliveries[0].Error)
+
+		require.Equal(t, 1, deliveries[1].Attempt)
+		require.Equal(t, ngmodels.NotificationDeliveryFailed, deliveries[1].Status)
+		require.Equal(t, http.StatusInternalServerError, deliveries[1].StatusCode)
+		require.Equal(t, "webhook response status 500", deliveries[1].Error)
+	})
+
+	t.Run("the integration and the alerts of the notification are recorded", func(t *testing.T) {
+		d := deliveries[0]
+		require.Equal(t, int64(1), d.OrgID)
+		require.Equal(t, "team-a", d.Receiver)
+		require.Equal(t, "webhook-uid", d.IntegrationUID)
+		require.Equal(t, "webhook", d.IntegrationName)
+		require.Equal(t, "webhook", d.IntegrationType)
+		require.Equal(t, "group", d.GroupKey)
+		require.Equal(t, []string{firing.Fingerprint().String(), resolved.Fingerprint().String()}, d.AlertFingerprints)
+		require.Equal(t, 1, d.Firing)
+		require.Equal(t, 1, d.Resolved)
+	})
+}
+
+type notifierFunc func(ctx context.Context, alerts ...*types.Alert) (bool, error)
+
+func (f notifierFunc) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	return f(ctx, alerts...)
+}
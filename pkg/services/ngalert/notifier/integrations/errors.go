@@ -0,0 +1,30 @@
+package integrations
+
+import (
+	"fmt"
+
+	"github.com/grafana/alerting/alerting/notifier/channels"
+)
+
+// receiverInitError is returned by the factories if the settings of the integration are invalid.
+type receiverInitError struct {
+	Reason string
+	Err    error
+	Cfg    channels.NotificationChannelConfig
+}
+
+func (e receiverInitError) Error() string {
+	name := ""
+	if e.Cfg.Name != "" {
+		name = fmt.Sprintf("%q ", e.Cfg.Name)
+	}
+
+	s := fmt.Sprintf("failed to validate receiver %sof type %q: %s", name, e.Cfg.Type, e.Reason)
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", s, e.Err.Error())
+	}
+
+	return s
+}
+
+func (e receiverInitError) Unwrap() error { return e.Err }
@@ -0,0 +1,289 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/alerting/alerting/notifier/channels"
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// DefaultHTTPBody is the body of the request if the body is not configured. It is the template data as JSON.
+const DefaultHTTPBody = `{{ toJson . }}`
+
+// httpMethods are the methods that the HTTP contact point can send requests with.
+var httpMethods = map[string]struct{}{
+	http.MethodGet:    {},
+	http.MethodPost:   {},
+	http.MethodPut:    {},
+	http.MethodPatch:  {},
+	http.MethodDelete: {},
+}
+
+// HTTPNotifier sends a request in which the method, URL, headers, query parameters and body are templates of
+// the notification.
+type HTTPNotifier struct {
+	*channels.Base
+	log      channels.Logger
+	tmpl     *template.Template
+	client   *http.Client
+	settings httpSettings
+}
+
+type httpSettings struct {
+	URL         string
+	HTTPMethod  string
+	Headers     map[string]string
+	QueryParams map[string]string
+	Body        string
+	// SecureHeaders are the headers that contain credentials. They are not templated.
+	SecureHeaders map[string]string
+
+	OAuth2 *clientcredentials.Config
+	TLS    *sdkhttpclient.TLSOptions
+}
+
+func buildHTTPSettings(fc channels.FactoryConfig) (httpSettings, error) {
+	settings := httpSettings{}
+	rawSettings := struct {
+		URL                string                         `json:"url,omitempty" yaml:"url,omitempty"`
+		HTTPMethod         string                         `json:"httpMethod,omitempty" yaml:"httpMethod,omitempty"`
+		Headers            settingPairs                   `json:"headers,omitempty" yaml:"headers,omitempty"`
+		QueryParams        settingPairs                   `json:"queryParams,omitempty" yaml:"queryParams,omitempty"`
+		Body               string                         `json:"body,omitempty" yaml:"body,omitempty"`
+		SecureHeaders      string                         `json:"secureHeaders,omitempty" yaml:"secureHeaders,omitempty"`
+		OAuth2ClientID     string                         `json:"oauth2ClientId,omitempty" yaml:"oauth2ClientId,omitempty"`
+		OAuth2ClientSecret string                         `json:"oauth2ClientSecret,omitempty" yaml:"oauth2ClientSecret,omitempty"`
+		OAuth2TokenURL     string                         `json:"oauth2TokenUrl,omitempty" yaml:"oauth2TokenUrl,omitempty"`
+		OAuth2Scopes       channels.CommaSeparatedStrings `json:"oauth2Scopes,omitempty" yaml:"oauth2Scopes,omitempty"`
+		rawTLSSettings
+	}{}
+
+	if err := json.Unmarshal(fc.Config.Settings, &rawSettings); err != nil {
+		return settings, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if rawSettings.URL == "" {
+		return settings, errors.New("required field 'url' is not specified")
+	}
+	settings.URL = rawSettings.URL
+	settings.HTTPMethod = rawSettings.HTTPMethod
+	if settings.HTTPMethod == "" {
+		settings.HTTPMethod = http.MethodPost
+	}
+	settings.Headers = rawSettings.Headers
+	settings.QueryParams = rawSettings.QueryParams
+	settings.Body = rawSettings.Body
+	if settings.Body == "" {
+		settings.Body = DefaultHTTPBody
+	}
+
+	secureHeaders, err := parseSettingPairs(fc.DecryptFunc(context.Background(), fc.Config.SecureSettings, "secureHeaders", rawSettings.SecureHeaders))
+	if err != nil {
+		return settings, fmt.Errorf("invalid 'secureHeaders': %w", err)
+	}
+	for name := range secureHeaders {
+		for other := range settings.Headers {
+			if http.CanonicalHeaderKey(name) == http.CanonicalHeaderKey(other) {
+				return settings, fmt.Errorf("header %q is set in both 'headers' and 'secureHeaders'", name)
+			}
+		}
+	}
+	settings.SecureHeaders = secureHeaders
+
+	clientSecret := fc.DecryptFunc(context.Background(), fc.Config.SecureSettings, "oauth2ClientSecret", rawSettings.OAuth2ClientSecret)
+	if rawSettings.OAuth2ClientID != "" || rawSettings.OAuth2TokenURL != "" || clientSecret != "" {
+		if rawSettings.OAuth2ClientID == "" || rawSettings.OAuth2TokenURL == "" || clientSecret == "" {
+			return settings, errors.New("OAuth2 requires 'oauth2ClientId', 'oauth2ClientSecret' and 'oauth2TokenUrl'")
+		}
+		for name := range settings.SecureHeaders {
+			if http.CanonicalHeaderKey(name) == "Authorization" {
+				return settings, errors.New("'secureHeaders' cannot set the Authorization header if OAuth2 is configured")
+			}
+		}
+		settings.OAuth2 = &clientcredentials.Config{
+			ClientID:     rawSettings.OAuth2ClientID,
+			ClientSecret: clientSecret,
+			TokenURL:     rawSettings.OAuth2TokenURL,
+			Scopes:       rawSettings.OAuth2Scopes,
+		}
+	}
+
+	tlsOptions, err := rawSettings.tlsOptions(fc)
+	if err != nil {
+		return settings, err
+	}
+	settings.TLS = tlsOptions
+	return settings, nil
+}
+
+// HTTPFactory is the factory of the HTTP contact point.
+func HTTPFactory(fc channels.FactoryConfig) (channels.NotificationChannel, error) {
+	notifier, err := buildHTTPNotifier(fc)
+	if err != nil {
+		return nil, receiverInitError{
+			Reason: err.Error(),
+			Cfg:    *fc.Config,
+		}
+	}
+	return notifier, nil
+}
+
+func buildHTTPNotifier(fc channels.FactoryConfig) (*HTTPNotifier, error) {
+	settings, err := buildHTTPSettings(fc)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newHTTPClient(settings)
+	if err != nil {
+		return nil, err
+	}
+	return &HTTPNotifier{
+		Base:     channels.NewBase(fc.Config),
+		log:      fc.Logger,
+		tmpl:     fc.Template,
+		client:   client,
+		settings: settings,
+	}, nil
+}
+
+// newHTTPClient returns the client that sends the requests. If OAuth2 is configured, the client gets a token
+// with the client credentials grant and refreshes it when it expires.
+func newHTTPClient(settings httpSettings) (*http.Client, error) {
+	tlsCfg, err := tlsConfig(settings.TLS)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+			Proxy:           http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout: 30 * time.Second,
+			}).DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+	}
+	if settings.OAuth2 == nil {
+		return client, nil
+	}
+	// The token is requested with the same client, so that the token endpoint can require mTLS as well.
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client)
+	oauthClient := settings.OAuth2.Client(ctx)
+	oauthClient.Timeout = client.Timeout
+	return oauthClient, nil
+}
+
+// Notify implements the Notifier interface.
+func (hn *HTTPNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	req, err := hn.buildRequest(ctx, as...)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := hn.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			hn.log.Warn("failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, err
+	}
+	if resp.StatusCode/100 == 2 {
+		hn.log.Debug("request succeeded", "statuscode", resp.Status)
+		return true, nil
+	}
+
+	hn.log.Debug("request failed", "statuscode", resp.Status, "body", string(body))
+	// Only server errors and rate limiting are retried, other client errors would fail again.
+	retry := resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected response status %v", resp.Status)
+}
+
+// buildRequest executes the templates of the request with the data of the notification.
+func (hn *HTTPNotifier) buildRequest(ctx context.Context, as ...*types.Alert) (*http.Request, error) {
+	var tmplErr error
+	tmpl, _ := channels.TmplText(ctx, hn.tmpl, as, hn.log, &tmplErr)
+
+	method := strings.ToUpper(strings.TrimSpace(tmpl(hn.settings.HTTPMethod)))
+	rawURL := strings.TrimSpace(tmpl(hn.settings.URL))
+	headers := executePairs(tmpl, hn.settings.Headers)
+	queryParams := executePairs(tmpl, hn.settings.QueryParams)
+	body := tmpl(hn.settings.Body)
+	if tmplErr != nil {
+		return nil, fmt.Errorf("failed to template request: %w", tmplErr)
+	}
+
+	if _, ok := httpMethods[method]; !ok {
+		return nil, fmt.Errorf("unsupported HTTP method %q", method)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid URL %q: scheme must be http or https", rawURL)
+	}
+	if len(queryParams) > 0 {
+		query := u.Query()
+		for name, value := range queryParams {
+			query.Set(name, value)
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	var bodyReader io.Reader
+	if strings.TrimSpace(body) != "" {
+		bodyReader = bytes.NewBufferString(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Grafana")
+	if bodyReader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	for name, value := range hn.settings.SecureHeaders {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
+func (hn *HTTPNotifier) SendResolved() bool {
+	return !hn.GetDisableResolveMessage()
+}
+
+// executePairs executes the templates of the values of the pairs. Pairs with an empty value are left out, so
+// that a header or query parameter can be made conditional.
+func executePairs(tmpl func(string) string, pairs map[string]string) map[string]string {
+	result := make(map[string]string, len(pairs))
+	for name, value := range pairs {
+		if v := strings.TrimSpace(tmpl(value)); v != "" {
+			result[name] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,386 @@
+package integrations
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/alerting/alerting/notifier/channels"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/templates"
+)
+
+func TestHTTPNotifierSettings(t *testing.T) {
+	testCases := []struct {
+		name           string
+		settings       string
+		secureSettings map[string][]byte
+		expInitError   string
+	}{
+		{
+			name:     "valid settings",
+			settings: `{"url": "https://example.com", "headers": {"X-Team": "{{ .CommonLabels.team }}"}}`,
+		},
+		{
+			name:     "headers as lines",
+			settings: `{"url": "https://example.com", "headers": "X-Team: {{ .CommonLabels.team }}\nX-Source: grafana"}`,
+		},
+		{
+			name:         "missing URL",
+			settings:     `{}`,
+			expInitError: `failed to validate receiver "http_testing" of type "http": required field 'url' is not specified`,
+		},
+		{
+			name:         "invalid header line",
+			settings:     `{"url": "https://example.com", "headers": "X-Team"}`,
+			expInitError: `failed to validate receiver "http_testing" of type "http": failed to unmarshal settings: invalid line "X-Team", expected 'name: value'`,
+		},
+		{
+			name:         "OAuth2 without client secret",
+			settings:     `{"url": "https://example.com", "oauth2ClientId": "grafana", "oauth2TokenUrl": "https://example.com/token"}`,
+			expInitError: `failed to validate receiver "http_testing" of type "http": OAuth2 requires 'oauth2ClientId', 'oauth2ClientSecret' and 'oauth2TokenUrl'`,
+		},
+		{
+			name:           "OAuth2 with secure client secret",
+			settings:       `{"url": "https://example.com", "oauth2ClientId": "grafana", "oauth2TokenUrl": "https://example.com/token"}`,
+			secureSettings: map[string][]byte{"oauth2ClientSecret": []byte("secret")},
+		},
+		{
+			name:           "secure headers",
+			settings:       `{"url": "https://example.com", "headers": {"X-Team": "a"}}`,
+			secureSettings: map[string][]byte{"secureHeaders": []byte("Authorization: Bearer token\nX-Api-Key: key")},
+		},
+		{
+			name:           "invalid secure header line",
+			settings:       `{"url": "https://example.com"}`,
+			secureSettings: map[string][]byte{"secureHeaders": []byte("Authorization")},
+			expInitError:   `failed to validate receiver "http_testing" of type "http": invalid 'secureHeaders': invalid line "Authorization", expected 'name: value'`,
+		},
+		{
+			name:           "header in both headers and secure headers",
+			settings:       `{"url": "https://example.com", "headers": {"x-api-key": "{{ .CommonLabels.key }}"}}`,
+			secureSettings: map[string][]byte{"secureHeaders": []byte("X-Api-Key: key")},
+			expInitError:   `failed to validate receiver "http_testing" of type "http": header "X-Api-Key" is set in both 'headers' and 'secureHeaders'`,
+		},
+		{
+			name:           "secure Authorization header with OAuth2",
+			settings:       `{"url": "https://example.com", "oauth2ClientId": "grafana", "oauth2TokenUrl": "https://example.com/token"}`,
+			secureSettings: map[string][]byte{"oauth2ClientSecret": []byte("secret"), "secureHeaders": []byte("Authorization: Bearer token")},
+			expInitError:   `failed to validate receiver "http_testing" of type "http": 'secureHeaders' cannot set the Authorization header if OAuth2 is configured`,
+		},
+		{
+			name:         "client certificate without key",
+			settings:     `{"url": "https://example.com", "tlsClientCertificate": "cert"}`,
+			expInitError: `failed to validate receiver "http_testing" of type "http": TLS client authentication requires both 'tlsClientCertificate' and 'tlsClientKey'`,
+		},
+		{
+			name:         "invalid CA certificate",
+			settings:     `{"url": "https://example.com", "tlsCACertificate": "invalid"}`,
+			expInitError: `failed to validate receiver "http_testing" of type "http": invalid TLS configuration: failed to parse TLS CA PEM certificate`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := HTTPFactory(newFactoryConfig(t, "http", tc.settings, tc.secureSettings))
+			if tc.expInitError != "" {
+				require.EqualError(t, err, tc.expInitError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestHTTPNotifier(t *testing.T) {
+	alerts := []*types.Alert{{
+		Alert: model.Alert{
+			Labels:      model.LabelSet{"alertname": "HighCPU", "team": "a"},
+			Annotations: model.LabelSet{"summary": `CPU is "high"`},
+		},
+	}}
+
+	type request struct {
+		method string
+		path   string
+		query  url.Values
+		header http.Header
+		body   string
+	}
+
+	testCases := []struct {
+		name       string
+		settings   string
+		expRequest request
+	}{
+		{
+			name:     "default settings",
+			settings: `{"url": "SERVER_URL/alerts"}`,
+			expRequest: request{
+				method: http.MethodPost,
+				path:   "/alerts",
+				query:  url.Values{},
+				header: http.Header{"Content-Type": {"application/json"}},
+			},
+		},
+		{
+			name: "templated request",
+			settings: `{
+				"url": "SERVER_URL/teams/{{ .CommonLabels.team }}/tickets",
+				"httpMethod": "{{ if eq .Status \"firing\" }}put{{ else }}patch{{ end }}",
+				"headers": {"X-Alert": "{{ .CommonLabels.alertname }}", "X-Empty": "{{ .CommonLabels.missing }}"},
+				"queryParams": "source: grafana\nstatus: {{ .Status }}",
+				"body": "{\"title\": \"{{ jsonEscape .CommonAnnotations.summary }}\", \"labels\": {{ toJson .CommonLabels }}}"
+			}`,
+			expRequest: request{
+				method: http.MethodPut,
+				path:   "/teams/a/tickets",
+				query:  url.Values{"source": {"grafana"}, "status": {"firing"}},
+				header: http.Header{"Content-Type": {"application/json"}, "X-Alert": {"HighCPU"}},
+				body:   `{"title": "CPU is \"high\"", "labels": {"alertname":"HighCPU","team":"a"}}`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var received request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				received = request{method: r.Method, path: r.URL.Path, query: r.URL.Query(), header: r.Header, body: string(body)}
+			}))
+			t.Cleanup(server.Close)
+
+			// The URL of the test server is only known now.
+			settings := strings.ReplaceAll(tc.settings, "SERVER_URL", server.URL)
+			notifier, err := HTTPFactory(newFactoryConfig(t, "http", settings, nil))
+			require.NoError(t, err)
+
+			ok, err := notifier.Notify(notifyContext(), alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.Equal(t, tc.expRequest.method, received.method)
+			require.Equal(t, tc.expRequest.path, received.path)
+			require.Equal(t, tc.expRequest.query, received.query)
+			for name := range tc.expRequest.header {
+				require.Equal(t, tc.expRequest.header.Get(name), received.header.Get(name))
+			}
+			require.Empty(t, received.header.Get("X-Empty"))
+			if tc.expRequest.body != "" {
+				require.Equal(t, tc.expRequest.body, received.body)
+				return
+			}
+			// The default body is the template data.
+			var data channels.ExtendedData
+			require.NoError(t, json.Unmarshal([]byte(received.body), &data))
+			require.Equal(t, "firing", data.Status)
+			require.Len(t, data.Alerts, 1)
+		})
+	}
+
+	t.Run("retries server errors but not client errors", func(t *testing.T) {
+		status := http.StatusInternalServerError
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(server.Close)
+
+		notifier, err := HTTPFactory(newFactoryConfig(t, "http", `{"url": "`+server.URL+`"}`, nil))
+		require.NoError(t, err)
+
+		retry, err := notifier.Notify(notifyContext(), alerts...)
+		require.EqualError(t, err, "unexpected response status 500 Internal Server Error")
+		require.True(t, retry)
+
+		status = http.StatusBadRequest
+		retry, err = notifier.Notify(notifyContext(), alerts...)
+		require.EqualError(t, err, "unexpected response status 400 Bad Request")
+		require.False(t, retry)
+	})
+
+	t.Run("fails if the templates fail", func(t *testing.T) {
+		notifier, err := HTTPFactory(newFactoryConfig(t, "http", `{"url": "https://example.com", "body": "{{ template \"missing\" . }}"}`, nil))
+		require.NoError(t, err)
+
+		retry, err := notifier.Notify(notifyContext(), alerts...)
+		require.ErrorContains(t, err, "failed to template request")
+		require.False(t, retry)
+	})
+
+	t.Run("fails if the method is not supported", func(t *testing.T) {
+		notifier, err := HTTPFactory(newFactoryConfig(t, "http", `{"url": "https://example.com", "httpMethod": "TRACE"}`, nil))
+		require.NoError(t, err)
+
+		retry, err := notifier.Notify(notifyContext(), alerts...)
+		require.EqualError(t, err, `unsupported HTTP method "TRACE"`)
+		require.False(t, retry)
+	})
+
+	t.Run("sends the secure headers without templating them", func(t *testing.T) {
+		var header http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header
+		}))
+		t.Cleanup(server.Close)
+
+		secureHeaders := "Authorization: Bearer {{ token }}\nX-Api-Key: key"
+		notifier, err := HTTPFactory(newFactoryConfig(t, "http", `{"url": "`+server.URL+`"}`, map[string][]byte{"secureHeaders": []byte(secureHeaders)}))
+		require.NoError(t, err)
+
+		ok, err := notifier.Notify(notifyContext(), alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "Bearer {{ token }}", header.Get("Authorization"))
+		require.Equal(t, "key", header.Get("X-Api-Key"))
+	})
+
+	t.Run("authenticates with OAuth2 client credentials", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+			require.Equal(t, "tickets", r.Form.Get("scope"))
+			user, password, ok := r.BasicAuth()
+			require.True(t, ok)
+			require.Equal(t, "grafana", user)
+			require.Equal(t, "secret", password)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "token", "token_type": "Bearer", "expires_in": 3600}`))
+		})
+		var authorization string
+		mux.HandleFunc("/alerts", func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+		})
+		server := httptest.NewServer(mux)
+		t.Cleanup(server.Close)
+
+		settings := `{"url": "` + server.URL + `/alerts", "oauth2ClientId": "grafana", "oauth2TokenUrl": "` + server.URL + `/token", "oauth2Scopes": "tickets"}`
+		notifier, err := HTTPFactory(newFactoryConfig(t, "http", settings, map[string][]byte{"oauth2ClientSecret": []byte("secret")}))
+		require.NoError(t, err)
+
+		ok, err := notifier.Notify(notifyContext(), alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "Bearer token", authorization)
+	})
+
+	t.Run("authenticates with a client certificate", func(t *testing.T) {
+		clientCert, clientKey := newTestCertificate(t)
+		pool := x509.NewCertPool()
+		require.True(t, pool.AppendCertsFromPEM([]byte(clientCert)))
+
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+		server.StartTLS()
+		t.Cleanup(server.Close)
+		caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+		settings, err := json.Marshal(map[string]string{
+			"url":                  server.URL,
+			"tlsCACertificate":     caCert,
+			"tlsClientCertificate": clientCert,
+		})
+		require.NoError(t, err)
+		notifier, err := HTTPFactory(newFactoryConfig(t, "http", string(settings), map[string][]byte{"tlsClientKey": []byte(clientKey)}))
+		require.NoError(t, err)
+
+		ok, err := notifier.Notify(notifyContext(), alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// Without the client certificate the server rejects the connection.
+		settings, err = json.Marshal(map[string]string{
+			"url":              server.URL,
+			"tlsCACertificate": caCert,
+		})
+		require.NoError(t, err)
+		notifier, err = HTTPFactory(newFactoryConfig(t, "http", string(settings), nil))
+		require.NoError(t, err)
+
+		retry, err := notifier.Notify(notifyContext(), alerts...)
+		require.Error(t, err)
+		require.True(t, retry)
+	})
+}
+
+func newFactoryConfig(t *testing.T, integrationType, settings string, secureSettings map[string][]byte) channels.FactoryConfig {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "default.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(channels.DefaultTemplateString), 0600))
+	tmpl, err := template.FromGlobs([]string{path}, templates.WithFuncs)
+	require.NoError(t, err)
+	tmpl.ExternalURL, err = url.Parse("http://localhost")
+	require.NoError(t, err)
+
+	return channels.FactoryConfig{
+		Config: &channels.NotificationChannelConfig{
+			Name:           integrationType + "_testing",
+			Type:           integrationType,
+			Settings:       json.RawMessage(settings),
+			SecureSettings: secureSettings,
+		},
+		DecryptFunc: func(ctx context.Context, sjd map[string][]byte, key string, fallback string) string {
+			if v, ok := sjd[key]; ok {
+				return string(v)
+			}
+			return fallback
+		},
+		Template: tmpl,
+		Logger:   &channels.FakeLogger{},
+	}
+}
+
+func notifyContext() context.Context {
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+	return notify.WithReceiverName(ctx, "my_receiver")
+}
+
+// newTestCertificate returns a PEM encoded self-signed certificate and its key.
+func newTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cert := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "grafana"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, cert, cert, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
@@ -0,0 +1,93 @@
+package integrations
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/alerting/alerting/notifier/channels"
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+)
+
+// settingPairs are the settings that map names to values, like headers. They are either a JSON object, or a
+// string with one pair per line in the form "name: value", as they are entered in the UI.
+type settingPairs map[string]string
+
+func (p *settingPairs) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		m := map[string]string{}
+		if err := json.Unmarshal(b, &m); err != nil {
+			return errors.New("must be an object or a string with one 'name: value' pair per line")
+		}
+		*p = m
+		return nil
+	}
+
+	m, err := parseSettingPairs(str)
+	if err != nil {
+		return err
+	}
+	*p = m
+	return nil
+}
+
+// parseSettingPairs parses one 'name: value' pair per line.
+func parseSettingPairs(str string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, line := range strings.Split(str, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid line %q, expected 'name: value'", line)
+		}
+		m[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return m, nil
+}
+
+// rawTLSSettings are the TLS settings of the integrations that connect to a server.
+type rawTLSSettings struct {
+	TLSCACertificate      string `json:"tlsCACertificate,omitempty" yaml:"tlsCACertificate,omitempty"`
+	TLSClientCertificate  string `json:"tlsClientCertificate,omitempty" yaml:"tlsClientCertificate,omitempty"`
+	TLSClientKey          string `json:"tlsClientKey,omitempty" yaml:"tlsClientKey,omitempty"`
+	TLSServerName         string `json:"tlsServerName,omitempty" yaml:"tlsServerName,omitempty"`
+	TLSInsecureSkipVerify bool   `json:"tlsInsecureSkipVerify,omitempty" yaml:"tlsInsecureSkipVerify,omitempty"`
+}
+
+// tlsOptions returns the TLS options of the settings, or nil if none of them are set. The client key is a
+// secure setting.
+func (s rawTLSSettings) tlsOptions(fc channels.FactoryConfig) (*sdkhttpclient.TLSOptions, error) {
+	clientKey := fc.DecryptFunc(context.Background(), fc.Config.SecureSettings, "tlsClientKey", s.TLSClientKey)
+	if (s.TLSClientCertificate == "") != (clientKey == "") {
+		return nil, errors.New("TLS client authentication requires both 'tlsClientCertificate' and 'tlsClientKey'")
+	}
+	if s.TLSCACertificate == "" && s.TLSClientCertificate == "" && s.TLSServerName == "" && !s.TLSInsecureSkipVerify {
+		return nil, nil
+	}
+	opts := &sdkhttpclient.TLSOptions{
+		CACertificate:      s.TLSCACertificate,
+		ClientCertificate:  s.TLSClientCertificate,
+		ClientKey:          clientKey,
+		ServerName:         s.TLSServerName,
+		InsecureSkipVerify: s.TLSInsecureSkipVerify,
+	}
+	// Check the certificates now rather than on the first notification.
+	if _, err := tlsConfig(opts); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+func tlsConfig(opts *sdkhttpclient.TLSOptions) (*tls.Config, error) {
+	cfg, err := sdkhttpclient.GetTLSConfig(sdkhttpclient.Options{TLS: opts})
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS configuration: %w", err)
+	}
+	return cfg, nil
+}
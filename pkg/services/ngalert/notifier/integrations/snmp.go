@@ -0,0 +1,279 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/alerting/alerting/notifier/channels"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+)
+
+const (
+	defaultSNMPPort      = "162"
+	defaultSNMPCommunity = "public"
+
+	// sysUpTimeOID and snmpTrapOID are the first two variable bindings of every SNMPv2 trap.
+	sysUpTimeOID = "1.3.6.1.2.1.1.3.0"
+	snmpTrapOID  = "1.3.6.1.6.3.1.1.4.1.0"
+)
+
+// The tags of the BER encoding of SNMP messages.
+const (
+	berInteger     = 0x02
+	berOctetString = 0x04
+	berOID         = 0x06
+	berSequence    = 0x30
+	berTimeTicks   = 0x43
+	berSNMPv2Trap  = 0xa7
+)
+
+// startTime is the start of the process. The uptime of the process is sent as sysUpTime in the traps.
+var startTime = time.Now()
+
+// SNMPNotifier sends an SNMPv2c trap per alert. The OID of the trap and the values of the variable bindings are
+// templates of the alert.
+type SNMPNotifier struct {
+	*channels.Base
+	log      channels.Logger
+	tmpl     *template.Template
+	settings snmpSettings
+}
+
+type snmpSettings struct {
+	Address   string
+	Community string
+	TrapOID   string
+	// VarBinds are the templates of the values of the variable bindings by OID.
+	VarBinds map[string]string
+}
+
+func buildSNMPSettings(fc channels.FactoryConfig) (snmpSettings, error) {
+	settings := snmpSettings{}
+	rawSettings := struct {
+		Address   string       `json:"address,omitempty" yaml:"address,omitempty"`
+		Community string       `json:"community,omitempty" yaml:"community,omitempty"`
+		TrapOID   string       `json:"trapOid,omitempty" yaml:"trapOid,omitempty"`
+		VarBinds  settingPairs `json:"varbinds,omitempty" yaml:"varbinds,omitempty"`
+	}{}
+
+	if err := json.Unmarshal(fc.Config.Settings, &rawSettings); err != nil {
+		return settings, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if rawSettings.Address == "" {
+		return settings, errors.New("required field 'address' is not specified")
+	}
+	settings.Address = rawSettings.Address
+	if _, _, err := net.SplitHostPort(settings.Address); err != nil {
+		settings.Address = net.JoinHostPort(settings.Address, defaultSNMPPort)
+	}
+	settings.Community = fc.DecryptFunc(context.Background(), fc.Config.SecureSettings, "community", rawSettings.Community)
+	if settings.Community == "" {
+		settings.Community = defaultSNMPCommunity
+	}
+	if rawSettings.TrapOID == "" {
+		return settings, errors.New("required field 'trapOid' is not specified")
+	}
+	settings.TrapOID = rawSettings.TrapOID
+	for oid := range rawSettings.VarBinds {
+		if _, err := encodeOID(oid); err != nil {
+			return settings, fmt.Errorf("invalid variable binding: %w", err)
+		}
+	}
+	settings.VarBinds = rawSettings.VarBinds
+	return settings, nil
+}
+
+// SNMPFactory is the factory of the SNMP contact point.
+func SNMPFactory(fc channels.FactoryConfig) (channels.NotificationChannel, error) {
+	settings, err := buildSNMPSettings(fc)
+	if err != nil {
+		return nil, receiverInitError{
+			Reason: err.Error(),
+			Cfg:    *fc.Config,
+		}
+	}
+	return &SNMPNotifier{
+		Base:     channels.NewBase(fc.Config),
+		log:      fc.Logger,
+		tmpl:     fc.Template,
+		settings: settings,
+	}, nil
+}
+
+// Notify implements the Notifier interface.
+func (sn *SNMPNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	traps := make([][]byte, 0, len(as))
+	for _, a := range as {
+		trap, err := sn.buildTrap(ctx, a)
+		if err != nil {
+			return false, err
+		}
+		traps = append(traps, trap)
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", sn.settings.Address)
+	if err != nil {
+		return true, err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			sn.log.Warn("failed to close connection", "error", err)
+		}
+	}()
+	for _, trap := range traps {
+		if _, err := conn.Write(trap); err != nil {
+			return true, fmt.Errorf("failed to send trap: %w", err)
+		}
+	}
+	sn.log.Debug("traps sent", "address", sn.settings.Address, "count", len(traps))
+	return true, nil
+}
+
+// buildTrap returns the SNMPv2c trap of the alert. The templates are executed with the data of the alert only.
+func (sn *SNMPNotifier) buildTrap(ctx context.Context, a *types.Alert) ([]byte, error) {
+	var tmplErr error
+	tmpl, _ := channels.TmplText(ctx, sn.tmpl, []*types.Alert{a}, sn.log, &tmplErr)
+
+	trapOID := strings.TrimSpace(tmpl(sn.settings.TrapOID))
+	values := make(map[string]string, len(sn.settings.VarBinds))
+	for oid, value := range sn.settings.VarBinds {
+		values[oid] = tmpl(value)
+	}
+	if tmplErr != nil {
+		return nil, fmt.Errorf("failed to template trap: %w", tmplErr)
+	}
+
+	encodedTrapOID, err := encodeOID(trapOID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trap OID: %w", err)
+	}
+	// The uptime is in hundredths of a second and wraps around like a Counter32.
+	uptime := uint32(time.Since(startTime).Milliseconds() / 10)
+	varBinds := [][]byte{
+		encodeVarBind(sysUpTimeOID, berTLV(berTimeTicks, encodeUnsigned(uptime))),
+		encodeVarBind(snmpTrapOID, encodedTrapOID),
+	}
+	oids := make([]string, 0, len(values))
+	for oid := range values {
+		oids = append(oids, oid)
+	}
+	sort.Strings(oids)
+	for _, oid := range oids {
+		varBinds = append(varBinds, encodeVarBind(oid, berTLV(berOctetString, []byte(values[oid]))))
+	}
+
+	// #nosec G404 -- the request ID only has to be unique enough to match responses, and traps have none.
+	requestID := rand.Int31()
+	pdu := berTLV(berSNMPv2Trap,
+		encodeInteger(int64(requestID)),
+		encodeInteger(0), // error-status
+		encodeInteger(0), // error-index
+		berTLV(berSequence, varBinds...),
+	)
+	return berTLV(berSequence,
+		encodeInteger(1), // version-2c
+		berTLV(berOctetString, []byte(sn.settings.Community)),
+		pdu,
+	), nil
+}
+
+func (sn *SNMPNotifier) SendResolved() bool {
+	return !sn.GetDisableResolveMessage()
+}
+
+// encodeVarBind encodes the variable binding of the OID to the encoded value. The OID is validated in the
+// settings, so it cannot fail.
+func encodeVarBind(oid string, value []byte) []byte {
+	encodedOID, _ := encodeOID(oid)
+	return berTLV(berSequence, encodedOID, value)
+}
+
+// berTLV encodes the tag, the length of the contents and the contents.
+func berTLV(tag byte, contents ...[]byte) []byte {
+	length := 0
+	for _, c := range contents {
+		length += len(c)
+	}
+	b := append([]byte{tag}, encodeLength(length)...)
+	for _, c := range contents {
+		b = append(b, c...)
+	}
+	return b
+}
+
+func encodeLength(length int) []byte {
+	if length < 0x80 {
+		return []byte{byte(length)}
+	}
+	var b []byte
+	for l := length; l > 0; l >>= 8 {
+		b = append([]byte{byte(l)}, b...)
+	}
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
+
+// encodeInteger encodes the integer in the minimal number of bytes in two's complement.
+func encodeInteger(v int64) []byte {
+	b := []byte{byte(v)}
+	for (v > 0x7f || v < -0x80) && len(b) < 8 {
+		v >>= 8
+		b = append([]byte{byte(v)}, b...)
+	}
+	return berTLV(berInteger, b)
+}
+
+// encodeUnsigned returns the contents of an unsigned integer, which has a leading zero byte if the highest bit is
+// set so that it is not read as negative.
+func encodeUnsigned(v uint32) []byte {
+	b := []byte{byte(v)}
+	for v >>= 8; v > 0; v >>= 8 {
+		b = append([]byte{byte(v)}, b...)
+	}
+	if b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return b
+}
+
+// encodeOID encodes the OID in dotted notation, like 1.3.6.1.4.1.
+func encodeOID(oid string) ([]byte, error) {
+	parts := strings.Split(strings.TrimPrefix(oid, "."), ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("OID %q must have at least two arcs", oid)
+	}
+	arcs := make([]uint64, 0, len(parts))
+	for _, p := range parts {
+		arc, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID %q", oid)
+		}
+		arcs = append(arcs, arc)
+	}
+	if arcs[0] > 2 || (arcs[0] < 2 && arcs[1] > 39) {
+		return nil, fmt.Errorf("invalid OID %q", oid)
+	}
+
+	b := encodeBase128(arcs[0]*40 + arcs[1])
+	for _, arc := range arcs[2:] {
+		b = append(b, encodeBase128(arc)...)
+	}
+	return berTLV(berOID, b), nil
+}
+
+func encodeBase128(v uint64) []byte {
+	b := []byte{byte(v & 0x7f)}
+	for v >>= 7; v > 0; v >>= 7 {
+		b = append([]byte{byte(v&0x7f) | 0x80}, b...)
+	}
+	return b
+}
@@ -0,0 +1,172 @@
+package integrations
+
+import (
+	"encoding/asn1"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSNMPNotifierSettings(t *testing.T) {
+	testCases := []struct {
+		name         string
+		settings     string
+		expInitError string
+	}{
+		{
+			name:     "valid settings",
+			settings: `{"address": "nms.example.com", "trapOid": "1.3.6.1.4.1.99999.1", "varbinds": "1.3.6.1.4.1.99999.2.1: {{ .Status }}"}`,
+		},
+		{
+			name:         "missing address",
+			settings:     `{"trapOid": "1.3.6.1.4.1.99999.1"}`,
+			expInitError: `failed to validate receiver "snmp_testing" of type "snmp": required field 'address' is not specified`,
+		},
+		{
+			name:         "missing trap OID",
+			settings:     `{"address": "nms.example.com"}`,
+			expInitError: `failed to validate receiver "snmp_testing" of type "snmp": required field 'trapOid' is not specified`,
+		},
+		{
+			name:         "invalid variable binding OID",
+			settings:     `{"address": "nms.example.com", "trapOid": "1.3.6.1.4.1.99999.1", "varbinds": {"1.3.x": "value"}}`,
+			expInitError: `failed to validate receiver "snmp_testing" of type "snmp": invalid variable binding: invalid OID "1.3.x"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := SNMPFactory(newFactoryConfig(t, "snmp", tc.settings, nil))
+			if tc.expInitError != "" {
+				require.EqualError(t, err, tc.expInitError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSNMPNotifier(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	settings := `{
+		"address": "` + conn.LocalAddr().String() + `",
+		"trapOid": "{{ if eq .Status \"firing\" }}1.3.6.1.4.1.99999.0.1{{ else }}1.3.6.1.4.1.99999.0.2{{ end }}",
+		"varbinds": {
+			"1.3.6.1.4.1.99999.1.1": "{{ (index .Alerts 0).Labels.alertname }}",
+			"1.3.6.1.4.1.99999.1.2": "{{ .CommonAnnotations.summary }}"
+		}
+	}`
+	notifier, err := SNMPFactory(newFactoryConfig(t, "snmp", settings, map[string][]byte{"community": []byte("secret")}))
+	require.NoError(t, err)
+
+	alerts := []*types.Alert{{
+		Alert: model.Alert{
+			Labels:      model.LabelSet{"alertname": "HighCPU"},
+			Annotations: model.LabelSet{"summary": "CPU is high"},
+		},
+	}, {
+		Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "HighDisk"},
+			StartsAt: time.Now().Add(-time.Hour),
+			EndsAt:   time.Now().Add(-time.Minute),
+		},
+	}}
+	ok, err := notifier.Notify(notifyContext(), alerts...)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// One trap is sent per alert.
+	for _, expected := range []struct {
+		trapOID asn1.ObjectIdentifier
+		values  []string
+	}{
+		{trapOID: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 0, 1}, values: []string{"HighCPU", "CPU is high"}},
+		{trapOID: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 0, 2}, values: []string{"HighDisk", ""}},
+	} {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		buf := make([]byte, 65535)
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+
+		var msg struct {
+			Version   int
+			Community []byte
+			PDU       asn1.RawValue
+		}
+		rest, err := asn1.Unmarshal(buf[:n], &msg)
+		require.NoError(t, err)
+		require.Empty(t, rest)
+		require.Equal(t, 1, msg.Version)
+		require.Equal(t, "secret", string(msg.Community))
+		require.Equal(t, asn1.ClassContextSpecific, msg.PDU.Class)
+		require.Equal(t, 7, msg.PDU.Tag)
+
+		var requestID, errorStatus, errorIndex int
+		var varBinds asn1.RawValue
+		pdu := msg.PDU.Bytes
+		for _, v := range []interface{}{&requestID, &errorStatus, &errorIndex, &varBinds} {
+			pdu, err = asn1.Unmarshal(pdu, v)
+			require.NoError(t, err)
+		}
+		require.Empty(t, pdu)
+		require.Zero(t, errorStatus)
+		require.Zero(t, errorIndex)
+
+		var binds []struct {
+			OID   asn1.ObjectIdentifier
+			Value asn1.RawValue
+		}
+		_, err = asn1.Unmarshal(varBinds.FullBytes, &binds)
+		require.NoError(t, err)
+		require.Len(t, binds, 4)
+
+		require.Equal(t, asn1.ObjectIdentifier{1, 3, 6, 1, 2, 1, 1, 3, 0}, binds[0].OID)
+		require.Equal(t, asn1.ClassApplication, binds[0].Value.Class)
+		require.Equal(t, 3, binds[0].Value.Tag)
+
+		require.Equal(t, asn1.ObjectIdentifier{1, 3, 6, 1, 6, 3, 1, 1, 4, 1, 0}, binds[1].OID)
+		var trapOID asn1.ObjectIdentifier
+		_, err = asn1.Unmarshal(binds[1].Value.FullBytes, &trapOID)
+		require.NoError(t, err)
+		require.Equal(t, expected.trapOID, trapOID)
+
+		require.Equal(t, asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1, 1}, binds[2].OID)
+		require.Equal(t, expected.values[0], string(binds[2].Value.Bytes))
+		require.Equal(t, asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1, 2}, binds[3].OID)
+		require.Equal(t, expected.values[1], string(binds[3].Value.Bytes))
+	}
+}
+
+func TestBEREncoding(t *testing.T) {
+	testCases := []struct {
+		name     string
+		encoded  []byte
+		expected []byte
+	}{
+		{name: "small integer", encoded: encodeInteger(5), expected: []byte{0x02, 0x01, 0x05}},
+		{name: "integer with the highest bit set", encoded: encodeInteger(128), expected: []byte{0x02, 0x02, 0x00, 0x80}},
+		{name: "negative integer", encoded: encodeInteger(-129), expected: []byte{0x02, 0x02, 0xff, 0x7f}},
+		{name: "unsigned with the highest bit set", encoded: encodeUnsigned(0xffffffff), expected: []byte{0x00, 0xff, 0xff, 0xff, 0xff}},
+		{name: "long length", encoded: encodeLength(300), expected: []byte{0x82, 0x01, 0x2c}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.encoded)
+		})
+	}
+
+	t.Run("OIDs are encoded like encoding/asn1", func(t *testing.T) {
+		encoded, err := encodeOID("1.3.6.1.4.1.2021.4294967295")
+		require.NoError(t, err)
+		expected, err := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 2021, 4294967295})
+		require.NoError(t, err)
+		require.Equal(t, expected, encoded)
+	})
+}
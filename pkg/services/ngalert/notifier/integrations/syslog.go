@@ -0,0 +1,302 @@
+package integrations
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/grafana/alerting/alerting/notifier/channels"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	defaultSyslogNetwork       = "udp"
+	defaultSyslogFacility      = "local0"
+	defaultSyslogSeverityLabel = "severity"
+	defaultSyslogSeverity      = "warning"
+	defaultSyslogAppName       = "grafana"
+	// resolvedSyslogSeverity is the severity of the messages of resolved alerts.
+	resolvedSyslogSeverity = "notice"
+)
+
+// syslogFacilities are the facility codes of RFC 5424 by keyword.
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11, "ntp": 12, "security": 13, "console": 14, "solaris-cron": 15,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSeverities are the severity codes of RFC 5424 by keyword, and by the values of severity labels that are
+// commonly used in alert rules.
+var syslogSeverities = map[string]int{
+	"emerg":         0,
+	"emergency":     0,
+	"panic":         0,
+	"alert":         1,
+	"crit":          2,
+	"critical":      2,
+	"err":           3,
+	"error":         3,
+	"high":          3,
+	"major":         3,
+	"warning":       4,
+	"warn":          4,
+	"medium":        4,
+	"minor":         4,
+	"notice":        5,
+	"low":           5,
+	"info":          6,
+	"informational": 6,
+	"debug":         7,
+}
+
+// SyslogNotifier sends an RFC 5424 syslog message per alert. The facility and the severity of the message are
+// taken from the labels of the alert.
+type SyslogNotifier struct {
+	*channels.Base
+	log      channels.Logger
+	tmpl     *template.Template
+	settings syslogSettings
+}
+
+type syslogSettings struct {
+	Network  string
+	Address  string
+	TLS      *tls.Config
+	Hostname string
+	AppName  string
+	Message  string
+
+	Facility      int
+	FacilityLabel string
+	SeverityLabel string
+	// SeverityMapping maps the values of the severity label to severity codes.
+	SeverityMapping map[string]int
+	DefaultSeverity int
+}
+
+func buildSyslogSettings(fc channels.FactoryConfig) (syslogSettings, error) {
+	settings := syslogSettings{}
+	rawSettings := struct {
+		Network         string       `json:"network,omitempty" yaml:"network,omitempty"`
+		Address         string       `json:"address,omitempty" yaml:"address,omitempty"`
+		Hostname        string       `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+		AppName         string       `json:"appName,omitempty" yaml:"appName,omitempty"`
+		Message         string       `json:"message,omitempty" yaml:"message,omitempty"`
+		Facility        string       `json:"facility,omitempty" yaml:"facility,omitempty"`
+		FacilityLabel   string       `json:"facilityLabel,omitempty" yaml:"facilityLabel,omitempty"`
+		SeverityLabel   string       `json:"severityLabel,omitempty" yaml:"severityLabel,omitempty"`
+		SeverityMapping settingPairs `json:"severityMapping,omitempty" yaml:"severityMapping,omitempty"`
+		DefaultSeverity string       `json:"defaultSeverity,omitempty" yaml:"defaultSeverity,omitempty"`
+		rawTLSSettings
+	}{}
+
+	if err := json.Unmarshal(fc.Config.Settings, &rawSettings); err != nil {
+		return settings, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if rawSettings.Address == "" {
+		return settings, errors.New("required field 'address' is not specified")
+	}
+	settings.Address = rawSettings.Address
+
+	settings.Network = rawSettings.Network
+	if settings.Network == "" {
+		settings.Network = defaultSyslogNetwork
+	}
+	switch settings.Network {
+	case "udp", "tcp":
+	case "tls":
+		tlsOptions, err := rawSettings.tlsOptions(fc)
+		if err != nil {
+			return settings, err
+		}
+		if settings.TLS, err = tlsConfig(tlsOptions); err != nil {
+			return settings, err
+		}
+	default:
+		return settings, fmt.Errorf("invalid network %q, must be one of udp, tcp or tls", settings.Network)
+	}
+
+	settings.Hostname = rawSettings.Hostname
+	if settings.Hostname == "" {
+		settings.Hostname, _ = os.Hostname()
+	}
+	settings.AppName = rawSettings.AppName
+	if settings.AppName == "" {
+		settings.AppName = defaultSyslogAppName
+	}
+	settings.Message = rawSettings.Message
+	if settings.Message == "" {
+		settings.Message = channels.DefaultMessageTitleEmbed
+	}
+
+	if rawSettings.Facility == "" {
+		rawSettings.Facility = defaultSyslogFacility
+	}
+	facility, ok := syslogFacilities[strings.ToLower(rawSettings.Facility)]
+	if !ok {
+		return settings, fmt.Errorf("invalid facility %q", rawSettings.Facility)
+	}
+	settings.Facility = facility
+	settings.FacilityLabel = rawSettings.FacilityLabel
+
+	settings.SeverityLabel = rawSettings.SeverityLabel
+	if settings.SeverityLabel == "" {
+		settings.SeverityLabel = defaultSyslogSeverityLabel
+	}
+	settings.SeverityMapping = make(map[string]int, len(syslogSeverities)+len(rawSettings.SeverityMapping))
+	for value, severity := range syslogSeverities {
+		settings.SeverityMapping[value] = severity
+	}
+	for value, keyword := range rawSettings.SeverityMapping {
+		severity, ok := syslogSeverities[strings.ToLower(keyword)]
+		if !ok {
+			return settings, fmt.Errorf("invalid severity %q for value %q", keyword, value)
+		}
+		settings.SeverityMapping[strings.ToLower(value)] = severity
+	}
+	if rawSettings.DefaultSeverity == "" {
+		rawSettings.DefaultSeverity = defaultSyslogSeverity
+	}
+	if settings.DefaultSeverity, ok = syslogSeverities[strings.ToLower(rawSettings.DefaultSeverity)]; !ok {
+		return settings, fmt.Errorf("invalid default severity %q", rawSettings.DefaultSeverity)
+	}
+	return settings, nil
+}
+
+// SyslogFactory is the factory of the syslog contact point.
+func SyslogFactory(fc channels.FactoryConfig) (channels.NotificationChannel, error) {
+	settings, err := buildSyslogSettings(fc)
+	if err != nil {
+		return nil, receiverInitError{
+			Reason: err.Error(),
+			Cfg:    *fc.Config,
+		}
+	}
+	return &SyslogNotifier{
+		Base:     channels.NewBase(fc.Config),
+		log:      fc.Logger,
+		tmpl:     fc.Template,
+		settings: settings,
+	}, nil
+}
+
+// Notify implements the Notifier interface.
+func (sn *SyslogNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	now := time.Now()
+	messages := make([]string, 0, len(as))
+	for _, a := range as {
+		msg, err := sn.buildMessage(ctx, a, now)
+		if err != nil {
+			return false, err
+		}
+		messages = append(messages, msg)
+	}
+
+	conn, err := sn.dial(ctx)
+	if err != nil {
+		return true, err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			sn.log.Warn("failed to close connection", "error", err)
+		}
+	}()
+	for _, msg := range messages {
+		// Messages over TCP are framed with octet counting, see RFC 6587.
+		if sn.settings.Network != "udp" {
+			msg = fmt.Sprintf("%d %s", len(msg), msg)
+		}
+		if _, err := conn.Write([]byte(msg)); err != nil {
+			return true, fmt.Errorf("failed to send message: %w", err)
+		}
+	}
+	sn.log.Debug("messages sent", "address", sn.settings.Address, "count", len(messages))
+	return true, nil
+}
+
+func (sn *SyslogNotifier) dial(ctx context.Context) (net.Conn, error) {
+	d := &net.Dialer{Timeout: 30 * time.Second}
+	if sn.settings.Network == "tls" {
+		td := &tls.Dialer{NetDialer: d, Config: sn.settings.TLS}
+		return td.DialContext(ctx, "tcp", sn.settings.Address)
+	}
+	return d.DialContext(ctx, sn.settings.Network, sn.settings.Address)
+}
+
+// buildMessage returns the syslog message of the alert. The message is a template that is executed with the data
+// of the alert only.
+func (sn *SyslogNotifier) buildMessage(ctx context.Context, a *types.Alert, now time.Time) (string, error) {
+	var tmplErr error
+	tmpl, _ := channels.TmplText(ctx, sn.tmpl, []*types.Alert{a}, sn.log, &tmplErr)
+	msg := strings.TrimSpace(tmpl(sn.settings.Message))
+	if tmplErr != nil {
+		return "", fmt.Errorf("failed to template message: %w", tmplErr)
+	}
+
+	status := a.Status()
+	priority := sn.facility(a)*8 + sn.severity(a, status)
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	return fmt.Sprintf("<%d>1 %s %s %s - %s - %s",
+		priority,
+		now.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderField(sn.settings.Hostname, 255),
+		syslogHeaderField(sn.settings.AppName, 48),
+		status,
+		msg,
+	), nil
+}
+
+// facility returns the facility of the label of the alert, or the configured facility.
+func (sn *SyslogNotifier) facility(a *types.Alert) int {
+	if sn.settings.FacilityLabel != "" {
+		value := a.Labels[model.LabelName(sn.settings.FacilityLabel)]
+		if facility, ok := syslogFacilities[strings.ToLower(string(value))]; ok {
+			return facility
+		}
+	}
+	return sn.settings.Facility
+}
+
+// severity returns the severity of the label of the alert, or the default severity if it has no such label or
+// its value is unknown. Resolved alerts have severity notice.
+func (sn *SyslogNotifier) severity(a *types.Alert, status model.AlertStatus) int {
+	if status == model.AlertResolved {
+		return syslogSeverities[resolvedSyslogSeverity]
+	}
+	value := a.Labels[model.LabelName(sn.settings.SeverityLabel)]
+	if severity, ok := sn.settings.SeverityMapping[strings.ToLower(string(value))]; ok {
+		return severity
+	}
+	return sn.settings.DefaultSeverity
+}
+
+func (sn *SyslogNotifier) SendResolved() bool {
+	return !sn.GetDisableResolveMessage()
+}
+
+// syslogHeaderField returns the value as a field of the header, which only allows printable ASCII characters
+// except for spaces, and is "-" if it is empty.
+func syslogHeaderField(value string, maxLength int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, value)
+	if len(value) > maxLength {
+		value = value[:maxLength]
+	}
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,178 @@
+package integrations
+
+import (
+	"bufio"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogNotifierSettings(t *testing.T) {
+	testCases := []struct {
+		name         string
+		settings     string
+		expInitError string
+	}{
+		{
+			name:     "valid settings",
+			settings: `{"address": "syslog.example.com:514", "facility": "local3", "severityMapping": "P1: crit\nP2: err"}`,
+		},
+		{
+			name:         "missing address",
+			settings:     `{}`,
+			expInitError: `failed to validate receiver "syslog_testing" of type "syslog": required field 'address' is not specified`,
+		},
+		{
+			name:         "invalid network",
+			settings:     `{"address": "syslog.example.com:514", "network": "unix"}`,
+			expInitError: `failed to validate receiver "syslog_testing" of type "syslog": invalid network "unix", must be one of udp, tcp or tls`,
+		},
+		{
+			name:         "invalid facility",
+			settings:     `{"address": "syslog.example.com:514", "facility": "local8"}`,
+			expInitError: `failed to validate receiver "syslog_testing" of type "syslog": invalid facility "local8"`,
+		},
+		{
+			name:         "invalid severity mapping",
+			settings:     `{"address": "syslog.example.com:514", "severityMapping": {"P1": "urgent"}}`,
+			expInitError: `failed to validate receiver "syslog_testing" of type "syslog": invalid severity "urgent" for value "P1"`,
+		},
+		{
+			name:         "invalid TLS settings",
+			settings:     `{"address": "syslog.example.com:6514", "network": "tls", "tlsCACertificate": "invalid"}`,
+			expInitError: `failed to validate receiver "syslog_testing" of type "syslog": invalid TLS configuration: failed to parse TLS CA PEM certificate`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := SyslogFactory(newFactoryConfig(t, "syslog", tc.settings, nil))
+			if tc.expInitError != "" {
+				require.EqualError(t, err, tc.expInitError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSyslogNotifier(t *testing.T) {
+	alerts := []*types.Alert{{
+		Alert: model.Alert{
+			Labels:      model.LabelSet{"alertname": "HighCPU", "severity": "critical"},
+			Annotations: model.LabelSet{"summary": "CPU is high"},
+		},
+	}, {
+		Alert: model.Alert{
+			Labels: model.LabelSet{"alertname": "HighLatency", "priority": "P2", "facility": "local5"},
+		},
+	}, {
+		Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "HighDisk", "severity": "critical"},
+			StartsAt: time.Now().Add(-time.Hour),
+			EndsAt:   time.Now().Add(-time.Minute),
+		},
+	}}
+	header := `^<(\d+)>1 \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}Z grafana-host grafana - (\w+) - (.*)$`
+
+	t.Run("sends a message per alert over UDP", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+
+		settings := `{"address": "` + conn.LocalAddr().String() + `", "hostname": "grafana-host", "message": "{{ (index .Alerts 0).Labels.alertname }} is {{ .Status }}"}`
+		notifier, err := SyslogFactory(newFactoryConfig(t, "syslog", settings, nil))
+		require.NoError(t, err)
+
+		ok, err := notifier.Notify(notifyContext(), alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// local0 is 16, critical is 2, unknown severities are warning (4) and resolved alerts are notice (5).
+		for _, expected := range []struct {
+			priority int
+			msgID    string
+			msg      string
+		}{
+			{priority: 16*8 + 2, msgID: "firing", msg: "HighCPU is firing"},
+			{priority: 16*8 + 4, msgID: "firing", msg: "HighLatency is firing"},
+			{priority: 16*8 + 5, msgID: "resolved", msg: "HighDisk is resolved"},
+		} {
+			require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+			buf := make([]byte, 65535)
+			n, _, err := conn.ReadFrom(buf)
+			require.NoError(t, err)
+
+			match := regexp.MustCompile(header).FindStringSubmatch(string(buf[:n]))
+			require.NotNil(t, match, string(buf[:n]))
+			require.Equal(t, strconv.Itoa(expected.priority), match[1])
+			require.Equal(t, expected.msgID, match[2])
+			require.Equal(t, expected.msg, match[3])
+		}
+	})
+
+	t.Run("maps labels to the facility and severity and frames messages over TCP", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = listener.Close() })
+		received := make(chan string, 1)
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer func() { _ = conn.Close() }()
+			var b strings.Builder
+			_, _ = bufio.NewReader(conn).WriteTo(&b)
+			received <- b.String()
+		}()
+
+		settings := `{
+			"address": "` + listener.Addr().String() + `",
+			"network": "tcp",
+			"hostname": "grafana-host",
+			"facility": "local3",
+			"facilityLabel": "facility",
+			"severityLabel": "priority",
+			"severityMapping": {"P2": "err"}
+		}`
+		notifier, err := SyslogFactory(newFactoryConfig(t, "syslog", settings, nil))
+		require.NoError(t, err)
+
+		ok, err := notifier.Notify(notifyContext(), alerts[:2]...)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		var data string
+		select {
+		case data = <-received:
+		case <-time.After(5 * time.Second):
+			t.Fatal("no messages received")
+		}
+
+		// The messages are framed with their length in octets.
+		var priorities []string
+		for data != "" {
+			length, rest, ok := strings.Cut(data, " ")
+			require.True(t, ok)
+			n, err := strconv.Atoi(length)
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, len(rest), n)
+
+			match := regexp.MustCompile(header).FindStringSubmatch(rest[:n])
+			require.NotNil(t, match, rest[:n])
+			priorities = append(priorities, match[1])
+			data = rest[n:]
+		}
+		// The first alert has no priority label, so it is sent with the default severity and facility. The second
+		// alert is sent with the facility of its label and the mapped severity.
+		require.Equal(t, []string{strconv.Itoa(19*8 + 4), strconv.Itoa(21*8 + 3)}, priorities)
+	})
+}
@@ -114,6 +114,14 @@ func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore AlertingStore, orgSto
 func (moa *MultiOrgAlertmanager) Run(ctx context.Context) error {
 	moa.logger.Info("starting MultiOrg Alertmanager")
 
+	// Attempts to deliver notifications are deleted once they are older than the retention period, unless it is zero.
+	var retentionC <-chan time.Time
+	if deliveryLog := moa.settings.UnifiedAlerting.NotificationDeliveryLog; deliveryLog.Enabled && deliveryLog.Retention > 0 {
+		ticker := time.NewTicker(deliveryLogRetentionInterval)
+		defer ticker.Stop()
+		retentionC = ticker.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -123,6 +131,8 @@ func (moa *MultiOrgAlertmanager) Run(ctx context.Context) error {
 			if err := moa.LoadAndSyncAlertmanagersForOrgs(ctx); err != nil {
 				moa.logger.Error("error while synchronizing Alertmanager orgs", "error", err)
 			}
+		case <-retentionC:
+			moa.deleteExpiredNotificationDeliveries(ctx)
 		}
 	}
 }
@@ -309,6 +319,12 @@ func (moa *MultiOrgAlertmanager) StopAndWait() {
 	}
 }
 
+// ClusterPeer returns the peer of the high availability cluster. It returns false if Grafana does not run in a cluster.
+func (moa *MultiOrgAlertmanager) ClusterPeer() (*cluster.Peer, bool) {
+	p, ok := moa.peer.(*cluster.Peer)
+	return p, ok
+}
+
 // AlertmanagerFor returns the Alertmanager instance for the organization provided.
 // When the organization does not have an active Alertmanager, it returns a ErrNoAlertmanagerForOrg.
 // When the Alertmanager of the organization is not ready, it returns a ErrAlertmanagerNotReady.
@@ -0,0 +1,119 @@
+package notifier
+
+import (
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// TestRouting returns the notification policies that an alert with the given labels matches, their effective
+// options, the mute timings that mute them at the given time and the contact points that would be notified.
+func (am *Alertmanager) TestRouting(labels model.LabelSet, at time.Time) apimodels.TestRoutingResult {
+	am.reloadConfigMtx.RLock()
+	defer am.reloadConfigMtx.RUnlock()
+
+	result := apimodels.TestRoutingResult{
+		Labels:    labels,
+		Time:      at,
+		Routes:    []apimodels.TestRoutingRoute{},
+		Receivers: []string{},
+	}
+	if am.route == nil {
+		return result
+	}
+
+	receivers := make(map[string]struct{})
+	for _, match := range matchRoutes(am.route, []int{}, labels) {
+		route := newTestRoutingRoute(match.route, match.path)
+		for _, name := range route.MuteTimeIntervals {
+			if inTimeIntervals(at, am.muteTimes[name]) {
+				route.ActiveMuteTimeIntervals = append(route.ActiveMuteTimeIntervals, name)
+			}
+		}
+		route.Muted = len(route.ActiveMuteTimeIntervals) > 0
+		result.Routes = append(result.Routes, route)
+
+		if _, ok := receivers[route.Receiver]; !ok {
+			receivers[route.Receiver] = struct{}{}
+			result.Receivers = append(result.Receivers, route.Receiver)
+		}
+	}
+	return result
+}
+
+type matchedRoute struct {
+	route *dispatch.Route
+	path  []int
+}
+
+// matchRoutes is like dispatch.Route.Match but also returns the path of every matching route in the tree.
+func matchRoutes(r *dispatch.Route, path []int, lset model.LabelSet) []matchedRoute {
+	if !r.Matchers.Matches(lset) {
+		return nil
+	}
+
+	var all []matchedRoute
+	for i, cr := range r.Routes {
+		childPath := make([]int, len(path), len(path)+1)
+		copy(childPath, path)
+		matches := matchRoutes(cr, append(childPath, i), lset)
+
+		all = append(all, matches...)
+
+		if matches != nil && !cr.Continue {
+			break
+		}
+	}
+
+	// If no child nodes were matches, the current node itself is a match.
+	if len(all) == 0 {
+		all = append(all, matchedRoute{route: r, path: path})
+	}
+	return all
+}
+
+func newTestRoutingRoute(r *dispatch.Route, path []int) apimodels.TestRoutingRoute {
+	opts := r.RouteOpts
+	// Grouping by all labels takes precedence over the labels inherited from the parent route.
+	groupBy := []string{"..."}
+	if !opts.GroupByAll {
+		groupBy = make([]string, 0, len(opts.GroupBy))
+		for name := range opts.GroupBy {
+			groupBy = append(groupBy, string(name))
+		}
+		sort.Strings(groupBy)
+	}
+
+	var matchers []string
+	for _, m := range r.Matchers {
+		matchers = append(matchers, m.String())
+	}
+
+	return apimodels.TestRoutingRoute{
+		Path:              path,
+		Matchers:          matchers,
+		Receiver:          opts.Receiver,
+		GroupBy:           groupBy,
+		GroupWait:         model.Duration(opts.GroupWait),
+		GroupInterval:     model.Duration(opts.GroupInterval),
+		RepeatInterval:    model.Duration(opts.RepeatInterval),
+		Continue:          r.Continue,
+		MuteTimeIntervals: opts.MuteTimeIntervals,
+	}
+}
+
+// inTimeIntervals returns true if the time is in one of the intervals. Like the time mute stage of the
+// notification pipeline, it checks the time in UTC unless the interval has a location.
+func inTimeIntervals(t time.Time, intervals []timeinterval.TimeInterval) bool {
+	for _, ti := range intervals {
+		if ti.ContainsTime(t.UTC()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestTestRouting(t *testing.T) {
+	am := setupAMTest(t)
+
+	t.Run("it returns no routes if there is no configuration", func(t *testing.T) {
+		result := am.TestRouting(model.LabelSet{"team": "a"}, time.Now())
+		require.Empty(t, result.Routes)
+		require.Empty(t, result.Receivers)
+	})
+
+	cfg, err := Load([]byte(`{
+		"alertmanager_config": {
+			"route": {
+				"receiver": "default",
+				"group_by": ["alertname"],
+				"routes": [
+					{
+						"receiver": "team-a",
+						"object_matchers": [["team", "=", "a"]],
+						"group_wait": "5s",
+						"continue": true,
+						"mute_time_intervals": ["weekends", "nights"]
+					},
+					{
+						"receiver": "team-b",
+						"object_matchers": [["team", "=~", "a|b"]],
+						"repeat_interval": "1h",
+						"routes": [
+							{
+								"receiver": "critical",
+								"object_matchers": [["severity", "=", "critical"]],
+								"group_by": ["..."]
+							}
+						]
+					}
+				]
+			},
+			"mute_time_intervals": [
+				{"name": "weekends", "time_intervals": [{"weekdays": ["saturday", "sunday"]}]},
+				{"name": "nights", "time_intervals": [{"times": [{"start_time": "00:00", "end_time": "06:00"}]}]}
+			],
+			"receivers": [
+				{"name": "default", "grafana_managed_receiver_configs": [{"uid": "default", "name": "default", "type": "email", "settings": {"addresses": "default@example.com"}}]},
+				{"name": "team-a", "grafana_managed_receiver_configs": [{"uid": "team-a", "name": "team-a", "type": "email", "settings": {"addresses": "a@example.com"}}]},
+				{"name": "team-b", "grafana_managed_receiver_configs": [{"uid": "team-b", "name": "team-b", "type": "email", "settings": {"addresses": "b@example.com"}}]},
+				{"name": "critical", "grafana_managed_receiver_configs": [{"uid": "critical", "name": "critical", "type": "email", "settings": {"addresses": "critical@example.com"}}]}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	require.NoError(t, am.SaveAndApplyConfig(context.Background(), cfg))
+
+	// Saturday at 3am UTC
+	saturdayNight := time.Date(2023, 1, 7, 3, 0, 0, 0, time.UTC)
+	// Monday at 10am UTC
+	mondayMorning := time.Date(2023, 1, 9, 10, 0, 0, 0, time.UTC)
+
+	t.Run("it returns the root route if no nested route matches", func(t *testing.T) {
+		result := am.TestRouting(model.LabelSet{"team": "c"}, mondayMorning)
+		require.Equal(t, []apimodels.TestRoutingRoute{{
+			Path:           []int{},
+			Receiver:       "default",
+			GroupBy:        []string{"alertname"},
+			GroupWait:      model.Duration(30 * time.Second),
+			GroupInterval:  model.Duration(5 * time.Minute),
+			RepeatInterval: model.Duration(4 * time.Hour),
+		}}, result.Routes)
+		require.Equal(t, []string{"default"}, result.Receivers)
+		require.Equal(t, mondayMorning, result.Time)
+	})
+
+	t.Run("it returns all matching routes with their inherited options", func(t *testing.T) {
+		result := am.TestRouting(model.LabelSet{"team": "a", "severity": "critical"}, mondayMorning)
+		require.Equal(t, []apimodels.TestRoutingRoute{
+			{
+				Path:              []int{0},
+				Matchers:          []string{`team="a"`},
+				Receiver:          "team-a",
+				GroupBy:           []string{"alertname"},
+				GroupWait:         model.Duration(5 * time.Second),
+				GroupInterval:     model.Duration(5 * time.Minute),
+				RepeatInterval:    model.Duration(4 * time.Hour),
+				Continue:          true,
+				MuteTimeIntervals: []string{"weekends", "nights"},
+			},
+			{
+				Path:           []int{1, 0},
+				Matchers:       []string{`severity="critical"`},
+				Receiver:       "critical",
+				GroupBy:        []string{"..."},
+				GroupWait:      model.Duration(30 * time.Second),
+				GroupInterval:  model.Duration(5 * time.Minute),
+				RepeatInterval: model.Duration(time.Hour),
+			},
+		}, result.Routes)
+		require.Equal(t, []string{"team-a", "critical"}, result.Receivers)
+	})
+
+	t.Run("it returns the mute timings that mute a route at the given time", func(t *testing.T) {
+		result := am.TestRouting(model.LabelSet{"team": "a"}, saturdayNight)
+		require.Len(t, result.Routes, 2)
+		require.Equal(t, []string{"weekends", "nights"}, result.Routes[0].ActiveMuteTimeIntervals)
+		require.True(t, result.Routes[0].Muted)
+		require.Equal(t, []int{1}, result.Routes[1].Path)
+		require.False(t, result.Routes[1].Muted)
+
+		result = am.TestRouting(model.LabelSet{"team": "a"}, mondayMorning.Add(-8*time.Hour))
+		require.Equal(t, []string{"nights"}, result.Routes[0].ActiveMuteTimeIntervals)
+	})
+}
@@ -22,7 +22,7 @@ func (s sender) SendWebhook(ctx context.Context, cmd *channels.SendWebhookSettin
 		HttpMethod:  cmd.HTTPMethod,
 		HttpHeader:  cmd.HTTPHeader,
 		ContentType: cmd.ContentType,
-		Validation:  cmd.Validation,
+		Validation:  withStatusCodeRecorder(ctx, cmd.Validation),
 	})
 }
 
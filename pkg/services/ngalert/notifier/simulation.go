@@ -0,0 +1,239 @@
+package notifier
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// SimulateNotifications replays the alerts through the notification policies, mute timings and contact points of
+// the current configuration, and returns the notifications that would have been sent for them up to the given
+// time. Like the dispatcher, alerts are grouped per policy and the groups are flushed after group_wait and then
+// every group_interval. Like the deduplication stage of the notification pipeline, a flush is only sent if the
+// group changed since the last notification or if repeat_interval has passed.
+func (am *Alertmanager) SimulateNotifications(alerts []*types.Alert, until time.Time) []apimodels.SimulatedNotification {
+	am.reloadConfigMtx.RLock()
+	route := am.route
+	muteTimes := am.muteTimes
+	sendResolved := am.sendResolvedByReceiver()
+	am.reloadConfigMtx.RUnlock()
+
+	if route == nil {
+		return []apimodels.SimulatedNotification{}
+	}
+	return simulateNotifications(route, muteTimes, sendResolved, alerts, until)
+}
+
+// sendResolvedByReceiver returns, per contact point, whether any of its integrations sends resolved notifications.
+func (am *Alertmanager) sendResolvedByReceiver() map[string]bool {
+	result := make(map[string]bool)
+	if am.config == nil {
+		return result
+	}
+	for _, r := range am.config.AlertmanagerConfig.Receivers {
+		for _, integration := range r.GrafanaManagedReceivers {
+			if !integration.DisableResolveMessage {
+				result[r.Name] = true
+			}
+		}
+	}
+	return result
+}
+
+type simulatedGroup struct {
+	key       string
+	route     matchedRoute
+	labels    model.LabelSet
+	alerts    map[model.Fingerprint]*types.Alert
+	nextFlush time.Time
+}
+
+// simulatedFlush is the last notification of a group, like the entry of the notification log.
+type simulatedFlush struct {
+	at       time.Time
+	firing   map[model.Fingerprint]struct{}
+	resolved map[model.Fingerprint]struct{}
+}
+
+func simulateNotifications(route *dispatch.Route, muteTimes map[string][]timeinterval.TimeInterval, sendResolved map[string]bool, alerts []*types.Alert, until time.Time) []apimodels.SimulatedNotification {
+	pending := make([]*types.Alert, len(alerts))
+	copy(pending, alerts)
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].StartsAt.Before(pending[j].StartsAt)
+	})
+
+	groups := make(map[string]*simulatedGroup)
+	// The last notifications outlive the groups, like the notification log.
+	last := make(map[string]*simulatedFlush)
+	result := []apimodels.SimulatedNotification{}
+	for {
+		next := nextGroupToFlush(groups)
+		if len(pending) > 0 && (next == nil || !pending[0].StartsAt.After(next.nextFlush)) {
+			alert := pending[0]
+			pending = pending[1:]
+			if alert.StartsAt.After(until) {
+				break
+			}
+			for _, match := range matchRoutes(route, []int{}, alert.Labels) {
+				labels := groupLabels(match.route, alert.Labels)
+				key := fmt.Sprintf("%v:%s", match.path, labels)
+				g, ok := groups[key]
+				if !ok {
+					g = &simulatedGroup{
+						key:       key,
+						route:     match,
+						labels:    labels,
+						alerts:    make(map[model.Fingerprint]*types.Alert),
+						nextFlush: alert.StartsAt.Add(match.route.RouteOpts.GroupWait),
+					}
+					groups[key] = g
+				}
+				g.alerts[alert.Fingerprint()] = alert
+			}
+			continue
+		}
+		if next == nil || next.nextFlush.After(until) {
+			break
+		}
+
+		if n, ok := next.flush(muteTimes, sendResolved[next.route.route.RouteOpts.Receiver], last); ok {
+			result = append(result, n)
+		}
+		if len(next.alerts) == 0 {
+			delete(groups, next.key)
+			continue
+		}
+		next.nextFlush = next.nextFlush.Add(next.route.route.RouteOpts.GroupInterval)
+	}
+	return result
+}
+
+// flush returns the notification of the group at the time of its next flush, if any, and removes the resolved
+// alerts from the group.
+func (g *simulatedGroup) flush(muteTimes map[string][]timeinterval.TimeInterval, sendResolved bool, last map[string]*simulatedFlush) (apimodels.SimulatedNotification, bool) {
+	at := g.nextFlush
+	current := &simulatedFlush{
+		at:       at,
+		firing:   make(map[model.Fingerprint]struct{}),
+		resolved: make(map[model.Fingerprint]struct{}),
+	}
+	var firing, resolved []model.LabelSet
+	for fp, alert := range g.alerts {
+		if alert.ResolvedAt(at) {
+			current.resolved[fp] = struct{}{}
+			resolved = append(resolved, alert.Labels)
+			delete(g.alerts, fp)
+			continue
+		}
+		current.firing[fp] = struct{}{}
+		firing = append(firing, alert.Labels)
+	}
+
+	opts := g.route.route.RouteOpts
+	notify, repeat := needsUpdate(last[g.key], current, sendResolved, opts.RepeatInterval)
+	if !notify {
+		return apimodels.SimulatedNotification{}, false
+	}
+
+	var mutedBy []string
+	for _, name := range opts.MuteTimeIntervals {
+		if inTimeIntervals(at, muteTimes[name]) {
+			mutedBy = append(mutedBy, name)
+		}
+	}
+	// Muted notifications are not sent and therefore not logged.
+	if len(mutedBy) == 0 {
+		last[g.key] = current
+	}
+	// Contact points that do not send resolved notifications drop the resolved alerts, and send nothing
+	// if there are only resolved alerts.
+	if !sendResolved {
+		if len(firing) == 0 {
+			return apimodels.SimulatedNotification{}, false
+		}
+		resolved = nil
+	}
+
+	return apimodels.SimulatedNotification{
+		Time:        at,
+		Receiver:    opts.Receiver,
+		RoutePath:   g.route.path,
+		GroupLabels: g.labels,
+		Firing:      sortLabelSets(firing),
+		Resolved:    sortLabelSets(resolved),
+		Repeat:      repeat,
+		MutedBy:     mutedBy,
+	}, true
+}
+
+// needsUpdate is like the deduplication stage of the notification pipeline. It returns whether the flush
+// must be sent and whether it is only sent because the repeat interval has passed.
+func needsUpdate(entry, current *simulatedFlush, sendResolved bool, repeatInterval time.Duration) (bool, bool) {
+	if entry == nil {
+		return len(current.firing) > 0, false
+	}
+	if !isSubset(current.firing, entry.firing) {
+		return true, false
+	}
+	if len(current.firing) == 0 {
+		return len(entry.firing) > 0, false
+	}
+	if sendResolved && !isSubset(current.resolved, entry.resolved) {
+		return true, false
+	}
+	if !entry.at.After(current.at.Add(-repeatInterval)) {
+		return true, true
+	}
+	return false, false
+}
+
+func isSubset(subset, set map[model.Fingerprint]struct{}) bool {
+	for fp := range subset {
+		if _, ok := set[fp]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// nextGroupToFlush returns the group with the earliest flush. Groups flushed at the same time are ordered by key.
+func nextGroupToFlush(groups map[string]*simulatedGroup) *simulatedGroup {
+	var next *simulatedGroup
+	for _, g := range groups {
+		if next == nil || g.nextFlush.Before(next.nextFlush) || (g.nextFlush.Equal(next.nextFlush) && g.key < next.key) {
+			next = g
+		}
+	}
+	return next
+}
+
+// groupLabels returns the labels of the alert that the route groups by.
+func groupLabels(r *dispatch.Route, labels model.LabelSet) model.LabelSet {
+	if r.RouteOpts.GroupByAll {
+		return labels.Clone()
+	}
+	result := model.LabelSet{}
+	for name, value := range labels {
+		if _, ok := r.RouteOpts.GroupBy[name]; ok {
+			result[name] = value
+		}
+	}
+	return result
+}
+
+func sortLabelSets(sets []model.LabelSet) []model.LabelSet {
+	if sets == nil {
+		return []model.LabelSet{}
+	}
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].String() < sets[j].String()
+	})
+	return sets
+}
@@ -0,0 +1,152 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestSimulateNotifications(t *testing.T) {
+	am := setupAMTest(t)
+
+	// Monday at 10am UTC
+	start := time.Date(2023, 1, 9, 10, 0, 0, 0, time.UTC)
+	// Saturday at 10am UTC
+	saturday := time.Date(2023, 1, 7, 10, 0, 0, 0, time.UTC)
+
+	newAlert := func(labels model.LabelSet, startsAt, endsAt time.Time) *types.Alert {
+		return &types.Alert{Alert: model.Alert{Labels: labels, StartsAt: startsAt, EndsAt: endsAt}}
+	}
+
+	t.Run("it returns no notifications if there is no configuration", func(t *testing.T) {
+		result := am.SimulateNotifications([]*types.Alert{newAlert(model.LabelSet{"alertname": "a"}, start, time.Time{})}, start.Add(time.Hour))
+		require.Empty(t, result)
+	})
+
+	cfg, err := Load([]byte(`{
+		"alertmanager_config": {
+			"route": {
+				"receiver": "default",
+				"group_by": ["alertname"],
+				"group_wait": "30s",
+				"group_interval": "5m",
+				"repeat_interval": "1h",
+				"routes": [
+					{
+						"receiver": "team-a",
+						"object_matchers": [["team", "=", "a"]],
+						"mute_time_intervals": ["weekends"]
+					},
+					{
+						"receiver": "quiet",
+						"object_matchers": [["team", "=", "b"]]
+					}
+				]
+			},
+			"mute_time_intervals": [
+				{"name": "weekends", "time_intervals": [{"weekdays": ["saturday", "sunday"]}]}
+			],
+			"receivers": [
+				{"name": "default", "grafana_managed_receiver_configs": [{"uid": "default", "name": "default", "type": "email", "settings": {"addresses": "default@example.com"}}]},
+				{"name": "team-a", "grafana_managed_receiver_configs": [{"uid": "team-a", "name": "team-a", "type": "email", "settings": {"addresses": "a@example.com"}}]},
+				{"name": "quiet", "grafana_managed_receiver_configs": [{"uid": "quiet", "name": "quiet", "type": "email", "disableResolveMessage": true, "settings": {"addresses": "b@example.com"}}]}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	require.NoError(t, am.SaveAndApplyConfig(context.Background(), cfg))
+
+	t.Run("it notifies after group_wait, repeats after repeat_interval and sends the resolved notification", func(t *testing.T) {
+		labels := model.LabelSet{"alertname": "HighCPU"}
+		alerts := []*types.Alert{newAlert(labels, start, start.Add(70*time.Minute))}
+
+		result := am.SimulateNotifications(alerts, start.Add(2*time.Hour))
+
+		group := model.LabelSet{"alertname": "HighCPU"}
+		require.Equal(t, []apimodels.SimulatedNotification{{
+			Time:        start.Add(30 * time.Second),
+			Receiver:    "default",
+			RoutePath:   []int{},
+			GroupLabels: group,
+			Firing:      []model.LabelSet{labels},
+			Resolved:    []model.LabelSet{},
+		}, {
+			Time:        start.Add(time.Hour + 30*time.Second),
+			Receiver:    "default",
+			RoutePath:   []int{},
+			GroupLabels: group,
+			Firing:      []model.LabelSet{labels},
+			Resolved:    []model.LabelSet{},
+			Repeat:      true,
+		}, {
+			Time:        start.Add(70*time.Minute + 30*time.Second),
+			Receiver:    "default",
+			RoutePath:   []int{},
+			GroupLabels: group,
+			Firing:      []model.LabelSet{},
+			Resolved:    []model.LabelSet{labels},
+		}}, result)
+	})
+
+	t.Run("it notifies new alerts of a group at the next group_interval", func(t *testing.T) {
+		first := model.LabelSet{"alertname": "HighCPU", "instance": "1"}
+		second := model.LabelSet{"alertname": "HighCPU", "instance": "2"}
+		alerts := []*types.Alert{
+			newAlert(first, start, time.Time{}),
+			newAlert(second, start.Add(time.Minute), time.Time{}),
+		}
+
+		result := am.SimulateNotifications(alerts, start.Add(10*time.Minute))
+
+		require.Len(t, result, 2)
+		require.Equal(t, start.Add(30*time.Second), result[0].Time)
+		require.Equal(t, []model.LabelSet{first}, result[0].Firing)
+		require.Equal(t, start.Add(5*time.Minute+30*time.Second), result[1].Time)
+		require.Equal(t, []model.LabelSet{first, second}, result[1].Firing)
+		require.False(t, result[1].Repeat)
+	})
+
+	t.Run("it does not notify alerts that resolve before group_wait", func(t *testing.T) {
+		alerts := []*types.Alert{newAlert(model.LabelSet{"alertname": "Flapping"}, start, start.Add(10*time.Second))}
+
+		require.Empty(t, am.SimulateNotifications(alerts, start.Add(time.Hour)))
+	})
+
+	t.Run("it reports the mute timings of muted notifications", func(t *testing.T) {
+		labels := model.LabelSet{"alertname": "HighCPU", "team": "a"}
+		alerts := []*types.Alert{newAlert(labels, saturday, time.Time{})}
+
+		result := am.SimulateNotifications(alerts, saturday.Add(6*time.Minute))
+
+		// Muted notifications are not logged, so the group is notified again at the next flush.
+		require.Len(t, result, 2)
+		for _, n := range result {
+			require.Equal(t, "team-a", n.Receiver)
+			require.Equal(t, []int{0}, n.RoutePath)
+			require.Equal(t, []string{"weekends"}, n.MutedBy)
+		}
+	})
+
+	t.Run("it does not send resolved notifications to contact points that disable them", func(t *testing.T) {
+		labels := model.LabelSet{"alertname": "HighCPU", "team": "b"}
+		alerts := []*types.Alert{newAlert(labels, start, start.Add(10*time.Minute))}
+
+		result := am.SimulateNotifications(alerts, start.Add(time.Hour))
+
+		require.Len(t, result, 1)
+		require.Equal(t, "quiet", result[0].Receiver)
+		require.Equal(t, []model.LabelSet{labels}, result[0].Firing)
+	})
+
+	t.Run("it ignores alerts after the end of the simulation", func(t *testing.T) {
+		alerts := []*types.Alert{newAlert(model.LabelSet{"alertname": "HighCPU"}, start, time.Time{})}
+
+		require.Empty(t, am.SimulateNotifications(alerts, start.Add(10*time.Second)))
+	})
+}
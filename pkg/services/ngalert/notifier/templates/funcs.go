@@ -0,0 +1,42 @@
+// Package templates contains the functions that Grafana adds to notification templates. It has no dependencies on
+// the notifier, so that templates can be validated without it.
+package templates
+
+import (
+	"encoding/json"
+	tmplhtml "html/template"
+	"strings"
+	tmpltext "text/template"
+
+	"github.com/prometheus/alertmanager/template"
+)
+
+// FuncMap are the functions that Grafana adds to the functions of the Alertmanager templates.
+var FuncMap = template.FuncMap{
+	"toJson":     toJSON,
+	"jsonEscape": jsonEscape,
+}
+
+// WithFuncs is an option of template.FromGlobs that adds the FuncMap to the templates.
+func WithFuncs(text *tmpltext.Template, html *tmplhtml.Template) {
+	text.Funcs(tmpltext.FuncMap(FuncMap))
+	html.Funcs(tmplhtml.FuncMap(FuncMap))
+}
+
+// toJSON returns the JSON encoding of the value.
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// jsonEscape escapes the string so that it can be used inside of a JSON string.
+func jsonEscape(s string) (string, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(string(b), `"`), `"`), nil
+}
@@ -0,0 +1,47 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuncs(t *testing.T) {
+	tmpl, err := template.FromGlobs(nil, WithFuncs)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		text     string
+		data     interface{}
+		expected string
+	}{
+		{
+			name:     "toJson encodes a map",
+			text:     `{{ toJson . }}`,
+			data:     map[string]string{"summary": "CPU is \"high\""},
+			expected: `{"summary":"CPU is \"high\""}`,
+		},
+		{
+			name:     "toJson encodes a string with quotes",
+			text:     `{"title": {{ toJson . }}}`,
+			data:     "line 1\nline 2",
+			expected: `{"title": "line 1\nline 2"}`,
+		},
+		{
+			name:     "jsonEscape escapes a string without quotes",
+			text:     `{"title": "[FIRING] {{ jsonEscape . }}"}`,
+			data:     `CPU is "high"`,
+			expected: `{"title": "[FIRING] CPU is \"high\""}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tmpl.ExecuteTextString(tc.text, tc.data)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -15,7 +16,8 @@ import (
 )
 
 type FakeConfigStore struct {
-	configs map[int64]*models.AlertConfiguration
+	configs    map[int64]*models.AlertConfiguration
+	deliveries []models.NotificationDelivery
 }
 
 // Saves the image or returns an error.
@@ -97,6 +99,73 @@ func (f *FakeConfigStore) UpdateAlertmanagerConfiguration(_ context.Context, cmd
 	return errors.New("config not found or hash not valid")
 }
 
+func (f *FakeConfigStore) SaveNotificationDelivery(_ context.Context, delivery *models.NotificationDelivery) error {
+	delivery.ID = int64(len(f.deliveries) + 1)
+	f.deliveries = append(f.deliveries, *delivery)
+	return nil
+}
+
+func (f *FakeConfigStore) GetNotificationDeliveries(_ context.Context, query models.NotificationDeliveryQuery) ([]models.NotificationDelivery, error) {
+	result := make([]models.NotificationDelivery, 0)
+	for i := len(f.deliveries) - 1; i >= 0; i-- {
+		d := f.deliveries[i]
+		if !matchNotificationDelivery(d, query) {
+			continue
+		}
+		result = append(result, d)
+		if query.Limit > 0 && len(result) == query.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func matchNotificationDelivery(d models.NotificationDelivery, query models.NotificationDeliveryQuery) bool {
+	if d.OrgID != query.OrgID {
+		return false
+	}
+	if query.Receiver != "" && d.Receiver != query.Receiver {
+		return false
+	}
+	if query.IntegrationUID != "" && d.IntegrationUID != query.IntegrationUID {
+		return false
+	}
+	if query.Status != "" && d.Status != query.Status {
+		return false
+	}
+	if query.AlertFingerprint != "" {
+		found := false
+		for _, fp := range d.AlertFingerprints {
+			if fp == query.AlertFingerprint {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !query.From.IsZero() && d.SentAt < query.From.UnixMilli() {
+		return false
+	}
+	if !query.To.IsZero() && d.SentAt > query.To.UnixMilli() {
+		return false
+	}
+	return true
+}
+
+func (f *FakeConfigStore) DeleteNotificationDeliveriesBefore(_ context.Context, before time.Time) (int64, error) {
+	kept := f.deliveries[:0]
+	for _, d := range f.deliveries {
+		if d.SentAt >= before.UnixMilli() {
+			kept = append(kept, d)
+		}
+	}
+	n := int64(len(f.deliveries) - len(kept))
+	f.deliveries = kept
+	return n, nil
+}
+
 type FakeOrgStore struct {
 	orgs []int64
 }
@@ -0,0 +1,228 @@
+// Package prom converts Prometheus rule groups to Grafana-managed rule groups.
+package prom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// queryRefID is the refID of the query of the Prometheus expression of a rule.
+	queryRefID = "A"
+	// conditionRefID is the refID of the condition of a converted alerting rule.
+	conditionRefID = "B"
+	// queryTimeRange is the relative time range of the query. Queries are instant queries that are evaluated at the
+	// end of the time range, so the time range only affects the look-back of the data source.
+	queryTimeRange = 10 * time.Minute
+	// alwaysFiring is a math expression that is true for all series of the result of the query. A Prometheus
+	// alerting rule fires for every series that its expression returns, whatever the value of the series is.
+	alwaysFiring = "is_number($A) || is_nan($A) || is_inf($A)"
+)
+
+// Config configures the conversion of Prometheus rule groups.
+type Config struct {
+	// DatasourceUID is the UID of the Prometheus data source the converted rules query.
+	DatasourceUID string
+	// DatasourceType is the type of the data source the converted rules query.
+	DatasourceType string
+	// BaseInterval is the base interval of the scheduler. Evaluation intervals that are not a multiple of it are
+	// rounded up to the next multiple.
+	BaseInterval time.Duration
+}
+
+// ConvertRuleGroups converts Prometheus rule groups to Grafana-managed rule groups. Every rule queries the
+// Prometheus data source with the expression of the rule as an instant query:
+//
+//   - An alerting rule fires for every series of the result of the query, after the duration of its `for` field.
+//     Its labels and annotations are kept, and their templates are converted to the templates of Grafana-managed
+//     alert rules. It is titled after the name of the alert.
+//   - A recording rule records the result of the query as the series of the given name. It is titled after the
+//     name of the series.
+//
+// The titles of the rules must be unique, as the groups are meant to be saved in the same folder.
+func ConvertRuleGroups(cfg Config, groups []apimodels.PrometheusRuleGroup) ([]apimodels.PostableRuleGroupConfig, error) {
+	result := make([]apimodels.PostableRuleGroupConfig, 0, len(groups))
+	groupNames := make(map[string]struct{}, len(groups))
+	titles := make(map[string]string)
+	for _, group := range groups {
+		if group.Name == "" {
+			return nil, errors.New("rule group name cannot be empty")
+		}
+		if _, ok := groupNames[group.Name]; ok {
+			return nil, fmt.Errorf("rule group '%s' is defined more than once", group.Name)
+		}
+		groupNames[group.Name] = struct{}{}
+
+		converted := apimodels.PostableRuleGroupConfig{
+			Name:     group.Name,
+			Interval: model.Duration(roundUpInterval(time.Duration(group.Interval), cfg.BaseInterval)),
+			Rules:    make([]apimodels.PostableExtendedRuleNode, 0, len(group.Rules)),
+		}
+		for idx, rule := range group.Rules {
+			node, err := convertRule(cfg, rule)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rule at index [%d] in rule group '%s': %w", idx, group.Name, err)
+			}
+			title := node.GrafanaManagedAlert.Title
+			if other, ok := titles[title]; ok {
+				return nil, fmt.Errorf("rule '%s' in rule group '%s' has the same name as a rule in rule group '%s', but the titles of the rules of a folder must be unique", title, group.Name, other)
+			}
+			titles[title] = group.Name
+			converted.Rules = append(converted.Rules, node)
+		}
+		result = append(result, converted)
+	}
+	return result, nil
+}
+
+func convertRule(cfg Config, rule apimodels.ApiRuleNode) (apimodels.PostableExtendedRuleNode, error) {
+	if (rule.Alert == "") == (rule.Record == "") {
+		return apimodels.PostableExtendedRuleNode{}, errors.New("rule must have either an alert or a record name")
+	}
+	if rule.Expr == "" {
+		return apimodels.PostableExtendedRuleNode{}, errors.New("rule expression cannot be empty")
+	}
+
+	query, err := prometheusQuery(cfg, rule.Expr)
+	if err != nil {
+		return apimodels.PostableExtendedRuleNode{}, err
+	}
+
+	var forDuration model.Duration
+	if rule.For != nil {
+		forDuration = *rule.For
+	}
+
+	if rule.Record != "" {
+		return apimodels.PostableExtendedRuleNode{
+			ApiRuleNode: &apimodels.ApiRuleNode{
+				For:    &forDuration,
+				Labels: rule.Labels,
+			},
+			GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+				Title:        rule.Record,
+				Data:         []ngmodels.AlertQuery{query},
+				NoDataState:  apimodels.OK,
+				ExecErrState: apimodels.ErrorErrState,
+				Record: &ngmodels.Record{
+					Metric: rule.Record,
+					From:   queryRefID,
+				},
+			},
+		}, nil
+	}
+
+	condition, err := conditionExpression()
+	if err != nil {
+		return apimodels.PostableExtendedRuleNode{}, err
+	}
+	labels, err := convertTemplates(rule.Labels)
+	if err != nil {
+		return apimodels.PostableExtendedRuleNode{}, fmt.Errorf("failed to convert the labels of alert '%s': %w", rule.Alert, err)
+	}
+	annotations, err := convertTemplates(rule.Annotations)
+	if err != nil {
+		return apimodels.PostableExtendedRuleNode{}, fmt.Errorf("failed to convert the annotations of alert '%s': %w", rule.Alert, err)
+	}
+	return apimodels.PostableExtendedRuleNode{
+		ApiRuleNode: &apimodels.ApiRuleNode{
+			For:         &forDuration,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+			Title:        rule.Alert,
+			Condition:    conditionRefID,
+			Data:         []ngmodels.AlertQuery{query, condition},
+			NoDataState:  apimodels.OK,
+			ExecErrState: apimodels.ErrorErrState,
+		},
+	}, nil
+}
+
+func convertTemplates(templates map[string]string) (map[string]string, error) {
+	if templates == nil {
+		return nil, nil
+	}
+	result := make(map[string]string, len(templates))
+	for k, v := range templates {
+		converted, err := convertTemplate(v, queryRefID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for '%s': %w", k, err)
+		}
+		result[k] = converted
+	}
+	return result, nil
+}
+
+type datasourceRef struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+func prometheusQuery(cfg Config, promQL string) (ngmodels.AlertQuery, error) {
+	raw, err := json.Marshal(struct {
+		RefID         string        `json:"refId"`
+		Datasource    datasourceRef `json:"datasource"`
+		Expr          string        `json:"expr"`
+		Instant       bool          `json:"instant"`
+		Range         bool          `json:"range"`
+		IntervalMs    int64         `json:"intervalMs"`
+		MaxDataPoints int64         `json:"maxDataPoints"`
+	}{
+		RefID:         queryRefID,
+		Datasource:    datasourceRef{Type: cfg.DatasourceType, UID: cfg.DatasourceUID},
+		Expr:          promQL,
+		Instant:       true,
+		IntervalMs:    1000,
+		MaxDataPoints: 43200,
+	})
+	if err != nil {
+		return ngmodels.AlertQuery{}, err
+	}
+	return ngmodels.AlertQuery{
+		RefID:             queryRefID,
+		RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(queryTimeRange)},
+		DatasourceUID:     cfg.DatasourceUID,
+		Model:             raw,
+	}, nil
+}
+
+func conditionExpression() (ngmodels.AlertQuery, error) {
+	raw, err := json.Marshal(struct {
+		RefID      string        `json:"refId"`
+		Datasource datasourceRef `json:"datasource"`
+		Type       string        `json:"type"`
+		Expression string        `json:"expression"`
+	}{
+		RefID:      conditionRefID,
+		Datasource: datasourceRef{Type: expr.DatasourceType, UID: expr.DatasourceUID},
+		Type:       "math",
+		Expression: alwaysFiring,
+	})
+	if err != nil {
+		return ngmodels.AlertQuery{}, err
+	}
+	return ngmodels.AlertQuery{
+		RefID:         conditionRefID,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         raw,
+	}, nil
+}
+
+// roundUpInterval rounds the interval up to the next multiple of the base interval. It returns zero, which stands
+// for the default evaluation interval, if the interval is not set.
+func roundUpInterval(interval, base time.Duration) time.Duration {
+	if interval <= 0 || base <= 0 || interval%base == 0 {
+		return interval
+	}
+	return (interval/base + 1) * base
+}
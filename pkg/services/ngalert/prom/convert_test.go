@@ -0,0 +1,157 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestConvertRuleGroups(t *testing.T) {
+	cfg := Config{
+		DatasourceUID:  "prometheus-uid",
+		DatasourceType: "prometheus",
+		BaseInterval:   10 * time.Second,
+	}
+	forDuration := model.Duration(5 * time.Minute)
+
+	t.Run("converts alerting rules", func(t *testing.T) {
+		groups, err := ConvertRuleGroups(cfg, []apimodels.PrometheusRuleGroup{{
+			Name:     "node",
+			Interval: model.Duration(time.Minute),
+			Rules: []apimodels.ApiRuleNode{{
+				Alert:       "HighDiskUsage",
+				Expr:        `node_filesystem_usage > 90`,
+				For:         &forDuration,
+				Labels:      map[string]string{"severity": "critical"},
+				Annotations: map[string]string{"summary": "Disk usage of {{ $labels.instance }} is {{ $value }}%"},
+			}},
+		}})
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		require.Equal(t, "node", groups[0].Name)
+		require.Equal(t, model.Duration(time.Minute), groups[0].Interval)
+		require.Len(t, groups[0].Rules, 1)
+
+		rule := groups[0].Rules[0]
+		require.Equal(t, forDuration, *rule.ApiRuleNode.For)
+		require.Equal(t, map[string]string{"severity": "critical"}, rule.ApiRuleNode.Labels)
+		require.Equal(t, map[string]string{"summary": "Disk usage of {{$labels.instance}} is {{$values.A.Value}}%"}, rule.ApiRuleNode.Annotations)
+
+		grafanaRule := rule.GrafanaManagedAlert
+		require.Equal(t, "HighDiskUsage", grafanaRule.Title)
+		require.Equal(t, "B", grafanaRule.Condition)
+		require.Equal(t, apimodels.OK, grafanaRule.NoDataState)
+		require.Equal(t, apimodels.ErrorErrState, grafanaRule.ExecErrState)
+		require.Nil(t, grafanaRule.Record)
+		require.Len(t, grafanaRule.Data, 2)
+
+		query := grafanaRule.Data[0]
+		require.Equal(t, "A", query.RefID)
+		require.Equal(t, "prometheus-uid", query.DatasourceUID)
+		require.Equal(t, ngmodels.RelativeTimeRange{From: ngmodels.Duration(10 * time.Minute)}, query.RelativeTimeRange)
+		require.JSONEq(t, `{
+			"refId": "A",
+			"datasource": {"type": "prometheus", "uid": "prometheus-uid"},
+			"expr": "node_filesystem_usage > 90",
+			"instant": true,
+			"range": false,
+			"intervalMs": 1000,
+			"maxDataPoints": 43200
+		}`, string(query.Model))
+
+		condition := grafanaRule.Data[1]
+		require.Equal(t, "B", condition.RefID)
+		require.Equal(t, expr.DatasourceUID, condition.DatasourceUID)
+		var conditionModel map[string]interface{}
+		require.NoError(t, json.Unmarshal(condition.Model, &conditionModel))
+		require.Equal(t, "math", conditionModel["type"])
+		require.Equal(t, "is_number($A) || is_nan($A) || is_inf($A)", conditionModel["expression"])
+	})
+
+	t.Run("converts recording rules", func(t *testing.T) {
+		groups, err := ConvertRuleGroups(cfg, []apimodels.PrometheusRuleGroup{{
+			Name: "node",
+			Rules: []apimodels.ApiRuleNode{{
+				Record: "job:node_cpu_seconds:rate5m",
+				Expr:   `sum by (job) (rate(node_cpu_seconds_total[5m]))`,
+				Labels: map[string]string{"team": "infra"},
+			}},
+		}})
+		require.NoError(t, err)
+
+		rule := groups[0].Rules[0]
+		require.Equal(t, map[string]string{"team": "infra"}, rule.ApiRuleNode.Labels)
+		require.Equal(t, "job:node_cpu_seconds:rate5m", rule.GrafanaManagedAlert.Title)
+		require.Empty(t, rule.GrafanaManagedAlert.Condition)
+		require.Equal(t, &ngmodels.Record{Metric: "job:node_cpu_seconds:rate5m", From: "A"}, rule.GrafanaManagedAlert.Record)
+		require.Len(t, rule.GrafanaManagedAlert.Data, 1)
+		require.Equal(t, "A", rule.GrafanaManagedAlert.Data[0].RefID)
+	})
+
+	t.Run("rounds evaluation intervals up to a multiple of the base interval", func(t *testing.T) {
+		groups, err := ConvertRuleGroups(cfg, []apimodels.PrometheusRuleGroup{
+			{Name: "unset", Rules: []apimodels.ApiRuleNode{{Alert: "a", Expr: "up == 0"}}},
+			{Name: "multiple", Interval: model.Duration(30 * time.Second), Rules: []apimodels.ApiRuleNode{{Alert: "b", Expr: "up == 0"}}},
+			{Name: "not multiple", Interval: model.Duration(15 * time.Second), Rules: []apimodels.ApiRuleNode{{Alert: "c", Expr: "up == 0"}}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.Duration(0), groups[0].Interval)
+		require.Equal(t, model.Duration(30*time.Second), groups[1].Interval)
+		require.Equal(t, model.Duration(20*time.Second), groups[2].Interval)
+	})
+
+	t.Run("rejects invalid rule groups", func(t *testing.T) {
+		testCases := []struct {
+			name   string
+			groups []apimodels.PrometheusRuleGroup
+			err    string
+		}{{
+			name:   "group without a name",
+			groups: []apimodels.PrometheusRuleGroup{{Rules: []apimodels.ApiRuleNode{{Alert: "a", Expr: "up == 0"}}}},
+			err:    "rule group name cannot be empty",
+		}, {
+			name: "duplicate groups",
+			groups: []apimodels.PrometheusRuleGroup{
+				{Name: "node", Rules: []apimodels.ApiRuleNode{{Alert: "a", Expr: "up == 0"}}},
+				{Name: "node", Rules: []apimodels.ApiRuleNode{{Alert: "b", Expr: "up == 0"}}},
+			},
+			err: "rule group 'node' is defined more than once",
+		}, {
+			name: "duplicate rule names",
+			groups: []apimodels.PrometheusRuleGroup{
+				{Name: "node", Rules: []apimodels.ApiRuleNode{{Alert: "InstanceDown", Expr: "up == 0"}}},
+				{Name: "kubernetes", Rules: []apimodels.ApiRuleNode{{Alert: "InstanceDown", Expr: "up == 0"}}},
+			},
+			err: "rule 'InstanceDown' in rule group 'kubernetes' has the same name as a rule in rule group 'node'",
+		}, {
+			name:   "rule that is both alerting and recording",
+			groups: []apimodels.PrometheusRuleGroup{{Name: "node", Rules: []apimodels.ApiRuleNode{{Alert: "a", Record: "b", Expr: "up == 0"}}}},
+			err:    "rule must have either an alert or a record name",
+		}, {
+			name:   "rule without an expression",
+			groups: []apimodels.PrometheusRuleGroup{{Name: "node", Rules: []apimodels.ApiRuleNode{{Alert: "a"}}}},
+			err:    "rule expression cannot be empty",
+		}, {
+			name: "unsupported template",
+			groups: []apimodels.PrometheusRuleGroup{{Name: "node", Rules: []apimodels.ApiRuleNode{{
+				Alert:       "a",
+				Expr:        "up == 0",
+				Annotations: map[string]string{"summary": "{{ $externalLabels.cluster }}"},
+			}}}},
+			err: "invalid rule at index [0] in rule group 'node': failed to convert the annotations of alert 'a': invalid template for 'summary'",
+		}}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := ConvertRuleGroups(cfg, tc.groups)
+				require.ErrorContains(t, err, tc.err)
+			})
+		}
+	})
+}
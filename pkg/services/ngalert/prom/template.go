@@ -0,0 +1,125 @@
+package prom
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template/parse"
+)
+
+// prometheusTemplateVariables are the variables that Prometheus defines in the templates of labels and
+// annotations. They are declared before the template is parsed, because the parser rejects undefined variables.
+var prometheusTemplateVariables = []string{
+	"{{$labels := .Labels}}",
+	"{{$externalLabels := .ExternalLabels}}",
+	"{{$externalURL := .ExternalURL}}",
+	"{{$value := .Value}}",
+}
+
+// convertTemplate converts the template of a label or an annotation of a Prometheus alerting rule to a template
+// of a Grafana-managed alert rule. The templates of Grafana-managed alert rules define $labels like Prometheus does,
+// but $value is the evaluation string of the alert rather than the value of the series, so $value is replaced
+// with the value of the query with the given refID. $externalURL is replaced with the externalURL function.
+//
+// Templates that use $externalLabels or the query function cannot be converted, as Grafana-managed alert rules
+// do not support them.
+func convertTemplate(text, refID string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tree := parse.New("template")
+	tree.Mode = parse.SkipFuncCheck
+	trees := make(map[string]*parse.Tree)
+	if _, err := tree.Parse(strings.Join(prometheusTemplateVariables, "")+text, "{{", "}}", trees); err != nil {
+		return "", err
+	}
+	if len(trees) > 1 {
+		return "", errors.New("templates that define other templates are not supported")
+	}
+	// Remove the declarations of the variables.
+	tree.Root.Nodes = tree.Root.Nodes[len(prometheusTemplateVariables):]
+
+	c := templateConverter{refID: refID}
+	c.walk(tree.Root)
+	if c.err != nil {
+		return "", c.err
+	}
+	return tree.Root.String(), nil
+}
+
+type templateConverter struct {
+	refID string
+	err   error
+}
+
+func (c *templateConverter) walk(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			c.walk(child)
+		}
+	case *parse.ActionNode:
+		c.walk(n.Pipe)
+	case *parse.IfNode:
+		c.walkBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		c.walkBranch(&n.BranchNode)
+	case *parse.WithNode:
+		c.walkBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		c.walk(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			for i, arg := range cmd.Args {
+				cmd.Args[i] = c.convert(arg)
+			}
+		}
+	}
+}
+
+func (c *templateConverter) walkBranch(n *parse.BranchNode) {
+	c.walk(n.Pipe)
+	c.walk(n.List)
+	c.walk(n.ElseList)
+}
+
+// convert returns the node that replaces the argument of a command.
+func (c *templateConverter) convert(node parse.Node) parse.Node {
+	switch n := node.(type) {
+	case *parse.VariableNode:
+		switch n.Ident[0] {
+		case "$value":
+			n.Ident = append([]string{"$values", c.refID, "Value"}, n.Ident[1:]...)
+		case "$externalURL":
+			if len(n.Ident) > 1 {
+				c.fail(fmt.Errorf("unsupported use of $externalURL in %s", n))
+				return n
+			}
+			return parse.NewIdentifier("externalURL").SetPos(n.Pos)
+		case "$externalLabels":
+			c.fail(errors.New("$externalLabels is not supported"))
+		}
+	case *parse.IdentifierNode:
+		if n.Ident == "query" {
+			c.fail(errors.New("the query function is not supported"))
+		}
+	case *parse.ChainNode:
+		n.Node = c.convert(n.Node)
+	case *parse.PipeNode:
+		c.walk(n)
+	}
+	return node
+}
+
+func (c *templateConverter) fail(err error) {
+	if c.err == nil {
+		c.err = err
+	}
+}
@@ -0,0 +1,80 @@
+package prom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertTemplate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+		err      string
+	}{{
+		name:     "text without actions is not changed",
+		text:     "Instance is down",
+		expected: "Instance is down",
+	}, {
+		name:     "$labels is kept",
+		text:     "{{ $labels.instance }} of job {{ $labels.job }} is down",
+		expected: "{{$labels.instance}} of job {{$labels.job}} is down",
+	}, {
+		name:     "$value is replaced with the value of the query",
+		text:     "Disk usage is {{ $value }}%",
+		expected: "Disk usage is {{$values.A.Value}}%",
+	}, {
+		name:     "$value is replaced in pipelines",
+		text:     "Disk usage is {{ $value | humanizePercentage }}",
+		expected: "Disk usage is {{$values.A.Value | humanizePercentage}}",
+	}, {
+		name:     "$value is replaced in function arguments",
+		text:     `Disk usage is {{ printf "%.2f" $value }}%`,
+		expected: `Disk usage is {{printf "%.2f" $values.A.Value}}%`,
+	}, {
+		name:     "$value is replaced in conditions",
+		text:     "{{ if gt $value 90.0 }}critical{{ else }}warning{{ end }}",
+		expected: "{{if gt $values.A.Value 90.0}}critical{{else}}warning{{end}}",
+	}, {
+		name:     "$value is replaced in parenthesized pipelines",
+		text:     "{{ (printf \"%.0f\" $value) }}",
+		expected: "{{(printf \"%.0f\" $values.A.Value)}}",
+	}, {
+		name:     "$externalURL is replaced with the externalURL function",
+		text:     "{{ $externalURL }}/alerting/list",
+		expected: "{{externalURL}}/alerting/list",
+	}, {
+		name:     "trim markers are applied",
+		text:     "{{- $labels.instance -}} \n is down",
+		expected: "{{$labels.instance}}is down",
+	}, {
+		name: "$externalLabels is not supported",
+		text: "{{ $externalLabels.cluster }}",
+		err:  "$externalLabels is not supported",
+	}, {
+		name: "the query function is not supported",
+		text: `{{ with query "up" }}{{ . | first | value }}{{ end }}`,
+		err:  "the query function is not supported",
+	}, {
+		name: "defining templates is not supported",
+		text: `{{ define "down" }}is down{{ end }}{{ $labels.instance }} {{ template "down" }}`,
+		err:  "templates that define other templates are not supported",
+	}, {
+		name: "invalid templates are rejected",
+		text: "{{ $labels.instance",
+		err:  "unclosed action",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := convertTemplate(tc.text, "A")
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
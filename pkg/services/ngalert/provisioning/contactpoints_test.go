@@ -47,6 +47,29 @@ func TestContactPointService(t *testing.T) {
 		require.Equal(t, "email receiver", cps[0].Name)
 	})
 
+	t.Run("service redacts secure settings unless asked to decrypt them", func(t *testing.T) {
+		sut := createContactPointServiceSut(secretsService)
+		newCp := createTestContactPoint()
+		_, err := sut.CreateContactPoint(context.Background(), 1, newCp, models.ProvenanceAPI)
+		require.NoError(t, err)
+
+		q := ContactPointQuery{
+			OrgID: 1,
+			Name:  "test-contact-point",
+		}
+		cps, err := sut.GetContactPoints(context.Background(), q)
+		require.NoError(t, err)
+		require.Len(t, cps, 1)
+		require.Equal(t, definitions.RedactedValue, cps[0].Settings.Get("token").MustString())
+
+		q.Decrypt = true
+		cps, err = sut.GetContactPoints(context.Background(), q)
+		require.NoError(t, err)
+		require.Len(t, cps, 1)
+		require.Equal(t, "value_token", cps[0].Settings.Get("token").MustString())
+		require.Equal(t, "value_recipient", cps[0].Settings.Get("recipient").MustString())
+	})
+
 	t.Run("service stitches contact point into org's AM config", func(t *testing.T) {
 		sut := createContactPointServiceSut(secretsService)
 		newCp := createTestContactPoint()
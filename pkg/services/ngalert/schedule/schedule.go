@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/hashicorp/go-multierror"
 	prometheusModel "github.com/prometheus/common/model"
 	"go.opentelemetry.io/otel/attribute"
@@ -49,6 +50,11 @@ type AlertsSender interface {
 	Send(key ngmodels.AlertRuleKey, alerts definitions.PostableAlerts)
 }
 
+// RecordingWriter is an interface for a service that writes the results of recording rules as series.
+type RecordingWriter interface {
+	Write(ctx context.Context, rule *ngmodels.AlertRule, t time.Time, frames data.Frames) error
+}
+
 // RulesStore is a store that provides alert rules for scheduling
 type RulesStore interface {
 	GetAlertRulesKeysForScheduling(ctx context.Context) ([]ngmodels.AlertRuleKeyWithVersion, error)
@@ -90,6 +96,7 @@ type schedule struct {
 	metrics *metrics.Scheduler
 
 	alertsSender    AlertsSender
+	recordingWriter RecordingWriter
 	minRuleInterval time.Duration
 
 	// schedulableAlertRules contains the alert rules that are considered for
@@ -98,6 +105,9 @@ type schedule struct {
 	// last evaluated.
 	schedulableAlertRules alertRulesRegistry
 
+	// sharder assigns the alert rules to the members of the cluster. It is nil if every rule is evaluated by this replica.
+	sharder *ruleSharder
+
 	tracer tracing.Tracer
 }
 
@@ -113,7 +123,10 @@ type SchedulerCfg struct {
 	RuleStore            RulesStore
 	Metrics              *metrics.Scheduler
 	AlertSender          AlertsSender
+	RecordingWriter      RecordingWriter
 	Tracer               tracing.Tracer
+	// ClusterMembership enables the sharding of the evaluation of alert rules between the members of the cluster.
+	ClusterMembership ClusterMembership
 }
 
 // NewScheduler returns a new schedule.
@@ -133,8 +146,12 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		minRuleInterval:       cfg.MinRuleInterval,
 		schedulableAlertRules: alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
 		alertsSender:          cfg.AlertSender,
+		recordingWriter:       cfg.RecordingWriter,
 		tracer:                cfg.Tracer,
 	}
+	if cfg.ClusterMembership != nil {
+		sch.sharder = newRuleSharder(cfg.ClusterMembership, cfg.Metrics, sch.log)
+	}
 
 	return &sch
 }
@@ -229,6 +246,12 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 	sch.metrics.SchedulableAlertRules.Set(float64(len(alertRules)))
 	sch.metrics.SchedulableAlertRulesHash.Set(float64(hashUIDs(alertRules)))
 
+	// takeOver is true if this replica takes over rules from other members of the cluster.
+	var takeOver bool
+	if sch.sharder != nil {
+		alertRules, takeOver = sch.shardAlertRules(alertRules, registeredDefinitions)
+	}
+
 	readyToRun := make([]readyToRunItem, 0)
 	missingFolder := make(map[string][]string)
 	for _, item := range alertRules {
@@ -244,7 +267,13 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		invalidInterval := item.IntervalSeconds%int64(sch.baseInterval.Seconds()) != 0
 
 		if newRoutine && !invalidInterval {
+			rule := item
+			warm := takeOver
 			dispatcherGroup.Go(func() error {
+				// the previous owner of the rule has saved its state in the instance store.
+				if warm {
+					sch.stateManager.WarmRule(ruleInfo.ctx, rule)
+				}
 				return sch.ruleRoutine(ruleInfo.ctx, key, ruleInfo.evalCh, ruleInfo.updateCh)
 			})
 		}
@@ -332,6 +361,45 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 		}
 	}
 
+	// record evaluates a recording rule and writes its result. Recording rules do not have a state.
+	record := func(ctx context.Context, evalCtx eval.EvaluationContext, e *evaluation, span tracing.Span) {
+		logger := logger.New("version", e.rule.Version, "now", e.scheduledAt)
+		start := sch.clock.Now()
+
+		frames, err := sch.evaluateRecordingRule(ctx, evalCtx, e)
+		if err == nil {
+			if sch.recordingWriter == nil {
+				err = errors.New("recording rules are not supported")
+			} else {
+				err = sch.recordingWriter.Write(ctx, e.rule, e.scheduledAt, frames)
+			}
+		}
+		dur := sch.clock.Now().Sub(start)
+
+		evalTotal.Inc()
+		evalDuration.Observe(dur.Seconds())
+
+		if err != nil {
+			evalTotalFailures.Inc()
+			logger.Error("Failed to record rule", "error", err, "duration", dur)
+			span.RecordError(err)
+			span.AddEvents(
+				[]string{"error", "message"},
+				[]tracing.EventValue{
+					{Str: fmt.Sprintf("%v", err)},
+					{Str: "rule recording failed"},
+				})
+			return
+		}
+		logger.Debug("Recording rule evaluated", "frames", len(frames), "duration", dur)
+		span.AddEvents(
+			[]string{"message", "frames"},
+			[]tracing.EventValue{
+				{Str: "rule recorded"},
+				{Num: int64(len(frames))},
+			})
+	}
+
 	evaluate := func(ctx context.Context, attempt int64, e *evaluation, span tracing.Span) {
 		logger := logger.New("version", e.rule.Version, "attempt", attempt, "now", e.scheduledAt)
 		start := sch.clock.Now()
@@ -351,7 +419,20 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 			},
 		}
 		evalCtx := eval.Context(ctx, schedulerUser)
-		ruleEval, err := sch.evaluatorFactory.Create(evalCtx, e.rule.GetEvalCondition())
+		if e.rule.IsRecordingRule() {
+			record(ctx, evalCtx, e, span)
+			return
+		}
+		condition := e.rule.GetEvalCondition()
+		var err error
+		// Threshold expressions with a recovery threshold need to know which instances were firing.
+		if loaded := sch.stateManager.GetLoadedDimensions(e.rule.OrgID, e.rule.UID); len(loaded) > 0 {
+			condition, err = condition.WithLoadedDimensions(loaded)
+		}
+		var ruleEval eval.ConditionEvaluator
+		if err == nil {
+			ruleEval, err = sch.evaluatorFactory.Create(evalCtx, condition)
+		}
 		var results eval.Results
 		var dur time.Duration
 		if err == nil {
@@ -491,12 +572,36 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 			if errors.Is(grafanaCtx.Err(), errRuleDeleted) {
 				clearState()
 			}
+			// keep the state in the instance store for the member of the cluster that takes the rule over
+			if errors.Is(grafanaCtx.Err(), errRuleReassigned) {
+				sch.stateManager.ForgetStateByRuleUID(key)
+			}
 			logger.Debug("Stopping alert rule routine")
 			return nil
 		}
 	}
 }
 
+// evaluateRecordingRule evaluates the queries and expressions of a recording rule and returns the result to record.
+func (sch *schedule) evaluateRecordingRule(ctx context.Context, evalCtx eval.EvaluationContext, e *evaluation) (data.Frames, error) {
+	ruleEval, err := sch.evaluatorFactory.Create(evalCtx, e.rule.GetEvalCondition())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build rule evaluator: %w", err)
+	}
+	resp, err := ruleEval.EvaluateRaw(ctx, e.scheduledAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate rule: %w", err)
+	}
+	result, ok := resp.Responses[e.rule.Record.From]
+	if !ok {
+		return nil, fmt.Errorf("no result for '%s'", e.rule.Record.From)
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to evaluate '%s': %w", e.rule.Record.From, result.Error)
+	}
+	return result.Frames, nil
+}
+
 // evalApplied is only used on tests.
 func (sch *schedule) evalApplied(alertDefKey ngmodels.AlertRuleKey, now time.Time) {
 	if sch.evalAppliedFunc == nil {
@@ -376,6 +376,28 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 
 			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
 		})
+		t.Run("and forget the state without expiring alerts if the rule is reassigned", func(t *testing.T) {
+			stoppedChan := make(chan error)
+			sender := AlertsSenderMock{}
+			sch, _, _, _ := createSchedule(make(chan time.Time), &sender)
+
+			rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting))()
+			_ = sch.stateManager.ProcessEvalResults(context.Background(), sch.clock.Now(), rule, eval.GenerateResults(rand.Intn(5)+1, eval.ResultGen(eval.WithEvaluatedAt(sch.clock.Now()), eval.WithState(eval.Alerting))), nil)
+			require.NotEmpty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+
+			ctx, cancel := util.WithCancelCause(context.Background())
+			go func() {
+				err := sch.ruleRoutine(ctx, rule.GetKey(), make(chan *evaluation), make(chan ruleVersion))
+				stoppedChan <- err
+			}()
+
+			cancel(errRuleReassigned)
+			err := waitForErrChannel(t, stoppedChan)
+			require.NoError(t, err)
+
+			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+			sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+		})
 	})
 
 	t.Run("when a message is sent to update channel", func(t *testing.T) {
@@ -591,6 +613,76 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 
 		require.NotEmpty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
 	})
+
+	t.Run("when the rule is a recording rule", func(t *testing.T) {
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), models.WithRecord("test:recorded"))()
+
+		evalChan := make(chan *evaluation)
+		evalAppliedChan := make(chan time.Time)
+
+		sender := AlertsSenderMock{}
+		sch, ruleStore, _, reg := createSchedule(evalAppliedChan, &sender)
+		ruleStore.PutRule(context.Background(), rule)
+		writer := &fakeRecordingWriter{}
+		sch.recordingWriter = writer
+
+		go func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersion))
+		}()
+
+		expectedTime := sch.clock.Now()
+		evalChan <- &evaluation{
+			scheduledAt: expectedTime,
+			rule:        rule,
+		}
+
+		waitForTimeChannel(t, evalAppliedChan)
+
+		t.Run("it should write the result of the recorded expression", func(t *testing.T) {
+			require.Len(t, writer.writes, 1)
+			require.Equal(t, rule, writer.writes[0].rule)
+			require.Equal(t, expectedTime, writer.writes[0].t)
+			require.Len(t, writer.writes[0].frames, 1)
+			value, err := writer.writes[0].frames[0].Fields[0].NullableFloatAt(0)
+			require.NoError(t, err)
+			require.Equal(t, 1.0, *value)
+		})
+
+		t.Run("it should not create state or send alerts", func(t *testing.T) {
+			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+			sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+		})
+
+		t.Run("it reports metrics", func(t *testing.T) {
+			metrics, err := reg.Gather()
+			require.NoError(t, err)
+			for _, m := range metrics {
+				if m.GetName() != "grafana_alerting_rule_evaluations_total" {
+					continue
+				}
+				require.Equal(t, 1.0, m.GetMetric()[0].GetCounter().GetValue())
+				return
+			}
+			require.Fail(t, "metric grafana_alerting_rule_evaluations_total is not reported")
+		})
+	})
+}
+
+type recordingWrite struct {
+	rule   *models.AlertRule
+	t      time.Time
+	frames data.Frames
+}
+
+type fakeRecordingWriter struct {
+	writes []recordingWrite
+}
+
+func (f *fakeRecordingWriter) Write(_ context.Context, rule *models.AlertRule, t time.Time, frames data.Frames) error {
+	f.writes = append(f.writes, recordingWrite{rule: rule, t: t, frames: frames})
+	return nil
 }
 
 func TestSchedule_UpdateAlertRule(t *testing.T) {
@@ -0,0 +1,191 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"github.com/prometheus/alertmanager/cluster"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ringTokensPerMember is the number of tokens, or virtual nodes, of every member in the ring.
+// The more tokens, the more even the distribution of the rules between the members.
+const ringTokensPerMember = 128
+
+var errRuleReassigned = errors.New("rule reassigned to another member of the cluster")
+
+// ClusterMembership provides the members of the high availability cluster that share the evaluation of alert rules.
+type ClusterMembership interface {
+	// Name returns the name of this replica in the cluster.
+	Name() string
+	// Peers returns the alive members of the cluster, including this replica.
+	Peers() []cluster.ClusterMember
+}
+
+// hashRing is a consistent hash ring. Every member owns the keys whose tokens come after
+// one of its tokens and before the next token of any member.
+type hashRing struct {
+	tokens []uint64
+	owners []string
+}
+
+func newHashRing(members []string, tokensPerMember int) hashRing {
+	type token struct {
+		value uint64
+		owner string
+	}
+	tokens := make([]token, 0, len(members)*tokensPerMember)
+	for _, member := range members {
+		for i := 0; i < tokensPerMember; i++ {
+			tokens = append(tokens, token{value: hashToken(member + "-" + strconv.Itoa(i)), owner: member})
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].value == tokens[j].value {
+			return tokens[i].owner < tokens[j].owner
+		}
+		return tokens[i].value < tokens[j].value
+	})
+
+	r := hashRing{tokens: make([]uint64, 0, len(tokens)), owners: make([]string, 0, len(tokens))}
+	for _, t := range tokens {
+		r.tokens = append(r.tokens, t.value)
+		r.owners = append(r.owners, t.owner)
+	}
+	return r
+}
+
+// owner returns the member that owns the token. It returns an empty string if the ring is empty.
+func (r hashRing) owner(token uint64) string {
+	if len(r.tokens) == 0 {
+		return ""
+	}
+	i := sort.Search(len(r.tokens), func(i int) bool { return r.tokens[i] >= token })
+	if i == len(r.tokens) {
+		i = 0
+	}
+	return r.owners[i]
+}
+
+func hashToken(s string) uint64 {
+	h := fnv.New64a()
+	// We can ignore err as fnv64 does not return an error
+	// nolint:errcheck,gosec
+	h.Write([]byte(s))
+	// fnv does not spread similar inputs, such as the tokens of a member, evenly enough over the ring.
+	// Mix the bits with the finalizer of MurmurHash3.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+func ruleToken(key ngmodels.AlertRuleKey) uint64 {
+	return hashToken(fmt.Sprintf("%d/%s", key.OrgID, key.UID))
+}
+
+// ruleSharder assigns every alert rule to one member of the cluster with consistent hashing, so that only a
+// fraction of the rules move to other members when a member joins or leaves the cluster.
+// It is only used by the scheduling loop and is not safe for concurrent use.
+type ruleSharder struct {
+	membership ClusterMembership
+	metrics    *metrics.Scheduler
+	log        log.Logger
+
+	self    string
+	members []string
+	ring    hashRing
+}
+
+func newRuleSharder(membership ClusterMembership, m *metrics.Scheduler, logger log.Logger) *ruleSharder {
+	return &ruleSharder{
+		membership: membership,
+		metrics:    m,
+		log:        logger,
+	}
+}
+
+// refresh rebuilds the ring if the members of the cluster have changed since the last refresh.
+// It returns whether the ring has changed and whether it is the first ring.
+func (s *ruleSharder) refresh() (changed bool, first bool) {
+	self := s.membership.Name()
+	members := []string{self}
+	for _, peer := range s.membership.Peers() {
+		if peer.Name() != self {
+			members = append(members, peer.Name())
+		}
+	}
+	sort.Strings(members)
+
+	first = s.members == nil
+	if !first && self == s.self && equalMembers(members, s.members) {
+		return false, false
+	}
+	s.self = self
+	s.members = members
+	s.ring = newHashRing(members, ringTokensPerMember)
+	s.metrics.ShardMembers.Set(float64(len(members)))
+	if !first {
+		s.metrics.ShardRebalances.Inc()
+	}
+	s.log.Info("Members of the cluster have changed, rebalancing alert rules", "self", self, "members", members)
+	return true, first
+}
+
+// owns returns true if the rule is evaluated by this replica.
+func (s *ruleSharder) owns(key ngmodels.AlertRuleKey) bool {
+	owner := s.ring.owner(ruleToken(key))
+	return owner == "" || owner == s.self
+}
+
+func equalMembers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// shardAlertRules returns the rules that this replica evaluates, and whether it takes over rules from other members
+// because the cluster has been rebalanced. The routines of the registered rules that are assigned to other members
+// are stopped, and the rules are removed from registered. Their state is kept in the instance store, so that the
+// member that takes them over can load it.
+func (sch *schedule) shardAlertRules(alertRules []*ngmodels.AlertRule, registered map[ngmodels.AlertRuleKey]struct{}) ([]*ngmodels.AlertRule, bool) {
+	changed, first := sch.sharder.refresh()
+
+	owned := make([]*ngmodels.AlertRule, 0, len(alertRules))
+	for _, rule := range alertRules {
+		key := rule.GetKey()
+		if sch.sharder.owns(key) {
+			owned = append(owned, rule)
+			continue
+		}
+		if _, ok := registered[key]; ok {
+			delete(registered, key)
+			if ruleInfo, ok := sch.registry.del(key); ok {
+				sch.log.Debug("Alert rule is reassigned to another member of the cluster", key.LogContext()...)
+				ruleInfo.stop(errRuleReassigned)
+			}
+			continue
+		}
+		// forget the states that the state manager has loaded at startup for the rules of other members.
+		if changed {
+			sch.stateManager.ForgetStateByRuleUID(key)
+		}
+	}
+	sch.metrics.OwnedAlertRules.Set(float64(len(owned)))
+	return owned, changed && !first
+}
@@ -0,0 +1,177 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/cluster"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestHashRing(t *testing.T) {
+	keys := make([]uint64, 0, 3000)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, ruleToken(models.AlertRuleKey{OrgID: int64(i%3 + 1), UID: fmt.Sprintf("rule-%d", i)}))
+	}
+
+	t.Run("an empty ring has no owner", func(t *testing.T) {
+		require.Equal(t, "", newHashRing(nil, ringTokensPerMember).owner(keys[0]))
+	})
+
+	t.Run("distributes the keys evenly between the members", func(t *testing.T) {
+		ring := newHashRing([]string{"a", "b", "c"}, ringTokensPerMember)
+		owned := map[string]int{}
+		for _, key := range keys {
+			owned[ring.owner(key)]++
+		}
+		require.Len(t, owned, 3)
+		for member, count := range owned {
+			require.InDeltaf(t, len(keys)/3, count, float64(len(keys))/10, "member %s owns %d keys", member, count)
+		}
+	})
+
+	t.Run("only moves the keys of the member that leaves", func(t *testing.T) {
+		before := newHashRing([]string{"a", "b", "c"}, ringTokensPerMember)
+		after := newHashRing([]string{"a", "b"}, ringTokensPerMember)
+		for _, key := range keys {
+			if owner := before.owner(key); owner != "c" {
+				require.Equal(t, owner, after.owner(key))
+			}
+		}
+	})
+}
+
+func TestSchedule_shardAlertRules(t *testing.T) {
+	ruleStore := newFakeRulesStore()
+	gen := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(time.Second), withQueryForState(t, eval.Normal))
+	rules := models.GenerateAlertRules(30, gen)
+	ruleStore.PutRule(context.Background(), rules...)
+
+	membership := &fakeClusterMembership{members: []string{"a", "b"}}
+	newSharded := func(name string, instanceStore *state.FakeInstanceStore) (*schedule, chan models.AlertRuleKey) {
+		sch := setupScheduler(t, ruleStore, instanceStore, nil, nil, nil)
+		sch.sharder = newRuleSharder(membership.as(name), sch.metrics, sch.log)
+		stopped := make(chan models.AlertRuleKey, len(rules))
+		sch.stopAppliedFunc = func(key models.AlertRuleKey) {
+			stopped <- key
+		}
+		return sch, stopped
+	}
+	instanceStore := &state.FakeInstanceStore{}
+	schA, stoppedA := newSharded("a", instanceStore)
+	schB, _ := newSharded("b", &state.FakeInstanceStore{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	tick := time.Time{}.Add(time.Second)
+	scheduledA, _ := schA.processTick(ctx, dispatcherGroup, tick)
+	scheduledB, _ := schB.processTick(ctx, dispatcherGroup, tick)
+
+	ownedA := scheduledKeys(scheduledA)
+	ownedB := scheduledKeys(scheduledB)
+	t.Run("every rule is evaluated by one member", func(t *testing.T) {
+		require.NotEmpty(t, ownedA)
+		require.NotEmpty(t, ownedB)
+		require.Len(t, rules, len(ownedA)+len(ownedB))
+		for key := range ownedA {
+			require.NotContains(t, ownedB, key)
+		}
+	})
+
+	t.Run("the remaining member takes over the rules of the member that leaves", func(t *testing.T) {
+		membership.set("a")
+		tick = tick.Add(time.Second)
+		scheduled, stopped := schA.processTick(ctx, dispatcherGroup, tick)
+		require.Len(t, scheduled, len(rules))
+		require.Empty(t, stopped)
+
+		// the state of the rules that were taken over is loaded from the instance store.
+		require.Eventually(t, func() bool {
+			loaded := map[string]struct{}{}
+			for _, op := range instanceStore.RecordedOps {
+				if q, ok := op.(models.ListAlertInstancesQuery); ok {
+					loaded[q.RuleUID] = struct{}{}
+				}
+			}
+			return len(loaded) == len(ownedB)
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("the rules move back when the member joins again", func(t *testing.T) {
+		membership.set("a", "b")
+		tick = tick.Add(time.Second)
+		scheduled, stopped := schA.processTick(ctx, dispatcherGroup, tick)
+		require.Equal(t, ownedA, scheduledKeys(scheduled))
+		// the rules are reassigned, not deleted.
+		require.Empty(t, stopped)
+		for range ownedB {
+			select {
+			case key := <-stoppedA:
+				require.Contains(t, ownedB, key)
+			case <-time.After(time.Second):
+				t.Fatal("rule routine was not stopped")
+			}
+		}
+		for key := range ownedB {
+			require.False(t, schA.registry.exists(key))
+			require.NotNil(t, schA.schedulableAlertRules.get(key))
+		}
+	})
+}
+
+func scheduledKeys(items []readyToRunItem) map[models.AlertRuleKey]struct{} {
+	keys := make(map[models.AlertRuleKey]struct{}, len(items))
+	for _, item := range items {
+		keys[item.rule.GetKey()] = struct{}{}
+	}
+	return keys
+}
+
+type fakeClusterMembership struct {
+	mtx     sync.Mutex
+	members []string
+}
+
+func (f *fakeClusterMembership) set(members ...string) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.members = members
+}
+
+// as returns the membership as seen by the member with the given name.
+func (f *fakeClusterMembership) as(name string) ClusterMembership {
+	return &fakeClusterMember{membership: f, name: name}
+}
+
+type fakeClusterMember struct {
+	membership *fakeClusterMembership
+	name       string
+}
+
+func (f *fakeClusterMember) Name() string {
+	return f.name
+}
+
+func (f *fakeClusterMember) Address() string {
+	return f.name
+}
+
+func (f *fakeClusterMember) Peers() []cluster.ClusterMember {
+	f.membership.mtx.Lock()
+	defer f.membership.mtx.Unlock()
+	peers := make([]cluster.ClusterMember, 0, len(f.membership.members))
+	for _, member := range f.membership.members {
+		peers = append(peers, &fakeClusterMember{membership: f.membership, name: member})
+	}
+	return peers
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/annotations"
@@ -20,13 +22,20 @@ import (
 type AnnotationBackend struct {
 	annotations annotations.Repository
 	dashboards  *dashboardResolver
+	rules       RuleStore
 	log         log.Logger
 }
 
-func NewAnnotationBackend(annotations annotations.Repository, dashboards dashboards.DashboardService) *AnnotationBackend {
+// RuleStore represents the ability to fetch alert rules.
+type RuleStore interface {
+	GetAlertRuleByUID(ctx context.Context, query *ngmodels.GetAlertRuleByUIDQuery) error
+}
+
+func NewAnnotationBackend(annotations annotations.Repository, dashboards dashboards.DashboardService, rules RuleStore) *AnnotationBackend {
 	return &AnnotationBackend{
 		annotations: annotations,
 		dashboards:  newDashboardResolver(dashboards, defaultDashboardCacheExpiry),
+		rules:       rules,
 		log:         log.New("ngalert.state.historian"),
 	}
 }
@@ -40,6 +49,75 @@ func (h *AnnotationBackend) RecordStatesAsync(ctx context.Context, rule *ngmodel
 	go h.recordAnnotationsSync(ctx, panel, annotations, logger)
 }
 
+// QueryStates returns the state history of a rule that matches the query as a frame suitable for a state timeline panel.
+// Annotations are stored by rule, so the query must have a rule UID.
+func (h *AnnotationBackend) QueryStates(ctx context.Context, query ngmodels.HistoryQuery) (*data.Frame, error) {
+	if query.RuleUID == "" {
+		return nil, fmt.Errorf("ruleUID is required to query annotations")
+	}
+
+	ruleQuery := ngmodels.GetAlertRuleByUIDQuery{UID: query.RuleUID, OrgID: query.OrgID}
+	if err := h.rules.GetAlertRuleByUID(ctx, &ruleQuery); err != nil {
+		return nil, fmt.Errorf("failed to look up the requested rule: %w", err)
+	}
+	rule := ruleQuery.Result
+
+	q := annotations.ItemQuery{
+		OrgId:        query.OrgID,
+		AlertId:      rule.ID,
+		Type:         "alert",
+		SignedInUser: query.SignedInUser,
+	}
+	if !query.From.IsZero() {
+		q.From = query.From.UnixMilli()
+	}
+	if !query.To.IsZero() {
+		q.To = query.To.UnixMilli()
+	}
+	// The labels are filtered after the query, so the annotations are fetched again with a greater limit until the
+	// limit of the query is reached or there are no more annotations.
+	fetch := int64(query.Limit)
+	var entries []historyEntry
+	for {
+		q.Limit = fetch
+		items, err := h.annotations.Find(ctx, &q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query annotations for state history: %w", err)
+		}
+		entries = h.historyEntries(ctx, rule, items, query.Labels)
+		if query.Limit <= 0 || len(entries) >= query.Limit || int64(len(items)) < fetch {
+			break
+		}
+		fetch *= 2
+	}
+	// The annotations are returned newest first, so the limit keeps the newest entries.
+	if query.Limit > 0 && len(entries) > query.Limit {
+		entries = entries[:query.Limit]
+	}
+	return buildStateTimelineFrame(entries, query.States), nil
+}
+
+// historyEntries returns the entries of the annotations of the rule for the alert instances with the given labels.
+func (h *AnnotationBackend) historyEntries(ctx context.Context, rule *ngmodels.AlertRule, items []*annotations.ItemDTO, matchers map[string]string) []historyEntry {
+	entries := make([]historyEntry, 0, len(items))
+	for _, item := range items {
+		labels, ok := annotationLabels(rule.Title, item)
+		if !ok {
+			h.log.FromContext(ctx).Debug("Skipping annotation with unexpected text", "annotationID", item.Id)
+			continue
+		}
+		if !matchLabels(labels, matchers) {
+			continue
+		}
+		entries = append(entries, historyEntry{
+			labels: labels,
+			state:  item.NewState,
+			time:   time.UnixMilli(item.Time),
+		})
+	}
+	return entries
+}
+
 func (h *AnnotationBackend) buildAnnotations(rule *ngmodels.AlertRule, states []state.StateTransition, logger log.Logger) []annotations.Item {
 	items := make([]annotations.Item, 0, len(states))
 	for _, state := range states {
@@ -117,5 +195,64 @@ func buildAnnotationTextAndData(rule *ngmodels.AlertRule, currentState *state.St
 	}
 
 	labels := removePrivateLabels(currentState.Labels)
+	jsonData.Set("labels", labels)
 	return fmt.Sprintf("%s {%s} - %s", rule.Title, labels.String(), value), jsonData
 }
+
+// annotationLabels returns the labels of the alert instance of an annotation of the rule with the given title. The
+// labels are stored in the data of the annotation, or parsed from its text if the annotation was created before they
+// were stored.
+func annotationLabels(title string, item *annotations.ItemDTO) (data.Labels, bool) {
+	if item.Data != nil {
+		if stored, err := item.Data.Get("labels").Map(); err == nil {
+			labels := make(data.Labels, len(stored))
+			for k, v := range stored {
+				value, ok := v.(string)
+				if !ok {
+					return nil, false
+				}
+				labels[k] = value
+			}
+			return labels, true
+		}
+	}
+	return parseAnnotationLabels(title, item.Text)
+}
+
+// parseAnnotationLabels parses the labels of an alert instance from the text of an annotation created by buildAnnotationTextAndData.
+// If the text does not start with the title, the annotation was created before the rule was renamed, and the labels
+// start at the first " {". The text is not structured, so a former title that contains " {" or a label value that
+// contains ", " followed by a "=" can be parsed incorrectly.
+func parseAnnotationLabels(title, text string) (data.Labels, bool) {
+	start := len(title)
+	if !strings.HasPrefix(text, title+" {") {
+		start = strings.Index(text, " {")
+		if start < 0 {
+			return nil, false
+		}
+	}
+	rest := text[start+len(" {"):]
+	end := strings.LastIndex(rest, "} - ")
+	if end < 0 {
+		return nil, false
+	}
+	labels := data.Labels{}
+	var last string
+	for _, pair := range strings.Split(rest[:end], ", ") {
+		if pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			if last == "" {
+				return nil, false
+			}
+			// The pair is part of the value of the previous label.
+			labels[last] += ", " + pair
+			continue
+		}
+		labels[k] = v
+		last = k
+	}
+	return labels, true
+}
@@ -0,0 +1,165 @@
+package historian
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestParseAnnotationLabels(t *testing.T) {
+	rule := &models.AlertRule{Title: "High {latency} - p99"}
+
+	cases := []struct {
+		desc   string
+		labels data.Labels
+	}{
+		{
+			desc:   "multiple labels",
+			labels: data.Labels{"instance": "web-01:9100", "job": "node"},
+		},
+		{
+			desc:   "no labels",
+			labels: data.Labels{},
+		},
+		{
+			desc:   "value with separator",
+			labels: data.Labels{"a": "x, y", "b": "z"},
+		},
+		{
+			desc:   "value with braces",
+			labels: data.Labels{"query": "{job} - x"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			text, _ := buildAnnotationTextAndData(rule, &state.State{State: eval.Alerting, Labels: tc.labels, Values: map[string]float64{"A": 1}})
+			labels, ok := parseAnnotationLabels(rule.Title, text)
+			require.True(t, ok)
+			require.Equal(t, tc.labels, labels)
+		})
+	}
+
+	t.Run("text of the rule before it was renamed is parsed", func(t *testing.T) {
+		labels, ok := parseAnnotationLabels(rule.Title, "Old title {instance=a} - A=1.000000")
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"instance": "a"}, labels)
+	})
+
+	t.Run("text without labels is not parsed", func(t *testing.T) {
+		_, ok := parseAnnotationLabels(rule.Title, "Old title - A=1.000000")
+		require.False(t, ok)
+	})
+}
+
+func TestAnnotationLabels(t *testing.T) {
+	rule := &models.AlertRule{Title: "Renamed"}
+	labels := data.Labels{"a": "x, b=y"}
+
+	_, jsonData := buildAnnotationTextAndData(&models.AlertRule{Title: "Old title"}, &state.State{State: eval.Alerting, Labels: labels})
+	// The data is read back from the database.
+	raw, err := jsonData.MarshalJSON()
+	require.NoError(t, err)
+	stored, err := simplejson.NewJson(raw)
+	require.NoError(t, err)
+
+	result, ok := annotationLabels(rule.Title, &annotations.ItemDTO{Text: "Old title {a=x, b=y} - ", Data: stored})
+	require.True(t, ok)
+	require.Equal(t, labels, result)
+}
+
+func TestAnnotationBackendQueryStates(t *testing.T) {
+	rule := models.AlertRuleGen()()
+	rules := &fakeRuleStore{rule: rule}
+	now := time.Now()
+
+	// The annotations are returned newest first, like the annotations repository does.
+	var items []*annotations.ItemDTO
+	for i := 0; i < 10; i++ {
+		instance := "a"
+		if i%3 == 0 {
+			instance = "b"
+		}
+		text, jsonData := buildAnnotationTextAndData(rule, &state.State{State: eval.Alerting, Labels: data.Labels{"instance": instance}})
+		raw, err := jsonData.MarshalJSON()
+		require.NoError(t, err)
+		stored, err := simplejson.NewJson(raw)
+		require.NoError(t, err)
+		items = append(items, &annotations.ItemDTO{
+			Id:       int64(i + 1),
+			Text:     text,
+			Data:     stored,
+			NewState: eval.Alerting.String(),
+			Time:     now.Add(-time.Duration(i) * time.Minute).UnixMilli(),
+		})
+	}
+	repo := &fakeAnnotationsFinder{items: items}
+	h := &AnnotationBackend{annotations: repo, rules: rules, log: log.NewNopLogger()}
+
+	t.Run("should apply the limit after filtering by labels", func(t *testing.T) {
+		frame, err := h.QueryStates(context.Background(), models.HistoryQuery{
+			OrgID:   rule.OrgID,
+			RuleUID: rule.UID,
+			Labels:  map[string]string{"instance": "b"},
+			Limit:   3,
+		})
+		require.NoError(t, err)
+		require.Len(t, frame.Fields, 2)
+		require.Equal(t, data.Labels{"instance": "b"}, frame.Fields[1].Labels)
+		require.Equal(t, 3, frame.Rows())
+
+		// The newest entries are returned.
+		times := frame.Fields[0]
+		require.Equal(t, now.Add(-6*time.Minute).UnixMilli(), times.At(0).(time.Time).UnixMilli())
+		require.Equal(t, now.UnixMilli(), times.At(2).(time.Time).UnixMilli())
+	})
+
+	t.Run("should not fetch again if the limit is reached", func(t *testing.T) {
+		repo.queries = nil
+		frame, err := h.QueryStates(context.Background(), models.HistoryQuery{
+			OrgID:   rule.OrgID,
+			RuleUID: rule.UID,
+			Limit:   2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Len(t, repo.queries, 1)
+	})
+}
+
+type fakeRuleStore struct {
+	rule *models.AlertRule
+}
+
+func (f *fakeRuleStore) GetAlertRuleByUID(_ context.Context, query *models.GetAlertRuleByUIDQuery) error {
+	if query.UID != f.rule.UID {
+		return models.ErrAlertRuleNotFound
+	}
+	query.Result = f.rule
+	return nil
+}
+
+// fakeAnnotationsFinder returns the first items up to the limit of the query.
+type fakeAnnotationsFinder struct {
+	annotations.Repository
+	items   []*annotations.ItemDTO
+	queries []annotations.ItemQuery
+}
+
+func (f *fakeAnnotationsFinder) Find(_ context.Context, query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
+	f.queries = append(f.queries, *query)
+	if query.Limit > 0 && int(query.Limit) < len(f.items) {
+		return f.items[:query.Limit], nil
+	}
+	return f.items, nil
+}
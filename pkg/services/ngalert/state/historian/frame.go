@@ -0,0 +1,97 @@
+package historian
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// historyEntry is a state transition of an alert instance read back from a historian backend.
+type historyEntry struct {
+	labels data.Labels
+	// state is the formatted state the instance transitioned to, such as "Alerting" or "Normal (MissingSeries)".
+	state string
+	time  time.Time
+}
+
+// buildStateTimelineFrame returns a frame with a time field and a string field with the state of each alert instance.
+// The frame has a row for every time an instance changed state. The state of an instance is carried forward until
+// its next transition, so each field describes the periods the instance spent in each state. If states is not empty,
+// only the periods in one of the given states are kept and instances that never were in those states are dropped.
+func buildStateTimelineFrame(entries []historyEntry, states []string) *data.Frame {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].time.Before(entries[j].time)
+	})
+
+	times := make([]time.Time, 0, len(entries))
+	for _, e := range entries {
+		if len(times) == 0 || !times[len(times)-1].Equal(e.time) {
+			times = append(times, e.time)
+		}
+	}
+
+	type instance struct {
+		labels data.Labels
+		values []*string
+	}
+	var instances []*instance
+	byLabels := make(map[string]*instance)
+	row := -1
+	for i, e := range entries {
+		if i == 0 || !entries[i-1].time.Equal(e.time) {
+			row++
+		}
+		key := e.labels.String()
+		inst, ok := byLabels[key]
+		if !ok {
+			inst = &instance{labels: e.labels, values: make([]*string, len(times))}
+			byLabels[key] = inst
+			instances = append(instances, inst)
+		}
+		state := e.state
+		inst.values[row] = &state
+	}
+
+	fields := make([]*data.Field, 0, len(instances)+1)
+	fields = append(fields, data.NewField("time", nil, times))
+	for _, inst := range instances {
+		var current *string
+		found := false
+		for i, v := range inst.values {
+			if v != nil {
+				current = v
+			}
+			if current != nil && !matchState(*current, states) {
+				inst.values[i] = nil
+				continue
+			}
+			inst.values[i] = current
+			found = found || current != nil
+		}
+		if !found {
+			continue
+		}
+		fields = append(fields, data.NewField("state", inst.labels, inst.values))
+	}
+	return data.NewFrame("State history", fields...)
+}
+
+// matchState returns true if the formatted state is one of states, ignoring the reason and case.
+// All states match if states is empty.
+func matchState(formatted string, states []string) bool {
+	if len(states) == 0 {
+		return true
+	}
+	name := formatted
+	if i := strings.Index(name, " ("); i >= 0 {
+		name = name[:i]
+	}
+	for _, s := range states {
+		if strings.EqualFold(name, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package historian
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildStateTimelineFrame(t *testing.T) {
+	start := time.Unix(0, 0)
+	a := data.Labels{"instance": "a"}
+	b := data.Labels{"instance": "b"}
+	entries := []historyEntry{
+		{labels: b, state: "Pending", time: start.Add(time.Minute)},
+		{labels: a, state: "Alerting", time: start},
+		{labels: a, state: "Normal", time: start.Add(2 * time.Minute)},
+		{labels: b, state: "Alerting (Error)", time: start.Add(2 * time.Minute)},
+	}
+
+	cases := []struct {
+		desc     string
+		states   []string
+		expected *data.Frame
+	}{
+		{
+			desc: "states are carried forward until the next transition",
+			expected: data.NewFrame("State history",
+				data.NewField("time", nil, []time.Time{start, start.Add(time.Minute), start.Add(2 * time.Minute)}),
+				data.NewField("state", a, []*string{strPtr("Alerting"), strPtr("Alerting"), strPtr("Normal")}),
+				data.NewField("state", b, []*string{nil, strPtr("Pending"), strPtr("Alerting (Error)")}),
+			),
+		},
+		{
+			desc:   "periods in other states are removed",
+			states: []string{"alerting"},
+			expected: data.NewFrame("State history",
+				data.NewField("time", nil, []time.Time{start, start.Add(time.Minute), start.Add(2 * time.Minute)}),
+				data.NewField("state", a, []*string{strPtr("Alerting"), strPtr("Alerting"), nil}),
+				data.NewField("state", b, []*string{nil, nil, strPtr("Alerting (Error)")}),
+			),
+		},
+		{
+			desc:   "instances that never were in the states are dropped",
+			states: []string{"Pending"},
+			expected: data.NewFrame("State history",
+				data.NewField("time", nil, []time.Time{start, start.Add(time.Minute), start.Add(2 * time.Minute)}),
+				data.NewField("state", b, []*string{nil, strPtr("Pending"), nil}),
+			),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			input := make([]historyEntry, len(entries))
+			copy(input, entries)
+			require.Equal(t, tc.expected, buildStateTimelineFrame(input, tc.states))
+		})
+	}
+
+	t.Run("no entries returns a frame without instances", func(t *testing.T) {
+		frame := buildStateTimelineFrame(nil, nil)
+		require.Len(t, frame.Fields, 1)
+		require.Equal(t, 0, frame.Rows())
+	})
+}
+
+func strPtr(s string) *string {
+	return &s
+}
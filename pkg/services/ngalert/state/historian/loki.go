@@ -2,12 +2,18 @@ package historian
 
 import (
 	"context"
+	"errors"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 )
 
+// ErrQueryNotSupported is returned by the backends that cannot read back the state history they record.
+var ErrQueryNotSupported = errors.New("querying state history is not supported by the configured backend")
+
 type RemoteLokiBackend struct {
 	log log.Logger
 }
@@ -20,3 +26,7 @@ func NewRemoteLokiBackend() *RemoteLokiBackend {
 
 func (h *RemoteLokiBackend) RecordStatesAsync(ctx context.Context, _ *models.AlertRule, _ []state.StateTransition) {
 }
+
+func (h *RemoteLokiBackend) QueryStates(ctx context.Context, _ models.HistoryQuery) (*data.Frame, error) {
+	return nil, ErrQueryNotSupported
+}
@@ -3,6 +3,8 @@ package historian
 import (
 	"context"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 )
@@ -16,3 +18,8 @@ func NewNopHistorian() *NoOpHistorian {
 
 func (f *NoOpHistorian) RecordStatesAsync(ctx context.Context, _ *models.AlertRule, _ []state.StateTransition) {
 }
+
+// QueryStates returns an empty frame, since no state history is recorded.
+func (f *NoOpHistorian) QueryStates(ctx context.Context, _ models.HistoryQuery) (*data.Frame, error) {
+	return buildStateTimelineFrame(nil, nil), nil
+}
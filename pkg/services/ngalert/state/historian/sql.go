@@ -2,21 +2,328 @@ package historian
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 )
 
+const (
+	// defaultQueryLimit is the maximum number of entries returned by a query that does not specify a limit.
+	defaultQueryLimit = 1000
+	// retentionInterval controls how often entries older than the retention period are deleted.
+	retentionInterval = 10 * time.Minute
+)
+
+// labelsQueryPageSize is the number of rows read at once by a query that filters by labels.
+var labelsQueryPageSize = 1000
+
+// TimeNow makes it possible to test usage of time
+var TimeNow = time.Now
+
+// StateHistoryEntry is a single state transition of an alert instance stored in the alert_state_history table.
+type StateHistoryEntry struct {
+	ID            int64                 `xorm:"pk autoincr 'id'"`
+	OrgID         int64                 `xorm:"org_id"`
+	RuleUID       string                `xorm:"rule_uid"`
+	Labels        models.InstanceLabels `xorm:"labels"`
+	LabelsHash    string                `xorm:"labels_hash"`
+	PreviousState string                `xorm:"previous_state"`
+	CurrentState  string                `xorm:"current_state"`
+	Reason        string                `xorm:"reason"`
+	Values        string                `xorm:"state_values"`
+	Error         string                `xorm:"state_error"`
+	EvaluatedAt   int64                 `xorm:"evaluated_at"`
+}
+
+// historyRow is the write model of StateHistoryEntry. models.InstanceLabels can be read from the database, but
+// writing it is not supported by its ToDB, so the labels are serialized manually like for alert instances.
+type historyRow struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	RuleUID       string `xorm:"rule_uid"`
+	Labels        string `xorm:"labels"`
+	LabelsHash    string `xorm:"labels_hash"`
+	PreviousState string `xorm:"previous_state"`
+	CurrentState  string `xorm:"current_state"`
+	Reason        string `xorm:"reason"`
+	Values        string `xorm:"state_values"`
+	Error         string `xorm:"state_error"`
+	EvaluatedAt   int64  `xorm:"evaluated_at"`
+}
+
+func (StateHistoryEntry) TableName() string {
+	return "alert_state_history"
+}
+
+func (historyRow) TableName() string {
+	return "alert_state_history"
+}
+
+// Time returns the time of the evaluation that caused the transition.
+func (e StateHistoryEntry) Time() time.Time {
+	return time.UnixMilli(e.EvaluatedAt)
+}
+
+// StateHistoryQuery filters the entries returned by SqlBackend.Query.
+type StateHistoryQuery struct {
+	OrgID int64
+	// RuleUID restricts the results to a single rule if set.
+	RuleUID string
+	// Labels restricts the results to instances that have all of the given labels.
+	Labels map[string]string
+	From   time.Time
+	To     time.Time
+	// Limit is the maximum number of entries to return. Defaults to defaultQueryLimit.
+	Limit int
+}
+
+// SqlBackend is an implementation of state.Historian that uses the Grafana database as the backing datastore.
 type SqlBackend struct {
-	log log.Logger
+	store     db.DB
+	retention time.Duration
+	log       log.Logger
 }
 
-func NewSqlBackend() *SqlBackend {
+// NewSqlBackend creates a new SqlBackend. Entries older than retention are deleted while the backend is running.
+// A retention of zero keeps entries forever.
+func NewSqlBackend(store db.DB, retention time.Duration) *SqlBackend {
 	return &SqlBackend{
-		log: log.New("ngalert.state.historian"),
+		store:     store,
+		retention: retention,
+		log:       log.New("ngalert.state.historian"),
+	}
+}
+
+// RecordStatesAsync writes a number of state transitions for a given rule to state history.
+func (h *SqlBackend) RecordStatesAsync(ctx context.Context, rule *models.AlertRule, states []state.StateTransition) {
+	logger := h.log.FromContext(ctx)
+	// Build rows before starting goroutine, to make sure all data is copied and won't mutate underneath us.
+	rows := h.buildRows(rule, states, logger)
+	if len(rows) == 0 {
+		return
+	}
+	go h.recordRowsSync(ctx, rows, logger)
+}
+
+func (h *SqlBackend) buildRows(rule *models.AlertRule, states []state.StateTransition, logger log.Logger) []historyRow {
+	rows := make([]historyRow, 0, len(states))
+	for _, st := range states {
+		if !shouldRecord(st) {
+			continue
+		}
+
+		labels := models.InstanceLabels(st.Labels)
+		labelsJSON, labelsHash, err := labels.StringAndHash()
+		if err != nil {
+			logger.Error("Error serializing labels of state transition", "error", err)
+			continue
+		}
+
+		values, err := json.Marshal(jsonifyValues(st.Values))
+		if err != nil {
+			logger.Error("Error serializing values of state transition", "error", err)
+			continue
+		}
+
+		row := historyRow{
+			OrgID:         rule.OrgID,
+			RuleUID:       rule.UID,
+			Labels:        labelsJSON,
+			LabelsHash:    labelsHash,
+			PreviousState: st.PreviousFormatted(),
+			CurrentState:  st.Formatted(),
+			Reason:        st.State.StateReason,
+			Values:        string(values),
+			EvaluatedAt:   st.LastEvaluationTime.UnixMilli(),
+		}
+		if st.Error != nil {
+			row.Error = st.Error.Error()
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (h *SqlBackend) recordRowsSync(ctx context.Context, rows []historyRow, logger log.Logger) {
+	err := h.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.InsertMulti(rows)
+		return err
+	})
+	if err != nil {
+		logger.Error("Error saving state history batch", "error", err)
+		return
 	}
+	logger.Debug("Done saving state history batch", "count", len(rows))
 }
 
-func (h *SqlBackend) RecordStatesAsync(ctx context.Context, _ *models.AlertRule, _ []state.StateTransition) {
+// Query returns the state transitions that match the query, ordered by evaluation time. Like the annotation backend,
+// the limit keeps the newest transitions.
+func (h *SqlBackend) Query(ctx context.Context, query StateHistoryQuery) ([]StateHistoryEntry, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultQueryLimit
+	}
+
+	if len(query.Labels) == 0 {
+		entries, err := h.find(ctx, query, limit, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query state history: %w", err)
+		}
+		return reverseEntries(entries), nil
+	}
+
+	// Labels are stored serialized, so they are matched after the rows are fetched. The rows are read in pages
+	// until enough of them match, to not load the entire history into memory.
+	result := make([]StateHistoryEntry, 0, limit)
+	for offset := 0; ; offset += labelsQueryPageSize {
+		entries, err := h.find(ctx, query, labelsQueryPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query state history: %w", err)
+		}
+		for _, e := range entries {
+			if !matchLabels(data.Labels(e.Labels), query.Labels) {
+				continue
+			}
+			result = append(result, e)
+			if len(result) == limit {
+				return reverseEntries(result), nil
+			}
+		}
+		if len(entries) < labelsQueryPageSize {
+			return reverseEntries(result), nil
+		}
+	}
+}
+
+// find returns a page of the state transitions that match the query except for its labels, newest first.
+func (h *SqlBackend) find(ctx context.Context, query StateHistoryQuery, limit, offset int) ([]StateHistoryEntry, error) {
+	var entries []StateHistoryEntry
+	err := h.store.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("org_id = ?", query.OrgID)
+		if query.RuleUID != "" {
+			q = q.And("rule_uid = ?", query.RuleUID)
+		}
+		if !query.From.IsZero() {
+			q = q.And("evaluated_at >= ?", query.From.UnixMilli())
+		}
+		if !query.To.IsZero() {
+			q = q.And("evaluated_at <= ?", query.To.UnixMilli())
+		}
+		return q.Desc("evaluated_at", "id").Limit(limit, offset).Find(&entries)
+	})
+	return entries, err
+}
+
+// reverseEntries reverses the entries in place and returns them.
+func reverseEntries(entries []StateHistoryEntry) []StateHistoryEntry {
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries
+}
+
+// QueryStates returns the state history that matches the query as a frame suitable for a state timeline panel.
+func (h *SqlBackend) QueryStates(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	entries, err := h.Query(ctx, StateHistoryQuery{
+		OrgID:   query.OrgID,
+		RuleUID: query.RuleUID,
+		Labels:  query.Labels,
+		From:    query.From,
+		To:      query.To,
+		Limit:   query.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]historyEntry, 0, len(entries))
+	for _, e := range entries {
+		history = append(history, historyEntry{
+			labels: removePrivateLabels(data.Labels(e.Labels)),
+			state:  e.CurrentState,
+			time:   e.Time(),
+		})
+	}
+	return buildStateTimelineFrame(history, query.States), nil
+}
+
+// DeleteExpired deletes the state transitions that are older than the retention period.
+// It returns the number of deleted entries.
+func (h *SqlBackend) DeleteExpired(ctx context.Context) (int64, error) {
+	if h.retention <= 0 {
+		return 0, nil
+	}
+	var n int64
+	err := h.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		rows, err := sess.Where("evaluated_at < ?", TimeNow().Add(-h.retention).UnixMilli()).Delete(&historyRow{})
+		if err != nil {
+			return fmt.Errorf("failed to delete expired state history: %w", err)
+		}
+		n = rows
+		return nil
+	})
+	if err != nil {
+		return -1, err
+	}
+	return n, nil
+}
+
+// Run periodically deletes expired state transitions until the context is cancelled.
+func (h *SqlBackend) Run(ctx context.Context) error {
+	if h.retention <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			n, err := h.DeleteExpired(ctx)
+			if err != nil {
+				h.log.Error("Failed to delete expired state history", "error", err)
+				continue
+			}
+			h.log.Debug("Deleted expired state history", "count", n)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func matchLabels(labels data.Labels, matchers map[string]string) bool {
+	for k, v := range matchers {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// floatValue is a float64 that marshals non-finite numbers as strings, since JSON cannot represent them.
+type floatValue float64
+
+func (v floatValue) MarshalJSON() ([]byte, error) {
+	f := float64(v)
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return json.Marshal(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	return json.Marshal(f)
+}
+
+func jsonifyValues(values map[string]float64) map[string]floatValue {
+	result := make(map[string]floatValue, len(values))
+	for k, v := range values {
+		result[k] = floatValue(v)
+	}
+	return result
 }
@@ -0,0 +1,148 @@
+package historian
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestIntegrationSqlBackend(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	sqlStore := db.InitTestDB(t)
+	now := time.Now().Truncate(time.Millisecond)
+	rule := &models.AlertRule{OrgID: 1, UID: "rule-uid"}
+
+	transitions := []state.StateTransition{
+		{
+			State: &state.State{
+				State:              eval.Alerting,
+				Labels:             data.Labels{"instance": "a", "job": "node"},
+				Values:             map[string]float64{"A": 1, "B": math.NaN()},
+				LastEvaluationTime: now.Add(-2 * time.Hour),
+			},
+			PreviousState: eval.Normal,
+		},
+		{
+			State: &state.State{
+				State:              eval.Error,
+				StateReason:        eval.Error.String(),
+				Error:              errors.New("boom"),
+				Labels:             data.Labels{"instance": "b", "job": "node"},
+				LastEvaluationTime: now.Add(-time.Minute),
+			},
+			PreviousState: eval.Normal,
+		},
+		{
+			// Not recorded, because the state did not change.
+			State: &state.State{
+				State:              eval.Normal,
+				Labels:             data.Labels{"instance": "c", "job": "node"},
+				LastEvaluationTime: now,
+			},
+			PreviousState: eval.Normal,
+		},
+	}
+
+	h := NewSqlBackend(sqlStore, time.Hour)
+	rows := h.buildRows(rule, transitions, log.NewNopLogger())
+	require.Len(t, rows, 2)
+	h.recordRowsSync(ctx, rows, log.NewNopLogger())
+
+	t.Run("query by rule returns entries ordered by time", func(t *testing.T) {
+		res, err := h.Query(ctx, StateHistoryQuery{OrgID: 1, RuleUID: "rule-uid"})
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+
+		require.Equal(t, "a", res[0].Labels["instance"])
+		require.Equal(t, "Normal", res[0].PreviousState)
+		require.Equal(t, "Alerting", res[0].CurrentState)
+		require.JSONEq(t, `{"A":1,"B":"NaN"}`, res[0].Values)
+		require.Equal(t, now.Add(-2*time.Hour), res[0].Time())
+
+		require.Equal(t, "b", res[1].Labels["instance"])
+		require.Equal(t, "Error (Error)", res[1].CurrentState)
+		require.Equal(t, "boom", res[1].Error)
+	})
+
+	t.Run("query with a limit returns the newest entries ordered by time", func(t *testing.T) {
+		res, err := h.Query(ctx, StateHistoryQuery{OrgID: 1, RuleUID: "rule-uid", Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "b", res[0].Labels["instance"])
+
+		res, err = h.Query(ctx, StateHistoryQuery{OrgID: 1, RuleUID: "rule-uid", Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		require.Equal(t, "a", res[0].Labels["instance"])
+		require.Equal(t, "b", res[1].Labels["instance"])
+	})
+
+	t.Run("query filters by labels", func(t *testing.T) {
+		res, err := h.Query(ctx, StateHistoryQuery{OrgID: 1, Labels: map[string]string{"instance": "b"}})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "b", res[0].Labels["instance"])
+	})
+
+	t.Run("query filters by labels in pages until the limit is reached", func(t *testing.T) {
+		pageSize := labelsQueryPageSize
+		labelsQueryPageSize = 1
+		t.Cleanup(func() { labelsQueryPageSize = pageSize })
+
+		res, err := h.Query(ctx, StateHistoryQuery{OrgID: 1, Labels: map[string]string{"job": "node"}, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "b", res[0].Labels["instance"])
+
+		res, err = h.Query(ctx, StateHistoryQuery{OrgID: 1, Labels: map[string]string{"instance": "b"}})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "b", res[0].Labels["instance"])
+	})
+
+	t.Run("query filters by time range and org", func(t *testing.T) {
+		res, err := h.Query(ctx, StateHistoryQuery{OrgID: 1, From: now.Add(-time.Hour), To: now})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+
+		res, err = h.Query(ctx, StateHistoryQuery{OrgID: 2})
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+
+	t.Run("query states returns a state timeline frame", func(t *testing.T) {
+		frame, err := h.QueryStates(ctx, models.HistoryQuery{OrgID: 1, RuleUID: "rule-uid", States: []string{"Error"}})
+		require.NoError(t, err)
+		require.Len(t, frame.Fields, 2)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, data.Labels{"instance": "b", "job": "node"}, frame.Fields[1].Labels)
+		require.Nil(t, frame.Fields[1].At(0))
+		require.Equal(t, "Error (Error)", *frame.Fields[1].At(1).(*string))
+	})
+
+	t.Run("delete expired removes entries older than retention", func(t *testing.T) {
+		n, err := h.DeleteExpired(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), n)
+
+		res, err := h.Query(ctx, StateHistoryQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "b", res[0].Labels["instance"])
+	})
+}
@@ -130,24 +130,8 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				orgStates[entry.RuleUID] = rulesStates
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheID, err := entry.Labels.StringKey()
-			if err != nil {
-				st.log.Error("Error getting cacheId for entry", "error", err)
-			}
-			rulesStates.states[cacheID] = &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheID:              cacheID,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
-			}
+			s := st.stateFromInstance(entry, ruleForEntry)
+			rulesStates.states[s.CacheID] = s
 			statesCount++
 		}
 	}
@@ -155,6 +139,51 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 	st.log.Info("State cache has been initialized", "states", statesCount, "duration", time.Since(startTime))
 }
 
+// WarmRule replaces the states of the rule in the cache with the alert instances of the rule in the instance store.
+// It is used when a rule is taken over from another replica, which has been saving the states of the rule.
+func (st *Manager) WarmRule(ctx context.Context, rule *ngModels.AlertRule) {
+	if st.instanceStore == nil {
+		return
+	}
+	logger := st.log.New(rule.GetKey().LogContext()...)
+	cmd := ngModels.ListAlertInstancesQuery{
+		RuleOrgID: rule.OrgID,
+		RuleUID:   rule.UID,
+	}
+	if err := st.instanceStore.ListAlertInstances(ctx, &cmd); err != nil {
+		logger.Error("Unable to fetch previous state of the rule", "error", err)
+		return
+	}
+	rulesStates := &ruleStates{states: make(map[string]*State, len(cmd.Result))}
+	for _, entry := range cmd.Result {
+		s := st.stateFromInstance(entry, rule)
+		rulesStates.states[s.CacheID] = s
+	}
+	st.cache.setRuleStates(rule.OrgID, rule.UID, rulesStates)
+	logger.Debug("State of the rule has been loaded", "states", len(rulesStates.states))
+}
+
+func (st *Manager) stateFromInstance(entry *ngModels.AlertInstance, rule *ngModels.AlertRule) *State {
+	lbs := map[string]string(entry.Labels)
+	cacheID, err := entry.Labels.StringKey()
+	if err != nil {
+		st.log.Error("Error getting cacheId for entry", "error", err)
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheID:              cacheID,
+		Labels:               lbs,
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          rule.Annotations,
+	}
+}
+
 func (st *Manager) Get(orgID int64, alertRuleUID, stateId string) *State {
 	return st.cache.get(orgID, alertRuleUID, stateId)
 }
@@ -174,6 +203,14 @@ func (st *Manager) ResetStateByRuleUID(ctx context.Context, ruleKey ngModels.Ale
 	return states
 }
 
+// ForgetStateByRuleUID deletes all entries in the state manager that match the given rule UID.
+// Unlike ResetStateByRuleUID, it keeps the states in the instance store, for example, for another replica to load them.
+func (st *Manager) ForgetStateByRuleUID(ruleKey ngModels.AlertRuleKey) []*State {
+	states := st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+	st.log.Debug("Rules state was forgotten", append(ruleKey.LogContext(), "states", len(states))...)
+	return states
+}
+
 // ProcessEvalResults updates the current states that belong to a rule with the evaluation results.
 // if extraLabels is not empty, those labels will be added to every state. The extraLabels take precedence over rule labels and result labels
 func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time, alertRule *ngModels.AlertRule, results eval.Results, extraLabels data.Labels) []StateTransition {
@@ -213,6 +250,7 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 	currentState.TrimResults(alertRule)
 	oldState := currentState.State
 	oldReason := currentState.StateReason
+	oldStartsAt := currentState.StartsAt
 
 	// Add the instance to the log context to help correlate log lines for a state
 	logger = logger.New("instance", result.Instance)
@@ -243,6 +281,19 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		currentState.StateReason = result.State.String()
 	}
 
+	// An instance that would be Pending or Alerting is inhibited while a rule it depends on is firing
+	if currentState.State == eval.Pending || currentState.State == eval.Alerting {
+		if ruleUID, ok := st.inhibitedBy(alertRule, currentState); ok {
+			logger.Debug("Alert instance is inhibited", "inhibited_by", ruleUID, "state", currentState.State)
+			// Normal states have the same start and end timestamps
+			startsAt := result.EvaluatedAt
+			if oldState == eval.Normal {
+				startsAt = oldStartsAt
+			}
+			currentState.SetNormal(ngModels.StateReasonInhibited, startsAt, startsAt)
+		}
+	}
+
 	// Set Resolved property so the scheduler knows to send a postable alert
 	// to Alertmanager.
 	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
@@ -270,6 +321,22 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 	return nextState
 }
 
+// inhibitedBy returns the UID of a rule that the alert rule depends on and that has a firing alert instance
+// inhibiting the given state.
+// Only the states in the cache of this instance are considered. This is why dependencies are rejected when the
+// evaluation of rules is sharded between high availability instances: the rule that is depended on might be
+// evaluated by another instance.
+func (st *Manager) inhibitedBy(alertRule *ngModels.AlertRule, s *State) (string, bool) {
+	for _, d := range alertRule.Dependencies {
+		for _, firing := range st.cache.getStatesForRuleUID(alertRule.OrgID, d.RuleUID) {
+			if firing.State == eval.Alerting && d.Inhibits(firing.Labels, s.Labels) {
+				return d.RuleUID, true
+			}
+		}
+	}
+	return "", false
+}
+
 func (st *Manager) GetAll(orgID int64) []*State {
 	return st.cache.getAll(orgID)
 }
@@ -278,6 +345,19 @@ func (st *Manager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*Stat
 	return st.cache.getStatesForRuleUID(orgID, alertRuleUID)
 }
 
+// GetLoadedDimensions returns the labels of the alert instances of the rule that are Alerting or Pending,
+// i.e. the instances whose condition was met at the previous evaluation.
+func (st *Manager) GetLoadedDimensions(orgID int64, alertRuleUID string) []data.Labels {
+	states := st.cache.getStatesForRuleUID(orgID, alertRuleUID)
+	result := make([]data.Labels, 0, len(states))
+	for _, s := range states {
+		if s.State == eval.Alerting || s.State == eval.Pending {
+			result = append(result, s.Labels.Copy())
+		}
+	}
+	return result
+}
+
 func (st *Manager) Put(states []*State) {
 	for _, s := range states {
 		st.cache.set(s)
@@ -209,6 +209,26 @@ func TestWarmStateCache(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("the states of a rule can be forgotten and loaded again", func(t *testing.T) {
+		st := state.NewManager(cfg)
+		st.WarmRule(ctx, rule)
+		require.Len(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID), len(expectedEntries))
+
+		forgotten := st.ForgetStateByRuleUID(rule.GetKey())
+		require.Len(t, forgotten, len(expectedEntries))
+		require.Empty(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID))
+
+		// The states are still in the instance store.
+		st.WarmRule(ctx, rule)
+		for _, entry := range expectedEntries {
+			cacheEntry := st.Get(entry.OrgID, entry.AlertRuleUID, entry.CacheID)
+			if diff := cmp.Diff(entry, cacheEntry, cmpopts.IgnoreFields(state.State{}, "Results")); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+				t.FailNow()
+			}
+		}
+	})
 }
 
 func TestDashboardAnnotations(t *testing.T) {
@@ -219,7 +239,7 @@ func TestDashboardAnnotations(t *testing.T) {
 	_, dbstore := tests.SetupTestEnv(t, 1)
 
 	fakeAnnoRepo := annotationstest.NewFakeAnnotationsRepo()
-	hist := historian.NewAnnotationBackend(fakeAnnoRepo, &dashboards.FakeDashboardService{})
+	hist := historian.NewAnnotationBackend(fakeAnnoRepo, &dashboards.FakeDashboardService{}, nil)
 	cfg := state.ManagerCfg{
 		Metrics:       testMetrics.GetStateMetrics(),
 		ExternalURL:   nil,
@@ -2208,7 +2228,7 @@ func TestProcessEvalResults(t *testing.T) {
 
 	for _, tc := range testCases {
 		fakeAnnoRepo := annotationstest.NewFakeAnnotationsRepo()
-		hist := historian.NewAnnotationBackend(fakeAnnoRepo, &dashboards.FakeDashboardService{})
+		hist := historian.NewAnnotationBackend(fakeAnnoRepo, &dashboards.FakeDashboardService{}, nil)
 		cfg := state.ManagerCfg{
 			Metrics:       testMetrics.GetStateMetrics(),
 			ExternalURL:   nil,
@@ -2543,3 +2563,116 @@ func TestStaleResults(t *testing.T) {
 		}
 	})
 }
+
+func TestGetLoadedDimensions(t *testing.T) {
+	cfg := state.ManagerCfg{
+		Metrics:       testMetrics.GetStateMetrics(),
+		ExternalURL:   nil,
+		InstanceStore: &state.FakeInstanceStore{},
+		Images:        &state.NotAvailableImageService{},
+		Clock:         clock.NewMock(),
+		Historian:     &state.FakeHistorian{},
+	}
+	st := state.NewManager(cfg)
+
+	newState := func(id string, s eval.State) *state.State {
+		return &state.State{OrgID: 1, AlertRuleUID: "rule", CacheID: id, State: s, Labels: data.Labels{"instance": id}}
+	}
+	st.Put([]*state.State{
+		newState("normal", eval.Normal),
+		newState("pending", eval.Pending),
+		newState("alerting", eval.Alerting),
+		newState("nodata", eval.NoData),
+	})
+
+	loaded := st.GetLoadedDimensions(1, "rule")
+	require.ElementsMatch(t, []data.Labels{{"instance": "pending"}, {"instance": "alerting"}}, loaded)
+	require.Empty(t, st.GetLoadedDimensions(1, "unknown"))
+}
+
+func TestProcessEvalResultsWithDependencies(t *testing.T) {
+	cfg := state.ManagerCfg{
+		Metrics:       testMetrics.GetStateMetrics(),
+		ExternalURL:   nil,
+		InstanceStore: &state.FakeInstanceStore{},
+		Images:        &state.NotAvailableImageService{},
+		Clock:         clock.NewMock(),
+		Historian:     &state.FakeHistorian{},
+	}
+	st := state.NewManager(cfg)
+
+	setDatacenterDown := func(s eval.State) {
+		st.Put([]*state.State{{
+			OrgID:        1,
+			AlertRuleUID: "dc-down",
+			CacheID:      "eu-1",
+			State:        s,
+			Labels:       data.Labels{"alertname": "DatacenterDown", "datacenter": "eu-1"},
+		}})
+	}
+	rule := models.AlertRuleGen(
+		models.WithOrgID(1),
+		models.WithFor(0),
+		models.WithInterval(time.Minute),
+		models.WithDependencies(models.RuleDependency{RuleUID: "dc-down", Equal: []string{"datacenter"}}),
+	)()
+	results := func(evaluatedAt time.Time) eval.Results {
+		return eval.Results{
+			{Instance: data.Labels{"datacenter": "eu-1"}, State: eval.Alerting, EvaluatedAt: evaluatedAt},
+			{Instance: data.Labels{"datacenter": "us-1"}, State: eval.Alerting, EvaluatedAt: evaluatedAt},
+		}
+	}
+	byDatacenter := func(transitions []state.StateTransition) map[string]state.StateTransition {
+		result := make(map[string]state.StateTransition, len(transitions))
+		for _, s := range transitions {
+			result[s.Labels["datacenter"]] = s
+		}
+		return result
+	}
+
+	start := time.Now()
+
+	t.Run("should inhibit alert instances with the same labels as a firing instance of a dependency", func(t *testing.T) {
+		setDatacenterDown(eval.Alerting)
+
+		states := byDatacenter(st.ProcessEvalResults(context.Background(), start, rule, results(start), nil))
+
+		require.Equal(t, eval.Normal, states["eu-1"].State.State)
+		require.Equal(t, models.StateReasonInhibited, states["eu-1"].StateReason)
+		require.Equal(t, eval.Alerting, states["us-1"].State.State)
+		require.Empty(t, states["us-1"].StateReason)
+	})
+
+	t.Run("should fire when the dependency is resolved", func(t *testing.T) {
+		setDatacenterDown(eval.Normal)
+		evaluatedAt := start.Add(time.Minute)
+
+		states := byDatacenter(st.ProcessEvalResults(context.Background(), evaluatedAt, rule, results(evaluatedAt), nil))
+
+		require.Equal(t, eval.Alerting, states["eu-1"].State.State)
+		require.Equal(t, models.StateReasonInhibited, states["eu-1"].PreviousStateReason)
+		require.Equal(t, evaluatedAt, states["eu-1"].StartsAt)
+	})
+
+	t.Run("should resolve firing alert instances when the dependency fires", func(t *testing.T) {
+		setDatacenterDown(eval.Alerting)
+		evaluatedAt := start.Add(2 * time.Minute)
+
+		states := byDatacenter(st.ProcessEvalResults(context.Background(), evaluatedAt, rule, results(evaluatedAt), nil))
+
+		require.Equal(t, eval.Normal, states["eu-1"].State.State)
+		require.Equal(t, models.StateReasonInhibited, states["eu-1"].StateReason)
+		require.True(t, states["eu-1"].Resolved)
+		require.Equal(t, eval.Alerting, states["us-1"].State.State)
+	})
+
+	t.Run("should keep the start time of inhibited alert instances", func(t *testing.T) {
+		evaluatedAt := start.Add(3 * time.Minute)
+
+		states := byDatacenter(st.ProcessEvalResults(context.Background(), evaluatedAt, rule, results(evaluatedAt), nil))
+
+		require.Equal(t, eval.Normal, states["eu-1"].State.State)
+		require.Equal(t, start.Add(2*time.Minute), states["eu-1"].StartsAt)
+		require.False(t, states["eu-1"].Resolved)
+	})
+}
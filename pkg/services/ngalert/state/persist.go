@@ -3,6 +3,8 @@ package state
 import (
 	"context"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
@@ -26,6 +28,13 @@ type Historian interface {
 	RecordStatesAsync(ctx context.Context, rule *models.AlertRule, states []StateTransition)
 }
 
+// HistoryReader reads back the alert state history written by a Historian.
+type HistoryReader interface {
+	// QueryStates returns the state history that matches the query as a frame with a time field
+	// and a field with the state of each alert instance, suitable for a state timeline panel.
+	QueryStates(ctx context.Context, query models.HistoryQuery) (*data.Frame, error)
+}
+
 // ImageCapturer captures images.
 //
 //go:generate mockgen -destination=image_mock.go -package=state github.com/grafana/grafana/pkg/services/ngalert/state ImageCapturer
@@ -149,6 +149,8 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				For:              r.For,
 				Annotations:      r.Annotations,
 				Labels:           r.Labels,
+				Record:           r.Record,
+				Dependencies:     r.Dependencies,
 			})
 		}
 		if len(newRules) > 0 {
@@ -170,7 +172,7 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				return fmt.Errorf("failed to create new rule versions: %w", err)
 			}
 		}
-		return nil
+		return validateRuleDependencies(sess, newRules)
 	})
 }
 
@@ -189,7 +191,9 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				return err
 			}
 			// no way to update multiple rules at once
-			if updated, err := sess.ID(r.Existing.ID).AllCols().Update(r.New); err != nil || updated == 0 {
+			// xorm can only convert the record of an addressable rule. It is a copy because xorm increases the version of the rule it updates.
+			rule := r.New
+			if updated, err := sess.ID(r.Existing.ID).AllCols().Update(&rule); err != nil || updated == 0 {
 				if err != nil {
 					if st.SQLStore.GetDialect().IsUniqueConstraintViolation(err) {
 						return ngmodels.ErrAlertRuleUniqueConstraintViolation
@@ -217,6 +221,8 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				For:              r.New.For,
 				Annotations:      r.New.Annotations,
 				Labels:           r.New.Labels,
+				Record:           r.New.Record,
+				Dependencies:     r.New.Dependencies,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -224,7 +230,11 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				return fmt.Errorf("failed to create new rule versions: %w", err)
 			}
 		}
-		return nil
+		updated := make([]ngmodels.AlertRule, 0, len(rules))
+		for _, r := range rules {
+			updated = append(updated, r.New)
+		}
+		return validateRuleDependencies(sess, updated)
 	})
 }
 
@@ -366,6 +376,36 @@ func (st DBstore) GetUserVisibleNamespaces(ctx context.Context, orgID int64, use
 	return namespaceMap, nil
 }
 
+// GetNamespacesByUID returns the folders of the organization with the given UIDs, regardless of the permissions of
+// any user. It is meant for operations on all alert rules of an organization, such as exporting them.
+func (st DBstore) GetNamespacesByUID(ctx context.Context, orgID int64, uids ...string) (map[string]*folder.Folder, error) {
+	namespaceMap := make(map[string]*folder.Folder, len(uids))
+	if len(uids) == 0 {
+		return namespaceMap, nil
+	}
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		args := []interface{}{orgID, st.SQLStore.GetDialect().BooleanStr(true)}
+		in := make([]string, 0, len(uids))
+		for _, uid := range uids {
+			args = append(args, uid)
+			in = append(in, "?")
+		}
+		var folders []struct {
+			ID    int64  `xorm:"id"`
+			UID   string `xorm:"uid"`
+			Title string `xorm:"title"`
+		}
+		if err := sess.SQL(fmt.Sprintf("SELECT id, uid, title FROM dashboard WHERE org_id = ? AND is_folder = ? AND uid IN (%s)", strings.Join(in, ",")), args...).Find(&folders); err != nil {
+			return err
+		}
+		for _, f := range folders {
+			namespaceMap[f.UID] = &folder.Folder{ID: f.ID, UID: f.UID, Title: f.Title}
+		}
+		return nil
+	})
+	return namespaceMap, err
+}
+
 // GetNamespaceByTitle is a handler for retrieving a namespace by its title. Alerting rules follow a Grafana folder-like structure which we call namespaces.
 func (st DBstore) GetNamespaceByTitle(ctx context.Context, namespace string, orgID int64, user *user.SignedInUser, withCanSave bool) (*folder.Folder, error) {
 	folder, err := st.FolderService.Get(ctx, &folder.GetFolderQuery{OrgID: orgID, Title: &namespace, SignedInUser: user})
@@ -491,6 +531,23 @@ var GenerateNewAlertRuleUID = func(sess *db.Session, orgID int64, ruleTitle stri
 	return "", ngmodels.ErrAlertRuleFailedGenerateUniqueUID
 }
 
+// validateRuleDependencies checks that the rules the given rules depend on exist in their organisation. It runs after
+// the rules are written, so that rules can depend on rules that are created in the same transaction.
+func validateRuleDependencies(sess *db.Session, rules []ngmodels.AlertRule) error {
+	for _, r := range rules {
+		for _, d := range r.Dependencies {
+			exists, err := sess.Table("alert_rule").Where("org_id = ? AND uid = ?", r.OrgID, d.RuleUID).Exist()
+			if err != nil {
+				return fmt.Errorf("failed to check the dependency of rule %s on rule %s: %w", r.UID, d.RuleUID, err)
+			}
+			if !exists {
+				return fmt.Errorf("%w: rule %s depends on rule %s that does not exist", ngmodels.ErrAlertRuleFailedValidation, r.UID, d.RuleUID)
+			}
+		}
+	}
+	return nil
+}
+
 // validateAlertRule validates the alert rule interval and organisation.
 func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 	if len(alertRule.Data) == 0 {
@@ -534,5 +591,24 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 	if alertRule.For < 0 {
 		return fmt.Errorf("%w: field `for` cannot be negative", ngmodels.ErrAlertRuleFailedValidation)
 	}
+
+	if len(alertRule.Dependencies) > 0 && st.Cfg.HAEvaluationSharding {
+		return fmt.Errorf("%w: rule dependencies are not supported when the evaluation of alert rules is sharded between high availability instances", ngmodels.ErrAlertRuleFailedValidation)
+	}
+	if len(alertRule.Dependencies) > 0 && alertRule.IsRecordingRule() {
+		return fmt.Errorf("%w: recording rule cannot have dependencies", ngmodels.ErrAlertRuleFailedValidation)
+	}
+	for _, d := range alertRule.Dependencies {
+		if err := d.Validate(); err != nil {
+			return err
+		}
+		if d.RuleUID == alertRule.UID {
+			return fmt.Errorf("%w: rule cannot depend on itself", ngmodels.ErrAlertRuleFailedValidation)
+		}
+	}
+
+	if alertRule.IsRecordingRule() {
+		return alertRule.Record.Validate()
+	}
 	return nil
 }
@@ -12,6 +12,7 @@ import (
 	"golang.org/x/exp/rand"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	grafanaModels "github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -66,6 +67,112 @@ func TestIntegrationUpdateAlertRules(t *testing.T) {
 
 		require.ErrorIs(t, err, ErrOptimisticLock)
 	})
+
+	t.Run("should store the record of recording rules", func(t *testing.T) {
+		rule := createRule(t, store)
+		require.False(t, rule.IsRecordingRule())
+
+		newRule := models.CopyRule(rule)
+		newRule.Record = models.Record{Metric: "test:recorded", From: newRule.Condition, TargetDatasourceUID: "prom"}
+		newRule.Condition = ""
+		err := store.UpdateAlertRules(context.Background(), []models.UpdateRule{{
+			Existing: rule,
+			New:      *newRule,
+		},
+		})
+		require.NoError(t, err)
+
+		dbrule := &models.AlertRule{}
+		dbversion := &models.AlertRuleVersion{}
+		err = sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			exist, err := sess.Table(models.AlertRule{}).ID(rule.ID).Get(dbrule)
+			require.Truef(t, exist, fmt.Sprintf("rule with ID %d does not exist", rule.ID))
+			if err != nil {
+				return err
+			}
+			exist, err = sess.Table("alert_rule_version").Where("rule_uid = ? AND version = ?", rule.UID, rule.Version+1).Get(dbversion)
+			require.Truef(t, exist, fmt.Sprintf("version %d of rule with ID %d does not exist", rule.Version+1, rule.ID))
+			return err
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, newRule.Record, dbrule.Record)
+		require.Equal(t, rule.Version+1, dbrule.Version)
+		require.Equal(t, newRule.Record, dbversion.Record)
+	})
+
+	t.Run("should store the dependencies of the rule", func(t *testing.T) {
+		rule := createRule(t, store)
+		dependency := createRule(t, store, models.WithOrgID(rule.OrgID))
+
+		newRule := models.CopyRule(rule)
+		newRule.Dependencies = []models.RuleDependency{{RuleUID: dependency.UID, Equal: []string{"datacenter"}}}
+		err := store.UpdateAlertRules(context.Background(), []models.UpdateRule{{
+			Existing: rule,
+			New:      *newRule,
+		},
+		})
+		require.NoError(t, err)
+
+		dbrule := &models.AlertRule{}
+		dbversion := &models.AlertRuleVersion{}
+		err = sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			exist, err := sess.Table(models.AlertRule{}).ID(rule.ID).Get(dbrule)
+			require.Truef(t, exist, fmt.Sprintf("rule with ID %d does not exist", rule.ID))
+			if err != nil {
+				return err
+			}
+			exist, err = sess.Table("alert_rule_version").Where("rule_uid = ? AND version = ?", rule.UID, rule.Version+1).Get(dbversion)
+			require.Truef(t, exist, fmt.Sprintf("version %d of rule with ID %d does not exist", rule.Version+1, rule.ID))
+			return err
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, newRule.Dependencies, dbrule.Dependencies)
+		require.Equal(t, newRule.Dependencies, dbversion.Dependencies)
+	})
+
+	t.Run("should fail if the rule depends on itself", func(t *testing.T) {
+		rule := createRule(t, store)
+
+		newRule := models.CopyRule(rule)
+		newRule.Dependencies = []models.RuleDependency{{RuleUID: rule.UID}}
+		err := store.UpdateAlertRules(context.Background(), []models.UpdateRule{{
+			Existing: rule,
+			New:      *newRule,
+		},
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("should fail if the rule depends on a rule that does not exist", func(t *testing.T) {
+		rule := createRule(t, store)
+
+		newRule := models.CopyRule(rule)
+		newRule.Dependencies = []models.RuleDependency{{RuleUID: "does-not-exist"}}
+		err := store.UpdateAlertRules(context.Background(), []models.UpdateRule{{
+			Existing: rule,
+			New:      *newRule,
+		},
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("should fail if the evaluation of rules is sharded", func(t *testing.T) {
+		rule := createRule(t, store)
+		dependency := createRule(t, store, models.WithOrgID(rule.OrgID))
+
+		shardedStore := &DBstore{SQLStore: sqlStore, Cfg: store.Cfg}
+		shardedStore.Cfg.HAEvaluationSharding = true
+		newRule := models.CopyRule(rule)
+		newRule.Dependencies = []models.RuleDependency{{RuleUID: dependency.UID}}
+		err := shardedStore.UpdateAlertRules(context.Background(), []models.UpdateRule{{
+			Existing: rule,
+			New:      *newRule,
+		},
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
 }
 
 func withIntervalMatching(baseInterval time.Duration) func(*models.AlertRule) {
@@ -175,8 +282,45 @@ func TestIntegration_CountAlertRules(t *testing.T) {
 	}
 }
 
-func createRule(t *testing.T, store *DBstore) *models.AlertRule {
-	rule := models.AlertRuleGen(withIntervalMatching(store.Cfg.BaseInterval))()
+func TestIntegrationGetNamespacesByUID(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	store := &DBstore{SQLStore: sqlStore}
+
+	createFolder := func(orgID int64, uid, title string) {
+		f := grafanaModels.NewDashboardFolder(title)
+		f.OrgId = orgID
+		f.Uid = uid
+		f.Slug = uid
+		err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Insert(f)
+			return err
+		})
+		require.NoError(t, err)
+	}
+	createFolder(1, "folder-1", "Folder 1")
+	createFolder(1, "folder-2", "Folder 2")
+	createFolder(2, "folder-3", "Folder 3")
+
+	t.Run("returns folders of the organization with the given UIDs", func(t *testing.T) {
+		namespaces, err := store.GetNamespacesByUID(context.Background(), 1, "folder-1", "folder-3", "unknown")
+		require.NoError(t, err)
+		require.Len(t, namespaces, 1)
+		require.Equal(t, "Folder 1", namespaces["folder-1"].Title)
+	})
+
+	t.Run("returns empty map when no UIDs are given", func(t *testing.T) {
+		namespaces, err := store.GetNamespacesByUID(context.Background(), 1)
+		require.NoError(t, err)
+		require.Empty(t, namespaces)
+	})
+}
+
+func createRule(t *testing.T, store *DBstore, mutators ...models.AlertRuleMutator) *models.AlertRule {
+	rule := models.AlertRuleGen(append([]models.AlertRuleMutator{withIntervalMatching(store.Cfg.BaseInterval)}, mutators...)...)()
 	err := store.SQLStore.WithDbSession(context.Background(), func(sess *db.Session) error {
 		_, err := sess.Table(models.AlertRule{}).InsertOne(rule)
 		if err != nil {
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// defaultNotificationDeliveryLimit is the maximum number of notification deliveries returned by a query that does not specify a limit.
+const defaultNotificationDeliveryLimit = 1000
+
+type NotificationDeliveryStore interface {
+	// SaveNotificationDelivery saves an attempt to deliver a notification.
+	SaveNotificationDelivery(ctx context.Context, delivery *models.NotificationDelivery) error
+
+	// GetNotificationDeliveries returns the notification deliveries that match the query, the most recent first.
+	GetNotificationDeliveries(ctx context.Context, query models.NotificationDeliveryQuery) ([]models.NotificationDelivery, error)
+
+	// DeleteNotificationDeliveriesBefore deletes the notification deliveries of all organizations that were attempted
+	// before the given time. It returns the number of deleted notification deliveries or an error.
+	DeleteNotificationDeliveriesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+func (st DBstore) SaveNotificationDelivery(ctx context.Context, delivery *models.NotificationDelivery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(delivery); err != nil {
+			return fmt.Errorf("failed to insert notification delivery: %w", err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) GetNotificationDeliveries(ctx context.Context, query models.NotificationDeliveryQuery) ([]models.NotificationDelivery, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultNotificationDeliveryLimit
+	}
+
+	deliveries := make([]models.NotificationDelivery, 0)
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("org_id = ?", query.OrgID)
+		if query.Receiver != "" {
+			q = q.And("receiver = ?", query.Receiver)
+		}
+		if query.IntegrationUID != "" {
+			q = q.And("integration_uid = ?", query.IntegrationUID)
+		}
+		if query.Status != "" {
+			q = q.And("status = ?", query.Status)
+		}
+		if query.AlertFingerprint != "" {
+			// Fingerprints are stored as a JSON array of strings.
+			q = q.And("alert_fingerprints LIKE ?", fmt.Sprintf("%%%q%%", query.AlertFingerprint))
+		}
+		if !query.From.IsZero() {
+			q = q.And("sent_at >= ?", query.From.UnixMilli())
+		}
+		if !query.To.IsZero() {
+			q = q.And("sent_at <= ?", query.To.UnixMilli())
+		}
+		return q.Desc("sent_at", "id").Limit(limit).Find(&deliveries)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to get notification deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (st DBstore) DeleteNotificationDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	var n int64
+	if err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		rows, err := sess.Where("sent_at < ?", before.UnixMilli()).Delete(&models.NotificationDelivery{})
+		if err != nil {
+			return fmt.Errorf("failed to delete notification deliveries: %w", err)
+		}
+		n = rows
+		return nil
+	}); err != nil {
+		return -1, err
+	}
+	return n, nil
+}
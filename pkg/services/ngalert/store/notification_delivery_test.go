@@ -0,0 +1,141 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationNotificationDeliveries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.UnixMilli(time.Now().UnixMilli())
+	deliveries := []models.NotificationDelivery{
+		{
+			OrgID:             1,
+			Receiver:          "team-a",
+			IntegrationUID:    "slack",
+			IntegrationName:   "slack",
+			IntegrationType:   "slack",
+			GroupKey:          "{}:{alertname=\"a\"}",
+			AlertFingerprints: []string{"aaaa", "bbbb"},
+			Firing:            2,
+			Attempt:           1,
+			Status:            models.NotificationDeliveryFailed,
+			StatusCode:        500,
+			Error:             "webhook response status 500",
+			Duration:          time.Second,
+			SentAt:            now.Add(-2 * time.Hour).UnixMilli(),
+		},
+		{
+			OrgID:             1,
+			Receiver:          "team-a",
+			IntegrationUID:    "slack",
+			IntegrationName:   "slack",
+			IntegrationType:   "slack",
+			AlertFingerprints: []string{"aaaa", "bbbb"},
+			Firing:            2,
+			Attempt:           2,
+			Status:            models.NotificationDeliverySuccess,
+			StatusCode:        200,
+			Duration:          time.Second,
+			SentAt:            now.Add(-time.Hour).UnixMilli(),
+		},
+		{
+			OrgID:             1,
+			Receiver:          "team-b",
+			IntegrationUID:    "email",
+			IntegrationName:   "email",
+			IntegrationType:   "email",
+			AlertFingerprints: []string{"cccc"},
+			Resolved:          1,
+			Attempt:           1,
+			Status:            models.NotificationDeliverySuccess,
+			SentAt:            now.UnixMilli(),
+		},
+		{
+			OrgID:             2,
+			Receiver:          "team-a",
+			AlertFingerprints: []string{"aaaa"},
+			Attempt:           1,
+			Status:            models.NotificationDeliverySuccess,
+			SentAt:            now.UnixMilli(),
+		},
+	}
+	for i := range deliveries {
+		require.NoError(t, dbstore.SaveNotificationDelivery(ctx, &deliveries[i]))
+		require.NotZero(t, deliveries[i].ID)
+	}
+
+	t.Run("returns the deliveries of the organization, the most recent first", func(t *testing.T) {
+		result, err := dbstore.GetNotificationDeliveries(ctx, models.NotificationDeliveryQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, []models.NotificationDelivery{deliveries[2], deliveries[1], deliveries[0]}, result)
+	})
+
+	t.Run("filters the deliveries", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			query    models.NotificationDeliveryQuery
+			expected []models.NotificationDelivery
+		}{
+			{
+				name:     "by receiver",
+				query:    models.NotificationDeliveryQuery{OrgID: 1, Receiver: "team-b"},
+				expected: []models.NotificationDelivery{deliveries[2]},
+			},
+			{
+				name:     "by integration",
+				query:    models.NotificationDeliveryQuery{OrgID: 1, IntegrationUID: "slack"},
+				expected: []models.NotificationDelivery{deliveries[1], deliveries[0]},
+			},
+			{
+				name:     "by status",
+				query:    models.NotificationDeliveryQuery{OrgID: 1, Status: models.NotificationDeliveryFailed},
+				expected: []models.NotificationDelivery{deliveries[0]},
+			},
+			{
+				name:     "by alert",
+				query:    models.NotificationDeliveryQuery{OrgID: 1, AlertFingerprint: "bbbb"},
+				expected: []models.NotificationDelivery{deliveries[1], deliveries[0]},
+			},
+			{
+				name:     "by time",
+				query:    models.NotificationDeliveryQuery{OrgID: 1, From: now.Add(-90 * time.Minute), To: now.Add(-time.Minute)},
+				expected: []models.NotificationDelivery{deliveries[1]},
+			},
+			{
+				name:     "with a limit",
+				query:    models.NotificationDeliveryQuery{OrgID: 1, Limit: 1},
+				expected: []models.NotificationDelivery{deliveries[2]},
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				result, err := dbstore.GetNotificationDeliveries(ctx, tc.query)
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, result)
+			})
+		}
+	})
+
+	t.Run("deletes the deliveries attempted before the given time", func(t *testing.T) {
+		n, err := dbstore.DeleteNotificationDeliveriesBefore(ctx, now.Add(-time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), n)
+
+		result, err := dbstore.GetNotificationDeliveries(ctx, models.NotificationDeliveryQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, []models.NotificationDelivery{deliveries[2]}, result)
+	})
+}
@@ -244,6 +244,21 @@ func (f *RuleStore) GetUserVisibleNamespaces(_ context.Context, orgID int64, _ *
 	return namespacesMap, nil
 }
 
+func (f *RuleStore) GetNamespacesByUID(_ context.Context, orgID int64, uids ...string) (map[string]*folder.Folder, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	namespacesMap := map[string]*folder.Folder{}
+	for _, folder := range f.Folders[orgID] {
+		for _, uid := range uids {
+			if folder.UID == uid {
+				namespacesMap[folder.UID] = folder
+			}
+		}
+	}
+	return namespacesMap, nil
+}
+
 func (f *RuleStore) GetNamespaceByTitle(_ context.Context, title string, orgID int64, _ *user.SignedInUser, _ bool) (*folder.Folder, error) {
 	folders := f.Folders[orgID]
 	for _, folder := range folders {
@@ -0,0 +1,198 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// remoteWritePath is the path of the remote write receiver of a Prometheus data source.
+const remoteWritePath = "/api/v1/write"
+
+// ErrNoTarget is returned when a recording rule does not have a target data source and no remote write endpoint is configured.
+var ErrNoTarget = errors.New("recording rule does not have a target data source and no remote write endpoint is configured in [unified_alerting.recording_rules]")
+
+// DatasourceService represents the ability to get data sources and their basic authentication password.
+type DatasourceService interface {
+	GetDataSource(ctx context.Context, query *datasources.GetDataSourceQuery) error
+	DecryptedBasicAuthPassword(ctx context.Context, ds *datasources.DataSource) (string, error)
+}
+
+// PrometheusWriter writes the results of recording rules with the Prometheus remote write protocol, either to the
+// Prometheus data source that a rule targets or to the remote write endpoint of the recording rules settings.
+type PrometheusWriter struct {
+	cfg         setting.UnifiedAlertingRecordingRuleSettings
+	datasources DatasourceService
+	client      *http.Client
+	log         log.Logger
+}
+
+func NewPrometheusWriter(cfg setting.UnifiedAlertingRecordingRuleSettings, datasources DatasourceService) *PrometheusWriter {
+	return &PrometheusWriter{
+		cfg:         cfg,
+		datasources: datasources,
+		client:      &http.Client{Timeout: cfg.Timeout},
+		log:         log.New("ngalert.writer"),
+	}
+}
+
+type target struct {
+	url      string
+	username string
+	password string
+}
+
+// Write writes the result of a recording rule evaluated at t as series named after the metric of the rule.
+// Nothing is written if the result has no values.
+func (w *PrometheusWriter) Write(ctx context.Context, rule *ngmodels.AlertRule, t time.Time, frames data.Frames) error {
+	series, err := TimeSeriesFromResult(rule.Record.Metric, rule.Labels, t, frames)
+	if err != nil {
+		return err
+	}
+	if len(series) == 0 {
+		return nil
+	}
+
+	tgt, err := w.target(ctx, rule.OrgID, rule.Record.TargetDatasourceUID)
+	if err != nil {
+		return err
+	}
+	body, err := remotewrite.TimeSeriesToBytes(series)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tgt.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if tgt.username != "" {
+		req.SetBasicAuth(tgt.username, tgt.password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send remote write request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("remote write endpoint responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	w.log.FromContext(ctx).Debug("Recording rule result written", "series", len(series))
+	return nil
+}
+
+func (w *PrometheusWriter) target(ctx context.Context, orgID int64, datasourceUID string) (target, error) {
+	if datasourceUID == "" {
+		if w.cfg.URL == "" {
+			return target{}, ErrNoTarget
+		}
+		return target{url: w.cfg.URL, username: w.cfg.BasicAuthUsername, password: w.cfg.BasicAuthPassword}, nil
+	}
+
+	query := &datasources.GetDataSourceQuery{Uid: datasourceUID, OrgId: orgID}
+	if err := w.datasources.GetDataSource(ctx, query); err != nil {
+		return target{}, fmt.Errorf("failed to get the target data source '%s': %w", datasourceUID, err)
+	}
+	ds := query.Result
+	if ds.Type != datasources.DS_PROMETHEUS {
+		return target{}, fmt.Errorf("target data source '%s' is of type '%s', only Prometheus data sources are supported", datasourceUID, ds.Type)
+	}
+	u, err := url.Parse(ds.Url)
+	if err != nil {
+		return target{}, fmt.Errorf("invalid url of the target data source '%s': %w", datasourceUID, err)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + remoteWritePath
+	result := target{url: u.String()}
+	if ds.BasicAuth {
+		result.username = ds.BasicAuthUser
+		result.password, err = w.datasources.DecryptedBasicAuthPassword(ctx, ds)
+		if err != nil {
+			return target{}, fmt.Errorf("failed to decrypt the basic authentication password of the target data source '%s': %w", datasourceUID, err)
+		}
+	}
+	return result, nil
+}
+
+// TimeSeriesFromResult converts the result of a query or expression to series with one sample at t.
+// Like the condition of an alert rule, the result must be reduced to at most one number per series.
+// The series are labelled with the labels of the numbers and the given labels, which take precedence.
+func TimeSeriesFromResult(metric string, labels map[string]string, t time.Time, frames data.Frames) ([]prompb.TimeSeries, error) {
+	samples := make([]*data.Frame, 0, len(frames))
+	for _, frame := range frames {
+		if len(frame.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)) > 0 {
+			return nil, fmt.Errorf("invalid format of result of '%s': looks like time series data, only reduced data can be recorded", frame.RefID)
+		}
+		rowLen, err := frame.RowLen()
+		if err != nil {
+			return nil, fmt.Errorf("invalid format of result of '%s': %w", frame.RefID, err)
+		}
+		if rowLen == 0 {
+			continue
+		}
+		if rowLen > 1 {
+			return nil, fmt.Errorf("invalid format of result of '%s': unexpected row length: %d instead of 0 or 1", frame.RefID, rowLen)
+		}
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			value, err := field.NullableFloatAt(0)
+			if err != nil {
+				return nil, fmt.Errorf("invalid format of result of '%s': %w", frame.RefID, err)
+			}
+			if value == nil {
+				continue
+			}
+			samples = append(samples, data.NewFrame("",
+				data.NewField("time", nil, []time.Time{t}),
+				data.NewField("value", sampleLabels(field.Labels, labels), []float64{*value}),
+			))
+		}
+	}
+	series := remotewrite.TimeSeriesFromFramesWithName(metric, samples...)
+	// The remote write protocol expects the labels of a series to be sorted by name.
+	for _, s := range series {
+		sort.Slice(s.Labels, func(i, j int) bool {
+			return s.Labels[i].Name < s.Labels[j].Name
+		})
+	}
+	return series, nil
+}
+
+func sampleLabels(fieldLabels data.Labels, ruleLabels map[string]string) data.Labels {
+	result := make(data.Labels, len(fieldLabels)+len(ruleLabels))
+	for k, v := range fieldLabels {
+		result[k] = v
+	}
+	for k, v := range ruleLabels {
+		result[k] = v
+	}
+	// The metric name is the name of the recording rule.
+	delete(result, "__name__")
+	return result
+}
@@ -0,0 +1,180 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestTimeSeriesFromResult(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	t.Run("converts numbers to samples at the evaluation time", func(t *testing.T) {
+		frames := data.Frames{
+			data.NewFrame("", data.NewField("B", data.Labels{"host": "a", "__name__": "up"}, []*float64{floatPtr(1.5)})),
+			data.NewFrame("", data.NewField("B", data.Labels{"host": "b", "team": "other"}, []*float64{floatPtr(2.0)})),
+			data.NewFrame("", data.NewField("B", data.Labels{"host": "c"}, []*float64{nil})),
+		}
+
+		series, err := TimeSeriesFromResult("host:up:avg", map[string]string{"team": "infra"}, now, frames)
+		require.NoError(t, err)
+
+		require.Len(t, series, 2)
+		require.Equal(t, []prompb.Label{{Name: "__name__", Value: "host:up:avg"}, {Name: "host", Value: "a"}, {Name: "team", Value: "infra"}}, series[0].Labels)
+		require.Equal(t, []prompb.Sample{{Timestamp: 1000000, Value: 1.5}}, series[0].Samples)
+		require.Equal(t, []prompb.Label{{Name: "__name__", Value: "host:up:avg"}, {Name: "host", Value: "b"}, {Name: "team", Value: "infra"}}, series[1].Labels)
+		require.Equal(t, []prompb.Sample{{Timestamp: 1000000, Value: 2.0}}, series[1].Samples)
+	})
+
+	t.Run("returns nothing if there is no data", func(t *testing.T) {
+		series, err := TimeSeriesFromResult("metric", nil, now, data.Frames{data.NewFrame("", data.NewField("B", nil, []*float64{}))})
+		require.NoError(t, err)
+		require.Empty(t, series)
+	})
+
+	t.Run("fails if the result is a time series", func(t *testing.T) {
+		frame := data.NewFrame("",
+			data.NewField("time", nil, []time.Time{now, now.Add(time.Second)}),
+			data.NewField("value", nil, []float64{1, 2}),
+		)
+		frame.RefID = "A"
+		_, err := TimeSeriesFromResult("metric", nil, now, data.Frames{frame})
+		require.ErrorContains(t, err, "only reduced data can be recorded")
+	})
+
+	t.Run("fails if there are several rows", func(t *testing.T) {
+		_, err := TimeSeriesFromResult("metric", nil, now, data.Frames{data.NewFrame("", data.NewField("B", nil, []float64{1, 2}))})
+		require.ErrorContains(t, err, "unexpected row length")
+	})
+}
+
+func TestPrometheusWriter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	frames := data.Frames{data.NewFrame("", data.NewField("B", data.Labels{"host": "a"}, []*float64{floatPtr(3.0)}))}
+	rule := &ngmodels.AlertRule{
+		OrgID:  1,
+		Labels: map[string]string{"team": "infra"},
+		Record: ngmodels.Record{Metric: "host:up", From: "B"},
+	}
+
+	type request struct {
+		path     string
+		user     string
+		password string
+		series   []prompb.TimeSeries
+	}
+	newServer := func(t *testing.T, status int) (*httptest.Server, *request) {
+		t.Helper()
+		received := &request{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received.path = r.URL.Path
+			received.user, received.password, _ = r.BasicAuth()
+			require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			decoded, err := snappy.Decode(nil, b)
+			require.NoError(t, err)
+			var req prompb.WriteRequest
+			require.NoError(t, proto.Unmarshal(decoded, &req))
+			received.series = req.Timeseries
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(server.Close)
+		return server, received
+	}
+
+	t.Run("writes to the configured remote write endpoint", func(t *testing.T) {
+		server, received := newServer(t, http.StatusNoContent)
+		w := NewPrometheusWriter(setting.UnifiedAlertingRecordingRuleSettings{
+			URL:               server.URL + "/api/v1/push",
+			BasicAuthUsername: "user",
+			BasicAuthPassword: "password",
+			Timeout:           time.Second,
+		}, &fakeDatasourceService{})
+
+		require.NoError(t, w.Write(context.Background(), rule, now, frames))
+
+		require.Equal(t, "/api/v1/push", received.path)
+		require.Equal(t, "user", received.user)
+		require.Equal(t, "password", received.password)
+		require.Len(t, received.series, 1)
+		require.Equal(t, []prompb.Label{{Name: "__name__", Value: "host:up"}, {Name: "host", Value: "a"}, {Name: "team", Value: "infra"}}, received.series[0].Labels)
+		require.Equal(t, 3.0, received.series[0].Samples[0].Value)
+	})
+
+	t.Run("writes to the target data source", func(t *testing.T) {
+		server, received := newServer(t, http.StatusOK)
+		ds := &fakeDatasourceService{ds: &datasources.DataSource{
+			Uid:           "prom",
+			OrgId:         1,
+			Type:          datasources.DS_PROMETHEUS,
+			Url:           server.URL + "/prometheus/",
+			BasicAuth:     true,
+			BasicAuthUser: "ds-user",
+		}, password: "ds-password"}
+		w := NewPrometheusWriter(setting.UnifiedAlertingRecordingRuleSettings{URL: "http://unused", Timeout: time.Second}, ds)
+		targeted := ngmodels.CopyRule(rule)
+		targeted.Record.TargetDatasourceUID = "prom"
+
+		require.NoError(t, w.Write(context.Background(), targeted, now, frames))
+
+		require.Equal(t, "/prometheus/api/v1/write", received.path)
+		require.Equal(t, "ds-user", received.user)
+		require.Equal(t, "ds-password", received.password)
+		require.Len(t, received.series, 1)
+	})
+
+	t.Run("fails if the target data source is not Prometheus", func(t *testing.T) {
+		ds := &fakeDatasourceService{ds: &datasources.DataSource{Uid: "loki", OrgId: 1, Type: datasources.DS_LOKI}}
+		w := NewPrometheusWriter(setting.UnifiedAlertingRecordingRuleSettings{Timeout: time.Second}, ds)
+		targeted := ngmodels.CopyRule(rule)
+		targeted.Record.TargetDatasourceUID = "loki"
+
+		require.ErrorContains(t, w.Write(context.Background(), targeted, now, frames), "only Prometheus data sources are supported")
+	})
+
+	t.Run("fails if there is no target", func(t *testing.T) {
+		w := NewPrometheusWriter(setting.UnifiedAlertingRecordingRuleSettings{Timeout: time.Second}, &fakeDatasourceService{})
+		require.ErrorIs(t, w.Write(context.Background(), rule, now, frames), ErrNoTarget)
+	})
+
+	t.Run("fails if the endpoint rejects the write", func(t *testing.T) {
+		server, _ := newServer(t, http.StatusBadRequest)
+		w := NewPrometheusWriter(setting.UnifiedAlertingRecordingRuleSettings{URL: server.URL, Timeout: time.Second}, &fakeDatasourceService{})
+		require.ErrorContains(t, w.Write(context.Background(), rule, now, frames), "status 400")
+	})
+}
+
+type fakeDatasourceService struct {
+	ds       *datasources.DataSource
+	password string
+}
+
+func (f *fakeDatasourceService) GetDataSource(_ context.Context, query *datasources.GetDataSourceQuery) error {
+	if f.ds == nil || f.ds.Uid != query.Uid || f.ds.OrgId != query.OrgId {
+		return datasources.ErrDataSourceNotFound
+	}
+	query.Result = f.ds
+	return nil
+}
+
+func (f *fakeDatasourceService) DecryptedBasicAuthPassword(_ context.Context, _ *datasources.DataSource) (string, error) {
+	return f.password, nil
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
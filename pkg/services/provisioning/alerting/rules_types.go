@@ -80,6 +80,19 @@ type AlertRuleV1 struct {
 	For          values.StringValue    `json:"for" yaml:"for"`
 	Annotations  values.StringMapValue `json:"annotations" yaml:"annotations"`
 	Labels       values.StringMapValue `json:"labels" yaml:"labels"`
+	Record       *RecordV1             `json:"record" yaml:"record"`
+	Dependencies []DependencyV1        `json:"dependencies" yaml:"dependencies"`
+}
+
+type RecordV1 struct {
+	Metric              values.StringValue `json:"metric" yaml:"metric"`
+	From                values.StringValue `json:"from" yaml:"from"`
+	TargetDatasourceUID values.StringValue `json:"targetDatasourceUid" yaml:"targetDatasourceUid"`
+}
+
+type DependencyV1 struct {
+	RuleUID values.StringValue   `json:"ruleUid" yaml:"ruleUid"`
+	Equal   []values.StringValue `json:"equal" yaml:"equal"`
 }
 
 func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
@@ -121,9 +134,31 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 	}
 	alertRule.NoDataState = noDataState
 	alertRule.Condition = rule.Condition.Value()
-	if alertRule.Condition == "" {
+	if rule.Record != nil {
+		alertRule.Record = models.Record{
+			Metric:              rule.Record.Metric.Value(),
+			From:                rule.Record.From.Value(),
+			TargetDatasourceUID: rule.Record.TargetDatasourceUID.Value(),
+		}
+		if err := alertRule.Record.Validate(); err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		if alertRule.Condition != "" {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: recording rule cannot have a condition", alertRule.Title)
+		}
+	} else if alertRule.Condition == "" {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: no condition set", alertRule.Title)
 	}
+	for _, d := range rule.Dependencies {
+		dependency := models.RuleDependency{RuleUID: d.RuleUID.Value()}
+		for _, name := range d.Equal {
+			dependency.Equal = append(dependency.Equal, name.Value())
+		}
+		if err := dependency.Validate(); err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		alertRule.Dependencies = append(alertRule.Dependencies, dependency)
+	}
 	alertRule.Annotations = rule.Annotations.Raw
 	alertRule.Labels = rule.Labels.Value()
 	for _, queryV1 := range rule.Data {
@@ -130,6 +130,47 @@ func TestRules(t *testing.T) {
 		_, err := rule.mapToModel(1)
 		require.Error(t, err)
 	})
+	t.Run("a recording rule should not need a condition", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Condition = values.StringValue{}
+		rule.Record = validRecordV1(t)
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, models.Record{Metric: "test_metric", From: "A"}, ruleMapped.Record)
+	})
+	t.Run("a recording rule with a condition should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Record = validRecordV1(t)
+		_, err := rule.mapToModel(1)
+		require.ErrorContains(t, err, "recording rule cannot have a condition")
+	})
+	t.Run("a recording rule with an invalid metric name should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Condition = values.StringValue{}
+		rule.Record = validRecordV1(t)
+		rule.Record.Metric = values.StringValue{}
+		_, err := rule.mapToModel(1)
+		require.Error(t, err)
+	})
+	t.Run("a rule with dependencies should map them", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Dependencies = []DependencyV1{validDependencyV1(t, "dependency-uid", "instance")}
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, []models.RuleDependency{{RuleUID: "dependency-uid", Equal: []string{"instance"}}}, ruleMapped.Dependencies)
+	})
+	t.Run("a rule with a dependency without rule UID should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Dependencies = []DependencyV1{validDependencyV1(t, "", "instance")}
+		_, err := rule.mapToModel(1)
+		require.Error(t, err)
+	})
+	t.Run("a rule with a dependency on an invalid label name should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Dependencies = []DependencyV1{validDependencyV1(t, "dependency-uid", "not-a-label")}
+		_, err := rule.mapToModel(1)
+		require.Error(t, err)
+	})
 	t.Run("a rule with out data should error", func(t *testing.T) {
 		rule := validRuleV1(t)
 		rule.Data = []QueryV1{}
@@ -237,3 +278,25 @@ func validRuleV1(t *testing.T) AlertRuleV1 {
 		Data:      []QueryV1{{}},
 	}
 }
+
+func validRecordV1(t *testing.T) *RecordV1 {
+	t.Helper()
+	var metric, from values.StringValue
+	require.NoError(t, yaml.Unmarshal([]byte("test_metric"), &metric))
+	require.NoError(t, yaml.Unmarshal([]byte("A"), &from))
+	return &RecordV1{Metric: metric, From: from}
+}
+
+func validDependencyV1(t *testing.T, ruleUID string, equal ...string) DependencyV1 {
+	t.Helper()
+	var dependency DependencyV1
+	if ruleUID != "" {
+		require.NoError(t, yaml.Unmarshal([]byte(ruleUID), &dependency.RuleUID))
+	}
+	for _, name := range equal {
+		var value values.StringValue
+		require.NoError(t, yaml.Unmarshal([]byte(name), &value))
+		dependency.Equal = append(dependency.Equal, value)
+	}
+	return dependency
+}
@@ -455,6 +455,16 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LivePipelineStorage is a type of storage for Live pipeline channel rules
+	// and write configs, "file" or "database".
+	LivePipelineStorage string
+	// LiveManagedStreamHistoryMaxFrames is a maximum number of frames kept in
+	// the history of each managed stream channel. 0 means no limit.
+	LiveManagedStreamHistoryMaxFrames int
+	// LiveManagedStreamHistoryMaxAge is a maximum age of frames kept in the
+	// history of each managed stream channel. 0 means no limit. History is
+	// disabled if both limits are 0.
+	LiveManagedStreamHistoryMaxAge time.Duration
 
 	// Grafana.com URL, used for OAuth redirect.
 	GrafanaComURL string
@@ -1764,6 +1774,24 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		return fmt.Errorf("unsupported live HA engine type: %s", cfg.LiveHAEngine)
 	}
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
+	cfg.LivePipelineStorage = section.Key("pipeline_storage").MustString("file")
+	switch cfg.LivePipelineStorage {
+	case "file", "database":
+	default:
+		return fmt.Errorf("unsupported live pipeline storage type: %s", cfg.LivePipelineStorage)
+	}
+	cfg.LiveManagedStreamHistoryMaxFrames = section.Key("managed_stream_history_max_frames").MustInt(0)
+	if cfg.LiveManagedStreamHistoryMaxFrames < 0 {
+		return fmt.Errorf("unexpected value %d for [live] managed_stream_history_max_frames", cfg.LiveManagedStreamHistoryMaxFrames)
+	}
+	historyMaxAge, err := gtime.ParseDuration(section.Key("managed_stream_history_max_age").MustString("0"))
+	if err != nil {
+		return fmt.Errorf("invalid value for [live] managed_stream_history_max_age: %w", err)
+	}
+	if historyMaxAge < 0 {
+		return fmt.Errorf("unexpected value %s for [live] managed_stream_history_max_age", historyMaxAge)
+	}
+	cfg.LiveManagedStreamHistoryMaxAge = historyMaxAge
 
 	var originPatterns []string
 	allowedOrigins := section.Key("allowed_origins").MustString("")
@@ -1774,7 +1802,7 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		}
 		originPatterns = append(originPatterns, originPattern)
 	}
-	_, err := GetAllowedOriginGlobs(originPatterns)
+	_, err = GetAllowedOriginGlobs(originPatterns)
 	if err != nil {
 		return err
 	}
@@ -61,6 +61,11 @@ const (
 	// DefaultRuleEvaluationInterval indicates a default interval of for how long a rule should be evaluated to change state from Pending to Alerting
 	DefaultRuleEvaluationInterval = SchedulerBaseInterval * 6 // == 60 seconds
 	stateHistoryDefaultEnabled    = true
+	// stateHistoryDefaultSQLRetention is how long the sql state history backend keeps state transitions by default.
+	stateHistoryDefaultSQLRetention = 30 * 24 * time.Hour
+	recordingRulesDefaultTimeout    = 10 * time.Second
+	// notificationDeliveryLogDefaultRetention is how long the attempts to deliver notifications are kept by default.
+	notificationDeliveryLogDefaultRetention = 7 * 24 * time.Hour
 )
 
 type UnifiedAlertingSettings struct {
@@ -72,6 +77,7 @@ type UnifiedAlertingSettings struct {
 	HAPeerTimeout                  time.Duration
 	HAGossipInterval               time.Duration
 	HAPushPullInterval             time.Duration
+	HAEvaluationSharding           bool
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
 	EvaluationTimeout              time.Duration
@@ -87,6 +93,8 @@ type UnifiedAlertingSettings struct {
 	Screenshots                   UnifiedAlertingScreenshotSettings
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
 	StateHistory                  UnifiedAlertingStateHistorySettings
+	RecordingRules                UnifiedAlertingRecordingRuleSettings
+	NotificationDeliveryLog       UnifiedAlertingNotificationDeliveryLogSettings
 }
 
 type UnifiedAlertingScreenshotSettings struct {
@@ -103,6 +111,23 @@ type UnifiedAlertingReservedLabelSettings struct {
 type UnifiedAlertingStateHistorySettings struct {
 	Enabled bool
 	Backend string
+	// SQLRetention is how long state transitions are kept by the sql backend. Zero keeps them forever.
+	SQLRetention time.Duration
+}
+
+type UnifiedAlertingRecordingRuleSettings struct {
+	// URL is the Prometheus remote write endpoint that recording rules without a target data source write to.
+	URL               string
+	BasicAuthUsername string
+	BasicAuthPassword string
+	// Timeout is the timeout of a write request.
+	Timeout time.Duration
+}
+
+type UnifiedAlertingNotificationDeliveryLogSettings struct {
+	Enabled bool
+	// Retention is how long the attempts to deliver notifications are kept. Zero keeps them forever.
+	Retention time.Duration
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -222,6 +247,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 			uaCfg.HAPeers = append(uaCfg.HAPeers, peer)
 		}
 	}
+	uaCfg.HAEvaluationSharding = ua.Key("ha_evaluation_sharding").MustBool(false)
 
 	// TODO load from ini file
 	uaCfg.DefaultConfiguration = alertmanagerDefaultConfiguration
@@ -316,8 +342,34 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		Enabled: stateHistory.Key("enabled").MustBool(stateHistoryDefaultEnabled),
 		Backend: stateHistory.Key("backend").MustString("annotations"),
 	}
+	uaCfgStateHistory.SQLRetention, err = gtime.ParseDuration(valueAsString(stateHistory, "sql_retention", stateHistoryDefaultSQLRetention.String()))
+	if err != nil {
+		return err
+	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
+	recordingRules := iniFile.Section("unified_alerting.recording_rules")
+	uaCfgRecordingRules := UnifiedAlertingRecordingRuleSettings{
+		URL:               recordingRules.Key("url").MustString(""),
+		BasicAuthUsername: recordingRules.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword: recordingRules.Key("basic_auth_password").MustString(""),
+	}
+	uaCfgRecordingRules.Timeout, err = gtime.ParseDuration(valueAsString(recordingRules, "timeout", recordingRulesDefaultTimeout.String()))
+	if err != nil {
+		return err
+	}
+	uaCfg.RecordingRules = uaCfgRecordingRules
+
+	deliveryLog := iniFile.Section("unified_alerting.notification_delivery_log")
+	uaCfgDeliveryLog := UnifiedAlertingNotificationDeliveryLogSettings{
+		Enabled: deliveryLog.Key("enabled").MustBool(false),
+	}
+	uaCfgDeliveryLog.Retention, err = gtime.ParseDuration(valueAsString(deliveryLog, "retention", notificationDeliveryLogDefaultRetention.String()))
+	if err != nil {
+		return err
+	}
+	uaCfg.NotificationDeliveryLog = uaCfgDeliveryLog
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
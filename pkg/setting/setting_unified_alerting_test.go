@@ -25,6 +25,11 @@ func TestCfg_ReadUnifiedAlertingSettings(t *testing.T) {
 		require.Len(t, cfg.UnifiedAlerting.HAPeers, 0)
 		require.Equal(t, 200*time.Millisecond, cfg.UnifiedAlerting.HAGossipInterval)
 		require.Equal(t, time.Minute, cfg.UnifiedAlerting.HAPushPullInterval)
+		require.False(t, cfg.UnifiedAlerting.HAEvaluationSharding)
+		require.Equal(t, "", cfg.UnifiedAlerting.RecordingRules.URL)
+		require.Equal(t, 10*time.Second, cfg.UnifiedAlerting.RecordingRules.Timeout)
+		require.False(t, cfg.UnifiedAlerting.NotificationDeliveryLog.Enabled)
+		require.Equal(t, 7*24*time.Hour, cfg.UnifiedAlerting.NotificationDeliveryLog.Retention)
 	}
 
 	// With peers set, it correctly parses them.
@@ -3,7 +3,7 @@ import React, { ChangeEvent, FC } from 'react';
 import { SelectableValue } from '@grafana/data';
 import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
 
-import { downsamplingTypes, ExpressionQuery, upsamplingTypes } from '../types';
+import { downsamplingTypes, ExpressionQuery, resampleAlignments, upsamplingTypes } from '../types';
 
 interface Props {
   refIds: Array<SelectableValue<string>>;
@@ -15,6 +15,7 @@ interface Props {
 export const Resample: FC<Props> = ({ labelWidth = 'auto', onChange, refIds, query }) => {
   const downsampler = downsamplingTypes.find((o) => o.value === query.downsampler);
   const upsampler = upsamplingTypes.find((o) => o.value === query.upsampler);
+  const alignment = resampleAlignments.find((o) => o.value === (query.alignment || 'start'));
 
   const onWindowChange = (event: ChangeEvent<HTMLInputElement>) => {
     onChange({ ...query, window: event.target.value });
@@ -32,6 +33,10 @@ export const Resample: FC<Props> = ({ labelWidth = 'auto', onChange, refIds, que
     onChange({ ...query, upsampler: value.value });
   };
 
+  const onSelectAlignment = (value: SelectableValue<string>) => {
+    onChange({ ...query, alignment: value.value });
+  };
+
   return (
     <>
       <InlineFieldRow>
@@ -49,6 +54,9 @@ export const Resample: FC<Props> = ({ labelWidth = 'auto', onChange, refIds, que
         <InlineField label="Upsample">
           <Select options={upsamplingTypes} value={upsampler} onChange={onSelectUpsampler} width={25} />
         </InlineField>
+        <InlineField label="Align to">
+          <Select options={resampleAlignments} value={alignment} onChange={onSelectAlignment} width={20} />
+        </InlineField>
       </InlineFieldRow>
     </>
   );
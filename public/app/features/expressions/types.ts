@@ -48,6 +48,15 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
   { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: ReducerID.first, label: 'First', description: 'Get the first value' },
+  { value: 'count_non_null', label: 'Count non-null', description: 'Get the number of values that are not null' },
+  { value: 'median', label: 'Median', description: 'Get the median value' },
+  { value: 'p90', label: '90th percentile', description: 'Get the 90th percentile' },
+  { value: 'p95', label: '95th percentile', description: 'Get the 95th percentile' },
+  { value: 'p99', label: '99th percentile', description: 'Get the 99th percentile' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Get the standard deviation of the values' },
+  { value: ReducerID.range, label: 'Range', description: 'Get the difference between the maximum and minimum value' },
+  { value: ReducerID.diff, label: 'Difference', description: 'Get the difference between the last and first value' },
 ];
 
 export enum ReducerMode {
@@ -80,12 +89,26 @@ export const downsamplingTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.max, label: 'Max', description: 'Fill with the maximum value' },
   { value: ReducerID.mean, label: 'Mean', description: 'Fill with the average value' },
   { value: ReducerID.sum, label: 'Sum', description: 'Fill with the sum of all values' },
+  { value: ReducerID.first, label: 'First', description: 'Fill with the first value' },
+  { value: ReducerID.count, label: 'Count', description: 'Fill with the number of values' },
+  { value: 'median', label: 'Median', description: 'Fill with the median value' },
 ];
 
 export const upsamplingTypes: Array<SelectableValue<string>> = [
   { value: 'pad', label: 'pad', description: 'fill with the last known value' },
   { value: 'backfilling', label: 'backfilling', description: 'fill with the next known value' },
   { value: 'fillna', label: 'fillna', description: 'Fill with NaNs' },
+  { value: 'linear', label: 'linear', description: 'fill with a linear interpolation of the surrounding values' },
+  { value: 'nearest', label: 'nearest', description: 'fill with the closest known value' },
+];
+
+export const resampleAlignments: Array<SelectableValue<string>> = [
+  { value: 'start', label: 'Query start', description: 'Align to the start of the time range' },
+  {
+    value: 'epoch',
+    label: 'Epoch',
+    description: 'Align to multiples of the interval, so series line up across queries',
+  },
 ];
 
 export const thresholdFunctions: Array<SelectableValue<EvalFunction>> = [
@@ -106,6 +129,7 @@ export interface ExpressionQuery extends DataQuery {
   window?: string;
   downsampler?: string;
   upsampler?: string;
+  alignment?: string;
   conditions?: ClassicCondition[];
   settings?: ExpressionQuerySettings;
 }
@@ -13,6 +13,13 @@ export interface ChangeLogOutputConfig {
   fieldName: string;
   channel: string;
 }
+export interface ArchiveOutputConfig {
+  directory?: string;
+  maxFileSizeBytes?: number;
+  rotateSeconds?: number;
+  retentionSeconds?: number;
+  maxFiles?: number;
+}
 export interface RemoteWriteOutputConfig {
   uid: string;
   sampleMilliseconds: number;
@@ -56,6 +63,7 @@ export interface FrameOutputterConfig {
   remoteWrite?: RemoteWriteOutputConfig;
   loki?: LokiOutputConfig;
   changeLog?: ChangeLogOutputConfig;
+  archive?: ArchiveOutputConfig;
 }
 export interface MultipleFrameProcessorConfig {
   processors: FrameProcessorConfig[];
@@ -66,11 +74,27 @@ export interface KeepFieldsFrameProcessorConfig {
 export interface DropFieldsFrameProcessorConfig {
   fieldNames: string[];
 }
+export interface ComputedFieldConfig {
+  name: string;
+  expression: string;
+  config?: FieldConfig;
+}
+export interface ComputeFieldsFrameProcessorConfig {
+  fields: ComputedFieldConfig[];
+}
+export interface WindowFrameProcessorConfig {
+  sizeSeconds: number;
+  slideSeconds?: number;
+  timeField?: string;
+  aggregations?: string[];
+}
 export interface FrameProcessorConfig {
   type: Omit<keyof FrameProcessorConfig, 'type'>;
   dropFields?: DropFieldsFrameProcessorConfig;
   keepFields?: KeepFieldsFrameProcessorConfig;
   multiple?: MultipleFrameProcessorConfig;
+  window?: WindowFrameProcessorConfig;
+  computeFields?: ComputeFieldsFrameProcessorConfig;
 }
 export interface JsonFrameConverterConfig {}
 export interface AutoInfluxConverterConfig {
@@ -93,12 +117,21 @@ export interface Field {
 export interface AutoJsonConverterConfig {
   fieldTips?: { [key: string]: Field };
 }
+export interface AutoPrometheusConverterConfig {
+  frameFormat?: string;
+}
+export interface AutoOTLPConverterConfig {
+  frameFormat?: string;
+  resourceAttributes?: string[];
+}
 export interface ConverterConfig {
   type: Omit<keyof ConverterConfig, 'type'>;
   jsonAuto?: AutoJsonConverterConfig;
   jsonExact?: ExactJsonConverterConfig;
   influxAuto?: AutoInfluxConverterConfig;
   jsonFrame?: JsonFrameConverterConfig;
+  prometheusAuto?: AutoPrometheusConverterConfig;
+  otlpAuto?: AutoOTLPConverterConfig;
 }
 export interface LokiOutputConfig {
   uid: string;